@@ -44,6 +44,8 @@ import (
 type Cli interface {
 	Out() io.Writer
 	ExecuteTask(startMessage, errorMessage, successMessage string, function func() error) error
+	ExecuteTaskWithProgress(startMessage, errorMessage, successMessage string,
+		function func(progress func(transferred, total int64)) error) error
 	FileSystem() FileSystemManager
 	BalExecutor() ballerina.BalExecutor
 	KubeCli() kubernetes.KubeCli
@@ -82,6 +84,12 @@ func NewCelleryCli(opts ...func(*CelleryCli)) *CelleryCli {
 	return cli
 }
 
+func SetKubeCli(kubecli kubernetes.KubeCli) func(*CelleryCli) {
+	return func(cli *CelleryCli) {
+		cli.kubecli = kubecli
+	}
+}
+
 func SetRegistry(registry registry.Registry) func(*CelleryCli) {
 	return func(cli *CelleryCli) {
 		cli.registry = registry
@@ -138,6 +146,26 @@ func (cli *CelleryCli) ExecuteTask(startMessage, errorMessage, successMessage st
 	return nil
 }
 
+// ExecuteTaskWithProgress executes a function which reports its progress.
+// The progress reported by the function is shown next to the start message of the spinner.
+func (cli *CelleryCli) ExecuteTaskWithProgress(startMessage, errorMessage, successMessage string,
+	function func(progress func(transferred, total int64)) error) error {
+	spinner := util.StartNewSpinner(startMessage)
+	err := function(spinner.SetProgress)
+	if err != nil {
+		spinner.Stop(false)
+		if errorMessage != "" {
+			fmt.Fprintln(cli.Out(), errorMessage)
+		}
+		return err
+	}
+	spinner.Stop(true)
+	if successMessage != "" {
+		fmt.Fprintln(cli.Out(), successMessage)
+	}
+	return nil
+}
+
 // FileSystem returns a FileSystemManager instance.
 func (cli *CelleryCli) FileSystem() FileSystemManager {
 	return cli.fileSystemManager
@@ -31,6 +31,7 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/ballerina"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
 	"cellery.io/cellery/components/cli/pkg/registry"
 	"cellery.io/cellery/components/cli/pkg/registry/credentials"
 	celleryRuntime "cellery.io/cellery/components/cli/pkg/runtime"
@@ -43,6 +44,12 @@ func newCliCommand(cli cli.Cli) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cellery <command>",
 		Short: "Manage immutable cell based applications",
+		Long: "Manage immutable cell based applications.\n\n" +
+			"The cluster is accessed using kubectl. If kubectl is not installed or if CELLERY_KUBE_CLIENT is set to " +
+			"api, the Kubernetes API server is accessed directly using the current context of the kubeconfig. " +
+			"This client does not support exec credential plugins and does not refresh the tokens of auth " +
+			"providers such as gcp and oidc, hence an expired token should be renewed by running a kubectl " +
+			"command. Set CELLERY_KUBE_CLIENT to kubectl to always use kubectl.",
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			cli.KubeCli().SetVerboseMode(verboseMode)
 			if insecureMode {
@@ -57,16 +64,25 @@ func newCliCommand(cli cli.Cli) *cobra.Command {
 		newVersionCommand(cli),
 		newInitCommand(cli),
 		newRunCommand(cli),
+		newApplyCommand(cli),
 		newTerminateCommand(cli),
 		newListCommand(cli),
 		newDescribeCommand(cli),
 		newStatusCommand(cli),
 		newLogsCommand(cli),
+		newExecCommand(cli),
+		newPortForwardCommand(cli),
+		newTopCommand(cli),
+		newScaleCommand(cli),
+		newRestartCommand(cli),
 		newLoginCommand(cli),
 		newLogoutCommand(cli),
 		newPushCommand(cli),
 		newPullCommand(cli),
+		newSearchCommand(cli),
+		newSignCommand(cli),
 		newSetupCommand(cli),
+		newSystemCommand(cli),
 		newExtractResourcesCommand(cli),
 		newInspectCommand(cli),
 		newViewCommand(cli),
@@ -76,6 +92,10 @@ func newCliCommand(cli cli.Cli) *cobra.Command {
 		newApplyPolicyCommand(cli),
 		newPatchComponentsCommand(cli),
 		newRouteTrafficCommand(cli),
+		newRolloutCommand(cli),
+		newRoutesCommand(cli),
+		newInjectFaultCommand(cli),
+		newPromoteCommand(cli),
 		newSetCommand(cli),
 		newDesignerCommand(cli),
 	)
@@ -108,8 +128,24 @@ func main() {
 	}
 	credReader := credentials.NewCelleryCredReader()
 	runtime := celleryRuntime.NewCelleryRuntime()
+	// Talk to the API server directly if requested or if kubectl is not installed.
+	var kubeCli kubernetes.KubeCli = kubernetes.NewCelleryKubeCli()
+	switch os.Getenv(constants.KubeClientEnvVar) {
+	case constants.KubeClientApi:
+		kubeCli = kubernetes.NewCelleryApiKubeCli()
+	case constants.KubeClientKubectl:
+	case "":
+		if !util.IsCommandAvailable(constants.KubeCtl) {
+			kubeCli = kubernetes.NewCelleryApiKubeCli()
+		}
+	default:
+		util.ExitWithErrorMessage("Error configuring kubernetes client", fmt.Errorf("invalid value %s for %s, "+
+			"expected %s or %s", os.Getenv(constants.KubeClientEnvVar), constants.KubeClientEnvVar,
+			constants.KubeClientKubectl, constants.KubeClientApi))
+	}
 	// Initialize the Cellery CLI.
 	celleryCli := cli.NewCelleryCli(
+		cli.SetKubeCli(kubeCli),
 		cli.SetRegistry(registry.NewCelleryRegistry()),
 		cli.SetFileSystem(fileSystem),
 		cli.SetBallerinaExecutor(ballerinaExecutor),
@@ -27,10 +27,12 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/image"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newDescribeCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
 	cmd := &cobra.Command{
 		Use:     "describe <instance-name|cell-image-name>",
 		Short:   "Describes a cell image",
@@ -47,15 +49,17 @@ func newDescribeCommand(cli cli.Cli) *cobra.Command {
 					return fmt.Errorf("expects a valid cell instance name or a cell image name, received %s", args[0])
 				}
 			}
-			return nil
+			return output.ValidateFormat(outputFormat)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image.RunDescribe(cli, args[0]); err != nil {
+			if err := image.RunDescribe(cli, args[0], outputFormat); err != nil {
 				util.ExitWithErrorMessage("Cellery describe command failed", err)
 			}
 		},
 		Example: "  cellery describe employee\n" +
-			"  cellery describe cellery-samples/employee:1.0.0",
+			"  cellery describe cellery-samples/employee:1.0.0\n" +
+			"  cellery describe employee -o json",
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
 	return cmd
 }
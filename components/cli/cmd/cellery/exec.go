@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newExecCommand(cli cli.Cli) *cobra.Command {
+	var tty bool
+	cmd := &cobra.Command{
+		Use:   "exec <instance-name> <component-name> -- <command> [args...]",
+		Short: "Execute a command in a component of a cell/composite instance",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 2 || len(args) < 3 {
+				return fmt.Errorf("expects an instance, a component and a command given after --")
+			}
+			for _, name := range args[:2] {
+				isValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), name)
+				if err != nil || !isValid {
+					return fmt.Errorf("expects valid instance and component names, received %s", name)
+				}
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunExec(cli, args[0], args[1], tty, args[2:]); err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to execute the command in component %s of "+
+					"instance %s", args[1], args[0]), err)
+			}
+		},
+		Example: "  cellery exec employee salary -- ls /tmp\n" +
+			"  cellery exec employee salary -t -- sh",
+	}
+	cmd.Flags().BoolVarP(&tty, "tty", "t", false, "allocate a terminal for the command")
+	return cmd
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newInjectFaultCommand(cli cli.Cli) *cobra.Command {
+	var dependency string
+	var delay time.Duration
+	var abortStatus int
+	var percentage int
+	var clear bool
+	cmd := &cobra.Command{
+		Use:   "inject-fault <instance> --dependency|-d <dependency_alias> [--delay <duration>] [--abort <http_status>] [--percent <x>]",
+		Short: "inject faults into the requests sent by a cell/composite instance to a dependency",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			if dependency == "" {
+				return fmt.Errorf("expects the dependency alias with --dependency")
+			}
+			isValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
+			if err != nil || !isValid {
+				return fmt.Errorf("expects a valid instance name, received %q", args[0])
+			}
+			if clear && (cmd.Flags().Changed("delay") || cmd.Flags().Changed("abort") ||
+				cmd.Flags().Changed("percent")) {
+				return fmt.Errorf("--clear cannot be used along with the faults to be injected")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if clear {
+				err = instance.RunClearFault(cli, args[0], dependency)
+			} else {
+				err = instance.RunInjectFault(cli, args[0], dependency, &routing.Fault{
+					Delay:       delay,
+					AbortStatus: abortStatus,
+					Percentage:  percentage,
+				})
+			}
+			if err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to inject faults into the requests sent to %s",
+					dependency), err)
+			}
+		},
+		Example: "  cellery inject-fault hr-client-inst1 --dependency employeeDep --delay 2s --percent 20\n" +
+			"  cellery inject-fault hr-client-inst1 --dependency employeeDep --abort 503 --percent 10\n" +
+			"  cellery inject-fault hr-client-inst1 --dependency employeeDep --delay 2s --abort 503\n" +
+			"  cellery inject-fault hr-client-inst1 --dependency employeeDep --clear",
+	}
+	cmd.Flags().StringVarP(&dependency, "dependency", "d", "", "alias of the dependency, or the dependency "+
+		"instance name")
+	cmd.Flags().DurationVar(&delay, "delay", 0, "delay to be added to the requests sent to the dependency")
+	cmd.Flags().IntVar(&abortStatus, "abort", 0, "http status with which the requests sent to the dependency "+
+		"should be aborted")
+	cmd.Flags().IntVar(&percentage, "percent", 100, "percentage of the requests into which the faults should "+
+		"be injected")
+	cmd.Flags().BoolVar(&clear, "clear", false, "restore the faults of the requests sent to the dependency to the faults "+
+		"found before injecting faults")
+	return cmd
+}
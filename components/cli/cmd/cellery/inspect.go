@@ -31,15 +31,24 @@ import (
 
 // newListFilesCommand creates a command which can be invoked to list the files (directory structure) of a cell images.
 func newInspectCommand(cli cli.Cli) *cobra.Command {
+	var remote bool
+	var username string
+	var password string
 	cmd := &cobra.Command{
-		Use:     "inspect <organization>/<cell-image>:<version>",
-		Short:   "List the files in the cell image",
+		Use:     "inspect [<registry>/]<organization>/<cell-image>:<version>",
+		Short:   "List the files in the cell image or show the metadata of a remote cell image",
 		Aliases: []string{"insp"},
 		Args: func(cmd *cobra.Command, args []string) error {
 			err := cobra.ExactArgs(1)(cmd, args)
 			if err != nil {
 				return err
 			}
+			if remote {
+				return image.ValidateImageTagWithRegistry(args[0])
+			}
+			if username != "" || password != "" {
+				return fmt.Errorf("credentials are only used with the --remote flag")
+			}
 			err = image.ValidateImageTag(args[0])
 			if err != nil {
 				return fmt.Errorf("expects <organization>/<cell-image>:<version> as cell-image, received %s", args[0])
@@ -47,11 +56,22 @@ func newInspectCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image2.RunInspect(cli, args[0]); err != nil {
+			var err error
+			if remote {
+				err = image2.RunInspectRemote(cli, args[0], username, password)
+			} else {
+				err = image2.RunInspect(cli, args[0])
+			}
+			if err != nil {
 				util.ExitWithErrorMessage("Cellery inspect command failed", err)
 			}
 		},
-		Example: "  cellery inspect cellery-samples/employee:1.0.0",
+		Example: "  cellery inspect cellery-samples/employee:1.0.0\n" +
+			"  cellery inspect registry.foo.io/cellery-samples/employee:1.0.0 --remote",
 	}
+	cmd.Flags().BoolVar(&remote, "remote", false, "Show the metadata of the cell image in the remote repository "+
+		"without pulling it")
+	cmd.Flags().StringVarP(&username, "username", "u", "", "Username for Cellery Registry")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "Password for Cellery Registry")
 	return cmd
 }
@@ -36,6 +36,7 @@ func newListCommand(cli cli.Cli) *cobra.Command {
 		newListIngressesCommand(cli),
 		newListComponentsCommand(cli),
 		newListDependenciesCommand(cli),
+		newListRemoteTagsCommand(cli),
 	)
 	return cmd
 }
@@ -23,21 +23,30 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newListInstancesCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
 	cmd := &cobra.Command{
 		Use:     "instances",
 		Short:   "List all running cells",
 		Aliases: []string{"instance", "inst"},
-		Args:    cobra.NoArgs,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			return output.ValidateFormat(outputFormat)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := instance.RunListInstances(cli); err != nil {
+			if err := instance.RunListInstances(cli, outputFormat); err != nil {
 				util.ExitWithErrorMessage("Cellery list instances command failed", err)
 			}
 		},
-		Example: "  cellery list instances",
+		Example: "  cellery list instances\n" +
+			"  cellery list instances -o jsonpath='{.cells[*].name}'",
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
 	return cmd
 }
@@ -27,10 +27,12 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/image"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newListComponentsCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
 	cmd := &cobra.Command{
 		Use:     "components <instance-name|cell-image-name>",
 		Short:   "List the components which the cell encapsulates",
@@ -47,15 +49,17 @@ func newListComponentsCommand(cli cli.Cli) *cobra.Command {
 					return fmt.Errorf("expects a valid cell instance name or a cell image name, received %s", args[0])
 				}
 			}
-			return nil
+			return output.ValidateFormat(outputFormat)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image.RunListComponents(cli, args[0]); err != nil {
+			if err := image.RunListComponents(cli, args[0], outputFormat); err != nil {
 				util.ExitWithErrorMessage("Cellery list components command failed", err)
 			}
 		},
 		Example: "  cellery list components employee\n" +
-			"  cellery list components cellery-samples/employee:1.0.0",
+			"  cellery list components cellery-samples/employee:1.0.0\n" +
+			"  cellery list components employee -o yaml",
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
 	return cmd
 }
@@ -27,10 +27,12 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newListDependenciesCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
 	cmd := &cobra.Command{
 		Use:     "dependencies <instance-name>",
 		Aliases: []string{"dep"},
@@ -47,14 +49,16 @@ func newListDependenciesCommand(cli cli.Cli) *cobra.Command {
 			} else {
 				util.ExitWithErrorMessage("Unable to list dependencies", err)
 			}
-			return nil
+			return output.ValidateFormat(outputFormat)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := instance.RunListDependencies(cli, args[0]); err != nil {
+			if err := instance.RunListDependencies(cli, args[0], outputFormat); err != nil {
 				util.ExitWithErrorMessage("Unable to list dependencies", err)
 			}
 		},
-		Example: "  cellery list dependencies mypetstoreportal",
+		Example: "  cellery list dependencies mypetstoreportal\n" +
+			"  cellery list dependencies mypetstoreportal -o wide",
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
 	return cmd
 }
@@ -23,21 +23,30 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/image"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newListImagesCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
 	cmd := &cobra.Command{
 		Use:     "images",
 		Short:   "List cell images",
 		Aliases: []string{"image", "img"},
-		Args:    cobra.NoArgs,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			return output.ValidateFormat(outputFormat)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image.RunListImages(cli); err != nil {
+			if err := image.RunListImages(cli, outputFormat); err != nil {
 				util.ExitWithErrorMessage("Cellery list images command failed", err)
 			}
 		},
-		Example: "  cellery list images",
+		Example: "  cellery list images\n" +
+			"  cellery list images -o go-template='{{range .}}{{.name}} {{end}}'",
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
 	return cmd
 }
@@ -27,11 +27,13 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/image"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 // newApisCommand creates a cobra command which can be invoked to get the APIs exposed by a cell
 func newListIngressesCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
 	cmd := &cobra.Command{
 		Use:     "ingresses <instance-name|cell-image-name>",
 		Aliases: []string{"ingress", "ing"},
@@ -48,15 +50,17 @@ func newListIngressesCommand(cli cli.Cli) *cobra.Command {
 					return fmt.Errorf("expects a valid cell instance name or a cell image name, received %s", args[0])
 				}
 			}
-			return nil
+			return output.ValidateFormat(outputFormat)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image.RunListIngresses(cli, args[0]); err != nil {
+			if err := image.RunListIngresses(cli, args[0], outputFormat); err != nil {
 				util.ExitWithErrorMessage("Cellery list ingresses command failed", err)
 			}
 		},
 		Example: "  cellery list ingresses employee\n" +
-			"  cellery list ingresses cellery-samples/employee:1.0.0\n",
+			"  cellery list ingresses cellery-samples/employee:1.0.0\n" +
+			"  cellery list ingresses employee -o json\n",
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
 	return cmd
 }
@@ -21,12 +21,14 @@ package main
 import (
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
@@ -34,36 +36,62 @@ func newLogsCommand(cli cli.Cli) *cobra.Command {
 	var component string
 	var syslog bool
 	var follow bool
+	var since time.Duration
+	var tail int
+	var timestamps bool
+	var withDependencies bool
 	cmd := &cobra.Command{
-		Use:   "logs <instance-name>",
-		Short: "Displays logs for either the cell instance, or a component of a running cell instance.",
+		Use:   "logs <instance-name>...",
+		Short: "Displays logs for either the instances, or a component of running instances.",
 		Args: func(cmd *cobra.Command, args []string) error {
-			err := cobra.ExactArgs(1)(cmd, args)
+			err := cobra.MinimumNArgs(1)(cmd, args)
 			if err != nil {
 				return err
 			}
-			isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
-			if err != nil || !isCellValid {
-				return fmt.Errorf("expects a valid cell name, received %s", args[0])
+			for _, instanceName := range args {
+				isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern),
+					instanceName)
+				if err != nil || !isCellValid {
+					return fmt.Errorf("expects a valid cell name, received %s", instanceName)
+				}
 			}
 			if component != "" {
 				isComponentValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), component)
 				if err != nil || !isComponentValid {
-					return fmt.Errorf("expects a valid component name, received %s", args[0])
+					return fmt.Errorf("expects a valid component name, received %s", component)
+				}
+				if withDependencies {
+					return fmt.Errorf("--component cannot be used with --with-dependencies")
 				}
 			}
+			if since < 0 {
+				return fmt.Errorf("expects a positive duration for --since, received %s", since)
+			}
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := instance.RunLogs(cli, args[0], component, syslog, follow); err != nil {
+			options := kubernetes.LogOptions{
+				Follow:     follow,
+				Since:      since,
+				Tail:       tail,
+				Timestamps: timestamps,
+			}
+			if err := instance.RunLogs(cli, args, component, syslog, withDependencies, options); err != nil {
 				util.ExitWithErrorMessage("Cellery logs command failed", err)
 			}
 		},
 		Example: "  cellery logs employee\n" +
-			"  cellery logs employee -c salary",
+			"  cellery logs employee -c salary\n" +
+			"  cellery logs employee stock --since 10m --tail 100 --timestamps\n" +
+			"  cellery logs hr --with-dependencies -f",
 	}
 	cmd.Flags().StringVarP(&component, "component", "c", "", "component of the cell")
 	cmd.Flags().BoolVarP(&syslog, "syslog", "s", false, "view system logs")
 	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "follow logs")
+	cmd.Flags().DurationVar(&since, "since", 0, "only return logs newer than a relative duration like 5s, 2m, or 3h")
+	cmd.Flags().IntVar(&tail, "tail", -1, "lines of recent logs to display from each container, -1 for all")
+	cmd.Flags().BoolVar(&timestamps, "timestamps", false, "include timestamps in the logs")
+	cmd.Flags().BoolVar(&withDependencies, "with-dependencies", false,
+		"view logs of the instances in the dependency trees of the instances as well")
 	return cmd
 }
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newPortForwardCommand(cli cli.Cli) *cobra.Command {
+	var component string
+	var ports []string
+	cmd := &cobra.Command{
+		Use:   "port-forward <instance-name> [component-name|gateway] <local-port>:<remote-port>...",
+		Short: "Forward local ports to a component or the gateway of a cell/composite instance",
+		Args: func(cmd *cobra.Command, args []string) error {
+			err := cobra.MinimumNArgs(2)(cmd, args)
+			if err != nil {
+				return err
+			}
+			isValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
+			if err != nil || !isValid {
+				return fmt.Errorf("expects a valid instance name, received %s", args[0])
+			}
+			// the ports are forwarded to the gateway if the component is not given
+			isPort, err := regexp.MatchString("^[0-9]*(:[0-9]*)?$", args[1])
+			if err != nil {
+				return err
+			}
+			component, ports = "", args[1:]
+			if !isPort {
+				component, ports = args[1], args[2:]
+			}
+			if len(ports) == 0 {
+				return fmt.Errorf("expects at least one port in the form <local-port>:<remote-port>")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunPortForward(cli, args[0], component, ports); err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to forward ports to instance %s", args[0]), err)
+			}
+		},
+		Example: "  cellery port-forward employee 9090:80\n" +
+			"  cellery port-forward employee salary 8080:8080\n" +
+			"  cellery port-forward employee gateway 9090:80 9443:443",
+	}
+	return cmd
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/metrics"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newPromoteCommand(cli cli.Cli) *cobra.Command {
+	var drainWindow time.Duration
+	var drainTimeout time.Duration
+	var metricsUrl string
+	var assumeYes bool
+	cmd := &cobra.Command{
+		Use:   "promote <old-instance> <new-instance>",
+		Short: "Switch all the dependents of a cell/composite instance to a new instance and terminate it",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+			for _, instanceName := range args {
+				if err := validateInstanceName(instanceName); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunPromote(cli, args[0], args[1], metrics.NewPrometheusSource(metricsUrl),
+				drainWindow, drainTimeout, assumeYes); err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to promote instance %s", args[1]), err)
+			}
+		},
+		Example: "  cellery promote hr-inst-1 hr-inst-2\n" +
+			"  cellery promote hr-inst-1 hr-inst-2 --drain-timeout 10m --metrics-url http://prometheus:9090 -y",
+	}
+	cmd.Flags().DurationVar(&drainWindow, "drain-window", 30*time.Second,
+		"period over which the old instance should not receive any requests to be considered drained")
+	cmd.Flags().DurationVar(&drainTimeout, "drain-timeout", 5*time.Minute,
+		"maximum time to wait for the traffic to the old instance to drain")
+	cmd.Flags().StringVar(&metricsUrl, "metrics-url", defaultMetricsUrl,
+		"url of the Prometheus compatible API serving the metrics of the instances")
+	cmd.Flags().BoolVarP(&assumeYes, "assume-yes", "y", false, "flag to assume yes for user confirmations")
+	return cmd
+}
@@ -26,12 +26,14 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	image2 "cellery.io/cellery/components/cli/pkg/commands/image"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/registry"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newPushCommand(cli cli.Cli) *cobra.Command {
 	var username string
 	var password string
+	var manifestFormat string
 	cmd := &cobra.Command{
 		Use:   "push [<registry>/]<organization>/<cell-image>:<version>",
 		Short: "Push cell image to the remote repository",
@@ -47,17 +49,20 @@ func newPushCommand(cli cli.Cli) *cobra.Command {
 			if password != "" && username == "" {
 				return fmt.Errorf("expects username if the password is provided, username not provided")
 			}
-			return nil
+			return registry.ValidateManifestFormat(manifestFormat)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image2.RunPush(cli, args[0], username, password); err != nil {
+			if err := image2.RunPush(cli, args[0], username, password, registry.ManifestFormat(manifestFormat)); err != nil {
 				util.ExitWithErrorMessage("Cellery push command failed", err)
 			}
 		},
 		Example: "  cellery push cellery-samples/employee:1.0.0\n" +
-			"  cellery push registry.foo.io/cellery-samples/employee:1.0.0",
+			"  cellery push registry.foo.io/cellery-samples/employee:1.0.0\n" +
+			"  cellery push registry.foo.io/cellery-samples/employee:1.0.0 --manifest-format schema1",
 	}
 	cmd.Flags().StringVarP(&username, "username", "u", "", "Username for Cellery Registry")
 	cmd.Flags().StringVarP(&password, "password", "p", "", "Password for Cellery Registry")
+	cmd.Flags().StringVar(&manifestFormat, "manifest-format", string(registry.ManifestFormatOci),
+		"Manifest format used to store the cell image. One of: oci|schema2|schema1")
 	return cmd
 }
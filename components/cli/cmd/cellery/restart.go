@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2018 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newRestartCommand(cli cli.Cli) *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "restart <instance-name> [component...]",
+		Short: "Restart the components of an instance and wait until they are ready",
+		Args: func(cmd *cobra.Command, args []string) error {
+			err := cobra.MinimumNArgs(1)(cmd, args)
+			if err != nil {
+				return err
+			}
+			isInstanceValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
+			if err != nil || !isInstanceValid {
+				return fmt.Errorf("expects a valid instance name, received %s", args[0])
+			}
+			for _, component := range args[1:] {
+				isComponentValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern),
+					component)
+				if err != nil || !isComponentValid {
+					return fmt.Errorf("expects a valid component name, received %s", component)
+				}
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunRestart(cli, args[0], args[1:], timeout); err != nil {
+				util.ExitWithErrorMessage("Cellery restart command failed", err)
+			}
+		},
+		Example: "  cellery restart employee\n" +
+			"  cellery restart employee salary gateway --timeout 10m",
+	}
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute,
+		"time to wait until the restarted components are ready")
+	return cmd
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+)
+
+func newRolloutCommand(cli cli.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollout <command>",
+		Short: "Manage the revisions and the rollouts of cell/composite instances",
+	}
+	cmd.AddCommand(
+		newRolloutHistoryCommand(cli),
+		newRolloutUndoCommand(cli),
+		newRolloutCanaryCommand(cli),
+	)
+	return cmd
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/metrics"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+const defaultMetricsUrl = "http://localhost:9090"
+
+func newRolloutCanaryCommand(cli cli.Cli) *cobra.Command {
+	var sourceInstance string
+	var dependencyInstance string
+	var targetInstance string
+	var srcInstances []string
+	var metricsUrl string
+	var enableSessionAwareness bool
+	var assumeYes bool
+	policy := instance.CanaryPolicy{}
+	cmd := &cobra.Command{
+		Use:   "canary [--source|-s=<list_of_source_cell_instances>] --dependency|-d <dependency_instance_name> --target|-t <target_instance_name>",
+		Short: "Route the traffic to a cell/composite instance step by step, reverting if the instance is unhealthy",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			if err := validateArguments(dependencyInstance, targetInstance); err != nil {
+				return err
+			}
+			srcInstances = getSourceCellInstanceArr(sourceInstance)
+			for _, srcInstance := range srcInstances {
+				if err := validateInstanceName(srcInstance); err != nil {
+					return err
+				}
+			}
+			return validateInstanceName(targetInstance)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunRolloutCanary(cli, srcInstances, dependencyInstance, targetInstance, policy,
+				metrics.NewPrometheusSource(metricsUrl), enableSessionAwareness, assumeYes); err != nil {
+				util.ExitWithErrorMessage("Cellery rollout canary command failed", err)
+			}
+		},
+		Example: "  cellery rollout canary --dependency hr-inst-1 --target hr-inst-2\n" +
+			"  cellery rollout canary --source hr-client-inst1 --dependency hr-inst-1 --target hr-inst-2 " +
+			"--steps 20,50,100 --interval 5m --max-error-rate 0.5 --max-latency 300ms\n" +
+			"  cellery rollout canary --dependency hr-inst-1 --target hr-inst-2 --metrics-url http://prometheus:9090",
+	}
+	cmd.Flags().StringVarP(&sourceInstance, "source", "s", "", "comma separated source instance list")
+	cmd.Flags().StringVarP(&dependencyInstance, "dependency", "d", "", "existing dependency instance name")
+	cmd.Flags().StringVarP(&targetInstance, "target", "t", "",
+		"target instance to which the traffic should be re-routed")
+	cmd.Flags().IntSliceVar(&policy.Steps, "steps", []int{10, 25, 50, 100},
+		"percentages of the traffic routed to the target instance at each step")
+	cmd.Flags().DurationVar(&policy.Interval, "interval", time.Minute,
+		"time to observe the target instance after each step")
+	cmd.Flags().Float64Var(&policy.MinRequestRate, "min-request-rate", 0,
+		"minimum requests per second received by the target instance, any request is sufficient if 0")
+	cmd.Flags().Float64Var(&policy.MaxErrorRate, "max-error-rate", 1,
+		"maximum percentage of failed requests to the target instance, 0 to not check the error rate")
+	cmd.Flags().DurationVar(&policy.MaxLatency, "max-latency", 0,
+		"maximum p99 latency of the target instance, 0 to not check the latency")
+	cmd.Flags().StringVar(&metricsUrl, "metrics-url", defaultMetricsUrl,
+		"url of the Prometheus compatible API serving the metrics of the instances")
+	cmd.Flags().BoolVarP(&enableSessionAwareness, "enable-session-awareness", "a", false,
+		"flag to enable session awareness based on user name")
+	cmd.Flags().BoolVarP(&assumeYes, "assume-yes", "y", false, "flag to assume yes for user confirmations")
+	return cmd
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newRolloutUndoCommand(cli cli.Cli) *cobra.Command {
+	var toRevision int
+	cmd := &cobra.Command{
+		Use:   "undo <instance>",
+		Short: "Roll back the last change, or the changes since a given revision, of a cell/composite instance",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
+			if err != nil || !isCellValid {
+				return fmt.Errorf("expects a valid instance name, received %s", args[0])
+			}
+			if toRevision < 0 {
+				return fmt.Errorf("expects a positive revision, received %d", toRevision)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunRolloutUndo(cli, args[0], toRevision); err != nil {
+				util.ExitWithErrorMessage("Cellery rollout undo command failed", err)
+			}
+		},
+		Example: "  cellery rollout undo employee\n" +
+			"  cellery rollout undo employee --to-revision 2",
+	}
+	cmd.Flags().IntVar(&toRevision, "to-revision", 0,
+		"Revision to roll back to, the latest revision is used if not provided")
+	return cmd
+}
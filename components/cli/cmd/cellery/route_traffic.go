@@ -29,6 +29,8 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
@@ -40,6 +42,15 @@ func newRouteTrafficCommand(cli cli.Cli) *cobra.Command {
 	var percentage int
 	var srcInstances []string
 	var enableSessionAwareness bool
+	var headers []string
+	var cookie string
+	var uriPrefix string
+	var grpcMethod string
+	var rulesFile string
+	var matchRules []routing.MatchRule
+	var mirror bool
+	var plan bool
+	var planFile string
 	var assumeYes bool
 	cmd := &cobra.Command{
 		Use:   "route-traffic [--source|-s=<list_of_source_cell_instances>] --dependency|-d <dependency_instance_name> --target|-t <target instance name> [--percentage|-p <x>]",
@@ -47,8 +58,22 @@ func newRouteTrafficCommand(cli cli.Cli) *cobra.Command {
 		Example: "cellery route-traffic --source hr-client-inst1 --dependency hr-inst-1 --target hr-inst-2 --percentage 20 \n" +
 			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --percentage 20 \n" +
 			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 \n" +
-			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --percentage 25 --enable-session-awareness",
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --percentage 25 --enable-session-awareness \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --header x-team=beta --uri-prefix /beta \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --cookie user=qa --percentage 10 \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --rules-file qa-rules.yaml \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --grpc-method helloworld.Greeter/SayHello \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --mirror --percentage 50 \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --percentage 20 --plan > hr-plan.yaml \n" +
+			"cellery route-traffic --from-file hr-plan.yaml",
 		Args: func(cmd *cobra.Command, args []string) error {
+			// a reviewed plan is applied as is, hence the routing flags are not accepted along with it
+			if planFile != "" {
+				if cmd.Flags().NFlag() > 1 {
+					return fmt.Errorf("flag from-file cannot be used with other flags")
+				}
+				return nil
+			}
 			// validate
 			err := validateArguments(dependencyInstance, targetInstance)
 			if err != nil {
@@ -68,6 +93,14 @@ func newRouteTrafficCommand(cli cli.Cli) *cobra.Command {
 			if err != nil {
 				util.ExitWithErrorMessage("Error in running route traffic command", err)
 			}
+			matchRules, err = getMatchRules(headers, cookie, uriPrefix, grpcMethod, rulesFile)
+			if err != nil {
+				util.ExitWithErrorMessage("Error in running route traffic command", err)
+			}
+			// only the matching requests are routed to the target if the percentage is not given with match rules
+			if len(matchRules) > 0 && targetPercentage == "" {
+				targetPercentage = "0"
+			}
 			// calculate target percentage value
 			percentage, err = getTargetInstancePercentage(targetPercentage)
 			if err != nil {
@@ -76,7 +109,20 @@ func newRouteTrafficCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			err := instance.RunRouteTrafficCommand(cli, srcInstances, dependencyInstance, targetInstance, percentage, enableSessionAwareness, assumeYes)
+			if planFile != "" {
+				if err := instance.RunRouteTrafficFromFile(cli, planFile); err != nil {
+					util.ExitWithErrorMessage("Unable to apply the routing plan", err)
+				}
+				return
+			}
+			if plan {
+				if err := instance.RunRouteTrafficPlan(cli, srcInstances, dependencyInstance, targetInstance,
+					percentage, enableSessionAwareness, matchRules, mirror); err != nil {
+					util.ExitWithErrorMessage("Unable to plan routing traffic to the target instance", err)
+				}
+				return
+			}
+			err := instance.RunRouteTrafficCommand(cli, srcInstances, dependencyInstance, targetInstance, percentage, enableSessionAwareness, matchRules, mirror, assumeYes)
 			if err != nil {
 				util.ExitWithErrorMessage(fmt.Sprintf("Unable to route traffic to the target instance: %s, percentage: %d", targetInstance, percentage), err)
 			}
@@ -85,12 +131,83 @@ func newRouteTrafficCommand(cli cli.Cli) *cobra.Command {
 	cmd.Flags().StringVarP(&sourceInstance, "source", "s", "", "comma separated source instance list")
 	cmd.Flags().StringVarP(&dependencyInstance, "dependency", "d", "", "existing dependency instance name")
 	cmd.Flags().StringVarP(&targetInstance, "target", "t", "", "target instance to which the traffic should be re-routed")
-	cmd.Flags().StringVarP(&targetPercentage, "percentage", "p", "", "percentage to be switched to the target instance")
+	cmd.Flags().StringVarP(&targetPercentage, "percentage", "p", "",
+		"percentage to be switched to the target instance, or to be mirrored to it in mirror mode")
 	cmd.Flags().BoolVarP(&enableSessionAwareness, "enable-session-awareness", "a", false, "flag to enable session awareness based on user name")
+	cmd.Flags().StringArrayVar(&headers, "header", []string{},
+		"route the requests with the header <name>=<value> to the target instance")
+	cmd.Flags().StringVar(&cookie, "cookie", "", "route the requests with the cookie <name>=<value> to the target instance")
+	cmd.Flags().StringVar(&uriPrefix, "uri-prefix", "", "route the requests with the uri prefix to the target instance")
+	cmd.Flags().StringVar(&grpcMethod, "grpc-method", "", "route the gRPC calls to the service, or to the method "+
+		"given as <service>/<method>, to the target instance")
+	cmd.Flags().StringVar(&rulesFile, "rules-file", "", "yaml file with the rules matching the requests routed "+
+		"to the target instance")
+	cmd.Flags().BoolVar(&mirror, "mirror", false, "flag to mirror the traffic to the target instance, while the "+
+		"dependency instance keeps serving all the requests")
+	cmd.Flags().BoolVar(&plan, "plan", false, "flag to print the routing plan along with the API compatibility "+
+		"findings, without routing the traffic")
+	cmd.Flags().StringVar(&planFile, "from-file", "", "routing plan file to be applied, printed previously with "+
+		"the plan flag")
 	cmd.Flags().BoolVarP(&assumeYes, "assume-yes", "y", false, "flag to assume yes for user confirmations")
 	return cmd
 }
 
+// getMatchRules returns the rule made of the header, cookie, uri prefix and gRPC method conditions, along with the
+// rules in the rules file if provided
+func getMatchRules(headers []string, cookie string, uriPrefix string, grpcMethod string,
+	rulesFile string) ([]routing.MatchRule, error) {
+	var matchRules []routing.MatchRule
+	if len(headers) > 0 || cookie != "" || uriPrefix != "" || grpcMethod != "" {
+		rule := routing.MatchRule{
+			Headers: map[string]*kubernetes.StringMatch{},
+		}
+		for _, header := range headers {
+			name, value, err := splitNameValue(header)
+			if err != nil {
+				return nil, fmt.Errorf("expects headers in the format <name>=<value>, received %s", header)
+			}
+			rule.Headers[name] = &kubernetes.StringMatch{Exact: value}
+		}
+		if cookie != "" {
+			name, value, err := splitNameValue(cookie)
+			if err != nil {
+				return nil, fmt.Errorf("expects the cookie in the format <name>=<value>, received %s", cookie)
+			}
+			rule.Cookie = &routing.Cookie{Name: name, Value: value}
+		}
+		if uriPrefix != "" {
+			rule.Uri = &kubernetes.StringMatch{Prefix: uriPrefix}
+		}
+		if grpcMethod != "" {
+			parts := strings.SplitN(grpcMethod, "/", 2)
+			rule.Grpc = &routing.GrpcMethod{Service: parts[0]}
+			if len(parts) == 2 {
+				rule.Grpc.Method = parts[1]
+			}
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		matchRules = append(matchRules, rule)
+	}
+	if rulesFile != "" {
+		fileRules, err := routing.ReadMatchRules(rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		matchRules = append(matchRules, fileRules...)
+	}
+	return matchRules, nil
+}
+
+func splitNameValue(nameValue string) (string, string, error) {
+	parts := strings.SplitN(nameValue, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid name value pair %s", nameValue)
+	}
+	return strings.TrimSpace(parts[0]), parts[1], nil
+}
+
 func getSourceCellInstanceArr(sourceCellInstances string) []string {
 	var trimmedInstances []string
 	if len(sourceCellInstances) == 0 {
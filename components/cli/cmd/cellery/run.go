@@ -37,6 +37,8 @@ func newRunCommand(cli cli.Cli) *cobra.Command {
 	var shareAllInstances bool
 	var dependencyLinks []string
 	var envVars []string
+	var dryRun bool
+	var outputDir string
 	cmd := &cobra.Command{
 		Use:   "run [<registry>/]<organization>/<cell-image>:<version>",
 		Short: "Use a cell image to create a running instance",
@@ -71,10 +73,17 @@ func newRunCommand(cli cli.Cli) *cobra.Command {
 						"[<instance>:]<key>=<value>, received %s", envVar)
 				}
 			}
+			if dryRun && outputDir == "" {
+				return fmt.Errorf("expects an output directory for the dry run, --output not provided")
+			}
+			if !dryRun && outputDir != "" {
+				return fmt.Errorf("output directory can only be used with the --dry-run flag")
+			}
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image2.RunRun(cli, args[0], name, startDependencies, shareAllInstances, dependencyLinks, envVars); err != nil {
+			if err := image2.RunRun(cli, args[0], name, startDependencies, shareAllInstances, dependencyLinks, envVars,
+				outputDir); err != nil {
 				util.ExitWithErrorMessage("Cellery run command failed", err)
 			}
 		},
@@ -87,7 +96,8 @@ func newRunCommand(cli cli.Cli) *cobra.Command {
 			"  cellery run cellery-samples/employee:1.0.0 --share-instances " +
 			"-l employee-inst.people-hr:people-hr-inst\n" +
 			"  cellery run cellery-samples/hr:1.0.0 -n hr-inst -l employee:employee-inst -e host=foo " +
-			"-e employee-inst:host=bar -e hr-inst:mode=dev\n",
+			"-e employee-inst:host=bar -e hr-inst:mode=dev\n" +
+			"  cellery run cellery-samples/hr:1.0.0 -n hr-inst -d --dry-run -o hr-manifests\n",
 	}
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the cell instance")
 	cmd.Flags().BoolVarP(&startDependencies, "start-dependencies", "d", false,
@@ -98,5 +108,8 @@ func newRunCommand(cli cli.Cli) *cobra.Command {
 		"Link an instance with a dependency alias")
 	cmd.Flags().StringArrayVarP(&envVars, "env", "e", []string{},
 		"Set an environment variable for the cellery run method in the Cell file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Write the resolved instances to the output directory instead of deploying them")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory of the dry run")
 	return cmd
 }
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2018 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newScaleCommand(cli cli.Cli) *cobra.Command {
+	var overridePolicy bool
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "scale <instance-name> <component>=<replicas>...",
+		Short: "Set the replicas of the components of an instance",
+		Args: func(cmd *cobra.Command, args []string) error {
+			err := cobra.MinimumNArgs(2)(cmd, args)
+			if err != nil {
+				return err
+			}
+			isInstanceValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
+			if err != nil || !isInstanceValid {
+				return fmt.Errorf("expects a valid instance name, received %s", args[0])
+			}
+			_, err = parseReplicas(args[1:])
+			return err
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			replicas, _ := parseReplicas(args[1:])
+			if err := instance.RunScale(cli, args[0], replicas, overridePolicy, force); err != nil {
+				util.ExitWithErrorMessage("Cellery scale command failed", err)
+			}
+		},
+		Example: "  cellery scale employee salary=3\n" +
+			"  cellery scale employee salary=3 gateway=2\n" +
+			"  cellery scale employee salary=1 --override-policy",
+	}
+	cmd.Flags().BoolVar(&overridePolicy, "override-policy", false,
+		"replace the autoscale policies of the components with the replicas")
+	cmd.Flags().BoolVar(&force, "force", false, "override the scaling policies which are not overridable")
+	return cmd
+}
+
+// parseReplicas returns the replicas given in the form <component>=<replicas>, keyed by the component
+func parseReplicas(args []string) (map[string]int, error) {
+	replicas := make(map[string]int)
+	replicasPattern := regexp.MustCompile(fmt.Sprintf("^(%s)=([0-9]+)$", constants.CelleryIdPattern))
+	for _, arg := range args {
+		match := replicasPattern.FindStringSubmatch(arg)
+		if match == nil {
+			return nil, fmt.Errorf("expects the replicas in the form <component>=<replicas>, received %s", arg)
+		}
+		component := match[1]
+		if _, exists := replicas[component]; exists {
+			return nil, fmt.Errorf("replicas of component %s are given more than once", component)
+		}
+		componentReplicas, err := strconv.Atoi(match[len(match)-1])
+		if err != nil {
+			return nil, fmt.Errorf("expects a valid number of replicas, received %s", arg)
+		}
+		replicas[component] = componentReplicas
+	}
+	return replicas, nil
+}
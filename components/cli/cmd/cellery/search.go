@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/image"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newSearchCommand(cli cli.Cli) *cobra.Command {
+	var registryHost string
+	var username string
+	var password string
+	var outputFormat string
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search cell images in the remote repository",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			if password != "" && username == "" {
+				return fmt.Errorf("expects username if the password is provided, username not provided")
+			}
+			return output.ValidateFormat(outputFormat)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := image.RunSearch(cli, registryHost, args[0], username, password, outputFormat); err != nil {
+				util.ExitWithErrorMessage("Cellery search command failed", err)
+			}
+		},
+		Example: "  cellery search employee\n" +
+			"  cellery search cellery-samples/ --registry registry.foo.io -u alice",
+	}
+	cmd.Flags().StringVar(&registryHost, "registry", constants.CentralRegistryHost, "Cellery Registry to search")
+	cmd.Flags().StringVarP(&username, "username", "u", "", "Username for Cellery Registry")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "Password for Cellery Registry")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
+	return cmd
+}
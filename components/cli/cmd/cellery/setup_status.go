@@ -24,20 +24,29 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/setup"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newSetupStatusCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Display status of cluster with a status list of system components",
-		Args:  cobra.NoArgs,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			return output.ValidateFormat(outputFormat)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := setup.RunSetupStatus(cli); err != nil {
+			if err := setup.RunSetupStatus(cli, outputFormat); err != nil {
 				util.ExitWithErrorMessage("Cellery setup status command failed", err)
 			}
 		},
-		Example: "  cellery setup status",
+		Example: "  cellery setup status\n" +
+			"  cellery setup status -o yaml",
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
 	return cmd
 }
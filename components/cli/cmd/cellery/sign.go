@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	image2 "cellery.io/cellery/components/cli/pkg/commands/image"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newSignCommand(cli cli.Cli) *cobra.Command {
+	var keyFile string
+	var certificateFile string
+	var localOnly bool
+	var username string
+	var password string
+	cmd := &cobra.Command{
+		Use:   "sign [<registry>/]<organization>/<cell-image>:<version>",
+		Short: "Sign a cell image in the local repository",
+		Args: func(cmd *cobra.Command, args []string) error {
+			err := cobra.ExactArgs(1)(cmd, args)
+			if err != nil {
+				return err
+			}
+			err = image.ValidateImageTagWithRegistry(args[0])
+			if err != nil {
+				return err
+			}
+			if keyFile == "" {
+				return fmt.Errorf("expects the private key used to sign the image, --key not provided")
+			}
+			if password != "" && username == "" {
+				return fmt.Errorf("expects username if the password is provided, username not provided")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := image2.RunSign(cli, args[0], keyFile, certificateFile, localOnly, username,
+				password); err != nil {
+				util.ExitWithErrorMessage("Cellery sign command failed", err)
+			}
+		},
+		Example: "  cellery sign cellery-samples/employee:1.0.0 --key signer.key\n" +
+			"  cellery sign registry.foo.io/cellery-samples/employee:1.0.0 --key signer.key --cert signer.crt\n" +
+			"  cellery sign cellery-samples/employee:1.0.0 --key signer.key --local",
+	}
+	cmd.Flags().StringVarP(&keyFile, "key", "k", "", "PEM encoded private key used to sign the image")
+	cmd.Flags().StringVar(&certificateFile, "cert", "", "PEM encoded x509 certificate of the signing key")
+	cmd.Flags().BoolVar(&localOnly, "local", false, "Only store the signature in the local repository")
+	cmd.Flags().StringVarP(&username, "username", "u", "", "Username for Cellery Registry")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "Password for Cellery Registry")
+	return cmd
+}
@@ -27,10 +27,13 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newStatusCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
+	var watch bool
 	cmd := &cobra.Command{
 		Use:   "status <instance-name>",
 		Short: "Performs a health check of a cell.",
@@ -43,14 +46,18 @@ func newStatusCommand(cli cli.Cli) *cobra.Command {
 			if err != nil || !isCellValid {
 				return fmt.Errorf("expects a valid cell name, received %s", args[0])
 			}
-			return nil
+			return output.ValidateFormat(outputFormat)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := instance.RunStatus(cli, args[0]); err != nil {
+			if err := instance.RunStatus(cli, args[0], outputFormat, watch); err != nil {
 				util.ExitWithErrorMessage("Cellery status command failed", err)
 			}
 		},
-		Example: "  cellery status employee",
+		Example: "  cellery status employee\n" +
+			"  cellery status employee -o json\n" +
+			"  cellery status employee --watch",
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "refresh the status until the instance is ready")
 	return cmd
 }
@@ -16,24 +16,22 @@
  * under the License.
  */
 
-package kubernetes
+package main
 
 import (
-	"fmt"
-	"os/exec"
+	"github.com/spf13/cobra"
 
-	"cellery.io/cellery/components/cli/pkg/osexec"
+	"cellery.io/cellery/components/cli/cli"
 )
 
-func (kubeCli *CelleryKubeCli) DescribeCell(cellName string) error {
-	cmd := exec.Command(
-		kubectl,
-		"describe",
-		"cells",
-		cellName,
+func newSystemCommand(cli cli.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "system <command>",
+		Short: "Manage the local cellery repository and temporary files",
+	}
+	cmd.AddCommand(
+		newSystemDiskUsageCommand(cli),
+		newSystemPruneCommand(cli),
 	)
-	displayVerboseOutput(cmd)
-	out, err := osexec.GetCommandOutputFromTextFile(cmd)
-	fmt.Print(string(out))
-	return err
+	return cmd
 }
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/system"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newSystemDiskUsageCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
+	cmd := &cobra.Command{
+		Use:   "df",
+		Short: "Show the disk usage of the local repository and temporary files",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			return output.ValidateFormat(outputFormat)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := system.RunDiskUsage(cli, outputFormat); err != nil {
+				util.ExitWithErrorMessage("Cellery system df command failed", err)
+			}
+		},
+		Example: "  cellery system df\n" +
+			"  cellery system df -o wide",
+	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
+	return cmd
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/system"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newSystemPruneCommand(cli cli.Cli) *cobra.Command {
+	var policy system.PrunePolicy
+	var dryRun bool
+	var assumeYes bool
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old cell images from the local repository and stale temporary files",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			if policy.KeepLast < 0 {
+				return fmt.Errorf("expects a positive number of versions to keep, received %d", policy.KeepLast)
+			}
+			if policy.OlderThan < 0 {
+				return fmt.Errorf("expects a positive duration, received %s", policy.OlderThan)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := system.RunPrune(cli, policy, dryRun, assumeYes); err != nil {
+				util.ExitWithErrorMessage("Cellery system prune command failed", err)
+			}
+		},
+		Example: "  cellery system prune\n" +
+			"  cellery system prune --keep-last 3\n" +
+			"  cellery system prune --older-than 720h --unused\n" +
+			"  cellery system prune --keep-last 2 --unused --dry-run",
+	}
+	cmd.Flags().IntVar(&policy.KeepLast, "keep-last", 0, "Keep the given number of latest versions of each image")
+	cmd.Flags().DurationVar(&policy.OlderThan, "older-than", 0, "Only remove images built before the given duration")
+	cmd.Flags().BoolVar(&policy.Unused, "unused", false, "Only remove images which are not used by running instances")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be removed without removing anything")
+	cmd.Flags().BoolVarP(&assumeYes, "assume-yes", "y", false, "Flag to assume yes for user confirmations")
+	return cmd
+}
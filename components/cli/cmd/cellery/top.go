@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2018 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newTopCommand(cli cli.Cli) *cobra.Command {
+	var outputFormat string
+	var watch bool
+	cmd := &cobra.Command{
+		Use:   "top [instance-name]",
+		Short: "Displays the cpu and memory usage of the components of the instances.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			err := cobra.MaximumNArgs(1)(cmd, args)
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				isInstanceValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
+				if err != nil || !isInstanceValid {
+					return fmt.Errorf("expects a valid instance name, received %s", args[0])
+				}
+			}
+			return output.ValidateFormat(outputFormat)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var instanceName string
+			if len(args) == 1 {
+				instanceName = args[0]
+			}
+			if err := instance.RunTop(cli, instanceName, outputFormat, watch); err != nil {
+				util.ExitWithErrorMessage("Cellery top command failed", err)
+			}
+		},
+		Example: "  cellery top\n" +
+			"  cellery top employee -o wide\n" +
+			"  cellery top employee --watch",
+	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "refresh the usage every 5 seconds until interrupted")
+	return cmd
+}
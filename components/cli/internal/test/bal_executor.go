@@ -116,6 +116,18 @@ func (balExecutor *MockBalExecutor) Build(fileName string, args []string, cmdDir
 
 // Build mocks execution of ballerina run on an executable bal file.
 func (balExecutor *MockBalExecutor) Run(fileName string, args []string, envVars []*ballerina.EnvironmentVariable, cmdDir string) error {
+	// Rendering the instance yaml instead of applying it in dry run mode
+	for _, envVar := range envVars {
+		if envVar.Key == "CELLERY_DRY_RUN_DIR" {
+			if err := os.MkdirAll(envVar.Value, os.ModePerm); err != nil {
+				return fmt.Errorf("failed to create dry run dir, %v", err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(envVar.Value, balExecutor.yamlName), balExecutor.yamlContent,
+				os.ModePerm); err != nil {
+				return fmt.Errorf("failed to write to yaml, %v", err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -127,6 +127,12 @@ func (cli *MockCli) ExecuteTask(startMessage, errorMessage, successMessage strin
 	return nil
 }
 
+// ExecuteTaskWithProgress mocks function execution ignoring the progress.
+func (cli *MockCli) ExecuteTaskWithProgress(startMessage, errorMessage, successMessage string,
+	function func(progress func(transferred, total int64)) error) error {
+	return function(func(transferred, total int64) {})
+}
+
 // FileSystem returns a mock FileSystemManager instance.
 func (cli *MockCli) FileSystem() cli.FileSystemManager {
 	return cli.manager
@@ -41,6 +41,18 @@ func SetRepository(repository string) func(*MockFileSystem) {
 	}
 }
 
+func SetTempDir(tempDir string) func(*MockFileSystem) {
+	return func(fs *MockFileSystem) {
+		fs.tempDir = tempDir
+	}
+}
+
+func SetUserHome(userHome string) func(*MockFileSystem) {
+	return func(fs *MockFileSystem) {
+		fs.userHome = userHome
+	}
+}
+
 func SetCurrentDir(currentDir string) func(*MockFileSystem) {
 	return func(fs *MockFileSystem) {
 		fs.currentDir = currentDir
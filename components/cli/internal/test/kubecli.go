@@ -19,8 +19,15 @@
 package test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
 
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
@@ -29,6 +36,8 @@ import (
 const celleryInstance = "cells.mesh.cellery.io"
 const celleryComposite = "composites.mesh.cellery.io"
 
+var yamlDocumentSeparator = regexp.MustCompile("(?m)^---\\s*$")
+
 type MockKubeCli struct {
 	clusterName      string
 	contexts         []string
@@ -41,6 +50,18 @@ type MockKubeCli struct {
 	k8sClientVersion string
 	services         map[string]kubernetes.Services
 	virtualServices  map[string]kubernetes.VirtualService
+	resources        map[string][]byte
+	appliedFiles     []string
+	replacedFiles    []string
+	deletedResources []string
+	jsonPatches      map[string]string
+	pods             map[string]kubernetes.Pods
+	execs            []string
+	portForwards     []string
+	containerLogs    map[string]string
+	logOptions       kubernetes.LogOptions
+	podMetrics       map[string]kubernetes.PodMetricsList
+	events           kubernetes.Events
 }
 
 // NewMockKubeCli returns a mock cli for the cli.KubeCli interface.
@@ -61,6 +82,35 @@ func WithCells(cells kubernetes.Cells) func(*MockKubeCli) {
 	}
 }
 
+// WithPods sets the pods of the cell and the composite instances, keyed by the instance name
+func WithPods(pods map[string]kubernetes.Pods) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.pods = pods
+	}
+}
+
+// WithPodMetrics sets the resource usage of the pods of the cell and the composite instances, keyed by the
+// instance name. The metrics API is considered unavailable if the pod metrics are not set.
+func WithPodMetrics(podMetrics map[string]kubernetes.PodMetricsList) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.podMetrics = podMetrics
+	}
+}
+
+// WithEvents sets the events in the namespace
+func WithEvents(events kubernetes.Events) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.events = events
+	}
+}
+
+// WithContainerLogs sets the logs of the containers, keyed by <pod>/<container>
+func WithContainerLogs(logs map[string]string) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.containerLogs = logs
+	}
+}
+
 func WithComposites(composites kubernetes.Composites) func(*MockKubeCli) {
 	return func(cli *MockKubeCli) {
 		cli.composites = composites
@@ -91,6 +141,14 @@ func WithVirtualServices(virtualServices map[string]kubernetes.VirtualService) f
 	}
 }
 
+// WithResources sets resources as json, keyed by <resource>/<name> where the resource is the lower case kind
+// qualified with the API group (eg:- configmap/foo, virtualservice.networking.istio.io/bar)
+func WithResources(resources map[string][]byte) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.resources = resources
+	}
+}
+
 func SetK8sVersions(serverVersion, clientVersion string) func(*MockKubeCli) {
 	return func(cli *MockKubeCli) {
 		cli.k8sServerVersion = serverVersion
@@ -152,9 +210,16 @@ func (kubeCli *MockKubeCli) GetComposite(compositeName string) (kubernetes.Compo
 }
 
 func (kubeCli *MockKubeCli) DeleteResource(kind, instance string) (string, error) {
+	delete(kubeCli.resources, kind+"/"+instance)
+	kubeCli.deletedResources = append(kubeCli.deletedResources, kind+"/"+instance)
 	return "", nil
 }
 
+// DeletedResources returns the resources deleted using the mock, in the form <kind>/<name>.
+func (kubeCli *MockKubeCli) DeletedResources() []string {
+	return kubeCli.deletedResources
+}
+
 func (kubeCli *MockKubeCli) GetInstancesNames() ([]string, error) {
 	var instanceNames []string
 	for _, cell := range kubeCli.cells.Items {
@@ -167,21 +232,15 @@ func (kubeCli *MockKubeCli) GetInstancesNames() ([]string, error) {
 }
 
 func (kubeCli *MockKubeCli) GetInstanceBytes(instanceKind, InstanceName string) ([]byte, error) {
+	if resource, ok := kubeCli.resources[instanceKind+"/"+InstanceName]; ok {
+		return resource, nil
+	}
 	if instanceKind == celleryInstance {
 		return kubeCli.cellsBytes[InstanceName], nil
 	} else if instanceKind == celleryComposite {
 		return kubeCli.cellsBytes[InstanceName], nil
 	}
-	return nil, nil
-}
-
-func (kubeCli *MockKubeCli) DescribeCell(cellName string) error {
-	for _, cell := range kubeCli.cells.Items {
-		if cell.CellMetaData.Name == cellName {
-			return nil
-		}
-	}
-	return fmt.Errorf(fmt.Sprintf("cell instance %s not found", cellName))
+	return nil, errorpkg.NotFoundError{Kind: instanceKind, Name: InstanceName}
 }
 
 func (kubeCli *MockKubeCli) Version() (string, string, error) {
@@ -195,26 +254,94 @@ func (kubeCli *MockKubeCli) GetServices(cellName string) (kubernetes.Services, e
 	return kubeCli.services[cellName], nil
 }
 
-func (kubeCli *MockKubeCli) StreamCellLogsUserComponents(instanceName string, follow bool) error {
-	return nil
+// StreamContainerLogs writes the logs of the container set using WithContainerLogs
+func (kubeCli *MockKubeCli) StreamContainerLogs(pod, container string, options kubernetes.LogOptions,
+	out io.Writer) error {
+	kubeCli.logOptions = options
+	_, err := io.WriteString(out, kubeCli.containerLogs[pod+"/"+container])
+	return err
 }
 
-func (kubeCli *MockKubeCli) StreamCellLogsAllComponents(instanceName string, follow bool) error {
-	return nil
+// LogOptions returns the options used to stream the logs of the last container.
+func (kubeCli *MockKubeCli) LogOptions() kubernetes.LogOptions {
+	return kubeCli.logOptions
 }
 
-func (kubeCli *MockKubeCli) StreamComponentLogs(instanceName, componentName string, follow bool) error {
+// JsonPatch records the patch, which can then be read using JsonPatches
+func (kubeCli *MockKubeCli) JsonPatch(kind, instance, jsonPatch string) error {
+	if kubeCli.jsonPatches == nil {
+		kubeCli.jsonPatches = map[string]string{}
+	}
+	kubeCli.jsonPatches[kind+"/"+instance] = jsonPatch
 	return nil
 }
 
-func (kubeCli *MockKubeCli) JsonPatch(kind, instance, jsonPatch string) error {
-	return nil
+// JsonPatches returns the patches applied using the mock, keyed by <kind>/<instance>.
+func (kubeCli *MockKubeCli) JsonPatches() map[string]string {
+	return kubeCli.jsonPatches
 }
 
+// ApplyFile stores the resources in the file, which can then be read using GetInstanceBytes
 func (kubeCli *MockKubeCli) ApplyFile(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	kubeCli.appliedFiles = append(kubeCli.appliedFiles, string(content))
+	return kubeCli.storeResources(content)
+}
+
+// ReplaceFile stores the resources in the file, which can then be read using GetInstanceBytes
+func (kubeCli *MockKubeCli) ReplaceFile(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	kubeCli.replacedFiles = append(kubeCli.replacedFiles, string(content))
+	return kubeCli.storeResources(content)
+}
+
+func (kubeCli *MockKubeCli) storeResources(content []byte) error {
+	if kubeCli.resources == nil {
+		kubeCli.resources = map[string][]byte{}
+	}
+	for _, document := range yamlDocumentSeparator.Split(string(content), -1) {
+		documentJson, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(document) == "" || bytes.Equal(documentJson, []byte("null")) {
+			continue
+		}
+		object := struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}{}
+		if err := json.Unmarshal(documentJson, &object); err != nil {
+			return err
+		}
+		resource := strings.ToLower(object.Kind)
+		if parts := strings.SplitN(object.APIVersion, "/", 2); len(parts) == 2 {
+			resource += "." + parts[0]
+		}
+		kubeCli.resources[resource+"/"+object.Metadata.Name] = documentJson
+	}
 	return nil
 }
 
+// AppliedFiles returns the content of the files applied using ApplyFile
+func (kubeCli *MockKubeCli) AppliedFiles() []string {
+	return kubeCli.appliedFiles
+}
+
+// ReplacedFiles returns the content of the files replaced using ReplaceFile
+func (kubeCli *MockKubeCli) ReplacedFiles() []string {
+	return kubeCli.replacedFiles
+}
+
 func (kubeCli *MockKubeCli) GetCellInstanceAsMapInterface(cell string) (map[string]interface{}, error) {
 	var output map[string]interface{}
 	out := kubeCli.cellsBytes[cell]
@@ -227,11 +354,63 @@ func (kubeCli *MockKubeCli) GetCompositeInstanceAsMapInterface(composite string)
 }
 
 func (kubeCli *MockKubeCli) GetPodsForCell(cellName string) (kubernetes.Pods, error) {
-	return kubernetes.Pods{}, nil
+	return kubeCli.pods[cellName], nil
 }
 
 func (kubeCli *MockKubeCli) GetPodsForComposite(compName string) (kubernetes.Pods, error) {
-	return kubernetes.Pods{}, nil
+	return kubeCli.pods[compName], nil
+}
+
+func (kubeCli *MockKubeCli) GetPodMetricsForCell(cellName string) (kubernetes.PodMetricsList, error) {
+	return kubeCli.getPodMetrics(cellName)
+}
+
+func (kubeCli *MockKubeCli) GetPodMetricsForComposite(compName string) (kubernetes.PodMetricsList, error) {
+	return kubeCli.getPodMetrics(compName)
+}
+
+func (kubeCli *MockKubeCli) getPodMetrics(instance string) (kubernetes.PodMetricsList, error) {
+	if kubeCli.podMetrics == nil {
+		return kubernetes.PodMetricsList{}, fmt.Errorf("the server doesn't have a resource type \"pods\"")
+	}
+	return kubeCli.podMetrics[instance], nil
+}
+
+// GetEvents returns the events of the mock involving the objects of the kind, or the object with the given name.
+// All the events are returned if the kind is empty.
+func (kubeCli *MockKubeCli) GetEvents(involvedObjectKind string, involvedObjectName string) (kubernetes.Events,
+	error) {
+	events := kubernetes.Events{}
+	for _, event := range kubeCli.events.Items {
+		if (involvedObjectKind == "" || event.InvolvedObject.Kind == involvedObjectKind) &&
+			(involvedObjectName == "" || event.InvolvedObject.Name == involvedObjectName) {
+			events.Items = append(events.Items, event)
+		}
+	}
+	return events, nil
+}
+
+// ExecInContainer records the command, which can then be read using Execs
+func (kubeCli *MockKubeCli) ExecInContainer(pod, container string, tty bool, command []string) error {
+	kubeCli.execs = append(kubeCli.execs, fmt.Sprintf("%s/%s tty=%t %s", pod, container, tty,
+		strings.Join(command, " ")))
+	return nil
+}
+
+// Execs returns the commands executed using the mock, in the form <pod>/<container> tty=<tty> <command>.
+func (kubeCli *MockKubeCli) Execs() []string {
+	return kubeCli.execs
+}
+
+// PortForward records the forwarded ports, which can then be read using PortForwards
+func (kubeCli *MockKubeCli) PortForward(pod string, ports []string) error {
+	kubeCli.portForwards = append(kubeCli.portForwards, fmt.Sprintf("%s %s", pod, strings.Join(ports, " ")))
+	return nil
+}
+
+// PortForwards returns the ports forwarded using the mock, in the form <pod> <ports>.
+func (kubeCli *MockKubeCli) PortForwards() []string {
+	return kubeCli.portForwards
 }
 
 func (kubeCli *MockKubeCli) GetVirtualService(vs string) (kubernetes.VirtualService, error) {
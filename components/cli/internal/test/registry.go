@@ -20,22 +20,31 @@ package test
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
 
 	"cellery.io/cellery/components/cli/pkg/image"
+	registrypkg "cellery.io/cellery/components/cli/pkg/registry"
 )
 
 type MockRegistry struct {
-	out       io.Writer
-	outBuffer *bytes.Buffer
-	images    map[string][]byte
+	out        io.Writer
+	outBuffer  *bytes.Buffer
+	images     map[string][]byte
+	metadata   map[string]*image.MetaData
+	signatures map[digest.Digest][]byte
 }
 
 func NewMockRegistry(opts ...func(*MockRegistry)) *MockRegistry {
 	outBuffer := new(bytes.Buffer)
 	registry := &MockRegistry{
-		out:       outBuffer,
-		outBuffer: outBuffer,
+		out:        outBuffer,
+		outBuffer:  outBuffer,
+		signatures: make(map[digest.Digest][]byte),
 	}
 	for _, opt := range opts {
 		opt(registry)
@@ -49,13 +58,96 @@ func SetImages(images map[string][]byte) func(*MockRegistry) {
 	}
 }
 
-func (registry *MockRegistry) Push(parsedCellImage *image.CellImage, fileBytes []byte, username, password string) error {
+// SetMetaData sets the metadata of the remote images, keyed by <organization>/<image>:<version>.
+func SetMetaData(metadata map[string]*image.MetaData) func(*MockRegistry) {
+	return func(registry *MockRegistry) {
+		registry.metadata = metadata
+	}
+}
+
+// SetSignatures sets the signatures of the remote images, keyed by the digest of the image.
+func SetSignatures(signatures map[digest.Digest][]byte) func(*MockRegistry) {
+	return func(registry *MockRegistry) {
+		registry.signatures = signatures
+	}
+}
+
+func (registry *MockRegistry) Push(parsedCellImage *image.CellImage, cellImage registrypkg.Blob, username,
+	password string, manifestFormat registrypkg.ManifestFormat, progress registrypkg.ProgressFunc) error {
 	return nil
 }
 
-func (registry *MockRegistry) Pull(parsedCellImage *image.CellImage, username string, password string) ([]byte, error) {
+func (registry *MockRegistry) Pull(parsedCellImage *image.CellImage, username string, password string,
+	cellImage io.Writer, progress registrypkg.ProgressFunc) error {
 	imageName := parsedCellImage.Organization + "/" + parsedCellImage.ImageName + ":" + parsedCellImage.ImageVersion
-	return registry.images[imageName], nil
+	_, err := cellImage.Write(registry.images[imageName])
+	return err
+}
+
+func (registry *MockRegistry) Repositories(registryHost, username, password string) ([]string, error) {
+	var repositories []string
+	for _, name := range registry.imageNames() {
+		repository := strings.Split(name, ":")[0]
+		if len(repositories) == 0 || repositories[len(repositories)-1] != repository {
+			repositories = append(repositories, repository)
+		}
+	}
+	return repositories, nil
+}
+
+func (registry *MockRegistry) Tags(registryHost, repository, username, password string) ([]string, error) {
+	var tags []string
+	for _, name := range registry.imageNames() {
+		if strings.HasPrefix(name, repository+":") {
+			tags = append(tags, strings.TrimPrefix(name, repository+":"))
+		}
+	}
+	if tags == nil {
+		return nil, fmt.Errorf("failed to fetch the tags of %s, 404 Not Found", repository)
+	}
+	return tags, nil
+}
+
+func (registry *MockRegistry) MetaData(parsedCellImage *image.CellImage, username,
+	password string) (*image.MetaData, error) {
+	imageName := parsedCellImage.Organization + "/" + parsedCellImage.ImageName + ":" + parsedCellImage.ImageVersion
+	metadata, ok := registry.metadata[imageName]
+	if !ok {
+		return nil, fmt.Errorf("unexpected status 404 Not Found while fetching manifest %s",
+			parsedCellImage.ImageVersion)
+	}
+	return metadata, nil
+}
+
+func (registry *MockRegistry) PushSignature(parsedCellImage *image.CellImage, imageDigest digest.Digest,
+	signature []byte, username, password string) error {
+	registry.signatures[imageDigest] = signature
+	return nil
+}
+
+func (registry *MockRegistry) PullSignature(parsedCellImage *image.CellImage, imageDigest digest.Digest,
+	username, password string) ([]byte, error) {
+	return registry.signatures[imageDigest], nil
+}
+
+// Signatures returns the signatures pushed to the mock registry.
+func (registry *MockRegistry) Signatures() map[digest.Digest][]byte {
+	return registry.signatures
+}
+
+// imageNames returns the sorted names of the images in the mock registry.
+func (registry *MockRegistry) imageNames() []string {
+	var names []string
+	for name := range registry.images {
+		names = append(names, name)
+	}
+	for name := range registry.metadata {
+		if _, ok := registry.images[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
 }
 
 // Out returns the mock writer used for the stdout.
@@ -70,7 +70,7 @@ func (runtime *MockRuntime) IsComponentEnabled(component runtime.SystemComponent
 	return false, fmt.Errorf("failed to check status of runtime")
 }
 
-func (runtime *MockRuntime) AddApim(isPersistentVolume bool, nfs runtime.Nfs, db runtime.MysqlDb) error {
+func (runtime *MockRuntime) AddApim(isCompleteSetup bool, isPersistentVolume bool, nfs runtime.Nfs, db runtime.MysqlDb) error {
 	return nil
 }
 
@@ -143,7 +143,7 @@ func (runtime *MockRuntime) UpdateNodePortIpAddress(nodePortIpAddress string) er
 	return nil
 }
 
-func (runtime *MockRuntime) AddObservability() error {
+func (runtime *MockRuntime) AddObservability(db runtime.MysqlDb) error {
 	return nil
 }
 
@@ -34,6 +34,7 @@ import (
 
 const ballerina = "ballerina"
 const celleryImageDirEnvVar = "CELLERY_IMAGE_DIR"
+const celleryDryRunDirEnvVar = "CELLERY_DRY_RUN_DIR"
 
 type BalExecutor interface {
 	Build(fileName string, args []string, cmdDir string) error
@@ -215,7 +215,7 @@ func (balExecutor *DockerBalExecutor) Run(fileName string, args []string, envVar
 	// set any explicitly passed env vars in cellery run command to the docker exec.
 	// This will override any env vars with identical names (prefixed with 'CELLERY') set previously.
 	for _, envVar := range envVars {
-		if envVar.Key == celleryImageDirEnvVar {
+		if envVar.Key == celleryImageDirEnvVar || envVar.Key == celleryDryRunDirEnvVar {
 			dockerImageDir := re.ReplaceAllString(envVar.Value, dockerCliCellImageDir)
 			cmd.Args = append(cmd.Args, "-e", envVar.Key+"="+dockerImageDir)
 		} else {
@@ -313,7 +313,7 @@ func (balExecutor *DockerBalExecutor) Test(args []string, envVars []*Environment
 
 	// This will override any env vars with identical names (prefixed with 'CELLERY') set previously.
 	for _, envVar := range envVars {
-		if envVar.Key == celleryImageDirEnvVar {
+		if envVar.Key == celleryImageDirEnvVar || envVar.Key == celleryDryRunDirEnvVar {
 			envVar.Value = strings.Replace(envVar.Value, util.UserHomeDir(), "/home/cellery", 1)
 		}
 		dockerCmdArgs = append(dockerCmdArgs, "-e", envVar.Key+"="+envVar.Value)
@@ -35,7 +35,7 @@ func RunDeleteImage(cli cli.Cli, images []string, regex string, deleteAll bool)
 		return fmt.Errorf("error getting images array, %v", err)
 	}
 	for _, imageInRepo := range imagesInRepo {
-		parsedCellImage, err := image.ParseImageTag(imageInRepo.name)
+		parsedCellImage, err := image.ParseImageTag(imageInRepo.Name)
 		if err != nil {
 			return fmt.Errorf("error occurred while parsing cell image, %v", err)
 		}
@@ -48,7 +48,7 @@ func RunDeleteImage(cli cli.Cli, images []string, regex string, deleteAll bool)
 		} else {
 			if regex != "" {
 				// Check if image name matches regex pattern
-				regexMatches, err := regexp.MatchString(regex, imageInRepo.name)
+				regexMatches, err := regexp.MatchString(regex, imageInRepo.Name)
 				if err != nil {
 					return fmt.Errorf("error checking if pattern matches with image name, %v", err)
 				}
@@ -61,7 +61,7 @@ func RunDeleteImage(cli cli.Cli, images []string, regex string, deleteAll bool)
 			}
 			if len(images) > 0 {
 				for _, imageToBeDeleted := range images {
-					if imageInRepo.name == imageToBeDeleted {
+					if imageInRepo.Name == imageToBeDeleted {
 						if os.RemoveAll(cellImagePath); err != nil {
 							return err
 						}
@@ -22,26 +22,55 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/ghodss/yaml"
+
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
-func RunDescribe(cli cli.Cli, name string) error {
+func RunDescribe(cli cli.Cli, name string, outputFormat string) error {
 	instancePattern, _ := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), name)
 	if instancePattern {
-		// If the input of user is an instance describe running cell instance.
-		err := cli.KubeCli().DescribeCell(name)
-		if err != nil {
-			return fmt.Errorf("error describing cell instance, %v", err)
-		}
-	} else {
-		// If the input of user is a cell image print the cell yaml
-		cellYamlContent, err := image.ReadCellImageYaml(cli.FileSystem().Repository(), name)
-		if err != nil {
-			return fmt.Errorf("error describing cell image, %v", err)
+		// If the input of user is an instance describe the running instance, which is printed as yaml by default
+		if output.IsTable(outputFormat) {
+			outputFormat = output.FormatYaml
 		}
+		return describeInstance(cli, name, outputFormat)
+	}
+	// If the input of user is a cell image print the cell yaml
+	cellYamlContent, err := image.ReadCellImageYaml(cli.FileSystem().Repository(), name)
+	if err != nil {
+		return fmt.Errorf("error describing cell image, %v", err)
+	}
+	if output.IsTable(outputFormat) {
 		fmt.Fprintln(cli.Out(), string(cellYamlContent))
+		return nil
+	}
+	var cellImage interface{}
+	if err := yaml.Unmarshal(cellYamlContent, &cellImage); err != nil {
+		return fmt.Errorf("error describing cell image, %v", err)
+	}
+	return output.Print(cli.Out(), outputFormat, cellImage)
+}
+
+// describeInstance prints the cell or composite instance in a machine readable output format.
+func describeInstance(cli cli.Cli, instanceName string, outputFormat string) error {
+	cell, err := cli.KubeCli().GetCell(instanceName)
+	if err == nil {
+		return output.Print(cli.Out(), outputFormat, cell)
+	}
+	if cellNotFound, _ := errorpkg.IsCellInstanceNotFoundError(instanceName, err); !cellNotFound {
+		return fmt.Errorf("error describing cell instance, %v", err)
+	}
+	composite, err := cli.KubeCli().GetComposite(instanceName)
+	if err != nil {
+		if compositeNotFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instanceName, err); compositeNotFound {
+			return fmt.Errorf("error describing instance, instance %s not available in the runtime", instanceName)
+		}
+		return fmt.Errorf("error describing composite instance, %v", err)
 	}
-	return nil
+	return output.Print(cli.Out(), outputFormat, composite)
 }
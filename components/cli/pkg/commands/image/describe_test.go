@@ -20,6 +20,7 @@ package image
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -58,16 +59,20 @@ func TestRunDescribeInstance(t *testing.T) {
 			instance:         "foo",
 			MockCli:          test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells)))),
 			expectedToPass:   false,
-			expectedErrorMsg: "error describing cell instance, cell instance foo not found",
+			expectedErrorMsg: "error describing instance, instance foo not available in the runtime",
 		},
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunDescribe(tst.MockCli, tst.instance)
+			err := RunDescribe(tst.MockCli, tst.instance, "")
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunDescribe instance")
 				}
+				// the instance is printed as yaml to the output of the cli
+				if !strings.Contains(tst.MockCli.OutBuffer().String(), "name: employee") {
+					t.Errorf("RunDescribe: expected the instance in yaml, got %q", tst.MockCli.OutBuffer().String())
+				}
 			} else {
 				if diff := cmp.Diff(tst.expectedErrorMsg, err.Error()); diff != "" {
 					t.Errorf("invalid error message (-want, +got)\n%v", diff)
@@ -106,7 +111,7 @@ func TestRunDescribeImage(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunDescribe(mockCli, tst.image)
+			err := RunDescribe(mockCli, tst.image, "")
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunDescribe image")
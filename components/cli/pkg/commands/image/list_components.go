@@ -20,7 +20,6 @@ package image
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
@@ -29,25 +28,27 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
-func RunListComponents(cli cli.Cli, name string) error {
+type componentData struct {
+	Name string `json:"name"`
+}
+
+func RunListComponents(cli cli.Cli, name string, outputFormat string) error {
 	var err error
+	var components []string
 	instancePattern, _ := regexp.MatchString(fmt.Sprintf("^%s$", celleryIdPattern), name)
 	if instancePattern {
-		var instanceComponents []string
-		if instanceComponents, err = getCellInstanceComponents(cli, name); err != nil {
+		if components, err = getCellInstanceComponents(cli, name); err != nil {
 			return err
 		}
-		displayComponentsTable(instanceComponents)
 	} else {
-		imageComponents, err := getCellImageCompoents(cli, name)
-		if err != nil {
+		if components, err = getCellImageCompoents(cli, name); err != nil {
 			return err
 		}
-		displayComponentsTable(imageComponents)
 	}
-	return nil
+	return displayComponentsTable(cli, components, outputFormat)
 }
 
 func getCellImageCompoents(cli cli.Cli, cellImage string) ([]string, error) {
@@ -85,22 +86,12 @@ func getCellInstanceComponents(cli cli.Cli, cellName string) ([]string, error) {
 	return components, nil
 }
 
-func displayComponentsTable(components []string) {
-	var tableData [][]string
-	for i := 0; i < len(components); i++ {
-		component := []string{components[i]}
-		tableData = append(tableData, component)
+func displayComponentsTable(cli cli.Cli, components []string, outputFormat string) error {
+	data := []componentData{}
+	table := output.NewTable("COMPONENT NAME").SetColumnColor(0, tablewriter.Colors{tablewriter.FgHiBlueColor})
+	for _, component := range components {
+		data = append(data, componentData{Name: component})
+		table.Append(component)
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"COMPONENT NAME"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(tablewriter.Colors{tablewriter.Bold})
-	table.SetColumnColor(tablewriter.Colors{tablewriter.FgHiBlueColor})
-
-	table.AppendBulk(tableData)
-	table.Render()
+	return output.PrintTable(cli.Out(), outputFormat, data, table)
 }
@@ -53,7 +53,7 @@ func TestRunListComponentsForInstance(t *testing.T) {
 	}
 	for _, testIteration := range tests {
 		t.Run(testIteration.name, func(t *testing.T) {
-			err := RunListComponents(mockCli, testIteration.instance)
+			err := RunListComponents(mockCli, testIteration.instance, "")
 			if err != nil {
 				t.Errorf("error in RunListComponents, %v", err)
 			}
@@ -79,7 +79,7 @@ func TestRunListComponentsForImage(t *testing.T) {
 	}
 	for _, testIteration := range tests {
 		t.Run(testIteration.name, func(t *testing.T) {
-			err := RunListComponents(mockCli, testIteration.image)
+			err := RunListComponents(mockCli, testIteration.image, "")
 			if err != nil {
 				t.Errorf("error in RunListComponents, %v", err)
 			}
@@ -21,58 +21,45 @@ package image
 import (
 	"fmt"
 	"log"
-	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/docker/go-units"
-	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 type imageData struct {
-	name    string
-	size    string
-	created string
-	kind    string
+	Name    string `json:"name"`
+	Size    string `json:"size"`
+	Created string `json:"created"`
+	Kind    string `json:"kind"`
+	// Fields only displayed in the wide output format
+	Components     int    `json:"components"`
+	CelleryVersion string `json:"celleryVersion"`
 }
 
-func RunListImages(cli cli.Cli) error {
-	var data [][]string
+func RunListImages(cli cli.Cli, outputFormat string) error {
 	images, err := getImagesArray(cli)
 	if err != nil {
 		return fmt.Errorf("error getting images arrays, %v", err)
 	}
-	if len(images) == 0 {
+	if len(images) == 0 && output.IsTable(outputFormat) {
 		fmt.Fprintln(cli.Out(), "No images found.")
-	} else {
-		for _, i := range images {
-			data = append(data, []string{i.name, i.size, i.created, i.kind})
-		}
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"IMAGE", "SIZE", "CREATED", "KIND"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(data)
-		table.Render()
+		return nil
+	}
+	if images == nil {
+		images = []imageData{}
+	}
+	table := output.NewTable("IMAGE", "SIZE", "CREATED", "KIND").AddWideColumns("COMPONENTS", "CELLERY VERSION")
+	for _, i := range images {
+		table.Append(i.Name, i.Size, i.Created, i.Kind, strconv.Itoa(i.Components), i.CelleryVersion)
 	}
-	return nil
+	return output.PrintTable(cli.Out(), outputFormat, images, table)
 }
 
 func getImagesArray(cli cli.Cli) ([]imageData, error) {
@@ -112,6 +99,8 @@ func getImagesArray(cli cli.Cli) ([]imageData, error) {
 						units.HumanSize(float64(size)),
 						fmt.Sprintf("%s ago", units.HumanDuration(time.Since(time.Unix(meta.BuildTimestamp, 0)))),
 						fmt.Sprintf("%s", meta.Kind),
+						len(meta.Components),
+						meta.BuildCelleryVersion,
 					})
 				}
 			}
@@ -41,7 +41,7 @@ func TestRunListImages(t *testing.T) {
 	}
 	for _, testIteration := range tests {
 		t.Run(testIteration.name, func(t *testing.T) {
-			err := RunListImages(mockCli)
+			err := RunListImages(mockCli, "")
 			if err != nil {
 				t.Errorf("error in RunListImages, %v", err)
 			}
@@ -71,10 +71,10 @@ func TestGetImagesArray(t *testing.T) {
 			if err != nil {
 				t.Errorf("error in getImagesArray, %v", err)
 			} else {
-				if diff := cmp.Diff(testIteration.wantName, imagesArray[0].name); diff != "" {
+				if diff := cmp.Diff(testIteration.wantName, imagesArray[0].Name); diff != "" {
 					t.Errorf("getImagesArray: name (-want, +got)\n%v", diff)
 				}
-				if diff := cmp.Diff(testIteration.wantKind, imagesArray[0].kind); diff != "" {
+				if diff := cmp.Diff(testIteration.wantKind, imagesArray[0].Kind); diff != "" {
 					t.Errorf("getImagesArray: kind (-want, +got)\n%v", diff)
 				}
 			}
@@ -29,28 +29,65 @@ import (
 	"strings"
 
 	"github.com/ghodss/yaml"
-	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/constants"
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
 	"cellery.io/cellery/components/cli/pkg/image"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
-func RunListIngresses(cli cli.Cli, name string) error {
+type cellInstanceIngress struct {
+	Context          string `json:"context"`
+	IngressType      string `json:"ingressType"`
+	Version          string `json:"version"`
+	Method           string `json:"method"`
+	Resource         string `json:"resource"`
+	LocalCellGateway string `json:"localCellGateway"`
+	GlobalApiUrl     string `json:"globalApiUrl,omitempty"`
+	Vhost            string `json:"vhost,omitempty"`
+}
+
+type compositeInstanceIngress struct {
+	Component   string `json:"component"`
+	IngressType string `json:"ingressType"`
+	IngressPort int32  `json:"ingressPort"`
+}
+
+type cellImageIngress struct {
+	Component      string `json:"component"`
+	IngressType    string `json:"ingressType"`
+	IngressContext string `json:"ingressContext"`
+	IngressVersion string `json:"ingressVersion"`
+	IngressPort    string `json:"ingressPort"`
+	Resource       string `json:"resource"`
+	Method         string `json:"method"`
+	Exposed        string `json:"exposed"`
+	Vhost          string `json:"vhost"`
+	IngressKey     string `json:"ingressKey"`
+}
+
+type compositeImageIngress struct {
+	Component   string `json:"component"`
+	IngressType string `json:"ingressType"`
+	IngressPort string `json:"ingressPort"`
+	IngressKey  string `json:"ingressKey"`
+}
+
+func RunListIngresses(cli cli.Cli, name string, outputFormat string) error {
 	instancePattern, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), name)
 	if err != nil {
 		return fmt.Errorf("%s is neither instance nor an image", name)
 	}
 	if instancePattern {
-		return displayInstanceApisTable(cli, name)
+		return displayInstanceApisTable(cli, name, outputFormat)
 	} else {
-		return displayImageApisTable(cli, name)
+		return displayImageApisTable(cli, name, outputFormat)
 	}
 }
 
-func displayInstanceApisTable(cli cli.Cli, instanceName string) error {
+func displayInstanceApisTable(cli cli.Cli, instanceName string, outputFormat string) error {
 	var canBeComposite bool
 	cell, err := cli.KubeCli().GetCell(instanceName)
 	if err != nil {
@@ -60,7 +97,7 @@ func displayInstanceApisTable(cli cli.Cli, instanceName string) error {
 			return fmt.Errorf("failed to check available Cells, %v", err)
 		}
 	} else {
-		displayCellInstanceApisTable(cli, cell, instanceName)
+		return displayCellInstanceApisTable(cli, cell, instanceName, outputFormat)
 	}
 
 	if canBeComposite {
@@ -72,44 +109,34 @@ func displayInstanceApisTable(cli cli.Cli, instanceName string) error {
 				return fmt.Errorf("failed to check available Composites, %v", err)
 			}
 		} else {
-			displayCompositeInstanceApisTable(cli, composite, instanceName)
+			return displayCompositeInstanceApisTable(cli, composite, instanceName, outputFormat)
 		}
 	}
 	return nil
 }
 
-func displayCompositeInstanceApisTable(cli cli.Cli, composite kubernetes.Composite, compositeInstance string) {
-	var tableData [][]string
+func displayCompositeInstanceApisTable(cli cli.Cli, composite kubernetes.Composite, compositeInstance string,
+	outputFormat string) error {
+	ingresses := []compositeInstanceIngress{}
 	for _, component := range composite.CompositeSpec.ComponentTemplates {
 		for _, port := range component.Spec.Ports {
-			tableRecord := []string{component.Metadata.Name, port.Protocol, fmt.Sprint(port.Port)}
-			tableData = append(tableData, tableRecord)
+			ingresses = append(ingresses, compositeInstanceIngress{component.Metadata.Name, port.Protocol,
+				port.Port})
 		}
 	}
-	if len(tableData) > 0 {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"COMPONENT", "INGRESS TYPE", "INGRESS PORT"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(tableData)
-		table.Render()
-	} else {
+	if len(ingresses) == 0 && output.IsTable(outputFormat) {
 		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for composite instance %s", compositeInstance))
+		return nil
 	}
+	table := output.NewTable("COMPONENT", "INGRESS TYPE", "INGRESS PORT")
+	for _, ingress := range ingresses {
+		table.Append(ingress.Component, ingress.IngressType, fmt.Sprint(ingress.IngressPort))
+	}
+	return output.PrintTable(cli.Out(), outputFormat, ingresses, table)
 }
 
-func displayCellInstanceApisTable(cli cli.Cli, cell kubernetes.Cell, cellInstanceName string) {
+func displayCellInstanceApisTable(cli cli.Cli, cell kubernetes.Cell, cellInstanceName string,
+	outputFormat string) error {
 	apiArray := cell.CellSpec.GateWayTemplate.GatewaySpec.Ingress.HttpApis
 	var ingressType = "web"
 	globalContext := ""
@@ -123,7 +150,7 @@ func displayCellInstanceApisTable(cli cli.Cli, cell kubernetes.Cell, cellInstanc
 	if cell.CellSpec.GateWayTemplate.GatewaySpec.Ingress.Extensions.ApiPublisher.Version != "" {
 		globalVersion = cell.CellSpec.GateWayTemplate.GatewaySpec.Ingress.Extensions.ApiPublisher.Version
 	}
-	var tableData [][]string
+	ingresses := []cellInstanceIngress{}
 	for i := 0; i < len(apiArray); i++ {
 		url := cellInstanceName + "--gateway-service"
 		context := apiArray[i].Context
@@ -134,8 +161,9 @@ func displayCellInstanceApisTable(cli cli.Cli, cell kubernetes.Cell, cellInstanc
 		}
 		url += context
 		if len(apiArray[i].Definitions) == 0 {
-			tableRecord := []string{context, ingressType, version, "", "", url, cell.CellSpec.GateWayTemplate.GatewaySpec.Ingress.Extensions.ClusterIngress.Host}
-			tableData = append(tableData, tableRecord)
+			ingresses = append(ingresses, cellInstanceIngress{Context: context, IngressType: ingressType,
+				Version: version, LocalCellGateway: url,
+				Vhost: cell.CellSpec.GateWayTemplate.GatewaySpec.Ingress.Extensions.ClusterIngress.Host})
 		}
 		for j := 0; j < len(apiArray[i].Definitions); j++ {
 			gatewayUrl := url
@@ -165,46 +193,35 @@ func displayCellInstanceApisTable(cli cli.Cli, cell kubernetes.Cell, cellInstanc
 					globalUrl = constants.Wso2ApimHost + strings.Replace("/"+globalUrlContext+"/"+context+"/"+globalUrlVersion, "//", "/", -1)
 				}
 			}
-			tableRecord := []string{context, ingressType, version, method, path, gatewayUrl, globalUrl}
-			tableData = append(tableData, tableRecord)
+			ingresses = append(ingresses, cellInstanceIngress{Context: context, IngressType: ingressType,
+				Version: version, Method: method, Resource: path, LocalCellGateway: gatewayUrl, GlobalApiUrl: globalUrl})
 		}
 	}
-	if len(tableData) > 0 {
-		table := tablewriter.NewWriter(os.Stdout)
+	if len(ingresses) == 0 && output.IsTable(outputFormat) {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for cell instance, %s", cellInstanceName))
+		return nil
+	}
+	var table *output.Table
+	if ingressType == "http" {
+		table = output.NewTable("CONTEXT", "INGRESS TYPE", "VERSION", "METHOD", "RESOURCE", "LOCAL CELL GATEWAY",
+			"GLOBAL API URL")
+	} else {
+		table = output.NewTable("CONTEXT", "INGRESS TYPE", "VERSION", "METHOD", "RESOURCE", "LOCAL CELL GATEWAY",
+			"VHOST")
+	}
+	for _, ingress := range ingresses {
 		if ingressType == "http" {
-			table.SetHeader([]string{"CONTEXT", "INGRESS TYPE", "VERSION", "METHOD", "RESOURCE", "LOCAL CELL GATEWAY", "GLOBAL API URL"})
+			table.Append(ingress.Context, ingress.IngressType, ingress.Version, ingress.Method, ingress.Resource,
+				ingress.LocalCellGateway, ingress.GlobalApiUrl)
 		} else {
-			table.SetHeader([]string{"CONTEXT", "INGRESS TYPE", "VERSION", "METHOD", "RESOURCE", "LOCAL CELL GATEWAY", "VHOST"})
+			table.Append(ingress.Context, ingress.IngressType, ingress.Version, ingress.Method, ingress.Resource,
+				ingress.LocalCellGateway, ingress.Vhost)
 		}
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(tableData)
-		table.Render()
-	} else {
-		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for cell instance, %s", cellInstanceName))
 	}
+	return output.PrintTable(cli.Out(), outputFormat, ingresses, table)
 }
 
-func displayImageApisTable(cli cli.Cli, imageName string) error {
+func displayImageApisTable(cli cli.Cli, imageName string, outputFormat string) error {
 	cellYamlContent, err := image.ReadCellImageYaml(cli.FileSystem().Repository(), imageName)
 	if err != nil {
 		return fmt.Errorf("error while reading cell image content, %v", err)
@@ -216,170 +233,121 @@ func displayImageApisTable(cli cli.Cli, imageName string) error {
 	}
 
 	if cellImageContent.Kind == "Cell" {
-		if err := displayCellImageApisTable(cli, imageName); err != nil {
+		if err := displayCellImageApisTable(cli, imageName, outputFormat); err != nil {
 			return fmt.Errorf("error displaying cell image apis table, %v", err)
 		}
 	} else if cellImageContent.Kind == "Composite" {
-		if err := displayCompositeImageApisTable(cli, imageName); err != nil {
+		if err := displayCompositeImageApisTable(cli, imageName, outputFormat); err != nil {
 			return fmt.Errorf("error displaying composite image apis table, %v", err)
 		}
 	}
 	return nil
 }
 
-func displayCompositeImageApisTable(cli cli.Cli, compositeImageContent string) error {
+func displayCompositeImageApisTable(cli cli.Cli, compositeImageContent string, outputFormat string) error {
 	cell, err := getIngressValues(cli, compositeImageContent)
 	if err != nil {
 		return fmt.Errorf("error occurred while displaying composite image ingress, %v", err)
 	}
-	var tableData [][]string
+	ingresses := []compositeImageIngress{}
 	for _, componentDetail := range cell.Component {
 		for ingressKey, ingressInfo := range componentDetail.Ingress {
-			var ingressData []string
-			ingressData = append(ingressData, componentDetail.ComponentName)
+			ingress := compositeImageIngress{Component: componentDetail.ComponentName}
 			if ingressInfo.IngressTypeTCP == constants.TcpIngress && ingressInfo.IngressType != constants.GrpcIngress {
-				ingressData = append(ingressData, ingressInfo.IngressTypeTCP)
+				ingress.IngressType = ingressInfo.IngressTypeTCP
 			} else {
-				ingressData = append(ingressData, ingressInfo.IngressType)
+				ingress.IngressType = ingressInfo.IngressType
 			}
 			if (ingressInfo.Port) == 0 {
-				ingressData = append(ingressData, "--")
+				ingress.IngressPort = "--"
 			} else {
-				ingressData = append(ingressData, strconv.Itoa(int(ingressInfo.Port)))
+				ingress.IngressPort = strconv.Itoa(int(ingressInfo.Port))
 			}
 			if ingressInfo.IngressTypeTCP == constants.TcpIngress {
-				ingressData = append(ingressData, fmt.Sprintf("%s_%s, %s_%s_tcp_%s", componentDetail.ComponentName,
-					constants.HOST, componentDetail.ComponentName, ingressKey, constants.PORT))
+				ingress.IngressKey = fmt.Sprintf("%s_%s, %s_%s_tcp_%s", componentDetail.ComponentName,
+					constants.HOST, componentDetail.ComponentName, ingressKey, constants.PORT)
 			} else {
-				ingressData = append(ingressData, fmt.Sprintf("%s_%s, %s_%s_%s", componentDetail.ComponentName,
-					constants.HOST, componentDetail.ComponentName, ingressKey, constants.PORT))
+				ingress.IngressKey = fmt.Sprintf("%s_%s, %s_%s_%s", componentDetail.ComponentName,
+					constants.HOST, componentDetail.ComponentName, ingressKey, constants.PORT)
 			}
-			tableData = append(tableData, ingressData)
+			ingresses = append(ingresses, ingress)
 		}
 	}
-	if len(tableData) > 0 {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"COMPONENT", "INGRESS TYPE", "INGRESS PORT", "INGRESS_KEY"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(tableData)
-		table.Render()
-	} else {
+	if len(ingresses) == 0 && output.IsTable(outputFormat) {
 		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for composite image, %s", compositeImageContent))
+		return nil
 	}
-	return nil
+	table := output.NewTable("COMPONENT", "INGRESS TYPE", "INGRESS PORT", "INGRESS_KEY")
+	for _, ingress := range ingresses {
+		table.Append(ingress.Component, ingress.IngressType, ingress.IngressPort, ingress.IngressKey)
+	}
+	return output.PrintTable(cli.Out(), outputFormat, ingresses, table)
 }
 
-func displayCellImageApisTable(cli cli.Cli, cellImageContent string) error {
+func displayCellImageApisTable(cli cli.Cli, cellImageContent string, outputFormat string) error {
 	cell, err := getIngressValues(cli, cellImageContent)
 	if err != nil {
 		return fmt.Errorf("error occurred while displaying cell image ingress, %v", err)
 	}
-	var tableData [][]string
+	ingresses := []cellImageIngress{}
 	for _, componentDetail := range cell.Component {
 		for ingress, ingressInfo := range componentDetail.Ingress {
-			var ingressData []string
 			if !(ingressInfo.Expose == "global" || ingressInfo.Expose == "local") {
 				ingressInfo.Expose = "false"
 			}
 			if ingressInfo.IngressType == constants.HttpApiIngress && ingressInfo.Context != "" {
 				if len(ingressInfo.Definition) == 0 {
-					ingressData = []string{componentDetail.ComponentName, ingressInfo.IngressType,
-						ingressInfo.Context, ingressInfo.ApiVersion, strconv.Itoa(int(ingressInfo.Port)),
-						constants.NA, constants.NA, ingressInfo.Expose, constants.NA,
-						fmt.Sprintf("%s_%s_%s", componentDetail.ComponentName, ingress, "api_url")}
-					tableData = append(tableData, ingressData)
+					ingresses = append(ingresses, cellImageIngress{componentDetail.ComponentName,
+						ingressInfo.IngressType, ingressInfo.Context, ingressInfo.ApiVersion,
+						strconv.Itoa(int(ingressInfo.Port)), constants.NA, constants.NA, ingressInfo.Expose,
+						constants.NA, fmt.Sprintf("%s_%s_%s", componentDetail.ComponentName, ingress, "api_url")})
 				} else {
 					for _, resourcesValue := range ingressInfo.Definition {
 						for _, resource := range resourcesValue {
-							ingressData = []string{componentDetail.ComponentName, ingressInfo.IngressType,
-								ingressInfo.Context, ingressInfo.ApiVersion, strconv.Itoa(int(ingressInfo.Port)),
-								resource.Path, resource.Method, ingressInfo.Expose, constants.NA,
-								fmt.Sprintf("%s_%s_%s", componentDetail.ComponentName, ingress, "api_url")}
-							tableData = append(tableData, ingressData)
+							ingresses = append(ingresses, cellImageIngress{componentDetail.ComponentName,
+								ingressInfo.IngressType, ingressInfo.Context, ingressInfo.ApiVersion,
+								strconv.Itoa(int(ingressInfo.Port)), resource.Path, resource.Method,
+								ingressInfo.Expose, constants.NA,
+								fmt.Sprintf("%s_%s_%s", componentDetail.ComponentName, ingress, "api_url")})
 						}
 					}
 				}
 			} else if ingressInfo.IngressType == constants.WebIngress {
-				ingressData = []string{componentDetail.ComponentName, ingressInfo.IngressType,
+				ingresses = append(ingresses, cellImageIngress{componentDetail.ComponentName, ingressInfo.IngressType,
 					ingressInfo.GatewayConfig.Context, ingressInfo.ApiVersion, strconv.Itoa(int(ingressInfo.Port)),
-					constants.NA, constants.NA, ingressInfo.Expose, ingressInfo.GatewayConfig.Vhost, constants.NA}
-				tableData = append(tableData, ingressData)
+					constants.NA, constants.NA, ingressInfo.Expose, ingressInfo.GatewayConfig.Vhost, constants.NA})
 
 			} else if ingressInfo.IngressType == constants.GrpcIngress {
-				ingressData = []string{componentDetail.ComponentName, ingressInfo.IngressType, constants.NA,
-					constants.NA, strconv.Itoa(ingressInfo.GatewayPort), constants.NA, constants.NA, constants.NA,
-					ingressInfo.GatewayConfig.Vhost,
+				ingresses = append(ingresses, cellImageIngress{componentDetail.ComponentName, ingressInfo.IngressType,
+					constants.NA, constants.NA, strconv.Itoa(ingressInfo.GatewayPort), constants.NA, constants.NA,
+					constants.NA, ingressInfo.GatewayConfig.Vhost,
 					fmt.Sprintf("%s, %s_%s_%s", constants.GatewayHost, componentDetail.ComponentName,
-						ingress, "grpc_port")}
-				tableData = append(tableData, ingressData)
+						ingress, "grpc_port")})
 
 			} else if ingressInfo.IngressTypeTCP == constants.TcpIngress {
-				if (ingressInfo.GatewayPort) == 0 {
-					ingressData = []string{componentDetail.ComponentName, ingressInfo.IngressTypeTCP, constants.NA,
-						constants.NA, "--", constants.NA, constants.NA, ingressInfo.Expose,
-						ingressInfo.GatewayConfig.Vhost, fmt.Sprintf("%s, %s_%s_tcp_%s", constants.GatewayHost,
-							componentDetail.ComponentName, ingress, constants.PORT)}
-				} else {
-					ingressData = []string{componentDetail.ComponentName, ingressInfo.IngressTypeTCP, constants.NA,
-						constants.NA, strconv.Itoa(ingressInfo.GatewayPort), constants.NA, constants.NA,
-						ingressInfo.Expose, ingressInfo.GatewayConfig.Vhost,
-						fmt.Sprintf("%s, %s_%s_tcp_%s", constants.GatewayHost, componentDetail.ComponentName,
-							ingress, constants.PORT)}
+				gatewayPort := "--"
+				if (ingressInfo.GatewayPort) != 0 {
+					gatewayPort = strconv.Itoa(ingressInfo.GatewayPort)
 				}
-				tableData = append(tableData, ingressData)
+				ingresses = append(ingresses, cellImageIngress{componentDetail.ComponentName,
+					ingressInfo.IngressTypeTCP, constants.NA, constants.NA, gatewayPort, constants.NA, constants.NA,
+					ingressInfo.Expose, ingressInfo.GatewayConfig.Vhost,
+					fmt.Sprintf("%s, %s_%s_tcp_%s", constants.GatewayHost, componentDetail.ComponentName,
+						ingress, constants.PORT)})
 			}
 		}
 	}
-	if len(tableData) > 0 {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"COMPONENT", "INGRESS TYPE", "INGRESS CONTEXT", "INGRESS_VERSION", "INGRESS PORT", "RESOURCE", "METHOD", "EXPOSED", "VHOST", "INGRESS_KEY"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(tableData)
-		table.Render()
-	} else {
+	if len(ingresses) == 0 && output.IsTable(outputFormat) {
 		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for cell image, %s", cellImageContent))
+		return nil
 	}
-	return nil
+	table := output.NewTable("COMPONENT", "INGRESS TYPE", "INGRESS CONTEXT", "INGRESS_VERSION", "INGRESS PORT",
+		"RESOURCE", "METHOD", "EXPOSED", "VHOST", "INGRESS_KEY")
+	for _, ingress := range ingresses {
+		table.Append(ingress.Component, ingress.IngressType, ingress.IngressContext, ingress.IngressVersion,
+			ingress.IngressPort, ingress.Resource, ingress.Method, ingress.Exposed, ingress.Vhost, ingress.IngressKey)
+	}
+	return output.PrintTable(cli.Out(), outputFormat, ingresses, table)
 }
 
 func getIngressValues(cli cli.Cli, cellImageContent string) (kubernetes.Cell, error) {
@@ -388,6 +356,7 @@ func getIngressValues(cli cli.Cli, cellImageContent string) (kubernetes.Cell, er
 	if err != nil {
 		return kubernetes.Cell{}, fmt.Errorf("error occurred while extracting image: %s", err)
 	}
+	defer os.RemoveAll(imageDir)
 
 	jsonFile, err := os.Open(fmt.Sprintf("%s/%s/%s/%s%s%s", imageDir, constants.ZipArtifacts, constants.CELLERY,
 		parsedCellImage.ImageName, constants.ZipMetaSuffix, constants.JsonExt))
@@ -112,7 +112,7 @@ func TestRunListIngresses(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunListIngresses(tst.mockCli, tst.arg)
+			err := RunListIngresses(tst.mockCli, tst.arg, "")
 			if err != nil {
 				t.Errorf("error in RunListIngresses, %v", err)
 			}
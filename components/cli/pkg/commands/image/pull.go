@@ -104,20 +104,42 @@ func RunPull(cli cli.Cli, cellImage string, isSilent bool, username string, pass
 }
 
 func pullImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, password string) error {
-	var err error
-	var cellImage []byte
-	if err := cli.ExecuteTask("Pulling cell image", "Failed to pull image",
-		"", func() error {
-			cellImage, err = cli.Registry().Pull(parsedCellImage, username, password)
-			if err != nil {
-				return err
-			}
-			return nil
-		}); err != nil {
+	imageLocation := filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization,
+		parsedCellImage.ImageName)
+	err := util.CreateDir(imageLocation)
+	if err != nil {
+		return fmt.Errorf("error occurred while saving cell image to local repo, %v", err)
+	}
+	// The cell image is downloaded to a temporary file to keep the old image until the new image is pulled
+	downloadFile, err := ioutil.TempFile(imageLocation, "."+parsedCellImage.ImageVersion+"-*.partial")
+	if err != nil {
+		return fmt.Errorf("error occurred while saving cell image to local repo, %v", err)
+	}
+	defer os.Remove(downloadFile.Name())
+	err = cli.ExecuteTaskWithProgress("Pulling cell image", "Failed to pull image",
+		"", func(progress func(transferred, total int64)) error {
+			return cli.Registry().Pull(parsedCellImage, username, password, downloadFile, progress)
+		})
+	if closeErr := downloadFile.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
 		return fmt.Errorf("error pulling image, %v", err)
 	}
-	repoLocation := filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization,
-		parsedCellImage.ImageName, parsedCellImage.ImageVersion)
+	imageDigest, err := localImageDigest(downloadFile.Name())
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the cell image, %v", err)
+	}
+	// Verifying the image before it replaces the image in the local repo, the old image is kept if the new image
+	// is not accepted
+	signatureBytes, err := pullSignature(cli, parsedCellImage, imageDigest, username, password)
+	if err == nil {
+		err = verifySignature(cli, parsedCellImage, imageDigest, signatureBytes)
+	}
+	if err != nil {
+		return fmt.Errorf("image verification failed, %v", err)
+	}
+	repoLocation := filepath.Join(imageLocation, parsedCellImage.ImageVersion)
 	// Cleaning up the old image if it already exists
 	hasOldImage, err := util.FileExists(repoLocation)
 	if err != nil {
@@ -135,11 +157,19 @@ func pullImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, p
 	if err != nil {
 		return fmt.Errorf("error occurred while saving cell image to local repo, %v", err)
 	}
-	// Writing the Cell Image to local file
+	// Moving the Cell Image to the local repo
 	cellImageFile := filepath.Join(repoLocation, parsedCellImage.ImageName+cellImageExt)
-	err = ioutil.WriteFile(cellImageFile, cellImage, 0644)
+	err = os.Rename(downloadFile.Name(), cellImageFile)
 	if err != nil {
 		return fmt.Errorf("error occurred while saving cell image to local repo, %v", err)
 	}
+	if err = os.Chmod(cellImageFile, 0644); err != nil {
+		return fmt.Errorf("error occurred while saving cell image to local repo, %v", err)
+	}
+	if signatureBytes != nil {
+		if err = ioutil.WriteFile(localSignatureFile(cli, parsedCellImage), signatureBytes, 0644); err != nil {
+			return fmt.Errorf("error occurred while saving the signature, %v", err)
+		}
+	}
 	return nil
 }
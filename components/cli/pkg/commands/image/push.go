@@ -32,19 +32,22 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/constants"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/registry"
 	"cellery.io/cellery/components/cli/pkg/registry/credentials"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 // RunPush parses the cell image name to recognize the Cellery Registry (A Docker Registry), Organization and version
 // and pushes to the Cellery Registry
-func RunPush(cli cli.Cli, cellImage string, username string, password string) error {
+func RunPush(cli cli.Cli, cellImage string, username string, password string,
+	manifestFormat registry.ManifestFormat) error {
 	parsedCellImage, err := image.ParseImageTag(cellImage)
 	//Read docker images from metadata.json
 	imageDir, err := ExtractImage(cli, parsedCellImage, false)
 	if err != nil {
 		return fmt.Errorf("error occurred while extracting image, %v", err)
 	}
+	defer os.RemoveAll(imageDir)
 	metadataFileContent, err := ioutil.ReadFile(filepath.Join(imageDir, artifacts, "cellery",
 		"metadata.json"))
 	if err != nil {
@@ -88,7 +91,8 @@ func RunPush(cli cli.Cli, cellImage string, username string, password string) er
 	}
 	if isCredentialsPresent {
 		// Pushing the image using the saved credentials
-		err = pushImage(cli, parsedCellImage, registryCredentials.Username, registryCredentials.Password)
+		err = pushImage(cli, parsedCellImage, registryCredentials.Username, registryCredentials.Password,
+			manifestFormat)
 		if err != nil {
 			return fmt.Errorf("failed to push image, %v", err)
 		}
@@ -97,7 +101,7 @@ func RunPush(cli cli.Cli, cellImage string, username string, password string) er
 		}
 	} else {
 		// Pushing image without credentials
-		err = pushImage(cli, parsedCellImage, "", "")
+		err = pushImage(cli, parsedCellImage, "", "", manifestFormat)
 		if err != nil {
 			if strings.Contains(err.Error(), "401") {
 				log.Printf("Unauthorized to push Cell image. Trying to login")
@@ -136,7 +140,8 @@ func RunPush(cli cli.Cli, cellImage string, username string, password string) er
 				fmt.Println()
 
 				// Trying to push the image again with the provided credentials
-				err = pushImage(cli, parsedCellImage, registryCredentials.Username, registryCredentials.Password)
+				err = pushImage(cli, parsedCellImage, registryCredentials.Username, registryCredentials.Password,
+					manifestFormat)
 				if err != nil {
 					return fmt.Errorf("failed to push image, %v", err)
 				}
@@ -168,7 +173,8 @@ func RunPush(cli cli.Cli, cellImage string, username string, password string) er
 	return nil
 }
 
-func pushImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, password string) error {
+func pushImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, password string,
+	manifestFormat registry.ManifestFormat) error {
 	log.Printf("Pushing image %s/%s:%s to registry %s", parsedCellImage.Organization,
 		parsedCellImage.ImageName, parsedCellImage.ImageVersion, parsedCellImage.Registry)
 	repository := parsedCellImage.Organization + "/" + parsedCellImage.ImageName
@@ -201,13 +207,9 @@ func pushImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, p
 			return nil
 		}()
 	}
-	cellImageFileBytes, err := ioutil.ReadAll(cellImageFile)
-	if err != nil {
-		return fmt.Errorf("error occurred while reading the cell image, %v", err)
-	}
-	if err := cli.ExecuteTask("Pushing cell image", "Failed to push image",
-		"", func() error {
-			err = cli.Registry().Push(parsedCellImage, cellImageFileBytes, username, password)
+	if err := cli.ExecuteTaskWithProgress("Pushing cell image", "Failed to push image",
+		"", func(progress func(transferred, total int64)) error {
+			err = cli.Registry().Push(parsedCellImage, cellImageFile, username, password, manifestFormat, progress)
 			if err != nil {
 				return err
 			}
@@ -215,5 +217,8 @@ func pushImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, p
 		}); err != nil {
 		return fmt.Errorf("error pushing image, %v", err)
 	}
+	if err := pushSignature(cli, parsedCellImage, username, password); err != nil {
+		return fmt.Errorf("error pushing the signature of the image, %v", err)
+	}
 	return nil
 }
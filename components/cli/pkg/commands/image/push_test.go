@@ -28,6 +28,7 @@ import (
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/registry"
 )
 
 func TestRunPush(t *testing.T) {
@@ -68,7 +69,7 @@ func TestRunPush(t *testing.T) {
 				test.SetRegistry(test.NewMockRegistry()),
 				test.SetDockerCli(test.NewMockDockerCli()),
 			)
-			err := RunPush(mockCli, tst.image, tst.username, tst.password)
+			err := RunPush(mockCli, tst.image, tst.username, tst.password, registry.ManifestFormatOci)
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunPush, %v", err)
@@ -101,7 +102,7 @@ func TestPushImage(t *testing.T) {
 	}
 	mockFileSystem := test.NewMockFileSystem(test.SetRepository(mockRepo))
 	err = pushImage(test.NewMockCli(test.SetRegistry(test.NewMockRegistry()), test.SetFileSystem(mockFileSystem)),
-		parsedCellImage, "alice", "alice123")
+		parsedCellImage, "alice", "alice123", registry.ManifestFormatOci)
 	if err != nil {
 		t.Errorf("pullImage err, %v", err)
 	}
@@ -0,0 +1,202 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/registry"
+	"cellery.io/cellery/components/cli/pkg/registry/credentials"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+type remoteImageData struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+type remoteTagData struct {
+	Tag            string `json:"tag"`
+	Kind           string `json:"kind"`
+	Created        string `json:"created"`
+	CelleryVersion string `json:"celleryVersion"`
+	// Fields only displayed in the wide output format
+	Components int `json:"components"`
+}
+
+// RunSearch searches the catalog of the Cellery Registry for the cell images with names containing the query.
+func RunSearch(cli cli.Cli, registryHost string, query string, username string, password string,
+	outputFormat string) error {
+	username, password, err := remoteCredentials(cli, registryHost, username, password)
+	if err != nil {
+		return fmt.Errorf("failed to acquire credentials, %v", err)
+	}
+	repositories, err := cli.Registry().Repositories(registryHost, username, password)
+	if err != nil {
+		return fmt.Errorf("error searching images, %v", err)
+	}
+	images := []remoteImageData{}
+	for _, repository := range repositories {
+		if !strings.Contains(strings.ToLower(repository), strings.ToLower(query)) {
+			continue
+		}
+		tags, err := cli.Registry().Tags(registryHost, repository, username, password)
+		if err != nil {
+			return fmt.Errorf("error searching images, %v", err)
+		}
+		images = append(images, remoteImageData{Name: repository, Tags: imageTags(tags)})
+	}
+	if len(images) == 0 && output.IsTable(outputFormat) {
+		fmt.Fprintf(cli.Out(), "No images matching %s found in %s.\n", query, registryHost)
+		return nil
+	}
+	table := output.NewTable("IMAGE", "TAGS")
+	for _, i := range images {
+		table.Append(i.Name, strings.Join(i.Tags, ", "))
+	}
+	return output.PrintTable(cli.Out(), outputFormat, images, table)
+}
+
+// RunListRemoteTags lists the tags of a cell image in the Cellery Registry along with the kind, build time and
+// Cellery version read from the metadata of each tag.
+func RunListRemoteTags(cli cli.Cli, repository string, username string, password string, outputFormat string) error {
+	parsedRepository, err := image.ParseImageRepository(repository)
+	if err != nil {
+		return fmt.Errorf("error occurred while parsing cell image, %v", err)
+	}
+	username, password, err = remoteCredentials(cli, parsedRepository.Registry, username, password)
+	if err != nil {
+		return fmt.Errorf("failed to acquire credentials, %v", err)
+	}
+	tags, err := cli.Registry().Tags(parsedRepository.Registry,
+		parsedRepository.Organization+"/"+parsedRepository.ImageName, username, password)
+	if err != nil {
+		return fmt.Errorf("error listing tags of image %s, %v", repository, err)
+	}
+	tags = imageTags(tags)
+	sort.Strings(tags)
+	tagsData := []remoteTagData{}
+	for _, tag := range tags {
+		tagData := remoteTagData{Tag: tag}
+		parsedCellImage := *parsedRepository
+		parsedCellImage.ImageVersion = tag
+		metadata, err := cli.Registry().MetaData(&parsedCellImage, username, password)
+		if err != nil {
+			// Images pushed with older manifest formats do not carry the metadata
+			log.Printf("Unable to read the metadata of %s:%s, %v", repository, tag, err)
+		} else {
+			tagData.Kind = metadata.Kind
+			tagData.Created = fmt.Sprintf("%s ago", units.HumanDuration(time.Since(
+				time.Unix(metadata.BuildTimestamp, 0))))
+			tagData.CelleryVersion = metadata.BuildCelleryVersion
+			tagData.Components = len(metadata.Components)
+		}
+		tagsData = append(tagsData, tagData)
+	}
+	if len(tagsData) == 0 && output.IsTable(outputFormat) {
+		fmt.Fprintf(cli.Out(), "No tags found for image %s.\n", repository)
+		return nil
+	}
+	table := output.NewTable("TAG", "KIND", "CREATED", "CELLERY VERSION").AddWideColumns("COMPONENTS")
+	for _, t := range tagsData {
+		if t.Kind == "" {
+			table.Append(t.Tag, "-", "-", "-", "-")
+		} else {
+			table.Append(t.Tag, t.Kind, t.Created, t.CelleryVersion, strconv.Itoa(t.Components))
+		}
+	}
+	return output.PrintTable(cli.Out(), outputFormat, tagsData, table)
+}
+
+// imageTags returns the tags of the cell images, leaving out the tags holding the signatures of the cell images
+func imageTags(tags []string) []string {
+	imageTags := []string{}
+	for _, tag := range tags {
+		if !registry.IsSignatureTag(tag) {
+			imageTags = append(imageTags, tag)
+		}
+	}
+	return imageTags
+}
+
+// RunInspectRemote prints the metadata of a cell image in the Cellery Registry without pulling the cell image.
+func RunInspectRemote(cli cli.Cli, cellImage string, username string, password string) error {
+	parsedCellImage, err := image.ParseImageTag(cellImage)
+	if err != nil {
+		return fmt.Errorf("error occurred while parsing cell image, %v", err)
+	}
+	username, password, err = remoteCredentials(cli, parsedCellImage.Registry, username, password)
+	if err != nil {
+		return fmt.Errorf("failed to acquire credentials, %v", err)
+	}
+	metadata, err := cli.Registry().MetaData(parsedCellImage, username, password)
+	if err != nil {
+		return fmt.Errorf("failed to read the metadata of image %s, %v", cellImage, err)
+	}
+	buildTime := time.Unix(metadata.BuildTimestamp, 0)
+	fmt.Fprintf(cli.Out(), "\n%s\n", util.Bold(fmt.Sprintf("%s/%s/%s:%s", parsedCellImage.Registry,
+		parsedCellImage.Organization, parsedCellImage.ImageName, parsedCellImage.ImageVersion)))
+	fmt.Fprintf(cli.Out(), "  Kind            : %s\n", metadata.Kind)
+	fmt.Fprintf(cli.Out(), "  Build Time      : %s (%s ago)\n", buildTime.Format(time.RFC1123),
+		units.HumanDuration(time.Since(buildTime)))
+	fmt.Fprintf(cli.Out(), "  Cellery Version : %s\n", metadata.BuildCelleryVersion)
+	fmt.Fprintf(cli.Out(), "  Components      :\n")
+	var components []string
+	for component := range metadata.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	for i, component := range components {
+		if i == len(components)-1 {
+			fmt.Fprintf(cli.Out(), "    └")
+		} else {
+			fmt.Fprintf(cli.Out(), "    ├")
+		}
+		fmt.Fprintf(cli.Out(), "──%s (%s)\n", component, metadata.Components[component].DockerImage)
+	}
+	fmt.Fprintln(cli.Out())
+	return nil
+}
+
+// remoteCredentials returns the credentials used to browse the registry. The saved credentials are used if
+// the credentials are not provided, and the registry is accessed anonymously if there are no saved credentials.
+func remoteCredentials(cli cli.Cli, registryHost string, username string, password string) (string, string,
+	error) {
+	if username != "" && password == "" {
+		return credentials.FromTerminal(username)
+	}
+	if username != "" || cli.CredManager() == nil {
+		return username, password, nil
+	}
+	savedCredentials, err := cli.CredManager().GetCredentials(registryHost)
+	if err == nil && savedCredentials != nil && savedCredentials.Username != "" && savedCredentials.Password != "" {
+		return savedCredentials.Username, savedCredentials.Password, nil
+	}
+	return "", "", nil
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+func newRemoteMockCli() *test.MockCli {
+	buildTimestamp := time.Now().Add(-2 * time.Hour).Unix()
+	mockRegistry := test.NewMockRegistry(test.SetMetaData(map[string]*image.MetaData{
+		"myorg/hello:1.0.0": {
+			Kind:                "Cell",
+			BuildTimestamp:      buildTimestamp,
+			BuildCelleryVersion: "0.5.0",
+			Components: map[string]*image.ComponentMetaData{
+				"greeter": {DockerImage: "wso2cellery/greeter:1.0.0"},
+			},
+		},
+		"myorg/hello:2.0.0": {
+			Kind:                "Composite",
+			BuildTimestamp:      buildTimestamp,
+			BuildCelleryVersion: "0.6.0",
+		},
+		// Signatures are stored as tags of the image, which are not listed as tags of the image
+		"myorg/hello:sha256-5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03.sig": {},
+		"myorg/employee:1.0.0": {
+			Kind:                "Cell",
+			BuildTimestamp:      buildTimestamp,
+			BuildCelleryVersion: "0.5.0",
+		},
+	}))
+	return test.NewMockCli(test.SetRegistry(mockRegistry), test.SetCredManager(test.NewMockCredManager()))
+}
+
+func TestRunSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		format   string
+		expected string
+	}{
+		{
+			name:     "search matching images",
+			query:    "Hello",
+			format:   "jsonpath={.[*].name}",
+			expected: "myorg/hello",
+		},
+		{
+			name:     "search all images in organization",
+			query:    "myorg/",
+			format:   "jsonpath={.[*].name}",
+			expected: "myorg/employee myorg/hello",
+		},
+		{
+			name:     "search tags of matching images",
+			query:    "hello",
+			format:   "jsonpath={.[0].tags}",
+			expected: `["1.0.0","2.0.0"]`,
+		},
+		{
+			name:     "search without matches",
+			query:    "foo",
+			format:   "",
+			expected: "No images matching foo found in registry.hub.cellery.io.\n",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := newRemoteMockCli()
+			err := RunSearch(mockCli, "registry.hub.cellery.io", tst.query, "", "", tst.format)
+			if err != nil {
+				t.Fatalf("error in RunSearch, %v", err)
+			}
+			if diff := cmp.Diff(tst.expected, mockCli.OutBuffer().String()); diff != "" {
+				t.Errorf("RunSearch: unexpected output (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRunListRemoteTags(t *testing.T) {
+	mockCli := newRemoteMockCli()
+	err := RunListRemoteTags(mockCli, "myorg/hello", "", "", "jsonpath={.[*].tag} {.[*].kind} "+
+		"{.[*].celleryVersion} {.[*].created}")
+	if err != nil {
+		t.Fatalf("error in RunListRemoteTags, %v", err)
+	}
+	if diff := cmp.Diff("1.0.0 2.0.0 Cell Composite 0.5.0 0.6.0 2 hours ago 2 hours ago",
+		mockCli.OutBuffer().String()); diff != "" {
+		t.Errorf("RunListRemoteTags: unexpected output (-want, +got)\n%v", diff)
+	}
+
+	err = RunListRemoteTags(newRemoteMockCli(), "myorg/foo", "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("RunListRemoteTags: expected a not found error for a non-existing image, got %v", err)
+	}
+}
+
+func TestRunInspectRemote(t *testing.T) {
+	mockCli := newRemoteMockCli()
+	err := RunInspectRemote(mockCli, "myorg/hello:1.0.0", "", "")
+	if err != nil {
+		t.Fatalf("error in RunInspectRemote, %v", err)
+	}
+	for _, expected := range []string{"Cell", "0.5.0", "2 hours ago", "greeter (wso2cellery/greeter:1.0.0)"} {
+		if !strings.Contains(mockCli.OutBuffer().String(), expected) {
+			t.Errorf("RunInspectRemote: expected %q in output\n%s", expected, mockCli.OutBuffer().String())
+		}
+	}
+
+	err = RunInspectRemote(newRemoteMockCli(), "myorg/hello:3.0.0", "", "")
+	if err == nil {
+		t.Errorf("RunInspectRemote: expected an error for a non-existing image")
+	}
+}
@@ -37,12 +37,17 @@ import (
 
 const celleryEnvVarPrefix = "cellery_env_"
 const celleryImageDirEnvVar = "CELLERY_IMAGE_DIR"
+const celleryDryRunDirEnvVar = "CELLERY_DRY_RUN_DIR"
+
+// dryRunDir is the directory within the extracted image to which the instances are rendered in dry run mode
+const dryRunDir = "dry-run"
 
 // RunRun starts Cell instance (along with dependency instances if specified by the user)
 // This also support linking instances to parts of the dependency tree
 // This command also strictly validates whether the requested Cell (and the dependencies are valid)
+// If an output directory is provided, the resolved instances are written to it as yaml instead of being applied
 func RunRun(cli cli.Cli, cellImageTag string, instanceName string, startDependencies bool, shareDependencies bool,
-	dependencyLinks []string, envVars []string) error {
+	dependencyLinks []string, envVars []string, dryRunOutputDir string) error {
 	var err error
 	if err = cli.Runtime().Validate(); err != nil {
 		return fmt.Errorf("runtime validation failed. %v", err)
@@ -51,11 +56,15 @@ func RunRun(cli cli.Cli, cellImageTag string, instanceName string, startDependen
 	if err != nil {
 		return err
 	}
+	if dryRunOutputDir != "" {
+		return renderCellInstance(cli, cellImageTag, extractedImage, instanceName, startDependencies,
+			shareDependencies, dryRunOutputDir)
+	}
 
 	if err = cli.ExecuteTask(fmt.Sprintf("Starting main instance %v", util.Bold(instanceName)),
 		fmt.Sprintf("Failed to start main instance %v", util.Bold(instanceName)),
 		"", func() error {
-			err = startCellInstance(cli, extractedImage, instanceName, startDependencies, shareDependencies)
+			err = startCellInstance(cli, extractedImage, instanceName, startDependencies, shareDependencies, "")
 			return err
 		}); err != nil {
 		return err
@@ -65,7 +74,45 @@ func RunRun(cli cli.Cli, cellImageTag string, instanceName string, startDependen
 	return nil
 }
 
-func startCellInstance(cli cli.Cli, extractedImage *ExtractedImage, instanceName string, startDependencies bool, shareDependencies bool) error {
+// renderCellInstance goes through the same steps as starting the instances, but writes the resolved Cell and
+// Composite resources of the main instance and the dependency instances to the output directory.
+func renderCellInstance(cli cli.Cli, cellImageTag string, extractedImage *ExtractedImage, instanceName string,
+	startDependencies bool, shareDependencies bool, outputDir string) error {
+	renderDir := filepath.Join(extractedImage.ImageDir, dryRunDir)
+	if err := util.CreateDir(renderDir); err != nil {
+		return fmt.Errorf("error occurred while creating the dry run directory, %v", err)
+	}
+	if err := cli.ExecuteTask(fmt.Sprintf("Rendering main instance %v", util.Bold(instanceName)),
+		fmt.Sprintf("Failed to render main instance %v", util.Bold(instanceName)),
+		"", func() error {
+			return startCellInstance(cli, extractedImage, instanceName, startDependencies, shareDependencies,
+				renderDir)
+		}); err != nil {
+		return err
+	}
+	renderedFiles, err := ioutil.ReadDir(renderDir)
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the rendered instances, %v", err)
+	}
+	if err := util.CreateDir(outputDir); err != nil {
+		return fmt.Errorf("error occurred while creating the output directory, %v", err)
+	}
+	for _, renderedFile := range renderedFiles {
+		if err := util.CopyFile(filepath.Join(renderDir, renderedFile.Name()),
+			filepath.Join(outputDir, renderedFile.Name())); err != nil {
+			return fmt.Errorf("error occurred while writing %s to the output directory, %v", renderedFile.Name(),
+				err)
+		}
+		fmt.Fprintf(cli.Out(), "Rendered %s\n", filepath.Join(outputDir, renderedFile.Name()))
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully rendered cell image %s to %s", util.Bold(cellImageTag),
+		util.Bold(outputDir)))
+	util.PrintWhatsNextMessage("deploy the rendered instances", "kubectl apply -f "+outputDir)
+	return nil
+}
+
+func startCellInstance(cli cli.Cli, extractedImage *ExtractedImage, instanceName string, startDependencies bool,
+	shareDependencies bool, renderDir string) error {
 	var tmpProjectDir string
 	var tempRunBalSource string
 	imageDir := extractedImage.ImageDir
@@ -113,6 +160,12 @@ func startCellInstance(cli cli.Cli, extractedImage *ExtractedImage, instanceName
 	balEnvVars = append(balEnvVars, &ballerina.EnvironmentVariable{
 		Key:   celleryImageDirEnvVar,
 		Value: imageDir})
+	if renderDir != "" {
+		// The instances are written to the directory instead of being applied
+		balEnvVars = append(balEnvVars, &ballerina.EnvironmentVariable{
+			Key:   celleryDryRunDirEnvVar,
+			Value: renderDir})
+	}
 	// Setting user defined environment variables.
 	for _, envVar := range envVars {
 		// Export environment variables defined by user for root instance
@@ -165,6 +218,10 @@ func ExtractImage(cli cli.Cli, cellImage *image.CellImage, pullIfNotPresent bool
 				cellImage.ImageName, cellImage.ImageVersion)
 		}
 	}
+	// Enforcing the image verification policy before the image is unzipped
+	if err = verifyImageSignature(cli, cellImage); err != nil {
+		return "", fmt.Errorf("image verification failed, %v", err)
+	}
 	// Unzipping image to a temporary location
 	celleryHomeTmp := path.Join(util.UserHomeDir(), celleryHome, "tmp")
 	if _, err := os.Stat(celleryHomeTmp); os.IsNotExist(err) {
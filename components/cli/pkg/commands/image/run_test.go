@@ -112,7 +112,7 @@ func TestRunRun(t *testing.T) {
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
 			err := RunRun(mockCli, tst.image, tst.instance, tst.startDependencies, tst.shareDependencies,
-				tst.dependencyLinks, tst.envVars)
+				tst.dependencyLinks, tst.envVars, "")
 			if err != nil {
 				t.Errorf("error in RunRun, %v", err)
 			}
@@ -158,8 +158,59 @@ func TestStartCellInstance(t *testing.T) {
 		RootNodeDependencies: rootNodeDependencies,
 		ImageDir:             imageDir,
 	}
-	err = startCellInstance(mockCli, extractedImage, "hello", false, false)
+	err = startCellInstance(mockCli, extractedImage, "hello", false, false, "")
 	if err != nil {
 		t.Errorf("startCellInstance failed: %v", err)
 	}
 }
+
+func TestRenderCellInstance(t *testing.T) {
+	yamlContent := []byte("apiVersion: mesh.cellery.io/v1alpha2\nkind: Cell\nmetadata:\n  name: hello\n")
+	mockBalExecutor := test.NewMockBalExecutor(test.SetYamlName("hello.yaml"), test.SetYamlContent(yamlContent))
+	mockCli := test.NewMockCli(test.SetBalExecutor(mockBalExecutor))
+	imageDir, err := ioutil.TempDir("", "temp")
+	if err != nil {
+		t.Errorf("Failed to create image dir: %v", err)
+	}
+	defer func() { os.RemoveAll(imageDir) }()
+	outputDir, err := ioutil.TempDir("", "output")
+	if err != nil {
+		t.Errorf("Failed to create output dir: %v", err)
+	}
+	defer func() { os.RemoveAll(outputDir) }()
+	err = os.MkdirAll(filepath.Join(imageDir, "src"), os.ModePerm)
+	if err != nil {
+		t.Errorf("Failed to create src directory: %v", err)
+	}
+	_, err = os.Create(filepath.Join(imageDir, "src", "hello.bal"))
+	if err != nil {
+		t.Errorf("Failed to create bal file: %v", err)
+	}
+	cellImageMetadata := &image.MetaData{
+		CellImageName: image.CellImageName{
+			Organization: "myorg",
+			Name:         "hello",
+			Version:      "1.0.0",
+		},
+	}
+	var extractedImage = &ExtractedImage{
+		MainNode: &dependencyTreeNode{
+			Instance: "hello",
+			MetaData: cellImageMetadata,
+		},
+		RootNodeDependencies: map[string]*dependencyInfo{},
+		ImageDir:             imageDir,
+	}
+	err = renderCellInstance(mockCli, "myorg/hello:1.0.0", extractedImage, "hello", false, false,
+		filepath.Join(outputDir, "manifests"))
+	if err != nil {
+		t.Errorf("renderCellInstance failed: %v", err)
+	}
+	renderedYaml, err := ioutil.ReadFile(filepath.Join(outputDir, "manifests", "hello.yaml"))
+	if err != nil {
+		t.Errorf("Failed to read the rendered yaml: %v", err)
+	}
+	if string(renderedYaml) != string(yamlContent) {
+		t.Errorf("Unexpected rendered yaml, want %q, got %q", yamlContent, renderedYaml)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/signature"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunSign signs a cell image in the local repository with the private key and stores the detached signature
+// next to the cell image. The signature is also pushed to the Cellery Registry unless only a local signature
+// is requested.
+func RunSign(cli cli.Cli, cellImage string, keyFile string, certificateFile string, localOnly bool,
+	username string, password string) error {
+	parsedCellImage, err := image.ParseImageTag(cellImage)
+	if err != nil {
+		return fmt.Errorf("error occurred while parsing cell image, %v", err)
+	}
+	cellImageFile := localCellImageFile(cli, parsedCellImage)
+	if isImagePresent, _ := util.FileExists(cellImageFile); !isImagePresent {
+		return fmt.Errorf("image %s not present on the local repository", cellImage)
+	}
+	signer, err := signature.LoadPrivateKey(keyFile)
+	if err != nil {
+		return err
+	}
+	var certificate *x509.Certificate
+	if certificateFile != "" {
+		certificates, err := signature.LoadCertificates(certificateFile)
+		if err != nil {
+			return err
+		}
+		certificate = certificates[0]
+	}
+	imageDigest, err := localImageDigest(cellImageFile)
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the cell image, %v", err)
+	}
+	imageSignature, err := signature.Sign(signedImageName(parsedCellImage), imageDigest, signer, certificate)
+	if err != nil {
+		return err
+	}
+	signatureBytes, err := imageSignature.Bytes()
+	if err != nil {
+		return fmt.Errorf("error occurred while saving the signature, %v", err)
+	}
+	if err := ioutil.WriteFile(localSignatureFile(cli, parsedCellImage), signatureBytes, 0644); err != nil {
+		return fmt.Errorf("error occurred while saving the signature, %v", err)
+	}
+	fmt.Fprintf(cli.Out(), "Signed image %s with digest %s\n", util.Bold(cellImage), imageDigest)
+	if !localOnly {
+		username, password, err = remoteCredentials(cli, parsedCellImage.Registry, username, password)
+		if err != nil {
+			return fmt.Errorf("failed to acquire credentials, %v", err)
+		}
+		if err := cli.ExecuteTask("Pushing signature", "Failed to push signature", "", func() error {
+			return cli.Registry().PushSignature(parsedCellImage, imageDigest, signatureBytes, username, password)
+		}); err != nil {
+			return fmt.Errorf("error pushing the signature of image %s, %v", cellImage, err)
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully signed cell image: %s", util.Bold(cellImage)))
+	return nil
+}
+
+// pushSignature pushes the local signature of the cell image, if the image is signed.
+func pushSignature(cli cli.Cli, parsedCellImage *image.CellImage, username string, password string) error {
+	signatureBytes, err := ioutil.ReadFile(localSignatureFile(cli, parsedCellImage))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the signature, %v", err)
+	}
+	imageSignature, err := signature.Parse(signatureBytes)
+	if err != nil {
+		return err
+	}
+	imageDigest, err := localImageDigest(localCellImageFile(cli, parsedCellImage))
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the cell image, %v", err)
+	}
+	if imageSignature.Digest != imageDigest {
+		util.PrintWarningMessage(fmt.Sprintf("Signature of the image %s is outdated and is not pushed, sign the "+
+			"image again", signedImageName(parsedCellImage)))
+		return nil
+	}
+	return cli.Registry().PushSignature(parsedCellImage, imageDigest, signatureBytes, username, password)
+}
+
+// pullSignature pulls the signature of the cell image with the given digest. Nil is returned if the image is not
+// signed.
+func pullSignature(cli cli.Cli, parsedCellImage *image.CellImage, imageDigest digest.Digest, username string,
+	password string) ([]byte, error) {
+	return cli.Registry().PullSignature(parsedCellImage, imageDigest, username, password)
+}
+
+// verifyImageSignature enforces the image verification policy on a cell image in the local repository.
+func verifyImageSignature(cli cli.Cli, parsedCellImage *image.CellImage) error {
+	imageDigest, err := localImageDigest(localCellImageFile(cli, parsedCellImage))
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the cell image, %v", err)
+	}
+	signatureBytes, err := ioutil.ReadFile(localSignatureFile(cli, parsedCellImage))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error occurred while reading the signature, %v", err)
+	}
+	return verifySignature(cli, parsedCellImage, imageDigest, signatureBytes)
+}
+
+// verifySignature enforces the image verification policy on a cell image with the given digest and signature.
+// The signature bytes are nil if the image is not signed.
+func verifySignature(cli cli.Cli, parsedCellImage *image.CellImage, imageDigest digest.Digest,
+	signatureBytes []byte) error {
+	policy, err := signature.LoadPolicy(filepath.Join(cli.FileSystem().UserHome(), celleryHome,
+		signature.PolicyFile))
+	if err != nil {
+		return err
+	}
+	var imageSignature *signature.Signature
+	if signatureBytes != nil {
+		if imageSignature, err = signature.Parse(signatureBytes); err != nil {
+			return err
+		}
+	}
+	return policy.Verify(signedImageName(parsedCellImage), imageDigest, imageSignature)
+}
+
+func localImageDigest(cellImageFile string) (digest.Digest, error) {
+	file, err := os.Open(cellImageFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return signature.Digest(file)
+}
+
+func localCellImageFile(cli cli.Cli, parsedCellImage *image.CellImage) string {
+	return filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization, parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion, parsedCellImage.ImageName+cellImageExt)
+}
+
+func localSignatureFile(cli cli.Cli, parsedCellImage *image.CellImage) string {
+	return filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization, parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion, parsedCellImage.ImageName+signature.FileExt)
+}
+
+// signedImageName returns the name of the cell image covered by the signature, which does not include the
+// registry since the same image can be pushed to multiple registries.
+func signedImageName(parsedCellImage *image.CellImage) string {
+	return fmt.Sprintf("%s/%s:%s", parsedCellImage.Organization, parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion)
+}
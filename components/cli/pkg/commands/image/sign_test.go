@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/signature"
+)
+
+func TestRunSign(t *testing.T) {
+	userHome, err := ioutil.TempDir("", "mock-home")
+	if err != nil {
+		t.Fatalf("failed to create mock user home, %v", err)
+	}
+	defer os.RemoveAll(userHome)
+	mockRepo := filepath.Join(userHome, celleryHome, "repo")
+	imageDir := filepath.Join(mockRepo, "myorg", "hello", "1.0.0")
+	if err := os.MkdirAll(imageDir, 0755); err != nil {
+		t.Fatalf("failed to create mock repository, %v", err)
+	}
+	sampleImage, err := ioutil.ReadFile(filepath.Join("testdata", "repo", "myorg", "hello", "1.0.0", "hello.zip"))
+	if err != nil {
+		t.Fatalf("error reading sample image file, %v", err)
+	}
+	ioutil.WriteFile(filepath.Join(imageDir, "hello.zip"), sampleImage, 0644)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keyDer, _ := x509.MarshalPKCS8PrivateKey(key)
+	keyFile := filepath.Join(userHome, "signer.key")
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600)
+	publicKeyDer, _ := x509.MarshalPKIXPublicKey(key.Public())
+	ioutil.WriteFile(filepath.Join(userHome, celleryHome, "signer.pub"),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer}), 0644)
+	ioutil.WriteFile(filepath.Join(userHome, celleryHome, signature.PolicyFile),
+		[]byte(`{"images": [{"match": "myorg/*", "policy": "signed", "publicKeys": ["signer.pub"]}]}`), 0644)
+
+	mockRegistry := test.NewMockRegistry()
+	mockCli := test.NewMockCli(
+		test.SetFileSystem(test.NewMockFileSystem(test.SetRepository(mockRepo), test.SetUserHome(userHome))),
+		test.SetRegistry(mockRegistry),
+		test.SetCredManager(test.NewMockCredManager()),
+	)
+	parsedCellImage, _ := image.ParseImageTag("myorg/hello:1.0.0")
+
+	err = verifyImageSignature(mockCli, parsedCellImage)
+	if err == nil || !strings.Contains(err.Error(), "is not signed") {
+		t.Errorf("verifyImageSignature: expected an error for an unsigned image, got %v", err)
+	}
+	if err := RunSign(mockCli, "myorg/hello:1.0.0", keyFile, "", false, "", ""); err != nil {
+		t.Fatalf("error in RunSign, %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(imageDir, "hello"+signature.FileExt)); err != nil {
+		t.Errorf("RunSign: signature not saved in the local repository, %v", err)
+	}
+	if len(mockRegistry.Signatures()) != 1 {
+		t.Errorf("RunSign: expected the signature to be pushed to the registry")
+	}
+	if err := verifyImageSignature(mockCli, parsedCellImage); err != nil {
+		t.Errorf("verifyImageSignature: unexpected error for a signed image, %v", err)
+	}
+
+	// Swapping the image should invalidate the signature
+	ioutil.WriteFile(filepath.Join(imageDir, "hello.zip"), append(sampleImage, 0), 0644)
+	err = verifyImageSignature(mockCli, parsedCellImage)
+	if err == nil || !strings.Contains(err.Error(), "does not match the image") {
+		t.Errorf("verifyImageSignature: expected an error for a modified image, got %v", err)
+	}
+
+	// A pulled image rejected by the policy should not replace the image in the local repository
+	ioutil.WriteFile(filepath.Join(imageDir, "hello.zip"), sampleImage, 0644)
+	mockCli = test.NewMockCli(
+		test.SetFileSystem(test.NewMockFileSystem(test.SetRepository(mockRepo), test.SetUserHome(userHome))),
+		test.SetRegistry(test.NewMockRegistry(test.SetImages(map[string][]byte{
+			"myorg/hello:1.0.0": append(sampleImage, 0),
+		}))),
+	)
+	err = pullImage(mockCli, parsedCellImage, "", "")
+	if err == nil || !strings.Contains(err.Error(), "image verification failed") {
+		t.Errorf("pullImage: expected an error for an unsigned image, got %v", err)
+	}
+	if err := verifyImageSignature(mockCli, parsedCellImage); err != nil {
+		t.Errorf("pullImage: expected the old image to be kept, %v", err)
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/revision"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
@@ -56,6 +57,10 @@ func RunApplyAutoscalePolicies(cli cli.Cli, kind kubernetes.InstanceKind, instan
 	if err != nil {
 		return fmt.Errorf("failed to marshall patch, %v", err)
 	}
+	snapshot, err := takeSnapshot(cli, instance, []revision.Resource{{Kind: ik, Name: instance}})
+	if err != nil {
+		return err
+	}
 	if err = cli.ExecuteTask("Applying autoscale policies", "Failed to apply autoscale policies",
 		"", func() error {
 			err = cli.KubeCli().JsonPatch(ik, instance, string(patchBytes))
@@ -63,6 +68,10 @@ func RunApplyAutoscalePolicies(cli cli.Cli, kind kubernetes.InstanceKind, instan
 		}); err != nil {
 		return fmt.Errorf("failed to apply patch, %v", err)
 	}
+	if err = recordRevision(cli, instance, fmt.Sprintf("apply autoscale policies from %s", file),
+		snapshot); err != nil {
+		return err
+	}
 	util.PrintSuccessMessage(fmt.Sprintf("Successfully applied autoscale policies for instance %q", instance))
 	return nil
 }
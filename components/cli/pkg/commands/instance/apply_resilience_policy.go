@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"cellery.io/cellery/components/cli/cli"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/policies"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func RunApplyResiliencePolicy(cli cli.Cli, instance string, file string) error {
+	policy, err := policies.ReadResiliencePolicy(file)
+	if err != nil {
+		return err
+	}
+	owner, err := getOwnerReference(cli, instance)
+	if err != nil {
+		return err
+	}
+	artifactFile := filepath.Join("./", fmt.Sprintf("%s-resilience.yaml", instance))
+	defer func() {
+		_ = os.Remove(artifactFile)
+	}()
+	var unusedDestinationRules []string
+	if err = cli.ExecuteTask("Preparing resilience policy data to apply", "Failed to prepare resilience policy",
+		"", func() error {
+			unusedDestinationRules, err = routing.BuildResilienceArtifacts(cli, instance, owner, policy, artifactFile)
+			return err
+		}); err != nil {
+		return fmt.Errorf("failed to build the resilience rules, %v", err)
+	}
+	snapshot, err := snapshotRevision(cli, instance, artifactFile)
+	if err != nil {
+		return err
+	}
+	if err = cli.ExecuteTask("Applying resilience policy", "Failed to apply resilience policy",
+		"", func() error {
+			if err := cli.KubeCli().ApplyFile(artifactFile); err != nil {
+				return err
+			}
+			for _, name := range unusedDestinationRules {
+				if _, err := cli.KubeCli().DeleteResource(policies.DestinationRuleResourceKind, name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+		return fmt.Errorf("failed to apply the resilience rules, %v", err)
+	}
+	if err = recordRevision(cli, instance, fmt.Sprintf("apply resilience policy from %s", file),
+		snapshot); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully applied resilience policy for instance %q", instance))
+	return nil
+}
+
+// getOwnerReference returns a reference to a cell or a composite instance, which owns the resources created for it
+func getOwnerReference(cli cli.Cli, instance string) (*kubernetes.OwnerReference, error) {
+	var kind, apiVersion string
+	var metadata kubernetes.K8SMetaData
+	cell, err := cli.KubeCli().GetCell(instance)
+	if err != nil {
+		if notFound, _ := errorpkg.IsCellInstanceNotFoundError(instance, err); !notFound {
+			return nil, err
+		}
+		composite, err := cli.KubeCli().GetComposite(instance)
+		if err != nil {
+			if notFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instance, err); notFound {
+				return nil, fmt.Errorf("unable to find a running instance with name: %s", instance)
+			}
+			return nil, err
+		}
+		kind, apiVersion, metadata = composite.Kind, composite.APIVersion, composite.CompositeMetaData
+	} else {
+		kind, apiVersion, metadata = cell.Kind, cell.APIVersion, cell.CellMetaData
+	}
+	if metadata.UID == "" {
+		return nil, nil
+	}
+	return &kubernetes.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       instance,
+		UID:        metadata.UID,
+	}, nil
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+const petBeDepDestinationRule = "destinationrule.networking.istio.io/pet-be-dep--gateway-resilience"
+
+// newPetBeDepDestinationRule returns the destination rule of the pet-be-dep gateway, shared by the given instances
+func newPetBeDepDestinationRule(t *testing.T, sources string, maxConnections int) []byte {
+	drBytes, err := json.Marshal(kubernetes.DestinationRule{
+		Kind:       "DestinationRule",
+		APIVersion: "networking.istio.io/v1alpha3",
+		Metadata: kubernetes.DestinationRuleMeta{
+			Name:        "pet-be-dep--gateway-resilience",
+			Labels:      map[string]string{"mesh.cellery.io/resilience-policy": "true"},
+			Annotations: map[string]string{"mesh.cellery.io/resilience-policy-of": sources},
+			OwnerReferences: []kubernetes.OwnerReference{
+				{APIVersion: "mesh.cellery.io/v1alpha2", Kind: "Cell", Name: "pet-fe-other", UID: "e5c8b0a2"},
+			},
+		},
+		Spec: kubernetes.DestinationRuleSpec{
+			Host: "pet-be-dep--gateway-service",
+			TrafficPolicy: kubernetes.TrafficPolicy{
+				ConnectionPool: &kubernetes.ConnectionPool{
+					Tcp:  &kubernetes.TCPConnectionPool{MaxConnections: maxConnections},
+					Http: &kubernetes.HTTPConnectionPool{Http1MaxPendingRequests: 10},
+				},
+				OutlierDetection: &kubernetes.OutlierDetection{
+					ConsecutiveErrors:  5,
+					Interval:           "10s",
+					BaseEjectionTime:   "30s",
+					MaxEjectionPercent: 50,
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal the destination rule, %v", err)
+	}
+	return drBytes
+}
+
+func TestRunApplyResiliencePolicy(t *testing.T) {
+	tests := []struct {
+		name                    string
+		file                    string
+		destinationRule         []byte
+		wantTimeout             string
+		wantRetries             *kubernetes.HTTPRetry
+		wantDestinationRule     *kubernetes.DestinationRule
+		wantDeletedResources    []string
+		wantErrorMessagePortion string
+	}{
+		{
+			name:        "apply timeouts, retries and circuit breaking",
+			file:        "pet-be-dep-resilience.yaml",
+			wantTimeout: "3s",
+			wantRetries: &kubernetes.HTTPRetry{
+				Attempts:      3,
+				PerTryTimeout: "1s",
+				RetryOn:       "5xx,connect-failure",
+			},
+			wantDestinationRule: &kubernetes.DestinationRule{
+				Kind:       "DestinationRule",
+				APIVersion: "networking.istio.io/v1alpha3",
+				Metadata: kubernetes.DestinationRuleMeta{
+					Name:        "pet-be-dep--gateway-resilience",
+					Labels:      map[string]string{"mesh.cellery.io/resilience-policy": "true"},
+					Annotations: map[string]string{"mesh.cellery.io/resilience-policy-of": "pet-fe-src"},
+					OwnerReferences: []kubernetes.OwnerReference{
+						{
+							APIVersion: "mesh.cellery.io/v1alpha2",
+							Kind:       "Cell",
+							Name:       "pet-fe-src",
+							UID:        "2b2a6e4c-0e4d-11ea-8d71-362b9e155667",
+						},
+					},
+				},
+				Spec: kubernetes.DestinationRuleSpec{
+					Host: "pet-be-dep--gateway-service",
+					TrafficPolicy: kubernetes.TrafficPolicy{
+						ConnectionPool: &kubernetes.ConnectionPool{
+							Tcp:  &kubernetes.TCPConnectionPool{MaxConnections: 100},
+							Http: &kubernetes.HTTPConnectionPool{Http1MaxPendingRequests: 10},
+						},
+						OutlierDetection: &kubernetes.OutlierDetection{
+							ConsecutiveErrors:  5,
+							Interval:           "10s",
+							BaseEjectionTime:   "30s",
+							MaxEjectionPercent: 50,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:            "apply circuit breaking shared with another instance",
+			file:            "pet-be-dep-resilience.yaml",
+			destinationRule: newPetBeDepDestinationRule(t, "pet-fe-other", 100),
+			wantTimeout:     "3s",
+			wantRetries: &kubernetes.HTTPRetry{
+				Attempts:      3,
+				PerTryTimeout: "1s",
+				RetryOn:       "5xx,connect-failure",
+			},
+			wantDestinationRule: func() *kubernetes.DestinationRule {
+				dr := mustUnmarshalDestinationRule(t, newPetBeDepDestinationRule(t, "pet-fe-other,pet-fe-src", 100))
+				dr.Metadata.OwnerReferences = append(dr.Metadata.OwnerReferences, kubernetes.OwnerReference{
+					APIVersion: "mesh.cellery.io/v1alpha2",
+					Kind:       "Cell",
+					Name:       "pet-fe-src",
+					UID:        "2b2a6e4c-0e4d-11ea-8d71-362b9e155667",
+				})
+				return dr
+			}(),
+		},
+		{
+			name:                    "apply circuit breaking conflicting with another instance",
+			file:                    "pet-be-dep-resilience.yaml",
+			destinationRule:         newPetBeDepDestinationRule(t, "pet-fe-other", 50),
+			wantErrorMessagePortion: "instance(s) pet-fe-other apply different circuit breaking or connection pool settings to host pet-be-dep--gateway-service",
+		},
+		{
+			name:            "apply circuit breaking conflicting with a terminated instance",
+			file:            "pet-be-dep-resilience.yaml",
+			destinationRule: newPetBeDepDestinationRule(t, "pet-fe-terminated", 50),
+			wantTimeout:     "3s",
+			wantRetries: &kubernetes.HTTPRetry{
+				Attempts:      3,
+				PerTryTimeout: "1s",
+				RetryOn:       "5xx,connect-failure",
+			},
+			wantDestinationRule: func() *kubernetes.DestinationRule {
+				dr := mustUnmarshalDestinationRule(t, newPetBeDepDestinationRule(t, "pet-fe-src", 100))
+				dr.Metadata.OwnerReferences = []kubernetes.OwnerReference{
+					{
+						APIVersion: "mesh.cellery.io/v1alpha2",
+						Kind:       "Cell",
+						Name:       "pet-fe-src",
+						UID:        "2b2a6e4c-0e4d-11ea-8d71-362b9e155667",
+					},
+				}
+				return dr
+			}(),
+		},
+		{
+			name:                 "apply a timeout removing circuit breaking",
+			file:                 "pet-be-dep-timeout.yaml",
+			destinationRule:      newPetBeDepDestinationRule(t, "pet-fe-src", 100),
+			wantTimeout:          "5s",
+			wantDeletedResources: []string{petBeDepDestinationRule},
+		},
+		{
+			name:                "apply a timeout removing circuit breaking shared with another instance",
+			file:                "pet-be-dep-timeout.yaml",
+			destinationRule:     newPetBeDepDestinationRule(t, "pet-fe-other,pet-fe-src", 100),
+			wantTimeout:         "5s",
+			wantDestinationRule: mustUnmarshalDestinationRule(t, newPetBeDepDestinationRule(t, "pet-fe-other", 100)),
+		},
+		{
+			name:                    "apply a policy to an unknown dependency",
+			file:                    "unknown-dependency.yaml",
+			wantErrorMessagePortion: "instance pet-fe-src does not depend on instance stock-be",
+		},
+		{
+			name:                    "apply a policy with an invalid timeout",
+			file:                    "invalid-timeout.yaml",
+			wantErrorMessagePortion: "invalid timeout \"5 seconds\" for dependency pet-be-dep",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := test.NewMockKubeCli(test.WithCells(kubernetes.Cells{
+				Items: []kubernetes.Cell{
+					{
+						Kind:       "Cell",
+						APIVersion: "mesh.cellery.io/v1alpha2",
+						CellMetaData: kubernetes.K8SMetaData{
+							Name: "pet-fe-src",
+							UID:  "2b2a6e4c-0e4d-11ea-8d71-362b9e155667",
+						},
+					},
+					{
+						Kind:         "Cell",
+						APIVersion:   "mesh.cellery.io/v1alpha2",
+						CellMetaData: kubernetes.K8SMetaData{Name: "pet-fe-other", UID: "e5c8b0a2"},
+					},
+				},
+			}), test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": readPetFeSrcVs(t)}))
+			if tst.destinationRule != nil {
+				test.WithResources(map[string][]byte{petBeDepDestinationRule: tst.destinationRule})(mockKubeCli)
+			}
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunApplyResiliencePolicy(mockCli, "pet-fe-src",
+				filepath.Join("testdata", "policies", "resilience", tst.file))
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in RunApplyResiliencePolicy, %v", err)
+			}
+			vsBytes, err := mockKubeCli.GetInstanceBytes("virtualservice.networking.istio.io", "pet-fe-src--vs")
+			if err != nil {
+				t.Fatalf("virtual service pet-fe-src--vs not applied, %v", err)
+			}
+			vs := kubernetes.VirtualService{}
+			if err := json.Unmarshal(vsBytes, &vs); err != nil {
+				t.Fatalf("failed to unmarshal the applied virtual service, %v", err)
+			}
+			for _, httpRule := range vs.VsSpec.HTTP {
+				if diff := cmp.Diff(tst.wantTimeout, httpRule.Timeout); diff != "" {
+					t.Errorf("invalid timeout (-want, +got)\n%v", diff)
+				}
+				if diff := cmp.Diff(tst.wantRetries, httpRule.Retries); diff != "" {
+					t.Errorf("invalid retries (-want, +got)\n%v", diff)
+				}
+			}
+			drBytes, err := mockKubeCli.GetInstanceBytes("destinationrule.networking.istio.io",
+				"pet-be-dep--gateway-resilience")
+			if tst.wantDestinationRule != nil {
+				if err != nil {
+					t.Fatalf("destination rule not applied, %v", err)
+				}
+				dr := &kubernetes.DestinationRule{}
+				if err := json.Unmarshal(drBytes, dr); err != nil {
+					t.Fatalf("failed to unmarshal the applied destination rule, %v", err)
+				}
+				if diff := cmp.Diff(tst.wantDestinationRule, dr); diff != "" {
+					t.Errorf("invalid destination rule (-want, +got)\n%v", diff)
+				}
+			}
+			if diff := cmp.Diff(tst.wantDeletedResources, mockKubeCli.DeletedResources()); diff != "" {
+				t.Errorf("invalid deleted resources (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func mustUnmarshalDestinationRule(t *testing.T, drBytes []byte) *kubernetes.DestinationRule {
+	dr := &kubernetes.DestinationRule{}
+	if err := json.Unmarshal(drBytes, dr); err != nil {
+		t.Fatalf("failed to unmarshal the destination rule, %v", err)
+	}
+	return dr
+}
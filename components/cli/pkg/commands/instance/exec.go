@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+const gatewayComponent = "gateway"
+
+// RunExec executes a command in the container of a component of a cell or a composite instance. The default
+// container of the gateway pod is used if the component is the gateway of a cell.
+func RunExec(cli cli.Cli, instance string, component string, tty bool, command []string) error {
+	if len(command) == 0 {
+		return fmt.Errorf("no command given to execute in component %s of instance %s", component, instance)
+	}
+	pods, _, err := getInstancePods(cli, instance)
+	if err != nil {
+		return err
+	}
+	pod, err := getComponentPod(pods, instance, component)
+	if err != nil {
+		return err
+	}
+	container := component
+	if component == gatewayComponent {
+		container = ""
+	}
+	return cli.KubeCli().ExecInContainer(pod, container, tty, command)
+}
+
+// getInstancePods returns the pods of a cell or a composite instance, along with whether the instance is a composite
+func getInstancePods(cli cli.Cli, instance string) (kubernetes.Pods, bool, error) {
+	_, err := cli.KubeCli().GetCell(instance)
+	if err == nil {
+		pods, err := cli.KubeCli().GetPodsForCell(instance)
+		if err != nil {
+			return pods, false, fmt.Errorf("error getting pods information of cell %s, %v", instance, err)
+		}
+		return pods, false, nil
+	}
+	if notFound, _ := errorpkg.IsCellInstanceNotFoundError(instance, err); !notFound {
+		return kubernetes.Pods{}, false, fmt.Errorf("error checking if cell exists, %v", err)
+	}
+	if _, err := cli.KubeCli().GetComposite(instance); err != nil {
+		if notFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instance, err); notFound {
+			return kubernetes.Pods{}, true, fmt.Errorf("instance %s does not exist", instance)
+		}
+		return kubernetes.Pods{}, true, fmt.Errorf("error checking if composite exists, %v", err)
+	}
+	pods, err := cli.KubeCli().GetPodsForComposite(instance)
+	if err != nil {
+		return pods, true, fmt.Errorf("error getting pods information of composite %s, %v", instance, err)
+	}
+	return pods, true, nil
+}
+
+// getComponentPod returns a running pod of a component
+func getComponentPod(pods kubernetes.Pods, instance string, component string) (string, error) {
+	var found bool
+	components := make(map[string]bool)
+	for _, pod := range pods.Items {
+		name := getComponentName(pod.MetaData.Name, instance)
+		components[name] = true
+		if name != component {
+			continue
+		}
+		found = true
+		if strings.EqualFold(pod.PodStatus.Phase, "Running") {
+			return pod.MetaData.Name, nil
+		}
+	}
+	if found {
+		return "", fmt.Errorf("no running pods found for component %s of instance %s", component, instance)
+	}
+	var names []string
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("component %s not found in instance %s, available components: %s", component, instance,
+		strings.Join(names, ", "))
+}
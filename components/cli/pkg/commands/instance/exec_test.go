@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func newPod(name string, phase string) kubernetes.Pod {
+	return kubernetes.Pod{
+		MetaData:  kubernetes.PodMetaData{Name: name},
+		PodStatus: kubernetes.PodStatus{Phase: phase},
+	}
+}
+
+// newEmployeeMockKubeCli returns a mock with the employee cell and the stock composite instances
+func newEmployeeMockKubeCli() *test.MockKubeCli {
+	return test.NewMockKubeCli(
+		test.WithCells(kubernetes.Cells{
+			Items: []kubernetes.Cell{{CellMetaData: kubernetes.K8SMetaData{Name: "employee"}}},
+		}),
+		test.WithComposites(kubernetes.Composites{
+			Items: []kubernetes.Composite{{CompositeMetaData: kubernetes.K8SMetaData{Name: "stock"}}},
+		}),
+		test.WithPods(map[string]kubernetes.Pods{
+			"employee": {
+				Items: []kubernetes.Pod{
+					newPod("employee--gateway-deployment-7d9f8c6b5-x2x4k", "Running"),
+					newPod("employee--salary-deployment-5c7b9d8f6-8kq2w", "Pending"),
+					newPod("employee--salary-deployment-5c7b9d8f6-mz7rt", "Running"),
+					newPod("employee--job-deployment-6b8c7d9f5-p4l9s", "Pending"),
+				},
+			},
+			"stock": {
+				Items: []kubernetes.Pod{newPod("stock--stock-deployment-6f9c8b7d5-q8w2e", "Running")},
+			},
+		}))
+}
+
+func TestRunExec(t *testing.T) {
+	tests := []struct {
+		name                    string
+		instance                string
+		component               string
+		tty                     bool
+		command                 []string
+		wantExecs               []string
+		wantErrorMessagePortion string
+	}{
+		{
+			name:      "exec in a component of a cell",
+			instance:  "employee",
+			component: "salary",
+			command:   []string{"ls", "/tmp"},
+			wantExecs: []string{"employee--salary-deployment-5c7b9d8f6-mz7rt/salary tty=false ls /tmp"},
+		},
+		{
+			name:      "exec in a component of a composite with a terminal",
+			instance:  "stock",
+			component: "stock",
+			tty:       true,
+			command:   []string{"sh"},
+			wantExecs: []string{"stock--stock-deployment-6f9c8b7d5-q8w2e/stock tty=true sh"},
+		},
+		{
+			name:      "exec in the gateway of a cell",
+			instance:  "employee",
+			component: "gateway",
+			command:   []string{"env"},
+			wantExecs: []string{"employee--gateway-deployment-7d9f8c6b5-x2x4k/ tty=false env"},
+		},
+		{
+			name:                    "exec in a component without running pods",
+			instance:                "employee",
+			component:               "job",
+			command:                 []string{"env"},
+			wantErrorMessagePortion: "no running pods found for component job of instance employee",
+		},
+		{
+			name:      "exec in an unknown component",
+			instance:  "employee",
+			component: "hr",
+			command:   []string{"env"},
+			wantErrorMessagePortion: "component hr not found in instance employee, available components: gateway, " +
+				"job, salary",
+		},
+		{
+			name:                    "exec in an unknown instance",
+			instance:                "hr",
+			component:               "hr",
+			command:                 []string{"env"},
+			wantErrorMessagePortion: "instance hr does not exist",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := newEmployeeMockKubeCli()
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunExec(mockCli, tst.instance, tst.component, tst.tty, tst.command)
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in RunExec, %v", err)
+			}
+			if diff := cmp.Diff(tst.wantExecs, mockKubeCli.Execs()); diff != "" {
+				t.Errorf("invalid commands executed (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
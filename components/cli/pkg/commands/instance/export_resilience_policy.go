@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/policies"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func RunExportResiliencePolicy(cli cli.Cli, instance string, outputfile string) error {
+	var err error
+	var policyData, yamlBytes []byte
+	var policy *policies.ResiliencePolicy
+	if err = cli.ExecuteTask("Exporting resilience policy", "Failed to export resilience policy",
+		"", func() error {
+			policy, err = routing.GetResiliencePolicy(cli, instance)
+			return err
+		}); err != nil {
+		return fmt.Errorf("failed to retrieve resilience policy data, %v", err)
+	}
+	if len(policy.Rules) == 0 {
+		return fmt.Errorf("instance %s does not have any dependencies", instance)
+	}
+	if policyData, err = json.Marshal(policy); err != nil {
+		return err
+	}
+	if yamlBytes, err = yaml.JSONToYAML(policyData); err != nil {
+		return err
+	}
+	file := outputfile
+	if file == "" {
+		file = filepath.Join("./", fmt.Sprintf("%s-resiliencepolicy.yaml", instance))
+	} else {
+		if err := ensureDir(file); err != nil {
+			return err
+		}
+	}
+	if err = writeToFile(yamlBytes, file); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully exported resilience policy for instance %s to %s", instance, file))
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/policies"
+)
+
+func TestRunExportResiliencePolicy(t *testing.T) {
+	vs := readPetFeSrcVs(t)
+	for i := range vs.VsSpec.HTTP {
+		vs.VsSpec.HTTP[i].Timeout = "3s"
+		vs.VsSpec.HTTP[i].Retries = &kubernetes.HTTPRetry{Attempts: 2, RetryOn: "5xx"}
+	}
+	drBytes, err := json.Marshal(kubernetes.DestinationRule{
+		Kind:       "DestinationRule",
+		APIVersion: "networking.istio.io/v1alpha3",
+		Metadata: kubernetes.DestinationRuleMeta{
+			Name:        "pet-be-dep--gateway-resilience",
+			Annotations: map[string]string{"mesh.cellery.io/resilience-policy-of": "pet-fe-other,pet-fe-src"},
+		},
+		Spec: kubernetes.DestinationRuleSpec{
+			Host: "pet-be-dep--gateway-service",
+			TrafficPolicy: kubernetes.TrafficPolicy{
+				OutlierDetection: &kubernetes.OutlierDetection{ConsecutiveErrors: 5, Interval: "10s"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal the destination rule, %v", err)
+	}
+	mockKubeCli := test.NewMockKubeCli(
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": vs}),
+		test.WithResources(map[string][]byte{
+			"destinationrule.networking.istio.io/pet-be-dep--gateway-resilience": drBytes,
+		}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	outputFile, err := ioutil.TempFile("", "resiliencepolicy*.yaml")
+	if err != nil {
+		t.Fatalf("failed to create the file to export to, %v", err)
+	}
+	defer os.Remove(outputFile.Name())
+
+	if err := RunExportResiliencePolicy(mockCli, "pet-fe-src", outputFile.Name()); err != nil {
+		t.Fatalf("error in RunExportResiliencePolicy, %v", err)
+	}
+	got, err := policies.ReadResiliencePolicy(outputFile.Name())
+	if err != nil {
+		t.Fatalf("failed to read the exported policy, %v", err)
+	}
+	want := &policies.ResiliencePolicy{
+		Type: "ResiliencePolicy",
+		Rules: []policies.ResilienceRule{
+			{
+				Target: policies.Target{Type: "dependency", Name: "pet-be-dep"},
+				Policy: policies.DependencyPolicy{
+					Timeout:          "3s",
+					Retries:          &policies.Retries{Attempts: 2, RetryOn: "5xx"},
+					OutlierDetection: &policies.OutlierDetection{ConsecutiveErrors: 5, Interval: "10s"},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("invalid exported policy (-want, +got)\n%v", diff)
+	}
+}
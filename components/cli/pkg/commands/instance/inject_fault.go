@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunInjectFault injects a fault into the requests sent by an instance to a dependency, which is given by the alias
+// or the name of the dependency instance.
+func RunInjectFault(cli cli.Cli, instance string, dependency string, fault *routing.Fault) error {
+	if err := fault.Validate(); err != nil {
+		return err
+	}
+	dependencyInstance, err := routing.ResolveDependency(cli, instance, dependency)
+	if err != nil {
+		return err
+	}
+	var faults []string
+	if fault.Delay > 0 {
+		faults = append(faults, fmt.Sprintf("a delay of %s", fault.Delay))
+	}
+	if fault.AbortStatus != 0 {
+		faults = append(faults, fmt.Sprintf("abort status %d", fault.AbortStatus))
+	}
+	description := fmt.Sprintf("%s in %d%% of the requests", strings.Join(faults, " and "), fault.Percentage)
+	if err := applyFault(cli, instance, dependencyInstance, func(artifactFile string) error {
+		return routing.BuildFaultArtifact(cli, instance, dependencyInstance, fault, artifactFile)
+	}, fmt.Sprintf("inject %s to %s", description, dependencyInstance)); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully injected %s sent from instance %s to instance %s",
+		description, instance, dependencyInstance))
+	return nil
+}
+
+// RunClearFault restores the faults of the requests sent by an instance to a dependency to the faults found before
+// the faults were injected by RunInjectFault. All the faults are cleared if the faults found before the injection
+// were not recorded.
+func RunClearFault(cli cli.Cli, instance string, dependency string) error {
+	dependencyInstance, err := routing.ResolveDependency(cli, instance, dependency)
+	if err != nil {
+		return err
+	}
+	restored := false
+	if err := applyFault(cli, instance, dependencyInstance, func(artifactFile string) error {
+		restored, err = routing.BuildFaultRestoreArtifact(cli, instance, dependencyInstance, artifactFile)
+		return err
+	}, fmt.Sprintf("clear the faults injected to %s", dependencyInstance)); err != nil {
+		return err
+	}
+	if !restored {
+		util.PrintWarningMessage(fmt.Sprintf("The faults found before injecting faults into the requests sent to "+
+			"instance %s were not recorded, hence all the faults of the requests are cleared", dependencyInstance))
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully cleared the faults injected into the requests sent from "+
+		"instance %s to instance %s", instance, dependencyInstance))
+	return nil
+}
+
+// applyFault applies the virtual service of an instance written by the build function, recording the state before
+// the change as a revision of the instance
+func applyFault(cli cli.Cli, instance string, dependency string, build func(artifactFile string) error,
+	cause string) error {
+	artifactFile := filepath.Join("./", fmt.Sprintf("%s-fault.yaml", instance))
+	defer func() {
+		_ = os.Remove(artifactFile)
+	}()
+	var err error
+	if err = cli.ExecuteTask("Building fault injection rules", "Failed to build fault injection rules",
+		"", func() error {
+			return build(artifactFile)
+		}); err != nil {
+		return fmt.Errorf("failed to build the fault injection rules, %v", err)
+	}
+	snapshot, err := snapshotRevision(cli, instance, artifactFile)
+	if err != nil {
+		return err
+	}
+	if err = cli.ExecuteTask("Applying fault injection rules", "Failed to apply fault injection rules",
+		"", func() error {
+			return cli.KubeCli().ApplyFile(artifactFile)
+		}); err != nil {
+		return fmt.Errorf("failed to apply the fault injection rules, %v", err)
+	}
+	return recordRevision(cli, instance, cause, snapshot)
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+func TestRunInjectFault(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, cell := range []string{"pet-fe-src", "pet-be-dep"} {
+		cellBytes, err := ioutil.ReadFile(filepath.Join("testdata", "cells", cell+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file, %v", cell, err)
+		}
+		cellMap[cell] = cellBytes
+	}
+	injectedVs := readPetFeSrcVs(t)
+	for i := range injectedVs.VsSpec.HTTP {
+		injectedVs.VsSpec.HTTP[i].Fault = &kubernetes.HTTPFaultInjection{
+			Abort: &kubernetes.FaultAbort{HttpStatus: 500},
+		}
+	}
+	tests := []struct {
+		name                    string
+		vs                      kubernetes.VirtualService
+		dependency              string
+		fault                   *routing.Fault
+		want                    *kubernetes.HTTPFaultInjection
+		wantErrorMessagePortion string
+	}{
+		{
+			name:       "inject a delay",
+			vs:         readPetFeSrcVs(t),
+			dependency: "pet-be-dep",
+			fault:      &routing.Fault{Delay: 2 * time.Second, Percentage: 20},
+			want: &kubernetes.HTTPFaultInjection{
+				Delay: &kubernetes.FaultDelay{Percentage: &kubernetes.Percent{Value: 20}, FixedDelay: "2s"},
+			},
+		},
+		{
+			name:       "inject a delay and an abort",
+			vs:         readPetFeSrcVs(t),
+			dependency: "pet-be-dep",
+			fault:      &routing.Fault{Delay: 1500 * time.Millisecond, AbortStatus: 503, Percentage: 100},
+			want: &kubernetes.HTTPFaultInjection{
+				Delay: &kubernetes.FaultDelay{Percentage: &kubernetes.Percent{Value: 100}, FixedDelay: "1.5s"},
+				Abort: &kubernetes.FaultAbort{Percentage: &kubernetes.Percent{Value: 100}, HttpStatus: 503},
+			},
+		},
+		{
+			name:       "clear the injected faults",
+			vs:         injectedVs,
+			dependency: "pet-be-dep",
+		},
+		{
+			name:                    "inject a fault into the requests to an unknown dependency",
+			vs:                      readPetFeSrcVs(t),
+			dependency:              "pet-be-target",
+			fault:                   &routing.Fault{AbortStatus: 503, Percentage: 100},
+			wantErrorMessagePortion: "instance pet-fe-src does not depend on an instance with alias or name pet-be-target",
+		},
+		{
+			name:                    "inject an invalid fault",
+			vs:                      readPetFeSrcVs(t),
+			dependency:              "pet-be-dep",
+			fault:                   &routing.Fault{Percentage: 100},
+			wantErrorMessagePortion: "expects a delay or an abort status for the fault",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+				test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": tst.vs}))
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			var err error
+			if tst.fault != nil {
+				err = RunInjectFault(mockCli, "pet-fe-src", tst.dependency, tst.fault)
+			} else {
+				err = RunClearFault(mockCli, "pet-fe-src", tst.dependency)
+			}
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in injecting the fault, %v", err)
+			}
+			vsBytes, err := mockKubeCli.GetInstanceBytes("virtualservice.networking.istio.io", "pet-fe-src--vs")
+			if err != nil {
+				t.Fatalf("virtual service pet-fe-src--vs not applied, %v", err)
+			}
+			vs := kubernetes.VirtualService{}
+			if err := json.Unmarshal(vsBytes, &vs); err != nil {
+				t.Fatalf("failed to unmarshal the applied virtual service, %v", err)
+			}
+			for _, httpRule := range vs.VsSpec.HTTP {
+				if diff := cmp.Diff(tst.want, httpRule.Fault); diff != "" {
+					t.Errorf("invalid fault (-want, +got)\n%v", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestRunInjectFaultWithAlias(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, cell := range []string{"pet-fe-src", "pet-be-dep"} {
+		cellBytes, err := ioutil.ReadFile(filepath.Join("testdata", "cells", cell+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file, %v", cell, err)
+		}
+		cellMap[cell] = cellBytes
+	}
+	// Linking the dependency with an alias as done by cellery run
+	cellMap["pet-fe-src"] = []byte(strings.Replace(string(cellMap["pet-fe-src"]),
+		`\"instance\":\"pet-be-dep\"`, `\"alias\":\"petBeDep\",\"instance\":\"pet-be-dep\"`, -1))
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": readPetFeSrcVs(t)}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	if err := RunInjectFault(mockCli, "pet-fe-src", "petBeDep",
+		&routing.Fault{AbortStatus: 503, Percentage: 10}); err != nil {
+		t.Fatalf("error in injecting the fault, %v", err)
+	}
+	vs := readAppliedPetFeSrcVs(t, mockKubeCli)
+	want := &kubernetes.HTTPFaultInjection{
+		Abort: &kubernetes.FaultAbort{Percentage: &kubernetes.Percent{Value: 10}, HttpStatus: 503},
+	}
+	for _, httpRule := range vs.VsSpec.HTTP {
+		if diff := cmp.Diff(want, httpRule.Fault); diff != "" {
+			t.Errorf("invalid fault (-want, +got)\n%v", diff)
+		}
+	}
+}
+
+func TestRunClearFaultRestoresPreviousFaults(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, cell := range []string{"pet-fe-src", "pet-be-dep"} {
+		cellBytes, err := ioutil.ReadFile(filepath.Join("testdata", "cells", cell+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file, %v", cell, err)
+		}
+		cellMap[cell] = cellBytes
+	}
+	previousFault := &kubernetes.HTTPFaultInjection{
+		Delay: &kubernetes.FaultDelay{Percentage: &kubernetes.Percent{Value: 5}, FixedDelay: "1s"},
+	}
+	previousVs := readPetFeSrcVs(t)
+	for i := range previousVs.VsSpec.HTTP {
+		previousVs.VsSpec.HTTP[i].Fault = previousFault
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": previousVs}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	if err := RunInjectFault(mockCli, "pet-fe-src", "pet-be-dep",
+		&routing.Fault{AbortStatus: 503, Percentage: 100}); err != nil {
+		t.Fatalf("error in injecting the fault, %v", err)
+	}
+	// Routing the requests matching a header to another instance before clearing the faults adds a rule before the
+	// rules routing the requests to the dependency
+	injectedVs := readAppliedPetFeSrcVs(t, mockKubeCli)
+	headerRule := injectedVs.VsSpec.HTTP[0]
+	headerRule.Route = []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{Host: "pet-be-new--gateway-service"}}}
+	injectedVs.VsSpec.HTTP = append([]kubernetes.HTTP{headerRule}, injectedVs.VsSpec.HTTP...)
+	mockKubeCli = test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": injectedVs}))
+	mockCli = test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	if err := RunClearFault(mockCli, "pet-fe-src", "pet-be-dep"); err != nil {
+		t.Fatalf("error in clearing the faults, %v", err)
+	}
+	vs := readAppliedPetFeSrcVs(t, mockKubeCli)
+	for _, httpRule := range vs.VsSpec.HTTP {
+		var want *kubernetes.HTTPFaultInjection
+		if httpRule.Route[0].Destination.Host == "pet-be-dep--gateway-service" {
+			want = previousFault
+		}
+		if diff := cmp.Diff(want, httpRule.Fault); diff != "" {
+			t.Errorf("invalid fault of the rule routing to %s (-want, +got)\n%v",
+				httpRule.Route[0].Destination.Host, diff)
+		}
+	}
+	if annotation, ok := vs.VsMetaData.Annotations["mesh.cellery.io/faults-before-injection"]; ok {
+		t.Errorf("faults recorded before the injection not removed, %s", annotation)
+	}
+}
+
+func readAppliedPetFeSrcVs(t *testing.T, mockKubeCli *test.MockKubeCli) kubernetes.VirtualService {
+	vsBytes, err := mockKubeCli.GetInstanceBytes("virtualservice.networking.istio.io", "pet-fe-src--vs")
+	if err != nil {
+		t.Fatalf("virtual service pet-fe-src--vs not applied, %v", err)
+	}
+	vs := kubernetes.VirtualService{}
+	if err := json.Unmarshal(vsBytes, &vs); err != nil {
+		t.Fatalf("failed to unmarshal the applied virtual service, %v", err)
+	}
+	return vs
+}
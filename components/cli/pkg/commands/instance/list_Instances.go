@@ -20,131 +20,119 @@ package instance
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 
-	"github.com/olekukonko/tablewriter"
-
 	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
-func RunListInstances(cli cli.Cli) error {
+type cellInstanceData struct {
+	Name       string `json:"name"`
+	Image      string `json:"image"`
+	Status     string `json:"status"`
+	Gateway    string `json:"gateway"`
+	Components int    `json:"components"`
+	Age        string `json:"age"`
+	Created    string `json:"created"`
+}
+
+type compositeInstanceData struct {
+	Name       string `json:"name"`
+	Image      string `json:"image"`
+	Status     string `json:"status"`
+	Components int    `json:"components"`
+	Age        string `json:"age"`
+	Created    string `json:"created"`
+}
+
+type instancesData struct {
+	Cells      []cellInstanceData      `json:"cells"`
+	Composites []compositeInstanceData `json:"composites"`
+}
+
+func RunListInstances(cli cli.Cli, outputFormat string) error {
 	var err error
-	var cellTableData, compositeTableData [][]string
-	if cellTableData, err = getCellTableData(cli); err != nil {
+	instances := instancesData{}
+	if instances.Cells, err = getCellInstancesData(cli); err != nil {
 		return fmt.Errorf("error getting cell data, %v", err)
 	}
-	if len(cellTableData) > 0 {
-		displayCellTable(cli, cellTableData)
-	}
-	if compositeTableData, err = getCompositeTableData(cli); err != nil {
+	if instances.Composites, err = getCompositeInstancesData(cli); err != nil {
 		return fmt.Errorf("error getting composite data, %v", err)
 	}
-	if len(compositeTableData) > 0 {
-		displayCompositeTable(cli, compositeTableData)
+	if !output.IsTable(outputFormat) {
+		return output.Print(cli.Out(), outputFormat, instances)
+	}
+	if len(instances.Cells) > 0 {
+		displayCellTable(cli, instances.Cells, output.IsWide(outputFormat))
 	}
-	if len(cellTableData) == 0 && len(compositeTableData) == 0 {
+	if len(instances.Composites) > 0 {
+		displayCompositeTable(cli, instances.Composites, output.IsWide(outputFormat))
+	}
+	if len(instances.Cells) == 0 && len(instances.Composites) == 0 {
 		fmt.Fprintln(cli.Out(), "No running instances.")
 	}
 	return nil
 }
 
-func displayCellTable(cli cli.Cli, tableData [][]string) error {
-	if len(tableData) > 0 {
-		fmt.Fprintf(cli.Out(), "\n %s\n", util.Bold("Cell Instances:"))
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"INSTANCE", "IMAGE", "STATUS", "GATEWAY", "COMPONENTS", "AGE"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-
-		table.AppendBulk(tableData)
-		table.Render()
+func displayCellTable(cli cli.Cli, cells []cellInstanceData, wide bool) {
+	fmt.Fprintf(cli.Out(), "\n %s\n", util.Bold("Cell Instances:"))
+	table := output.NewTable("INSTANCE", "IMAGE", "STATUS", "GATEWAY", "COMPONENTS", "AGE").AddWideColumns("CREATED")
+	for _, cell := range cells {
+		table.Append(cell.Name, cell.Image, cell.Status, cell.Gateway, strconv.Itoa(cell.Components), cell.Age,
+			cell.Created)
 	}
-	return nil
+	table.Render(cli.Out(), wide)
 }
 
-func displayCompositeTable(cli cli.Cli, tableData [][]string) error {
-	if len(tableData) > 0 {
-		fmt.Fprintf(cli.Out(), " \n %s\n", util.Bold("Composite Instances:"))
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"INSTANCE", "IMAGE", "STATUS", "COMPONENTS", "AGE"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-
-		table.AppendBulk(tableData)
-		table.Render()
+func displayCompositeTable(cli cli.Cli, composites []compositeInstanceData, wide bool) {
+	fmt.Fprintf(cli.Out(), " \n %s\n", util.Bold("Composite Instances:"))
+	table := output.NewTable("INSTANCE", "IMAGE", "STATUS", "COMPONENTS", "AGE").AddWideColumns("CREATED")
+	for _, composite := range composites {
+		table.Append(composite.Name, composite.Image, composite.Status, strconv.Itoa(composite.Components),
+			composite.Age, composite.Created)
 	}
-	return nil
+	table.Render(cli.Out(), wide)
 }
 
-func getCellTableData(cli cli.Cli) ([][]string, error) {
-	var tableData [][]string
+func getCellInstancesData(cli cli.Cli) ([]cellInstanceData, error) {
+	cellsData := []cellInstanceData{}
 	cells, err := cli.KubeCli().GetCells()
 	if err != nil {
 		return nil, fmt.Errorf("error getting information of cells, %v", err)
 	}
 	for i := 0; i < len(cells); i++ {
-		age := util.GetDuration(util.ConvertStringToTime(cells[i].CellMetaData.CreationTimestamp))
-		instance := cells[i].CellMetaData.Name
-		cellImage := cells[i].CellMetaData.Annotations.Organization + "/" + cells[i].CellMetaData.Annotations.Name + ":" + cells[i].CellMetaData.Annotations.Version
-		gateway := cells[i].CellStatus.Gateway
-		components := cells[i].CellStatus.ServiceCount
-		status := cells[i].CellStatus.Status
-		tableRecord := []string{instance, cellImage, status, gateway, strconv.Itoa(components), age}
-		tableData = append(tableData, tableRecord)
+		cellsData = append(cellsData, cellInstanceData{
+			Name: cells[i].CellMetaData.Name,
+			Image: cells[i].CellMetaData.Annotations.Organization + "/" + cells[i].CellMetaData.Annotations.Name +
+				":" + cells[i].CellMetaData.Annotations.Version,
+			Status:     cells[i].CellStatus.Status,
+			Gateway:    cells[i].CellStatus.Gateway,
+			Components: cells[i].CellStatus.ServiceCount,
+			Age:        util.GetDuration(util.ConvertStringToTime(cells[i].CellMetaData.CreationTimestamp)),
+			Created:    cells[i].CellMetaData.CreationTimestamp,
+		})
 	}
-	return tableData, nil
+	return cellsData, nil
 }
 
-func getCompositeTableData(cli cli.Cli) ([][]string, error) {
+func getCompositeInstancesData(cli cli.Cli) ([]compositeInstanceData, error) {
 	compositeData, err := cli.KubeCli().GetComposites()
 	if err != nil {
 		return nil, fmt.Errorf("error getting information of composites, %v", err)
 	}
-	var tableData [][]string
+	compositesData := []compositeInstanceData{}
 	for i := 0; i < len(compositeData); i++ {
-		age := util.GetDuration(util.ConvertStringToTime(compositeData[i].CompositeMetaData.CreationTimestamp))
-		instance := compositeData[i].CompositeMetaData.Name
-		cellImage := compositeData[i].CompositeMetaData.Annotations.Organization + "/" +
-			compositeData[i].CompositeMetaData.Annotations.Name + ":" +
-			compositeData[i].CompositeMetaData.Annotations.Version
-		components := compositeData[i].CompositeStatus.ServiceCount
-		status := compositeData[i].CompositeStatus.Status
-		tableRecord := []string{instance, cellImage, status, strconv.Itoa(components), age}
-		tableData = append(tableData, tableRecord)
+		compositesData = append(compositesData, compositeInstanceData{
+			Name: compositeData[i].CompositeMetaData.Name,
+			Image: compositeData[i].CompositeMetaData.Annotations.Organization + "/" +
+				compositeData[i].CompositeMetaData.Annotations.Name + ":" +
+				compositeData[i].CompositeMetaData.Annotations.Version,
+			Status:     compositeData[i].CompositeStatus.Status,
+			Components: compositeData[i].CompositeStatus.ServiceCount,
+			Age:        util.GetDuration(util.ConvertStringToTime(compositeData[i].CompositeMetaData.CreationTimestamp)),
+			Created:    compositeData[i].CompositeMetaData.CreationTimestamp,
+		})
 	}
-	return tableData, nil
+	return compositesData, nil
 }
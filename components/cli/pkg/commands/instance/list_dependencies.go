@@ -20,16 +20,21 @@ package instance
 
 import (
 	"fmt"
-	"os"
-
-	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/routing"
 )
 
-func RunListDependencies(cli cli.Cli, instanceName string) error {
+type dependencyData struct {
+	Instance string `json:"instance"`
+	Image    string `json:"image"`
+	Version  string `json:"version"`
+	Kind     string `json:"kind"`
+}
+
+func RunListDependencies(cli cli.Cli, instanceName string, outputFormat string) error {
 	var depJson string
 	var canBeComposite bool
 	cellInst, err := cli.KubeCli().GetCell(instanceName)
@@ -63,32 +68,17 @@ func RunListDependencies(cli cli.Cli, instanceName string) error {
 	if len(dependencies) == 0 {
 		return fmt.Errorf("no dependencies found in instance %s", instanceName)
 	}
-	var tableData [][]string
+	var dependenciesData []dependencyData
+	table := output.NewTable("CELL INSTANCE", "IMAGE", "VERSION").AddWideColumns("KIND")
 	for _, dependency := range dependencies {
-		record := []string{dependency["instance"], fmt.Sprintf("%s/%s", dependency["org"], dependency["name"]),
-			dependency["version"]}
-		tableData = append(tableData, record)
+		data := dependencyData{
+			Instance: dependency["instance"],
+			Image:    fmt.Sprintf("%s/%s", dependency["org"], dependency["name"]),
+			Version:  dependency["version"],
+			Kind:     dependency["kind"],
+		}
+		dependenciesData = append(dependenciesData, data)
+		table.Append(data.Instance, data.Image, data.Version, data.Kind)
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"CELL INSTANCE", "IMAGE", "VERSION"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold},
-	)
-	table.SetColumnColor(
-		tablewriter.Colors{},
-		tablewriter.Colors{},
-		tablewriter.Colors{},
-	)
-
-	table.AppendBulk(tableData)
-	table.Render()
-
-	return nil
+	return output.PrintTable(cli.Out(), outputFormat, dependenciesData, table)
 }
@@ -108,7 +108,7 @@ func TestRunListDependencies(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunListDependencies(mockCli, tst.instance)
+			err := RunListDependencies(mockCli, tst.instance, "")
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunListDependencies, %v", err)
@@ -121,3 +121,68 @@ func TestRunListDependencies(t *testing.T) {
 		})
 	}
 }
+
+func TestRunListDependenciesOutputFormats(t *testing.T) {
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:              "hr",
+					CreationTimestamp: "2019-10-19T11:40:36Z",
+					Annotations: kubernetes.CellAnnotations{
+						Dependencies: "[{\"org\":\"myorg\",\"name\":\"employee\",\"version\":\"1.0.0\",\"instance\":\"employee\",\"kind\":\"Cell\"}]",
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name         string
+		outputFormat string
+		expected     string
+	}{
+		{
+			name:         "list dependencies in json",
+			outputFormat: "json",
+			expected: `[
+    {
+        "image": "myorg/employee",
+        "instance": "employee",
+        "kind": "Cell",
+        "version": "1.0.0"
+    }
+]
+`,
+		},
+		{
+			name:         "list dependencies in yaml",
+			outputFormat: "yaml",
+			expected: `- image: myorg/employee
+  instance: employee
+  kind: Cell
+  version: 1.0.0
+`,
+		},
+		{
+			name:         "list dependencies with jsonpath",
+			outputFormat: "jsonpath={[*].instance}",
+			expected:     "employee",
+		},
+		{
+			name:         "list dependencies with go-template",
+			outputFormat: "go-template={{range .}}{{.image}}:{{.version}}{{end}}",
+			expected:     "myorg/employee:1.0.0",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells))))
+			if err := RunListDependencies(mockCli, "hr", tst.outputFormat); err != nil {
+				t.Fatalf("error in RunListDependencies, %v", err)
+			}
+			if diff := cmp.Diff(tst.expected, mockCli.OutBuffer().String()); diff != "" {
+				t.Errorf("RunListDependencies: unexpected output (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
@@ -63,6 +63,7 @@ func TestRunListInstances(t *testing.T) {
 	tests := []struct {
 		name             string
 		instancesRunning bool
+		outputFormat     string
 		expected         string
 		mockCli          *test.MockCli
 	}{
@@ -78,10 +79,24 @@ func TestRunListInstances(t *testing.T) {
 			expected:         "No running instances.\n",
 			mockCli:          test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli())),
 		},
+		{
+			name:             "list instances names with jsonpath",
+			instancesRunning: false,
+			outputFormat:     "jsonpath={.cells[*].name},{.composites[*].name}",
+			expected:         "employee stock,hr job",
+			mockCli:          test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells), test.WithComposites(composites)))),
+		},
+		{
+			name:             "list instances in json without instances running",
+			instancesRunning: false,
+			outputFormat:     "json",
+			expected:         "{\n    \"cells\": [],\n    \"composites\": []\n}\n",
+			mockCli:          test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli())),
+		},
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunListInstances(tst.mockCli)
+			err := RunListInstances(tst.mockCli, tst.outputFormat)
 			if tst.instancesRunning {
 				if err != nil {
 					t.Errorf("error in RunListInstances, %v", err)
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+type routeData struct {
+	Source     string            `json:"source"`
+	Dependency string            `json:"dependency"`
+	Host       string            `json:"host"`
+	Protocol   string            `json:"protocol"`
+	Conditions []string          `json:"conditions"`
+	Targets    []routeTargetData `json:"targets"`
+	Mirror     *routeTargetData  `json:"mirror,omitempty"`
+}
+
+type routeTargetData struct {
+	Instance string `json:"instance"`
+	Host     string `json:"host"`
+	Weight   int    `json:"weight"`
+}
+
+// RunListRoutes lists the rules of the instances which route the traffic addressed to an instance, or route
+// traffic to it
+func RunListRoutes(cli cli.Cli, instanceName string, outputFormat string) error {
+	rules, err := routing.GetRouteRules(cli, instanceName)
+	if err != nil {
+		return fmt.Errorf("error getting the routes of instance %s, %v", instanceName, err)
+	}
+	if len(rules) == 0 && output.IsTable(outputFormat) {
+		fmt.Fprintf(cli.Out(), "No routes found for instance %s.\n", instanceName)
+		return nil
+	}
+	routes := []routeData{}
+	table := output.NewTable("SOURCE", "DEPENDENCY", "MATCH", "TARGETS").AddWideColumns("PROTOCOL", "HOST",
+		"MIRROR")
+	for _, rule := range rules {
+		data := routeData{
+			Source:     rule.Source,
+			Dependency: rule.Dependency,
+			Host:       rule.Host,
+			Protocol:   rule.Protocol,
+			Conditions: rule.Conditions,
+		}
+		var targets []string
+		for _, target := range rule.Targets {
+			data.Targets = append(data.Targets, routeTargetData(target))
+			targets = append(targets, fmt.Sprintf("%s %d%%", target.Instance, target.Weight))
+		}
+		mirror := "-"
+		if rule.Mirror != nil {
+			mirrorData := routeTargetData(*rule.Mirror)
+			data.Mirror = &mirrorData
+			mirror = fmt.Sprintf("%s %d%%", rule.Mirror.Instance, rule.Mirror.Weight)
+		}
+		match := "*"
+		if len(rule.Conditions) > 0 {
+			match = strings.Join(rule.Conditions, ", ")
+		}
+		routes = append(routes, data)
+		table.Append(data.Source, data.Dependency, match, strings.Join(targets, ", "), data.Protocol, data.Host,
+			mirror)
+	}
+	return output.PrintTable(cli.Out(), outputFormat, routes, table)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+func TestRunListRoutes(t *testing.T) {
+	matchRules := []routing.MatchRule{
+		{Headers: map[string]*kubernetes.StringMatch{"x-team": {Exact: "beta"}}},
+	}
+	vs := buildPetFeSrcVs(t, readPetFeSrcVs(t), 20, matchRules, 0)
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name: "pet-fe-src",
+					Annotations: kubernetes.CellAnnotations{
+						Dependencies: "[{\"org\":\"myorg\",\"name\":\"petbe\",\"version\":\"1.0.0\",\"instance\":\"pet-be-dep\",\"kind\":\"Cell\"}]",
+					},
+				},
+			},
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name: "pet-be-dep",
+				},
+			},
+		},
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCells(cells),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": vs}))
+
+	percentageRule := func(conditions ...string) routeData {
+		return routeData{
+			Source:     "pet-fe-src",
+			Dependency: "pet-be-dep",
+			Host:       "pet-be-dep--gateway-service",
+			Protocol:   "HTTP",
+			Conditions: conditions,
+			Targets: []routeTargetData{
+				{Instance: "pet-be-dep", Host: "pet-be-dep--gateway-service", Weight: 80},
+				{Instance: "pet-be-target", Host: "pet-be-target--gateway-service", Weight: 20},
+			},
+		}
+	}
+	// the session based rules are routed by the percentage as the session awareness is not enabled
+	expected := []routeData{
+		percentageRule("x-instance-id=1"),
+		percentageRule("x-instance-id=2"),
+		{
+			Source:     "pet-fe-src",
+			Dependency: "pet-be-dep",
+			Host:       "pet-be-dep--gateway-service",
+			Protocol:   "HTTP",
+			Conditions: []string{"x-team=beta"},
+			Targets: []routeTargetData{
+				{Instance: "pet-be-target", Host: "pet-be-target--gateway-service", Weight: 100},
+			},
+		},
+		percentageRule(),
+	}
+	tests := []struct {
+		name     string
+		instance string
+		expected []routeData
+	}{
+		{
+			name:     "routes of the dependency instance",
+			instance: "pet-be-dep",
+			expected: expected,
+		},
+		{
+			name:     "routes of the target instance",
+			instance: "pet-be-target",
+			expected: expected,
+		},
+		{
+			name:     "instance without routes",
+			instance: "pet-fe-src",
+			expected: []routeData{},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			if err := RunListRoutes(mockCli, tst.instance, "json"); err != nil {
+				t.Fatalf("error in RunListRoutes, %v", err)
+			}
+			var actual []routeData
+			if err := json.Unmarshal(mockCli.OutBuffer().Bytes(), &actual); err != nil {
+				t.Fatalf("failed to unmarshal routes, %v", err)
+			}
+			if diff := cmp.Diff(tst.expected, actual); diff != "" {
+				t.Errorf("invalid routes (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRunListRoutesTable(t *testing.T) {
+	mockKubeCli := test.NewMockKubeCli()
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	if err := RunListRoutes(mockCli, "pet-be-dep", ""); err != nil {
+		t.Fatalf("error in RunListRoutes, %v", err)
+	}
+	if !strings.Contains(mockCli.OutBuffer().String(), "No routes found for instance pet-be-dep") {
+		t.Errorf("expected no routes, got %s", mockCli.OutBuffer().String())
+	}
+}
@@ -19,57 +19,239 @@
 package instance
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"strings"
+	"sync"
 
-	"github.com/hashicorp/go-version"
+	"github.com/fatih/color"
 
 	"cellery.io/cellery/components/cli/cli"
-	"cellery.io/cellery/components/cli/pkg/util"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
 )
 
-const minimumKubernetesVersionToFollowLogs = "v1.14.0"
+// logPrefixColors are assigned to the containers in a round robin manner, to tell apart the interleaved logs
+var logPrefixColors = []color.Attribute{
+	color.FgHiCyan,
+	color.FgHiGreen,
+	color.FgHiYellow,
+	color.FgHiBlue,
+	color.FgHiMagenta,
+	color.FgHiRed,
+}
+
+// containerLogs is a container of which the logs are streamed, with the [<instance>/<component>] prefix of each line
+type containerLogs struct {
+	pod       string
+	container string
+	prefix    string
+}
 
-func RunLogs(cli cli.Cli, instanceName string, componentName string, sysLog bool, follow bool) error {
-	if err := cli.KubeCli().IsInstanceAvailable(instanceName); err != nil {
-		return fmt.Errorf(fmt.Sprintf("No logs found"), fmt.Errorf("cannot find running "+
-			"instance %s", instanceName))
+// RunLogs prints the logs of the user components of the instances, or of all the components if sysLog is set.
+// If withDependencies is set, the logs of all the instances in the dependency trees of the instances are printed.
+// Each line is prefixed with the instance and the component it was logged from.
+func RunLogs(cli cli.Cli, instances []string, componentName string, sysLog bool, withDependencies bool,
+	options kubernetes.LogOptions) error {
+	for _, instanceName := range instances {
+		if err := cli.KubeCli().IsInstanceAvailable(instanceName); err != nil {
+			return fmt.Errorf("no logs found, cannot find running instance %s, %v", instanceName, err)
+		}
+		if componentName != "" {
+			if err := cli.KubeCli().IsComponentAvailable(instanceName, componentName); err != nil {
+				return fmt.Errorf("no logs found, cannot find component %s of instance %s, %v", componentName,
+					instanceName, err)
+			}
+		}
+	}
+	if withDependencies {
+		var err error
+		if instances, err = getDependencyTree(cli, instances); err != nil {
+			return err
+		}
+	}
+	var containers []containerLogs
+	for _, instanceName := range instances {
+		instanceContainers, err := getContainersForLogs(cli, instanceName, componentName, sysLog)
+		if err != nil {
+			return fmt.Errorf("error getting logs for instance %s, %v", instanceName, err)
+		}
+		containers = append(containers, instanceContainers...)
+	}
+	if len(containers) == 0 {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("No running containers found in instance(s) %s",
+			strings.Join(instances, ", ")))
+		return nil
 	}
+	return streamLogs(cli, containers, options)
+}
 
-	if follow {
-		_, clientVersion, err := cli.KubeCli().Version()
+// getDependencyTree returns the instances along with the instances in their dependency trees, in breadth first order
+func getDependencyTree(cli cli.Cli, instances []string) ([]string, error) {
+	visited := make(map[string]bool)
+	for _, instanceName := range instances {
+		visited[instanceName] = true
+	}
+	queue := append([]string{}, instances...)
+	var tree []string
+	for len(queue) > 0 {
+		instanceName := queue[0]
+		queue = queue[1:]
+		tree = append(tree, instanceName)
+		dependencies, err := getInstanceDependencies(cli, instanceName)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		kubectlClientVersion, err := version.NewVersion(clientVersion)
-		minimumKubernetesClientVersion, err := version.NewVersion(minimumKubernetesVersionToFollowLogs)
+		for _, dependency := range dependencies {
+			if dependency["instance"] != "" && !visited[dependency["instance"]] {
+				visited[dependency["instance"]] = true
+				queue = append(queue, dependency["instance"])
+			}
+		}
+	}
+	return tree, nil
+}
 
-		if kubectlClientVersion.LessThan(minimumKubernetesClientVersion) {
-			util.PrintWarningMessage(fmt.Sprintf("Unable to follow cellery logs for instance %s."+
-				"Please upgrade kubernetes client version to 1.14 or higher", instanceName))
-			return nil
+// getInstanceDependencies returns the dependencies in the dependency annotation of a cell or a composite instance
+func getInstanceDependencies(cli cli.Cli, instanceName string) ([]map[string]string, error) {
+	var dependencies string
+	cell, err := cli.KubeCli().GetCell(instanceName)
+	if err == nil {
+		dependencies = cell.CellMetaData.Annotations.Dependencies
+	} else {
+		if cellNotFound, _ := errorpkg.IsCellInstanceNotFoundError(instanceName, err); !cellNotFound {
+			return nil, fmt.Errorf("error checking if cell exists, %v", err)
+		}
+		composite, err := cli.KubeCli().GetComposite(instanceName)
+		if err != nil {
+			if compositeNotFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instanceName,
+				err); compositeNotFound {
+				return nil, fmt.Errorf("cannot find running dependency instance %s", instanceName)
+			}
+			return nil, fmt.Errorf("error checking if composite exists, %v", err)
 		}
+		dependencies = composite.CompositeMetaData.Annotations.Dependencies
 	}
+	if dependencies == "" {
+		return nil, nil
+	}
+	return routing.ExtractDependencies(dependencies)
+}
 
-	if componentName == "" {
-		var err error
-		if sysLog {
-			err = cli.KubeCli().StreamCellLogsAllComponents(instanceName, follow)
-		} else {
-			err = cli.KubeCli().StreamCellLogsUserComponents(instanceName, follow)
+// getContainersForLogs returns the containers of the running pods of an instance. Only the container of the given
+// component is returned if the component is not empty.
+func getContainersForLogs(cli cli.Cli, instanceName string, componentName string,
+	sysLog bool) ([]containerLogs, error) {
+	pods, _, err := getInstancePods(cli, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	var containers []containerLogs
+	for _, pod := range pods.Items {
+		if pod.PodStatus.Phase != "Running" {
+			continue
+		}
+		component := getComponentName(pod.MetaData.Name, instanceName)
+		if componentName != "" && component != componentName {
+			continue
+		}
+		if _, isUserComponent := pod.MetaData.Labels[constants.GroupName+"/component"]; !isUserComponent &&
+			!sysLog && componentName == "" {
+			continue
+		}
+		for _, container := range pod.PodSpec.Containers {
+			if componentName != "" && container.Name != componentName {
+				continue
+			}
+			prefix := fmt.Sprintf("%s/%s", instanceName, component)
+			if container.Name != component {
+				// sidecars of the component
+				prefix = fmt.Sprintf("%s/%s", prefix, container.Name)
+			}
+			containers = append(containers, containerLogs{pod: pod.MetaData.Name, container: container.Name,
+				prefix: fmt.Sprintf("[%s]", prefix)})
 		}
+	}
+	return containers, nil
+}
 
-		if err != nil {
-			return fmt.Errorf(fmt.Sprintf("Error getting logs for instance %s", instanceName), err)
+// streamLogs prints the logs of the containers one after the other, or concurrently in follow mode. In follow mode
+// the first error is returned as soon as it happens instead of after the other streams end, which ends the command.
+func streamLogs(cli cli.Cli, containers []containerLogs, options kubernetes.LogOptions) error {
+	var printLock sync.Mutex
+	stream := func(i int, container containerLogs) error {
+		out := &prefixWriter{
+			out:    cli.Out(),
+			prefix: color.New(logPrefixColors[i%len(logPrefixColors)]).Sprint(container.prefix),
+			lock:   &printLock,
 		}
-	} else {
-		if err := cli.KubeCli().IsComponentAvailable(instanceName, componentName); err != nil {
-			return fmt.Errorf(fmt.Sprintf("No logs found"), fmt.Errorf("cannot find component "+
-				"%s of cell instance %s", componentName, instanceName))
+		defer out.Flush()
+		if err := cli.KubeCli().StreamContainerLogs(container.pod, container.container, options,
+			out); err != nil {
+			return fmt.Errorf("error getting logs of %s, %v", container.prefix, err)
+		}
+		return nil
+	}
+	if !options.Follow {
+		for i, container := range containers {
+			if err := stream(i, container); err != nil {
+				return err
+			}
 		}
-		if err := cli.KubeCli().StreamComponentLogs(instanceName, componentName, follow); err != nil {
-			return fmt.Errorf(fmt.Sprintf("Error getting logs for component %s of instance %s",
-				componentName, instanceName), err)
+		return nil
+	}
+	// The channel is buffered so that the remaining streams do not block after an error is returned
+	errs := make(chan error, len(containers))
+	for i, container := range containers {
+		go func(i int, container containerLogs) {
+			errs <- stream(i, container)
+		}(i, container)
+	}
+	for range containers {
+		if err := <-errs; err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+// prefixWriter writes each line with a prefix. The lines of the containers streamed concurrently are not mixed up
+// as each line is written at once while holding the lock.
+type prefixWriter struct {
+	out    io.Writer
+	prefix string
+	lock   *sync.Mutex
+	buffer []byte
+}
+
+func (writer *prefixWriter) Write(p []byte) (int, error) {
+	writer.buffer = append(writer.buffer, p...)
+	for {
+		end := bytes.IndexByte(writer.buffer, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		if err := writer.writeLine(writer.buffer[:end+1]); err != nil {
+			return 0, err
+		}
+		writer.buffer = writer.buffer[end+1:]
+	}
+}
+
+// Flush writes the last line if it does not end with a new line
+func (writer *prefixWriter) Flush() {
+	if len(writer.buffer) > 0 {
+		_ = writer.writeLine(append(writer.buffer, '\n'))
+		writer.buffer = nil
+	}
+}
+
+func (writer *prefixWriter) writeLine(line []byte) error {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	_, err := fmt.Fprintf(writer.out, "%s %s", writer.prefix, line)
+	return err
+}
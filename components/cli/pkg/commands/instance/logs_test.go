@@ -19,7 +19,10 @@
 package instance
 
 import (
+	"fmt"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -157,7 +160,8 @@ func TestRunLogs(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunLogs(mockCli, tst.instance, tst.component, tst.sysLog, tst.follow)
+			err := RunLogs(mockCli, []string{tst.instance}, tst.component, tst.sysLog, false,
+				kubernetes.LogOptions{Follow: tst.follow, Tail: -1})
 			if err != nil {
 				t.Errorf("error in RunLogs, %v", err)
 			}
@@ -183,31 +187,210 @@ func TestRunLogsError(t *testing.T) {
 		errMessage string
 	}{
 		{
-			name:       "No logs of cell instance",
-			instance:   "employee",
-			component:  "",
-			errMessage: "No logs found%!(EXTRA *errors.errorString=cannot find running instance employee)",
+			name:      "No logs of cell instance",
+			instance:  "employee",
+			component: "",
+			errMessage: "no logs found, cannot find running instance employee, instance employee not " +
+				"available in the runtime",
 		},
 		{
-			name:       "No logs of cell component (both instance and component not available)",
-			instance:   "employee",
-			component:  "job",
-			errMessage: "No logs found%!(EXTRA *errors.errorString=cannot find running instance employee)",
+			name:      "No logs of cell component (both instance and component not available)",
+			instance:  "employee",
+			component: "job",
+			errMessage: "no logs found, cannot find running instance employee, instance employee not " +
+				"available in the runtime",
 		},
 		{
 			name:       "No logs of cell component (cell instance is available, component is not available)",
 			instance:   "hr",
 			component:  "hr",
-			errMessage: "No logs found%!(EXTRA *errors.errorString=cannot find component hr of cell instance hr)",
+			errMessage: "no logs found, cannot find component hr of instance hr, component hr not found",
 		},
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
 			err := RunLogs(test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells)))),
-				tst.instance, tst.component, false, false)
+				[]string{tst.instance}, tst.component, false, false, kubernetes.LogOptions{Tail: -1})
 			if diff := cmp.Diff(tst.errMessage, err.Error()); diff != "" {
 				t.Errorf("RunLogs: unexpected error (-want, +got)\n%v", diff)
 			}
 		})
 	}
 }
+
+// failingLogsKubeCli fails to stream the logs of a container while the logs of the other containers are followed
+// until the test ends
+type failingLogsKubeCli struct {
+	*test.MockKubeCli
+	failingContainer string
+	done             chan struct{}
+}
+
+func (kubeCli failingLogsKubeCli) StreamContainerLogs(pod, container string, options kubernetes.LogOptions,
+	out io.Writer) error {
+	if container == kubeCli.failingContainer {
+		return fmt.Errorf("container %s is terminated", container)
+	}
+	<-kubeCli.done
+	return nil
+}
+
+func TestStreamLogsReportsFollowErrors(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	mockCli := test.NewMockCli(test.SetKubeCli(failingLogsKubeCli{
+		MockKubeCli:      test.NewMockKubeCli(),
+		failingContainer: "envoy",
+		done:             done,
+	}))
+	containers := []containerLogs{
+		{pod: "employee--job-deployment-7d9f8c6b5-x2x4k", container: "job", prefix: "job"},
+		{pod: "employee--job-deployment-7d9f8c6b5-x2x4k", container: "envoy", prefix: "job/envoy"},
+	}
+	errs := make(chan error)
+	go func() {
+		errs <- streamLogs(mockCli, containers, kubernetes.LogOptions{Follow: true, Tail: -1})
+	}()
+	select {
+	case err := <-errs:
+		if diff := cmp.Diff("error getting logs of job/envoy, container envoy is terminated", fmt.Sprint(err)); diff != "" {
+			t.Errorf("streamLogs: unexpected error (-want, +got)\n%v", diff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("streamLogs: the error was not reported while the other logs were followed")
+	}
+}
+
+// newLogsPod returns a running pod of a component with the given containers
+func newLogsPod(name string, component string, containers ...string) kubernetes.Pod {
+	pod := newPod(name, "Running")
+	if component != "" {
+		pod.MetaData.Labels = map[string]string{"mesh.cellery.io/component": component}
+	}
+	for _, container := range containers {
+		pod.PodSpec.Containers = append(pod.PodSpec.Containers, kubernetes.ContainerTemplate{Name: container})
+	}
+	return pod
+}
+
+func TestRunLogsOutput(t *testing.T) {
+	mockKubeCli := test.NewMockKubeCli(
+		test.WithCells(kubernetes.Cells{
+			Items: []kubernetes.Cell{
+				{
+					CellMetaData: kubernetes.K8SMetaData{
+						Name: "hr",
+						Annotations: kubernetes.CellAnnotations{
+							Dependencies: "[{\"org\":\"myorg\",\"name\":\"employee\",\"version\":\"1.0.0\"," +
+								"\"instance\":\"employee\",\"kind\":\"Cell\"},{\"org\":\"myorg\",\"name\":\"stock\"," +
+								"\"version\":\"1.0.0\",\"instance\":\"stock\",\"kind\":\"Composite\"}]",
+						},
+					},
+				},
+				{
+					CellMetaData: kubernetes.K8SMetaData{
+						Name: "employee",
+						Annotations: kubernetes.CellAnnotations{
+							Dependencies: "[{\"org\":\"myorg\",\"name\":\"stock\",\"version\":\"1.0.0\"," +
+								"\"instance\":\"stock\",\"kind\":\"Composite\"}]",
+						},
+					},
+				},
+			},
+		}),
+		test.WithComposites(kubernetes.Composites{
+			Items: []kubernetes.Composite{{CompositeMetaData: kubernetes.K8SMetaData{Name: "stock"}}},
+		}),
+		test.WithComponents(kubernetes.Components{
+			Items: []kubernetes.Component{{ComponentMetaData: kubernetes.K8SMetaData{Name: "salary"}}},
+		}),
+		test.WithPods(map[string]kubernetes.Pods{
+			"hr": {
+				Items: []kubernetes.Pod{newLogsPod("hr--hr-deployment-6b8c7d9f5-p4l9s", "hr", "hr")},
+			},
+			"employee": {
+				Items: []kubernetes.Pod{
+					newLogsPod("employee--gateway-deployment-7d9f8c6b5-x2x4k", "", "envoy-gateway"),
+					newLogsPod("employee--salary-deployment-5c7b9d8f6-mz7rt", "salary", "salary", "istio-proxy"),
+					newPod("employee--job-deployment-6b8c7d9f5-p4l9s", "Pending"),
+				},
+			},
+			"stock": {
+				Items: []kubernetes.Pod{newLogsPod("stock--stock-deployment-6f9c8b7d5-q8w2e", "stock", "stock")},
+			},
+		}),
+		test.WithContainerLogs(map[string]string{
+			"hr--hr-deployment-6b8c7d9f5-p4l9s/hr":                       "hr started\n",
+			"employee--gateway-deployment-7d9f8c6b5-x2x4k/envoy-gateway": "gateway started\n",
+			"employee--salary-deployment-5c7b9d8f6-mz7rt/salary":         "salary started\nsalary ready",
+			"employee--salary-deployment-5c7b9d8f6-mz7rt/istio-proxy":    "proxy started\n",
+			"stock--stock-deployment-6f9c8b7d5-q8w2e/stock":              "stock started\n",
+		}))
+	tests := []struct {
+		name             string
+		instances        []string
+		component        string
+		sysLog           bool
+		withDependencies bool
+		options          kubernetes.LogOptions
+		want             string
+	}{
+		{
+			name:      "logs of the user components",
+			instances: []string{"employee"},
+			options:   kubernetes.LogOptions{Tail: -1},
+			want: "[employee/salary] salary started\n" +
+				"[employee/salary] salary ready\n" +
+				"[employee/salary/istio-proxy] proxy started\n",
+		},
+		{
+			name:      "logs of all the components",
+			instances: []string{"employee"},
+			sysLog:    true,
+			options:   kubernetes.LogOptions{Tail: 10, Timestamps: true},
+			want: "[employee/gateway/envoy-gateway] gateway started\n" +
+				"[employee/salary] salary started\n" +
+				"[employee/salary] salary ready\n" +
+				"[employee/salary/istio-proxy] proxy started\n",
+		},
+		{
+			name:      "logs of a component",
+			instances: []string{"employee"},
+			component: "salary",
+			options:   kubernetes.LogOptions{Tail: -1},
+			want:      "[employee/salary] salary started\n[employee/salary] salary ready\n",
+		},
+		{
+			name:      "logs of multiple instances",
+			instances: []string{"stock", "hr"},
+			options:   kubernetes.LogOptions{Tail: -1},
+			want:      "[stock/stock] stock started\n[hr/hr] hr started\n",
+		},
+		{
+			name:             "logs of the dependency tree",
+			instances:        []string{"hr"},
+			withDependencies: true,
+			options:          kubernetes.LogOptions{Tail: -1},
+			want: "[hr/hr] hr started\n" +
+				"[employee/salary] salary started\n" +
+				"[employee/salary] salary ready\n" +
+				"[employee/salary/istio-proxy] proxy started\n" +
+				"[stock/stock] stock started\n",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunLogs(mockCli, tst.instances, tst.component, tst.sysLog, tst.withDependencies, tst.options)
+			if err != nil {
+				t.Fatalf("error in RunLogs, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, mockCli.OutBuffer().String()); diff != "" {
+				t.Errorf("RunLogs: unexpected output (-want, +got)\n%v", diff)
+			}
+			if diff := cmp.Diff(tst.options, mockKubeCli.LogOptions()); diff != "" {
+				t.Errorf("RunLogs: unexpected log options (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
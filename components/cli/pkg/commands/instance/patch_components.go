@@ -77,9 +77,17 @@ func RunPatchForSingleComponent(cli cli.Cli, instance string, component string,
 			return fmt.Errorf("error patching single component in cell, %v", err)
 		}
 	}
+	snapshot, err := snapshotRevision(cli, instance, artifactFile)
+	if err != nil {
+		return err
+	}
 	if err = cli.KubeCli().ApplyFile(artifactFile); err != nil {
 		return fmt.Errorf("error applying yaml %s", artifactFile)
 	}
+	if err = recordRevision(cli, instance, fmt.Sprintf("patch component %s with container image %s", component,
+		containerImage), snapshot); err != nil {
+		return err
+	}
 	util.PrintSuccessMessage(fmt.Sprintf("Successfully patched the component %s in instance %s with container image %s", component, instance, containerImage))
 	return nil
 }
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+)
+
+// RunPortForward forwards local ports to a component of a cell or a composite instance. The ports are forwarded to
+// the gateway of a cell if the component is not given.
+func RunPortForward(cli cli.Cli, instance string, component string, ports []string) error {
+	for _, port := range ports {
+		if err := validatePortMapping(port); err != nil {
+			return err
+		}
+	}
+	pods, isComposite, err := getInstancePods(cli, instance)
+	if err != nil {
+		return err
+	}
+	if component == "" {
+		if isComposite {
+			return fmt.Errorf("composite instance %s does not have a gateway, expects a component", instance)
+		}
+		component = gatewayComponent
+	}
+	pod, err := getComponentPod(pods, instance, component)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cli.Out(), fmt.Sprintf("Forwarding ports %s to component %s of instance %s",
+		strings.Join(ports, ", "), component, instance))
+	return cli.KubeCli().PortForward(pod, ports)
+}
+
+// validatePortMapping checks whether the port mapping is in the form <local>:<remote>, where either of the ports
+// can be omitted
+func validatePortMapping(mapping string) error {
+	parts := strings.Split(mapping, ":")
+	if len(parts) > 2 || mapping == ":" {
+		return fmt.Errorf("expects ports in the form <local>:<remote>, received %s", mapping)
+	}
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		port, err := strconv.Atoi(part)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %s in %s", part, mapping)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+)
+
+func TestRunPortForward(t *testing.T) {
+	tests := []struct {
+		name                    string
+		instance                string
+		component               string
+		ports                   []string
+		wantPortForwards        []string
+		wantErrorMessagePortion string
+	}{
+		{
+			name:             "forward ports to the gateway of a cell",
+			instance:         "employee",
+			ports:            []string{"9090:80", "9443:443"},
+			wantPortForwards: []string{"employee--gateway-deployment-7d9f8c6b5-x2x4k 9090:80 9443:443"},
+		},
+		{
+			name:             "forward ports to a component of a cell",
+			instance:         "employee",
+			component:        "salary",
+			ports:            []string{"8080"},
+			wantPortForwards: []string{"employee--salary-deployment-5c7b9d8f6-mz7rt 8080"},
+		},
+		{
+			name:             "forward ports to a component of a composite",
+			instance:         "stock",
+			component:        "stock",
+			ports:            []string{":8080"},
+			wantPortForwards: []string{"stock--stock-deployment-6f9c8b7d5-q8w2e :8080"},
+		},
+		{
+			name:                    "forward ports to the gateway of a composite",
+			instance:                "stock",
+			ports:                   []string{"8080:8080"},
+			wantErrorMessagePortion: "composite instance stock does not have a gateway, expects a component",
+		},
+		{
+			name:                    "forward an invalid port",
+			instance:                "employee",
+			ports:                   []string{"9090:80000"},
+			wantErrorMessagePortion: "invalid port 80000 in 9090:80000",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := newEmployeeMockKubeCli()
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunPortForward(mockCli, tst.instance, tst.component, tst.ports)
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in RunPortForward, %v", err)
+			}
+			if diff := cmp.Diff(tst.wantPortForwards, mockKubeCli.PortForwards()); diff != "" {
+				t.Errorf("invalid ports forwarded (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/metrics"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// drainPollInterval is the time waited between the checks for the traffic to the retiring instance
+var drainPollInterval = 10 * time.Second
+
+// RunPromote switches all the instances depending on the old instance to the new instance, and terminates the old
+// instance once it stops receiving traffic. The traffic is considered drained when the request rate over the drain
+// window drops to zero within the drain timeout. The request metrics of the old instance should be available before
+// switching, since a missing metric cannot be told apart from drained traffic.
+func RunPromote(cli cli.Cli, oldInstance string, newInstance string, source metrics.Source,
+	drainWindow time.Duration, drainTimeout time.Duration, assumeYes bool) error {
+	if oldInstance == newInstance {
+		return fmt.Errorf("expects different instances to promote, received %s twice", oldInstance)
+	}
+	routes, err := routing.GetRoutes(cli, nil, oldInstance, newInstance)
+	if err != nil {
+		return fmt.Errorf("error getting the instances depending on instance %s, %v", oldInstance, err)
+	}
+	if len(routes) == 0 {
+		return fmt.Errorf("no cell/composite instances depend on instance %s", oldInstance)
+	}
+	var sourceInstances []string
+	table := output.NewTable("SOURCE", "KIND", "CURRENT DEPENDENCY", "NEW DEPENDENCY")
+	for _, route := range routes {
+		if err := route.Check(); err != nil {
+			versionErr, match := err.(errorpkg.CellGwApiVersionMismatchError)
+			if !match {
+				return fmt.Errorf("instance %s cannot be switched to instance %s, %v", route.Source(),
+					newInstance, err)
+			}
+			if !assumeYes {
+				canContinue, err := canContinueWithWarning(versionErr.ApiContext, versionErr.CurrentTargetApiVersion,
+					versionErr.NewTargetApiVersion)
+				if err != nil {
+					return err
+				}
+				if !canContinue {
+					fmt.Fprintln(cli.Out(), "Aborting promotion")
+					return nil
+				}
+			}
+		}
+		sourceInstances = append(sourceInstances, route.Source())
+		table.Append(route.Source(), sourceKind(route), oldInstance, newInstance)
+	}
+	if _, err := source.RequestRate(oldInstance, drainWindow); err == metrics.ErrNoSamples {
+		return fmt.Errorf("no request metrics found for instance %s, the traffic to the instance cannot be "+
+			"tracked to terminate it once drained", oldInstance)
+	} else if err != nil {
+		return fmt.Errorf("error getting the request rate of instance %s, %v", oldInstance, err)
+	}
+	fmt.Fprintln(cli.Out(), fmt.Sprintf("The following instances will be switched from instance %s to instance %s:",
+		oldInstance, newInstance))
+	if err := output.PrintTable(cli.Out(), "", nil, table); err != nil {
+		return err
+	}
+	if !assumeYes {
+		canContinue, _, err := util.GetYesOrNoFromUser(fmt.Sprintf("Switch the instances to %s and terminate "+
+			"instance %s", newInstance, oldInstance), false)
+		if err != nil {
+			return err
+		}
+		if !canContinue {
+			fmt.Fprintln(cli.Out(), "Aborting promotion")
+			return nil
+		}
+	}
+
+	// the API versions are already confirmed with the user
+	if err := routeTraffic(cli, sourceInstances, oldInstance, newInstance, 100, false, nil, 0,
+		true); err != nil {
+		return fmt.Errorf("failed to switch the dependent instances to instance %s, %v", newInstance, err)
+	}
+	if err := waitForDrain(cli, oldInstance, source, drainWindow, drainTimeout); err != nil {
+		util.PrintWhatsNextMessage("terminate the old instance once it stops receiving traffic",
+			fmt.Sprintf("cellery terminate %s", oldInstance))
+		return fmt.Errorf("switched the dependent instances to instance %s, but instance %s was not "+
+			"terminated, %v", newInstance, oldInstance, err)
+	}
+	if err := cli.ExecuteTask(fmt.Sprintf("Terminating instance %s", oldInstance),
+		fmt.Sprintf("Failed to terminate instance %s", oldInstance), "", func() error {
+			return terminateInstance(cli, oldInstance)
+		}); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully promoted instance %s and terminated instance %s",
+		newInstance, oldInstance))
+	return nil
+}
+
+// waitForDrain waits until an instance stops receiving requests, or the timeout elapses
+func waitForDrain(cli cli.Cli, instance string, source metrics.Source, window time.Duration,
+	timeout time.Duration) error {
+	return cli.ExecuteTask(fmt.Sprintf("Waiting for the traffic to instance %s to drain", instance),
+		fmt.Sprintf("Traffic to instance %s did not drain", instance), "", func() error {
+			deadline := time.Now().Add(timeout)
+			for {
+				requestRate, err := source.RequestRate(instance, window)
+				if err == metrics.ErrNoSamples {
+					return fmt.Errorf("request metrics of instance %s are no longer available", instance)
+				} else if err != nil {
+					return fmt.Errorf("error getting the request rate of instance %s, %v", instance, err)
+				}
+				if requestRate == 0 {
+					return nil
+				}
+				if !time.Now().Before(deadline) {
+					return fmt.Errorf("instance %s still receives %.2f requests per second after %s", instance,
+						requestRate, timeout)
+				}
+				time.Sleep(drainPollInterval)
+			}
+		})
+}
+
+func sourceKind(route routing.Route) string {
+	switch route.(type) {
+	case *routing.CellToCellRoute, *routing.CellToCompositeRoute:
+		return "Cell"
+	case *routing.CompositeToCellRoute, *routing.CompositeToCompositeRoute:
+		return "Composite"
+	}
+	return ""
+}
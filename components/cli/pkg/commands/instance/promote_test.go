@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+func newPetComposite(name string, version string, dependencies string) kubernetes.Composite {
+	return kubernetes.Composite{
+		Kind:       "Composite",
+		APIVersion: "mesh.cellery.io/v1alpha2",
+		CompositeMetaData: kubernetes.K8SMetaData{
+			Name: name,
+			Annotations: kubernetes.CellAnnotations{
+				Organization: "myorg",
+				Name:         "petbe",
+				Version:      version,
+				Dependencies: dependencies,
+			},
+		},
+		CompositeSpec: kubernetes.CompositeSpec{
+			ComponentTemplates: []kubernetes.ComponentTemplate{
+				{
+					Metadata: kubernetes.ComponentTemplateMetadata{Name: "controller"},
+					Spec: kubernetes.ComponentTemplateSpec{
+						Ports: []kubernetes.Port{{Protocol: "HTTP", Port: 80, TargetPort: 8080}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRunPromote(t *testing.T) {
+	tests := []struct {
+		name                    string
+		oldInstance             string
+		requestRate             float64
+		noSamples               bool
+		wantTerminated          bool
+		wantErrorMessagePortion string
+	}{
+		{
+			name:           "promote an instance after the traffic drains",
+			oldInstance:    "pet-be",
+			wantTerminated: true,
+		},
+		{
+			name:                    "promote an instance which keeps receiving traffic",
+			oldInstance:             "pet-be",
+			requestRate:             2.5,
+			wantErrorMessagePortion: "instance pet-be was not terminated",
+		},
+		{
+			name:                    "promote an instance without request metrics",
+			oldInstance:             "pet-be",
+			noSamples:               true,
+			wantErrorMessagePortion: "no request metrics found for instance pet-be",
+		},
+		{
+			name:                    "promote an instance without dependents",
+			oldInstance:             "pet-fe",
+			wantErrorMessagePortion: "no cell/composite instances depend on instance pet-fe",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			vs := kubernetes.VirtualService{
+				Kind:       "VirtualService",
+				APIVersion: "networking.istio.io/v1alpha3",
+				VsMetaData: kubernetes.VsMetaData{Name: "pet-fe--vs"},
+				VsSpec: kubernetes.VsSpec{
+					HTTP: []kubernetes.HTTP{
+						{
+							Route: []kubernetes.HTTPRoute{
+								{Destination: kubernetes.Destination{Host: "pet-be--controller-service"}},
+							},
+						},
+					},
+				},
+			}
+			petFe := newPetComposite("pet-fe", "1.0.0",
+				`[{"org":"myorg","name":"petbe","version":"1.0.0","instance":"pet-be","kind":"Composite","alias":"petbe"}]`)
+			mockKubeCli := test.NewMockKubeCli(
+				test.WithComposites(kubernetes.Composites{
+					Items: []kubernetes.Composite{
+						petFe,
+						newPetComposite("pet-be", "1.0.0", ""),
+						newPetComposite("pet-be-v2", "2.0.0", ""),
+					},
+				}),
+				test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe--vs": vs}))
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+
+			err := RunPromote(mockCli, tst.oldInstance, "pet-be-v2",
+				&stubMetricsSource{requestRate: tst.requestRate, noSamples: tst.noSamples}, 0, 0, true)
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+			} else if err != nil {
+				t.Fatalf("error in RunPromote, %v", err)
+			}
+			terminated := false
+			for _, resource := range mockKubeCli.DeletedResources() {
+				if resource == "composite/pet-be" {
+					terminated = true
+				}
+			}
+			if terminated != tst.wantTerminated {
+				t.Errorf("expected instance pet-be to be terminated: %t, deleted resources: %v", tst.wantTerminated,
+					mockKubeCli.DeletedResources())
+			}
+			if tst.noSamples {
+				if len(mockKubeCli.AppliedFiles()) > 0 {
+					t.Errorf("expected the dependents not to be switched without request metrics")
+				}
+				return
+			}
+			if tst.oldInstance != "pet-be" {
+				return
+			}
+			// the dependents should be switched to the new instance even if the old instance was not terminated
+			vsBytes, err := mockKubeCli.GetInstanceBytes("virtualservice.networking.istio.io", "pet-fe--vs")
+			if err != nil {
+				t.Fatalf("virtual service pet-fe--vs not applied, %v", err)
+			}
+			appliedVs := kubernetes.VirtualService{}
+			if err := json.Unmarshal(vsBytes, &appliedVs); err != nil {
+				t.Fatalf("failed to unmarshal the applied virtual service, %v", err)
+			}
+			wantRoutes := []kubernetes.HTTPRoute{
+				{Destination: kubernetes.Destination{Host: "pet-be-v2--controller-service"}, Weight: 100},
+			}
+			if diff := cmp.Diff(wantRoutes, appliedVs.VsSpec.HTTP[0].Route); diff != "" {
+				t.Errorf("invalid routes (-want, +got)\n%v", diff)
+			}
+			compositeBytes, err := mockKubeCli.GetInstanceBytes("composite.mesh.cellery.io", "pet-fe")
+			if err != nil {
+				t.Fatalf("composite pet-fe not applied, %v", err)
+			}
+			appliedComposite := kubernetes.Composite{}
+			if err := json.Unmarshal(compositeBytes, &appliedComposite); err != nil {
+				t.Fatalf("failed to unmarshal the applied composite, %v", err)
+			}
+			dependencies, err := routing.ExtractDependencies(appliedComposite.CompositeMetaData.Annotations.Dependencies)
+			if err != nil {
+				t.Fatalf("failed to read the dependencies of the applied composite, %v", err)
+			}
+			wantDependencies := []map[string]string{
+				{"org": "myorg", "name": "petbe", "version": "2.0.0", "instance": "pet-be-v2", "kind": "Composite",
+					"alias": "petbe"},
+			}
+			if diff := cmp.Diff(wantDependencies, dependencies); diff != "" {
+				t.Errorf("invalid dependencies (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
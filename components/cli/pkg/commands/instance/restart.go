@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/policies"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// restartPollInterval is the interval at which the deployments are checked while waiting for the restart
+const restartPollInterval = 2 * time.Second
+
+// restartedAtAnnotation is the pod template annotation changed to trigger a rolling restart, as done by
+// kubectl rollout restart
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// RunRestart triggers a rolling restart of the components of an instance, or of all the components if none is
+// given, and waits until the restarted components are ready. Components with a kpa scaling policy are served by
+// Knative revisions instead of deployments, hence they are skipped when restarting all the components.
+func RunRestart(cli cli.Cli, instance string, components []string, timeout time.Duration) error {
+	kind, err := getInstanceKind(cli, instance)
+	if err != nil {
+		return err
+	}
+	resource, err := getScaleResource(cli, kind, instance)
+	if err != nil {
+		return err
+	}
+	var available []string
+	var serverless []string
+	for _, component := range resource.Spec.Components {
+		available = append(available, component.Metadata.Name)
+		scalingPolicy, err := parseScalingPolicy(component.Spec.ScalingPolicy)
+		if err != nil {
+			return fmt.Errorf("error reading scaling policy of component %s, %v", component.Metadata.Name, err)
+		}
+		if scalingPolicy.Kpa != nil {
+			serverless = append(serverless, component.Metadata.Name)
+		}
+	}
+	if kind == kubernetes.InstanceKindCell {
+		available = append(available, gatewayComponent)
+	}
+	if err := validateComponents(instance, components, available); err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		for _, component := range available {
+			if util.ContainsInStringArray(serverless, component) {
+				fmt.Fprintf(cli.Out(), "Skipping component %s as it is served by Knative revisions\n", component)
+			} else {
+				components = append(components, component)
+			}
+		}
+		if len(components) == 0 {
+			return fmt.Errorf("instance %s does not have any components to restart", instance)
+		}
+	}
+	var deployments []string
+	for _, component := range components {
+		if util.ContainsInStringArray(serverless, component) {
+			return fmt.Errorf("component %s is scaled by a kpa policy and served by Knative revisions, which "+
+				"cannot be restarted", component)
+		}
+		deployment := policies.GetTargetComponentDeploymentName(instance, component)
+		if component == gatewayComponent && kind == kubernetes.InstanceKindCell {
+			deployment = policies.GetTargetGatewayeploymentName(instance)
+		}
+		deployments = append(deployments, deployment)
+	}
+	restartedAt := time.Now().Format(time.RFC3339)
+	if err = cli.ExecuteTask(fmt.Sprintf("Restarting components %s", strings.Join(components, ", ")),
+		"Failed to restart components", "", func() error {
+			for _, deployment := range deployments {
+				if err := restartDeployment(cli, deployment, restartedAt); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err = cli.ExecuteTask("Waiting for the restarted components to be ready",
+		"Failed to wait for the restarted components", "", func() error {
+			return waitForRollout(cli, deployments, timeout)
+		}); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully restarted components %s of instance %q",
+		strings.Join(components, ", "), instance))
+	return nil
+}
+
+// restartDeployment changes the restarted at annotation of the pod template of a deployment, which replaces the
+// pods of the deployment with a rolling update
+func restartDeployment(cli cli.Cli, name string, restartedAt string) error {
+	deployment, err := getDeployment(cli, name)
+	if err != nil {
+		return err
+	}
+	patch := []map[string]interface{}{
+		{
+			"op":    "add",
+			"path":  "/spec/template/metadata/annotations",
+			"value": map[string]string{restartedAtAnnotation: restartedAt},
+		},
+	}
+	if deployment.Spec.Template.Metadata.Annotations != nil {
+		// the other annotations of the pod template are kept
+		patch[0]["path"] = "/spec/template/metadata/annotations/" +
+			strings.Replace(restartedAtAnnotation, "/", "~1", -1)
+		patch[0]["value"] = restartedAt
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("failed to marshall patch, %v", err)
+	}
+	if err := cli.KubeCli().JsonPatch("deployment", name, string(patchBytes)); err != nil {
+		return fmt.Errorf("failed to restart deployment %s, %v", name, err)
+	}
+	return nil
+}
+
+// waitForRollout waits until the pods of the deployments are replaced and available
+func waitForRollout(cli cli.Cli, deployments []string, timeout time.Duration) error {
+	start := time.Now()
+	for _, name := range deployments {
+		for {
+			deployment, err := getDeployment(cli, name)
+			if err != nil {
+				return err
+			}
+			if isRolledOut(deployment) {
+				break
+			}
+			if time.Since(start) >= timeout {
+				return fmt.Errorf("timed out waiting for deployment %s to be ready, %d of %d updated replicas "+
+					"are available", name, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+			}
+			time.Sleep(restartPollInterval)
+		}
+	}
+	return nil
+}
+
+// isRolledOut checks whether the latest pod template of a deployment is rolled out to all the replicas, in the same
+// way as kubectl rollout status
+func isRolledOut(deployment kubernetes.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Metadata.Generation {
+		return false
+	}
+	replicas := 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.UpdatedReplicas >= replicas && status.Replicas <= status.UpdatedReplicas &&
+		status.AvailableReplicas >= status.UpdatedReplicas
+}
+
+func getDeployment(cli cli.Cli, name string) (kubernetes.Deployment, error) {
+	deployment := kubernetes.Deployment{}
+	data, err := cli.KubeCli().GetInstanceBytes("deployment", name)
+	if err != nil {
+		return deployment, fmt.Errorf("failed to get deployment %s, %v", name, err)
+	}
+	if err = json.Unmarshal(data, &deployment); err != nil {
+		return deployment, fmt.Errorf("failed to unmarshall deployment %s, %v", name, err)
+	}
+	return deployment, nil
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+)
+
+// newDeployment returns a deployment with the given pod template annotations and rollout status
+func newDeployment(name string, annotations string, rolledOut bool) []byte {
+	observedGeneration := 2
+	if !rolledOut {
+		observedGeneration = 1
+	}
+	return []byte(fmt.Sprintf(`{"metadata":{"name":"%s","generation":2},"spec":{"replicas":2,`+
+		`"template":{"metadata":{"annotations":%s}}},"status":{"observedGeneration":%d,"replicas":2,`+
+		`"updatedReplicas":2,"availableReplicas":2}}`, name, annotations, observedGeneration))
+}
+
+func TestRunRestart(t *testing.T) {
+	tests := []struct {
+		name                    string
+		instance                string
+		components              []string
+		rolledOut               bool
+		wantPatchPaths          map[string]string
+		wantErrorMessagePortion string
+	}{
+		{
+			name:       "restart all the components of a cell",
+			instance:   "pet-be-auto",
+			rolledOut:  true,
+			components: nil,
+			wantPatchPaths: map[string]string{
+				"deployment/pet-be-auto--catalog-deployment": "/spec/template/metadata/annotations/" +
+					"kubectl.kubernetes.io~1restartedAt",
+				"deployment/pet-be-auto--controller-deployment": "/spec/template/metadata/annotations",
+				"deployment/pet-be-auto--customers-deployment": "/spec/template/metadata/annotations/" +
+					"kubectl.kubernetes.io~1restartedAt",
+				"deployment/pet-be-auto--gateway-deployment": "/spec/template/metadata/annotations/" +
+					"kubectl.kubernetes.io~1restartedAt",
+				"deployment/pet-be-auto--orders-deployment": "/spec/template/metadata/annotations/" +
+					"kubectl.kubernetes.io~1restartedAt",
+			},
+		},
+		{
+			name:       "restart a component of a composite",
+			instance:   "stock",
+			components: []string{"stock"},
+			rolledOut:  true,
+			wantPatchPaths: map[string]string{
+				"deployment/stock--stock-deployment": "/spec/template/metadata/annotations/" +
+					"kubectl.kubernetes.io~1restartedAt",
+			},
+		},
+		{
+			name:      "restart all the components of a composite with a zero-scaled component",
+			instance:  "orders",
+			rolledOut: true,
+			wantPatchPaths: map[string]string{
+				"deployment/orders--api-deployment": "/spec/template/metadata/annotations/" +
+					"kubectl.kubernetes.io~1restartedAt",
+			},
+		},
+		{
+			name:                    "restart a zero-scaled component",
+			instance:                "orders",
+			components:              []string{"processor"},
+			wantErrorMessagePortion: "component processor is scaled by a kpa policy and served by Knative revisions",
+		},
+		{
+			name:       "restart unknown component",
+			instance:   "pet-be-auto",
+			components: []string{"controller", "payments"},
+			wantErrorMessagePortion: "component payments not found in instance pet-be-auto, available components: " +
+				"controller, catalog, orders, customers, gateway",
+		},
+		{
+			name:                    "restart times out",
+			instance:                "stock",
+			components:              []string{"stock"},
+			rolledOut:               false,
+			wantErrorMessagePortion: "timed out waiting for deployment stock--stock-deployment to be ready",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := newScaleMockKubeCli(t)
+			stock, _ := mockKubeCli.GetInstanceBytes("composites.mesh.cellery.io", "stock")
+			orders, _ := mockKubeCli.GetInstanceBytes("composites.mesh.cellery.io", "orders")
+			annotations := `{"sidecar.istio.io/inject":"true"}`
+			deployments := map[string][]byte{
+				"composites.mesh.cellery.io/stock":  stock,
+				"composites.mesh.cellery.io/orders": orders,
+				"deployment/pet-be-auto--controller-deployment": newDeployment("pet-be-auto--controller-deployment",
+					"null", tst.rolledOut),
+			}
+			for _, name := range []string{"pet-be-auto--catalog-deployment", "pet-be-auto--orders-deployment",
+				"pet-be-auto--customers-deployment", "pet-be-auto--gateway-deployment", "stock--stock-deployment",
+				"orders--api-deployment"} {
+				deployments["deployment/"+name] = newDeployment(name, annotations, tst.rolledOut)
+			}
+			test.WithResources(deployments)(mockKubeCli)
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunRestart(mockCli, tst.instance, tst.components, 0)
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in RunRestart, %v", err)
+			}
+			gotPatchPaths := make(map[string]string)
+			restartedAt := make(map[string]bool)
+			for key, patch := range mockKubeCli.JsonPatches() {
+				var gotPatch []map[string]interface{}
+				if err := json.Unmarshal([]byte(patch), &gotPatch); err != nil {
+					t.Fatalf("failed to unmarshal the applied patch, %v", err)
+				}
+				gotPatchPaths[key] = gotPatch[0]["path"].(string)
+				value := gotPatch[0]["value"]
+				if annotations, ok := value.(map[string]interface{}); ok {
+					value = annotations[restartedAtAnnotation]
+				}
+				restartedAt[fmt.Sprint(value)] = true
+			}
+			if diff := cmp.Diff(tst.wantPatchPaths, gotPatchPaths); diff != "" {
+				t.Errorf("invalid patches (-want, +got)\n%v", diff)
+			}
+			if len(restartedAt) != 1 {
+				t.Errorf("expected the deployments to be restarted at the same time, got %v", restartedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/revision"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+type revisionData struct {
+	Revision int    `json:"revision"`
+	Cause    string `json:"cause"`
+	Created  string `json:"created"`
+}
+
+// RunRolloutHistory lists the revisions recorded for an instance
+func RunRolloutHistory(cli cli.Cli, instance string, outputFormat string) error {
+	history, err := revision.History(cli, instance)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 && output.IsTable(outputFormat) {
+		fmt.Fprintf(cli.Out(), "No revisions found for instance %s.\n", instance)
+		return nil
+	}
+	revisions := []revisionData{}
+	table := output.NewTable("REVISION", "CHANGE-CAUSE", "CREATED")
+	for _, r := range history {
+		data := revisionData{
+			Revision: r.Number,
+			Cause:    r.Cause,
+			Created:  fmt.Sprintf("%s ago", units.HumanDuration(time.Since(time.Unix(r.Timestamp, 0)))),
+		}
+		revisions = append(revisions, data)
+		table.Append(strconv.Itoa(data.Revision), data.Cause, data.Created)
+	}
+	return output.PrintTable(cli.Out(), outputFormat, revisions, table)
+}
+
+// RunRolloutUndo restores the resources of an instance to a revision. The latest revision is restored if the
+// revision is zero.
+func RunRolloutUndo(cli cli.Cli, instance string, toRevision int) error {
+	var restored *revision.Revision
+	var err error
+	if err = cli.ExecuteTask(fmt.Sprintf("Rolling back instance %s", instance),
+		fmt.Sprintf("Failed to roll back instance %s", instance), "", func() error {
+			restored, err = revision.Restore(cli, instance, toRevision)
+			return err
+		}); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully rolled back instance %s to revision %d (before %s)",
+		instance, restored.Number, restored.Cause))
+	util.PrintWhatsNextMessage("view the revisions of the instance", "cellery rollout history "+instance)
+	return nil
+}
+
+// snapshotRevision takes a snapshot of the current state of the resources in the artifact file, which is recorded as
+// a revision of the instance with recordRevision once the artifact is applied
+func snapshotRevision(cli cli.Cli, instance string, artifactFile string) (*revision.Snapshot, error) {
+	resources, err := revision.ResourcesInFile(artifactFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the resources to be modified, %v", err)
+	}
+	return takeSnapshot(cli, instance, resources)
+}
+
+// takeSnapshot takes a snapshot of the current state of the resources of the instance before they are modified
+func takeSnapshot(cli cli.Cli, instance string, resources []revision.Resource) (*revision.Snapshot, error) {
+	snapshot, err := revision.TakeSnapshot(cli, instance, resources)
+	if err != nil {
+		return nil, fmt.Errorf("error taking a snapshot of instance %s, %v", instance, err)
+	}
+	return snapshot, nil
+}
+
+// instanceSnapshot is a snapshot of one of the instances modified by an operation on several instances
+type instanceSnapshot struct {
+	instance string
+	snapshot *revision.Snapshot
+}
+
+// recordRevisions records the snapshots taken before the instances were modified as revisions of the instances
+func recordRevisions(cli cli.Cli, cause string, snapshots []instanceSnapshot) error {
+	for _, s := range snapshots {
+		if err := recordRevision(cli, s.instance, cause, s.snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRevision records a snapshot taken before the instance was modified as a revision of the instance
+func recordRevision(cli cli.Cli, instance string, cause string, snapshot *revision.Snapshot) error {
+	if err := snapshot.Record(cli, cause); err != nil {
+		return fmt.Errorf("the changes were applied, but recording the revision of instance %s failed, %v",
+			instance, err)
+	}
+	return nil
+}
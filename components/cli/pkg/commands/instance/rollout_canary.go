@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/metrics"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+const canaryLatencyQuantile = 0.99
+
+// CanaryPolicy defines the percentages of the traffic routed to the target instance at each step of a canary rollout
+// and the thresholds the target instance should satisfy before moving to the next step. A maximum threshold is not
+// checked if it is zero, while the target instance should always receive requests to be analysed.
+type CanaryPolicy struct {
+	Steps          []int
+	Interval       time.Duration
+	MinRequestRate float64
+	MaxErrorRate   float64
+	MaxLatency     time.Duration
+}
+
+// RunRolloutCanary routes the traffic from the dependency instance to the target instance step by step. The error
+// rate and the latency of the target instance are read from the metrics source after each step, and the traffic is
+// routed back to the dependency instance if a threshold is breached.
+func RunRolloutCanary(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string,
+	policy CanaryPolicy, source metrics.Source, enableUserBasedSessionAwareness bool, assumeYes bool) error {
+	if err := validateCanaryPolicy(policy); err != nil {
+		return err
+	}
+	for i, percentage := range policy.Steps {
+		fmt.Fprintf(cli.Out(), "Step %d/%d: ", i+1, len(policy.Steps))
+		// The user confirms the API version mismatches, if any, at the first step
+		if err := routeTraffic(cli, sourceInstances, dependencyInstance, targetInstance, percentage,
+			enableUserBasedSessionAwareness, nil, 0, assumeYes || i > 0); err != nil {
+			if i == 0 {
+				return err
+			}
+			return revertCanary(cli, sourceInstances, dependencyInstance, targetInstance,
+				enableUserBasedSessionAwareness, err)
+		}
+		if percentage == 100 {
+			break
+		}
+		if err := analyseCanary(cli, targetInstance, policy, source); err != nil {
+			return revertCanary(cli, sourceInstances, dependencyInstance, targetInstance,
+				enableUserBasedSessionAwareness, fmt.Errorf("canary analysis failed at %d%% of traffic, %v",
+					percentage, err))
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully routed %d%% of traffic to instance %s",
+		policy.Steps[len(policy.Steps)-1], targetInstance))
+	util.PrintWhatsNextMessage("view the revisions of the source instances", "cellery rollout history <instance>")
+	return nil
+}
+
+func validateCanaryPolicy(policy CanaryPolicy) error {
+	if len(policy.Steps) == 0 {
+		return fmt.Errorf("at least one canary step is required")
+	}
+	previous := 0
+	for _, percentage := range policy.Steps {
+		if percentage <= previous || percentage > 100 {
+			return fmt.Errorf("canary steps should be increasing percentages between 1 and 100, received %v",
+				policy.Steps)
+		}
+		previous = percentage
+	}
+	if policy.Interval <= 0 {
+		return fmt.Errorf("canary interval should be greater than 0, received %s", policy.Interval)
+	}
+	if policy.MinRequestRate < 0 || policy.MaxErrorRate < 0 || policy.MaxLatency < 0 {
+		return fmt.Errorf("canary thresholds should not be negative")
+	}
+	return nil
+}
+
+// analyseCanary waits for the interval of the policy and checks the metrics of the target instance during the
+// interval against the thresholds. The target instance fails the analysis if it did not receive enough requests,
+// since the error rate and the latency cannot be trusted without them.
+func analyseCanary(cli cli.Cli, targetInstance string, policy CanaryPolicy, source metrics.Source) error {
+	var requestRate float64
+	var errorRate float64
+	var latency time.Duration
+	if err := cli.ExecuteTask(fmt.Sprintf("Analysing instance %s for %s", targetInstance, policy.Interval),
+		"Failed to analyse the canary", "", func() error {
+			time.Sleep(policy.Interval)
+			var err error
+			requestRate, err = source.RequestRate(targetInstance, policy.Interval)
+			if err == metrics.ErrNoSamples {
+				requestRate = 0
+			} else if err != nil {
+				return err
+			}
+			if requestRate == 0 || requestRate < policy.MinRequestRate {
+				return nil
+			}
+			if errorRate, err = source.ErrorRate(targetInstance, policy.Interval); err != nil {
+				return err
+			}
+			latency, err = source.Latency(targetInstance, canaryLatencyQuantile, policy.Interval)
+			return err
+		}); err != nil {
+		return err
+	}
+	if requestRate == 0 {
+		return fmt.Errorf("instance %s did not receive any requests to analyse", targetInstance)
+	}
+	if requestRate < policy.MinRequestRate {
+		return fmt.Errorf("request rate %.2f of instance %s is below %.2f requests per second", requestRate,
+			targetInstance, policy.MinRequestRate)
+	}
+	fmt.Fprintf(cli.Out(), "Instance %s: %.2f requests per second, error rate %.2f%%, p%g latency %s\n",
+		targetInstance, requestRate, errorRate, canaryLatencyQuantile*100, latency)
+	if policy.MaxErrorRate > 0 && errorRate > policy.MaxErrorRate {
+		return fmt.Errorf("error rate %.2f%% of instance %s exceeds %.2f%%", errorRate, targetInstance,
+			policy.MaxErrorRate)
+	}
+	if policy.MaxLatency > 0 && latency > policy.MaxLatency {
+		return fmt.Errorf("p%g latency %s of instance %s exceeds %s", canaryLatencyQuantile*100, latency,
+			targetInstance, policy.MaxLatency)
+	}
+	return nil
+}
+
+// revertCanary routes all the traffic back to the dependency instance and returns the cause of the revert
+func revertCanary(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string,
+	enableUserBasedSessionAwareness bool, cause error) error {
+	util.PrintWarningMessage(fmt.Sprintf("%v, routing all traffic back to instance %s", cause, dependencyInstance))
+	if err := routeTraffic(cli, sourceInstances, dependencyInstance, targetInstance, 0,
+		enableUserBasedSessionAwareness, nil, 0, true); err != nil {
+		return fmt.Errorf("%v, failed to route traffic back to instance %s, %v", cause, dependencyInstance, err)
+	}
+	return cause
+}
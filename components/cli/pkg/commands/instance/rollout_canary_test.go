@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/metrics"
+)
+
+// stubMetricsSource returns the error rates in order, one for each analysis
+type stubMetricsSource struct {
+	errorRates  []float64
+	latency     time.Duration
+	requestRate float64
+	noSamples   bool
+	queries     int
+}
+
+func (source *stubMetricsSource) ErrorRate(instance string, window time.Duration) (float64, error) {
+	if source.queries >= len(source.errorRates) {
+		return 0, fmt.Errorf("unexpected query for instance %s", instance)
+	}
+	source.queries++
+	return source.errorRates[source.queries-1], nil
+}
+
+func (source *stubMetricsSource) Latency(instance string, quantile float64, window time.Duration) (time.Duration,
+	error) {
+	return source.latency, nil
+}
+
+func (source *stubMetricsSource) RequestRate(instance string, window time.Duration) (float64, error) {
+	if source.noSamples {
+		return 0, metrics.ErrNoSamples
+	}
+	return source.requestRate, nil
+}
+
+func TestRunRolloutCanary(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, name := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", name+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file, %v", name, err)
+		}
+		cellMap[name] = cell
+	}
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs virtual service file, %v", err)
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err := json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshal pet-fe-src-vs virtual service, %v", err)
+	}
+	tests := []struct {
+		name              string
+		policy            CanaryPolicy
+		source            *stubMetricsSource
+		expectedError     string
+		expectedQueries   int
+		expectedDepWeight int
+	}{
+		{
+			name:              "healthy canary",
+			policy:            CanaryPolicy{Steps: []int{10, 50}, Interval: time.Millisecond, MaxErrorRate: 1},
+			source:            &stubMetricsSource{errorRates: []float64{0, 0.5}, requestRate: 5},
+			expectedQueries:   2,
+			expectedDepWeight: 50,
+		},
+		{
+			name:              "error rate breached",
+			policy:            CanaryPolicy{Steps: []int{10, 50, 100}, Interval: time.Millisecond, MaxErrorRate: 1},
+			source:            &stubMetricsSource{errorRates: []float64{0, 5}, requestRate: 5},
+			expectedError:     "canary analysis failed at 50% of traffic, error rate 5.00% of instance pet-be-target exceeds 1.00%",
+			expectedQueries:   2,
+			expectedDepWeight: 100,
+		},
+		{
+			name:              "latency breached",
+			policy:            CanaryPolicy{Steps: []int{25, 100}, Interval: time.Millisecond, MaxLatency: 100 * time.Millisecond},
+			source:            &stubMetricsSource{errorRates: []float64{0}, latency: time.Second, requestRate: 5},
+			expectedError:     "canary analysis failed at 25% of traffic, p99 latency 1s of instance pet-be-target exceeds 100ms",
+			expectedQueries:   1,
+			expectedDepWeight: 100,
+		},
+		{
+			name:              "target without requests",
+			policy:            CanaryPolicy{Steps: []int{10, 100}, Interval: time.Millisecond, MaxErrorRate: 1},
+			source:            &stubMetricsSource{errorRates: []float64{0}},
+			expectedError:     "canary analysis failed at 10% of traffic, instance pet-be-target did not receive any requests to analyse",
+			expectedQueries:   0,
+			expectedDepWeight: 100,
+		},
+		{
+			name:              "request rate below minimum",
+			policy:            CanaryPolicy{Steps: []int{10, 100}, Interval: time.Millisecond, MinRequestRate: 10},
+			source:            &stubMetricsSource{errorRates: []float64{0}, requestRate: 2.5},
+			expectedError:     "canary analysis failed at 10% of traffic, request rate 2.50 of instance pet-be-target is below 10.00 requests per second",
+			expectedQueries:   0,
+			expectedDepWeight: 100,
+		},
+		{
+			name:          "decreasing steps",
+			policy:        CanaryPolicy{Steps: []int{50, 10}, Interval: time.Millisecond},
+			source:        &stubMetricsSource{},
+			expectedError: "canary steps should be increasing percentages between 1 and 100, received [50 10]",
+		},
+		{
+			name:          "zero interval",
+			policy:        CanaryPolicy{Steps: []int{10, 100}},
+			source:        &stubMetricsSource{},
+			expectedError: "canary interval should be greater than 0, received 0s",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+				test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": petFeSrcVs}))
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunRolloutCanary(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target", tst.policy,
+				tst.source, false, true)
+			if diff := cmp.Diff(tst.expectedError, fmt.Sprint(err)); tst.expectedError != "" && diff != "" {
+				t.Errorf("RunRolloutCanary: unexpected error (-want, +got)\n%v", diff)
+			}
+			if tst.expectedError == "" && err != nil {
+				t.Fatalf("error in RunRolloutCanary, %v", err)
+			}
+			if diff := cmp.Diff(tst.expectedQueries, tst.source.queries); diff != "" {
+				t.Errorf("RunRolloutCanary: unexpected number of analyses (-want, +got)\n%v", diff)
+			}
+			if tst.expectedDepWeight == 0 {
+				return
+			}
+			var lastRoute string
+			for _, file := range mockKubeCli.AppliedFiles() {
+				if strings.Contains(file, "kind: VirtualService") {
+					lastRoute = file
+				}
+			}
+			depWeightPattern := regexp.MustCompile(`host: pet-be-dep--gateway-service\s+weight: (\d+)`)
+			depWeight := depWeightPattern.FindStringSubmatch(lastRoute)
+			if depWeight == nil || depWeight[1] != strconv.Itoa(tst.expectedDepWeight) {
+				t.Errorf("RunRolloutCanary: expected %d%% of traffic routed to pet-be-dep, got %s",
+					tst.expectedDepWeight, lastRoute)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/revision"
+)
+
+func newRolloutMockCli(t *testing.T) (*test.MockCli, *test.MockKubeCli) {
+	petBeCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-dep.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-be-dep cell file, %v", err)
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithResources(map[string][]byte{
+		"cell.mesh.cellery.io/pet-be-dep": petBeCell,
+	}))
+	return test.NewMockCli(test.SetKubeCli(mockKubeCli)), mockKubeCli
+}
+
+func TestRunRolloutHistory(t *testing.T) {
+	mockCli, _ := newRolloutMockCli(t)
+	if err := RunRolloutHistory(mockCli, "pet-be-dep", ""); err != nil {
+		t.Fatalf("error in RunRolloutHistory, %v", err)
+	}
+	if !strings.Contains(mockCli.OutBuffer().String(), "No revisions found for instance pet-be-dep") {
+		t.Errorf("expected no revisions, got %s", mockCli.OutBuffer().String())
+	}
+	resources := []revision.Resource{{Kind: "cell.mesh.cellery.io", Name: "pet-be-dep"}}
+	for _, cause := range []string{"patch component controller", "apply autoscale policies"} {
+		recordTestRevision(t, mockCli, "pet-be-dep", cause, resources)
+	}
+	mockCli.OutBuffer().Reset()
+	if err := RunRolloutHistory(mockCli, "pet-be-dep", "jsonpath={[*].cause}"); err != nil {
+		t.Fatalf("error in RunRolloutHistory, %v", err)
+	}
+	want := "patch component controller apply autoscale policies"
+	if got := mockCli.OutBuffer().String(); got != want {
+		t.Errorf("invalid history, want %q, got %q", want, got)
+	}
+}
+
+func TestRunRolloutUndo(t *testing.T) {
+	mockCli, mockKubeCli := newRolloutMockCli(t)
+	if err := RunRolloutUndo(mockCli, "pet-be-dep", 0); err == nil {
+		t.Errorf("expected an error when the instance has no revisions")
+	}
+	resources := []revision.Resource{{Kind: "cell.mesh.cellery.io", Name: "pet-be-dep"}}
+	recordTestRevision(t, mockCli, "pet-be-dep", "patch component controller", resources)
+	if err := RunRolloutUndo(mockCli, "pet-be-dep", 1); err != nil {
+		t.Fatalf("error in RunRolloutUndo, %v", err)
+	}
+	appliedFiles := mockKubeCli.AppliedFiles()
+	if !strings.Contains(appliedFiles[len(appliedFiles)-1], "name: pet-be-dep") {
+		t.Errorf("expected the cell to be restored, got %s", appliedFiles[len(appliedFiles)-1])
+	}
+}
+
+func recordTestRevision(t *testing.T, cli cli.Cli, instance string, cause string, resources []revision.Resource) {
+	snapshot, err := revision.TakeSnapshot(cli, instance, resources)
+	if err != nil {
+		t.Fatalf("error taking snapshot, %v", err)
+	}
+	if err := snapshot.Record(cli, cause); err != nil {
+		t.Fatalf("error recording revision, %v", err)
+	}
+}
@@ -20,6 +20,7 @@ package instance
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 
 	"cellery.io/cellery/components/cli/cli"
@@ -29,14 +30,68 @@ import (
 )
 
 func RunRouteTrafficCommand(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string, percentage int,
-	enableUserBasedSessionAwareness bool, assumeYes bool) error {
+	enableUserBasedSessionAwareness bool, matchRules []routing.MatchRule, mirror bool, assumeYes bool) error {
+	routePercentage, mirrorPercentage, err := getRoutingPercentages(targetInstance, percentage,
+		enableUserBasedSessionAwareness, matchRules, mirror)
+	if err != nil {
+		return err
+	}
+	if err := routeTraffic(cli, sourceInstances, dependencyInstance, targetInstance, routePercentage,
+		enableUserBasedSessionAwareness, matchRules, mirrorPercentage, assumeYes); err != nil {
+		return err
+	}
+	if mirror {
+		util.PrintSuccessMessage(fmt.Sprintf("Successfully mirrored %d%% of traffic to instance %s", percentage,
+			targetInstance))
+		util.PrintWhatsNextMessage("stop mirroring the traffic", fmt.Sprintf(
+			"cellery route-traffic --dependency %s --target %s --percentage 0", dependencyInstance, targetInstance))
+		return nil
+	}
+	if len(matchRules) > 0 {
+		util.PrintSuccessMessage(fmt.Sprintf("Successfully routed %d%% of traffic and the requests matching %d "+
+			"rule(s) to instance %s", percentage, len(matchRules), targetInstance))
+		return nil
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully routed %d%% of traffic to instance %s", percentage,
+		targetInstance))
+	return nil
+}
+
+// getRoutingPercentages returns the percentages of the traffic routed and mirrored to the target instance. In mirror
+// mode all the traffic is routed to the dependency instance while the given percentage is mirrored to the target.
+func getRoutingPercentages(targetInstance string, percentage int, enableUserBasedSessionAwareness bool,
+	matchRules []routing.MatchRule, mirror bool) (int, int, error) {
+	if len(matchRules) > 0 && percentage == 100 {
+		return 0, 0, fmt.Errorf("match rules cannot be used when routing 100%% of traffic to instance %s",
+			targetInstance)
+	}
+	if !mirror {
+		return percentage, 0, nil
+	}
+	if enableUserBasedSessionAwareness || len(matchRules) > 0 {
+		return 0, 0, fmt.Errorf("session awareness and match rules cannot be used when mirroring traffic")
+	}
+	if percentage == 0 {
+		return 0, 0, fmt.Errorf("mirror percentage should be greater than 0")
+	}
+	return 0, percentage, nil
+}
+
+// routeTraffic builds and applies the rules routing, and mirroring if the mirror percentage is not zero, a percentage
+// of the traffic from the dependency instance to the target instance
+func routeTraffic(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string,
+	percentage int, enableUserBasedSessionAwareness bool, matchRules []routing.MatchRule, mirrorPercentage int,
+	assumeYes bool) error {
 	var err error
 	artifactFile := fmt.Sprintf("./%s-routing-artifacts.yaml", dependencyInstance)
 	defer func() error {
 		return os.Remove(artifactFile)
 	}()
-	buildRouteArtifact(cli, sourceInstances, dependencyInstance, targetInstance, percentage,
-		enableUserBasedSessionAwareness, assumeYes)
+	snapshots, err := buildRouteArtifact(cli, sourceInstances, dependencyInstance, targetInstance, percentage,
+		enableUserBasedSessionAwareness, matchRules, mirrorPercentage, assumeYes)
+	if err != nil {
+		return err
+	}
 
 	if err = cli.ExecuteTask("Applying modified rules", "Failed to apply modified rules", "", func() error {
 		err = cli.KubeCli().ApplyFile(artifactFile)
@@ -47,29 +102,37 @@ func RunRouteTrafficCommand(cli cli.Cli, sourceInstances []string, dependencyIns
 	}); err != nil {
 		return err
 	}
-	util.PrintSuccessMessage(fmt.Sprintf("Successfully routed %d%% of traffic to instance %s", percentage,
-		targetInstance))
-	return nil
+	return recordRevisions(cli, getRoutingCause(dependencyInstance, targetInstance, percentage, matchRules,
+		mirrorPercentage), snapshots)
 }
 
+// buildRouteArtifact builds the modified rules of the source instances, returning the snapshots of the source
+// instances taken before they are modified
 func buildRouteArtifact(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string, percentage int,
-	enableUserBasedSessionAwareness bool, assumeYes bool) error {
-	fmt.Fprintln(cli.Out(), fmt.Sprintf("Starting to route %d%% of traffic to instance %s", percentage,
-		targetInstance))
+	enableUserBasedSessionAwareness bool, matchRules []routing.MatchRule, mirrorPercentage int,
+	assumeYes bool) ([]instanceSnapshot, error) {
+	if mirrorPercentage > 0 {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Starting to mirror %d%% of traffic to instance %s", mirrorPercentage,
+			targetInstance))
+	} else {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Starting to route %d%% of traffic to instance %s", percentage,
+			targetInstance))
+	}
 
 	// check the source instance and see if the dependency exists in the source
 	routes, err := routing.GetRoutes(cli, sourceInstances, dependencyInstance, targetInstance)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// now we have the source instance list which actually depend on the given dependency instance.
 	// get the virtual services corresponding to the given source instances and modify accordingly.
 	if len(routes) == 0 {
 		// no depending instances
-		return fmt.Errorf("cell/composite instance %s not found among dependencies of source instance(s)",
+		return nil, fmt.Errorf("cell/composite instance %s not found among dependencies of source instance(s)",
 			dependencyInstance)
 	}
 	artifactFile := fmt.Sprintf("./%s-routing-artifacts.yaml", dependencyInstance)
+	var snapshots []instanceSnapshot
 	for _, route := range routes {
 		err := route.Check()
 		if err != nil {
@@ -79,28 +142,64 @@ func buildRouteArtifact(cli cli.Cli, sourceInstances []string, dependencyInstanc
 					// prompt confirmation from user
 					canContinue, err := canContinueWithWarning(versionErr.ApiContext, versionErr.CurrentTargetApiVersion, versionErr.NewTargetApiVersion)
 					if err != nil {
-						return err
+						return nil, err
 					}
 					if !canContinue {
 						fmt.Fprintln(cli.Out(), "Aborting traffic routing")
-						return nil
+						return snapshots, nil
 					}
 				}
 			} else {
-				return err
+				return nil, err
 			}
 		}
+		// Each route is built separately to record the revision of the source instance
+		routeFile := fmt.Sprintf("./%s-%s-routing-artifacts.yaml", dependencyInstance, route.Source())
 		if err = cli.ExecuteTask("Building modified rules", "Failed to build modified rules", "", func() error {
-			return route.Build(cli, percentage, enableUserBasedSessionAwareness, artifactFile)
-			if err != nil {
+			defer os.Remove(routeFile)
+			if err := route.Build(cli, percentage, enableUserBasedSessionAwareness, matchRules, mirrorPercentage,
+				routeFile); err != nil {
 				return fmt.Errorf("error occurred while building modified rules, %v", err)
 			}
-			return nil
+			snapshot, err := snapshotRevision(cli, route.Source(), routeFile)
+			if err != nil {
+				return err
+			}
+			snapshots = append(snapshots, instanceSnapshot{instance: route.Source(), snapshot: snapshot})
+			return appendFile(routeFile, artifactFile)
 		}); err != nil {
-			return err
+			return nil, err
 		}
 	}
-	return nil
+	return snapshots, nil
+}
+
+// getRoutingCause returns the change recorded in the revision history of the source instances when routing traffic
+func getRoutingCause(dependencyInstance string, targetInstance string, percentage int, matchRules []routing.MatchRule,
+	mirrorPercentage int) string {
+	if mirrorPercentage > 0 {
+		return fmt.Sprintf("mirror %d%% of traffic from %s to %s", mirrorPercentage, dependencyInstance,
+			targetInstance)
+	}
+	cause := fmt.Sprintf("route %d%% of traffic from %s to %s", percentage, dependencyInstance, targetInstance)
+	if len(matchRules) > 0 {
+		cause = fmt.Sprintf("%s with %d match rule(s)", cause, len(matchRules))
+	}
+	return cause
+}
+
+func appendFile(src string, dst string) error {
+	content, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(content)
+	return err
 }
 
 func canContinueWithWarning(context string, currVersion string, newVersion string) (bool, error) {
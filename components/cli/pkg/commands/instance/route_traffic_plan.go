@@ -0,0 +1,267 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunRouteTrafficPlan prints the routing plan built for the given routing parameters, without modifying any of the
+// instances. The plan can be applied later with RunRouteTrafficFromFile.
+func RunRouteTrafficPlan(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string,
+	percentage int, enableUserBasedSessionAwareness bool, matchRules []routing.MatchRule, mirror bool) error {
+	routePercentage, mirrorPercentage, err := getRoutingPercentages(targetInstance, percentage,
+		enableUserBasedSessionAwareness, matchRules, mirror)
+	if err != nil {
+		return err
+	}
+	plan, err := buildRoutingPlan(cli, sourceInstances, dependencyInstance, targetInstance, routePercentage,
+		enableUserBasedSessionAwareness, matchRules, mirrorPercentage)
+	if err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("error writing the routing plan, %v", err)
+	}
+	fmt.Fprint(cli.Out(), string(content))
+	if blocking := plan.BlockingFindings(); blocking > 0 {
+		return fmt.Errorf("routing plan has %d blocking finding(s)", blocking)
+	}
+	return nil
+}
+
+// buildRoutingPlan checks the API compatibility of the dependency and the target instances for each source instance,
+// and builds the resources of the source instances which would be applied when routing traffic
+func buildRoutingPlan(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string,
+	percentage int, enableUserBasedSessionAwareness bool, matchRules []routing.MatchRule,
+	mirrorPercentage int) (*routing.Plan, error) {
+	routes, err := routing.GetRoutes(cli, sourceInstances, dependencyInstance, targetInstance)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("cell/composite instance %s not found among dependencies of source instance(s)",
+			dependencyInstance)
+	}
+	planDir, err := ioutil.TempDir("", "cellery-routing-plan")
+	if err != nil {
+		return nil, fmt.Errorf("error creating a temporary directory for the routing plan, %v", err)
+	}
+	defer os.RemoveAll(planDir)
+	plan := &routing.Plan{
+		Dependency:       dependencyInstance,
+		Target:           targetInstance,
+		Percentage:       percentage,
+		MirrorPercentage: mirrorPercentage,
+		SessionAwareness: enableUserBasedSessionAwareness,
+		MatchRules:       matchRules,
+	}
+	for _, route := range routes {
+		plannedRoute := routing.PlannedRoute{Source: route.Source()}
+		if err := route.Check(); err != nil {
+			if versionErr, match := err.(errorpkg.CellGwApiVersionMismatchError); match {
+				plannedRoute.Findings = append(plannedRoute.Findings, routing.Finding{
+					Severity:         routing.FindingSeverityWarning,
+					Message:          versionErr.Error(),
+					ApiContext:       versionErr.ApiContext,
+					CurrentVersion:   versionErr.CurrentTargetApiVersion,
+					AvailableVersion: versionErr.NewTargetApiVersion,
+				})
+			} else {
+				plannedRoute.Findings = append(plannedRoute.Findings, routing.Finding{
+					Severity: routing.FindingSeverityError,
+					Message:  err.Error(),
+				})
+				plan.Routes = append(plan.Routes, plannedRoute)
+				continue
+			}
+		}
+		routeFile := filepath.Join(planDir, fmt.Sprintf("%s-routing-artifacts.yaml", route.Source()))
+		if err := route.Build(cli, percentage, enableUserBasedSessionAwareness, matchRules, mirrorPercentage,
+			routeFile); err != nil {
+			return nil, fmt.Errorf("error occurred while building modified rules, %v", err)
+		}
+		if plannedRoute.Resources, err = readPlannedResources(routeFile); err != nil {
+			return nil, err
+		}
+		// the resource versions are recorded to detect the changes made to the resources after planning
+		for _, resource := range plannedRoute.Resources {
+			resourceVersion, err := currentResourceVersion(cli, resource)
+			if err != nil {
+				return nil, err
+			}
+			if metadata, ok := resource["metadata"].(map[string]interface{}); ok && resourceVersion != "" {
+				metadata["resourceVersion"] = resourceVersion
+			}
+		}
+		plan.Routes = append(plan.Routes, plannedRoute)
+	}
+	return plan, nil
+}
+
+// RunRouteTrafficFromFile applies a routing plan printed by RunRouteTrafficPlan, recording a revision of each source
+// instance once the plan is applied. The plan is rejected if any of the planned resources has changed after planning,
+// since applying it would revert those changes.
+func RunRouteTrafficFromFile(cli cli.Cli, planFile string) error {
+	plan, err := routing.ReadPlan(planFile)
+	if err != nil {
+		return err
+	}
+	for _, route := range plan.Routes {
+		for _, resource := range route.Resources {
+			if err := checkResourceVersion(cli, resource); err != nil {
+				return fmt.Errorf("routing plan of instance %s is outdated, %v, create a new plan", route.Source,
+					err)
+			}
+		}
+	}
+	artifactFile := fmt.Sprintf("./%s-routing-artifacts.yaml", plan.Dependency)
+	defer os.Remove(artifactFile)
+	cause := fmt.Sprintf("%s from plan %s", getRoutingCause(plan.Dependency, plan.Target, plan.Percentage,
+		plan.MatchRules, plan.MirrorPercentage), planFile)
+	var snapshots []instanceSnapshot
+	for _, route := range plan.Routes {
+		routeFile := fmt.Sprintf("./%s-%s-routing-artifacts.yaml", plan.Dependency, route.Source)
+		if err := writePlannedResources(route.Resources, routeFile); err != nil {
+			return err
+		}
+		snapshot, err := snapshotRevision(cli, route.Source, routeFile)
+		if err == nil {
+			snapshots = append(snapshots, instanceSnapshot{instance: route.Source, snapshot: snapshot})
+			err = appendFile(routeFile, artifactFile)
+		}
+		os.Remove(routeFile)
+		if err != nil {
+			return err
+		}
+	}
+	if err := cli.ExecuteTask("Applying routing plan", "Failed to apply routing plan", "", func() error {
+		if err := cli.KubeCli().ApplyFile(artifactFile); err != nil {
+			return fmt.Errorf("error occurred while applying routing plan, %v", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := recordRevisions(cli, cause, snapshots); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully applied the routing plan from instance %s to instance %s",
+		plan.Dependency, plan.Target))
+	return nil
+}
+
+// currentResourceVersion returns the resource version of a planned resource in the cluster, which is empty if the
+// resource does not exist yet
+func currentResourceVersion(cli cli.Cli, resource map[string]interface{}) (string, error) {
+	kind, name := plannedResourceName(resource)
+	out, err := cli.KubeCli().GetInstanceBytes(kind, name)
+	if err != nil {
+		if _, notFound := err.(errorpkg.NotFoundError); notFound || strings.Contains(err.Error(), "NotFound") {
+			return "", nil
+		}
+		return "", fmt.Errorf("error getting %s %s, %v", kind, name, err)
+	}
+	object := struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(out, &object); err != nil {
+		return "", fmt.Errorf("error reading %s %s, %v", kind, name, err)
+	}
+	return object.Metadata.ResourceVersion, nil
+}
+
+// checkResourceVersion checks whether a planned resource is unchanged in the cluster since planning
+func checkResourceVersion(cli cli.Cli, resource map[string]interface{}) error {
+	kind, name := plannedResourceName(resource)
+	plannedVersion := ""
+	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+		plannedVersion, _ = metadata["resourceVersion"].(string)
+	}
+	resourceVersion, err := currentResourceVersion(cli, resource)
+	if err != nil {
+		return err
+	}
+	if resourceVersion != plannedVersion {
+		if plannedVersion == "" {
+			return fmt.Errorf("%s %s was created after planning", kind, name)
+		}
+		return fmt.Errorf("%s %s was modified after planning", kind, name)
+	}
+	return nil
+}
+
+// plannedResourceName returns the kind (<kind>.<group>) and the name of a planned resource
+func plannedResourceName(resource map[string]interface{}) (string, string) {
+	kind := strings.ToLower(fmt.Sprint(resource["kind"]))
+	if parts := strings.SplitN(fmt.Sprint(resource["apiVersion"]), "/", 2); len(parts) == 2 {
+		kind += "." + parts[0]
+	}
+	name := ""
+	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+		name = fmt.Sprint(metadata["name"])
+	}
+	return kind, name
+}
+
+func readPlannedResources(file string) ([]map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading modified rules, %v", err)
+	}
+	var resources []map[string]interface{}
+	for _, document := range strings.Split(string(content), "---\n") {
+		resource := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(document), &resource); err != nil {
+			return nil, fmt.Errorf("error parsing modified rules, %v", err)
+		}
+		// the documents of the resources which are not modified are empty
+		if len(resource) > 0 {
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+func writePlannedResources(resources []map[string]interface{}, file string) error {
+	var documents []string
+	for _, resource := range resources {
+		document, err := yaml.Marshal(resource)
+		if err != nil {
+			return fmt.Errorf("error writing the resources of the routing plan, %v", err)
+		}
+		documents = append(documents, string(document))
+	}
+	return ioutil.WriteFile(file, []byte(strings.Join(documents, "---\n")+"---\n"), 0644)
+}
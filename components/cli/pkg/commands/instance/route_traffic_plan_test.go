@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+func TestRunRouteTrafficPlan(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, name := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", name+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file, %v", name, err)
+		}
+		cellMap[name] = cell
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": readPetFeSrcVs(t)}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+
+	if err := RunRouteTrafficPlan(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target", 40, false, nil, false); err != nil {
+		t.Fatalf("error in RunRouteTrafficPlan, %v", err)
+	}
+	// planning should not modify the instances
+	if _, err := mockKubeCli.GetInstanceBytes("virtualservice.networking.istio.io", "pet-fe-src--vs"); err == nil {
+		t.Errorf("expected virtual service pet-fe-src--vs not to be applied when planning")
+	}
+	plan := &routing.Plan{}
+	if err := yaml.Unmarshal(mockCli.OutBuffer().Bytes(), plan); err != nil {
+		t.Fatalf("failed to unmarshal the routing plan, %v", err)
+	}
+	if len(plan.Routes) != 1 || plan.Routes[0].Source != "pet-fe-src" || len(plan.Routes[0].Resources) == 0 {
+		t.Fatalf("expected the routing plan to modify the resources of pet-fe-src, received %v", plan.Routes)
+	}
+
+	planFile := filepath.Join(os.TempDir(), "pet-be-plan.yaml")
+	if err := ioutil.WriteFile(planFile, mockCli.OutBuffer().Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write the routing plan, %v", err)
+	}
+	defer os.Remove(planFile)
+	if err := RunRouteTrafficFromFile(mockCli, planFile); err != nil {
+		t.Fatalf("error in RunRouteTrafficFromFile, %v", err)
+	}
+	vsBytes, err := mockKubeCli.GetInstanceBytes("virtualservice.networking.istio.io", "pet-fe-src--vs")
+	if err != nil {
+		t.Fatalf("virtual service pet-fe-src--vs not applied, %v", err)
+	}
+	appliedVs := kubernetes.VirtualService{}
+	if err := json.Unmarshal(vsBytes, &appliedVs); err != nil {
+		t.Fatalf("failed to unmarshal the applied virtual service, %v", err)
+	}
+	wantRoutes := []kubernetes.HTTPRoute{
+		{Destination: kubernetes.Destination{Host: "pet-be-dep--gateway-service"}, Weight: 60},
+		{Destination: kubernetes.Destination{Host: "pet-be-target--gateway-service"}, Weight: 40},
+	}
+	if diff := cmp.Diff(wantRoutes, appliedVs.VsSpec.HTTP[2].Route); diff != "" {
+		t.Errorf("invalid routes (-want, +got)\n%v", diff)
+	}
+}
+
+func TestRunRouteTrafficFromFileWithBlockingFindings(t *testing.T) {
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli()))
+	err := RunRouteTrafficFromFile(mockCli, filepath.Join("testdata", "routing-plans", "blocked-plan.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "has 1 blocking finding(s)") {
+		t.Errorf("expected the routing plan with blocking findings to be rejected, received %v", err)
+	}
+}
+
+func TestRunRouteTrafficFromOutdatedFile(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, name := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", name+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file, %v", name, err)
+		}
+		cellMap[name] = cell
+	}
+	resources := map[string][]byte{
+		"virtualservice.networking.istio.io/pet-fe-src--vs": []byte(
+			`{"metadata":{"name":"pet-fe-src--vs","resourceVersion":"1001"}}`),
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap), test.WithResources(resources),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": readPetFeSrcVs(t)}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	if err := RunRouteTrafficPlan(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target", 40, false, nil,
+		false); err != nil {
+		t.Fatalf("error in RunRouteTrafficPlan, %v", err)
+	}
+	if !strings.Contains(mockCli.OutBuffer().String(), `resourceVersion: "1001"`) {
+		t.Errorf("expected the resource version of the virtual service to be recorded in the plan")
+	}
+	planFile := filepath.Join(os.TempDir(), "pet-be-outdated-plan.yaml")
+	if err := ioutil.WriteFile(planFile, mockCli.OutBuffer().Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write the routing plan, %v", err)
+	}
+	defer os.Remove(planFile)
+
+	// modifying the virtual service after planning
+	resources["virtualservice.networking.istio.io/pet-fe-src--vs"] = []byte(
+		`{"metadata":{"name":"pet-fe-src--vs","resourceVersion":"1002"}}`)
+	err := RunRouteTrafficFromFile(mockCli, planFile)
+	wantError := "routing plan of instance pet-fe-src is outdated, virtualservice.networking.istio.io " +
+		"pet-fe-src--vs was modified after planning"
+	if err == nil || !strings.Contains(err.Error(), wantError) {
+		t.Errorf("expected an error containing %q, received %v", wantError, err)
+	}
+	if len(mockKubeCli.AppliedFiles()) > 0 {
+		t.Errorf("expected the outdated routing plan not to be applied")
+	}
+}
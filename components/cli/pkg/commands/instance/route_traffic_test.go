@@ -24,12 +24,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/ghodss/yaml"
 	"github.com/google/go-cmp/cmp"
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
 )
 
 func TestRunRouteTraffic(t *testing.T) {
@@ -71,7 +74,7 @@ func TestRunRouteTraffic(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunRouteTrafficCommand(mockCli, tst.sourceInstances, tst.dependencyInstance, tst.targetInstance, tst.percentage, false, true)
+			err := RunRouteTrafficCommand(mockCli, tst.sourceInstances, tst.dependencyInstance, tst.targetInstance, tst.percentage, false, nil, false, true)
 			if err != nil {
 				t.Errorf("error in RunRouteTrafficCommand, %v", err)
 			}
@@ -137,8 +140,8 @@ func TestBuildRouteArtifact(t *testing.T) {
 					t.Errorf("failed to remove artifacts file, %v", err)
 				}
 			}()
-			err := buildRouteArtifact(mockCli, tst.sourceInstances, tst.dependencyInstance, tst.targetInstance,
-				tst.percentage, false, true)
+			_, err := buildRouteArtifact(mockCli, tst.sourceInstances, tst.dependencyInstance, tst.targetInstance,
+				tst.percentage, false, nil, 0, true)
 			if err != nil {
 				t.Errorf("error in buildRouteArtifact, %v", err)
 			}
@@ -157,3 +160,428 @@ func TestBuildRouteArtifact(t *testing.T) {
 		})
 	}
 }
+
+// buildPetFeSrcVs returns the virtual service of the pet-fe-src instance modified to route traffic from pet-be-dep to
+// pet-be-target
+func buildPetFeSrcVs(t *testing.T, vs kubernetes.VirtualService, percentage int, matchRules []routing.MatchRule,
+	mirrorPercentage int) kubernetes.VirtualService {
+	artifactFile := "./pet-be-dep-routing-artifacts.yaml"
+	defer os.Remove(artifactFile)
+	if _, err := buildRouteArtifact(newPetFeSrcMockCli(t, vs), []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target",
+		percentage, false,
+		matchRules, mirrorPercentage, true); err != nil {
+		t.Fatalf("error in buildRouteArtifact, %v", err)
+	}
+	artifacts, err := ioutil.ReadFile(artifactFile)
+	if err != nil {
+		t.Fatalf("failed to read route artifact file, %v", err)
+	}
+	modifiedVs := kubernetes.VirtualService{}
+	if err := yaml.Unmarshal([]byte(strings.Split(string(artifacts), "---\n")[0]), &modifiedVs); err != nil {
+		t.Fatalf("failed to unmarshal the modified virtual service, %v", err)
+	}
+	return modifiedVs
+}
+
+// newPetFeSrcMockCli returns a mock cli with the pet-fe-src instance depending on the pet-be-dep instance
+func newPetFeSrcMockCli(t *testing.T, vs kubernetes.VirtualService) *test.MockCli {
+	cellMap := make(map[string][]byte)
+	for _, name := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", name+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file, %v", name, err)
+		}
+		cellMap[name] = cell
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": vs}))
+	return test.NewMockCli(test.SetKubeCli(mockKubeCli))
+}
+
+func readPetFeSrcVs(t *testing.T) kubernetes.VirtualService {
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs virtual service file, %v", err)
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err := json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshal pet-fe-src-vs virtual service, %v", err)
+	}
+	return petFeSrcVs
+}
+
+func TestBuildRouteArtifactWithMatchRules(t *testing.T) {
+	matchRules, err := routing.ReadMatchRules(filepath.Join("testdata", "match-rules", "qa-rules.yaml"))
+	if err != nil {
+		t.Fatalf("error reading match rules, %v", err)
+	}
+	modifiedVs := buildPetFeSrcVs(t, readPetFeSrcVs(t), 10, matchRules, 0)
+	sourceLabels := map[string]string{"mesh.cellery.io.cell": "pet-fe-src", "mesh.cellery.io.component": "true"}
+	authority := kubernetes.Authority{Regex: `^(pet-be-dep)(--gateway-service)(\S*)$`}
+	targetRoute := []kubernetes.HTTPRoute{
+		{Destination: kubernetes.Destination{Host: "pet-be-target--gateway-service"}, Weight: 100},
+	}
+	expectedMatchRules := []kubernetes.HTTP{
+		{
+			Match: []kubernetes.HTTPMatch{
+				{
+					Authority:    authority,
+					SourceLabels: sourceLabels,
+					Headers:      map[string]*kubernetes.StringMatch{"x-team": {Exact: "beta"}},
+					Uri:          &kubernetes.StringMatch{Prefix: "/beta"},
+				},
+			},
+			Route: targetRoute,
+		},
+		{
+			Match: []kubernetes.HTTPMatch{
+				{
+					Authority:    authority,
+					SourceLabels: sourceLabels,
+					Headers: map[string]*kubernetes.StringMatch{
+						"cookie": {Regex: `^(.*;\s*)?user=qa(;.*)?$`},
+					},
+				},
+			},
+			Route: targetRoute,
+		},
+	}
+	if len(modifiedVs.VsSpec.HTTP) != 5 {
+		t.Fatalf("expected 5 http rules, got %d", len(modifiedVs.VsSpec.HTTP))
+	}
+	// the match based rules are added ahead of the percentage based rule
+	if diff := cmp.Diff(expectedMatchRules, modifiedVs.VsSpec.HTTP[2:4]); diff != "" {
+		t.Errorf("invalid match based rules (-want, +got)\n%v", diff)
+	}
+
+	// routing without match rules removes the match based rules added previously
+	modifiedVs = buildPetFeSrcVs(t, modifiedVs, 10, nil, 0)
+	if len(modifiedVs.VsSpec.HTTP) != 3 {
+		t.Errorf("expected the match based rules to be removed, got %d http rules", len(modifiedVs.VsSpec.HTTP))
+	}
+}
+
+func TestBuildRouteArtifactWithMirror(t *testing.T) {
+	modifiedVs := buildPetFeSrcVs(t, readPetFeSrcVs(t), 0, nil, 50)
+	expectedRule := kubernetes.HTTP{
+		Match: []kubernetes.HTTPMatch{
+			{
+				Authority: kubernetes.Authority{Regex: `^(pet-be-dep)(--gateway-service)(\S*)$`},
+				SourceLabels: map[string]string{
+					"mesh.cellery.io.cell":      "pet-fe-src",
+					"mesh.cellery.io.component": "true",
+				},
+			},
+		},
+		Route: []kubernetes.HTTPRoute{
+			{Destination: kubernetes.Destination{Host: "pet-be-dep--gateway-service"}, Weight: 100},
+			{Destination: kubernetes.Destination{Host: "pet-be-target--gateway-service"}},
+		},
+		Mirror:        &kubernetes.Destination{Host: "pet-be-target--gateway-service"},
+		MirrorPercent: 50,
+	}
+	if diff := cmp.Diff(expectedRule, modifiedVs.VsSpec.HTTP[2]); diff != "" {
+		t.Errorf("invalid mirrored rule (-want, +got)\n%v", diff)
+	}
+
+	// routing traffic without mirroring removes the mirror
+	modifiedVs = buildPetFeSrcVs(t, modifiedVs, 20, nil, 0)
+	for _, httpRule := range modifiedVs.VsSpec.HTTP {
+		if httpRule.Mirror != nil || httpRule.MirrorPercent != 0 {
+			t.Errorf("expected the mirror to be removed, got %v %d%%", httpRule.Mirror, httpRule.MirrorPercent)
+		}
+	}
+}
+
+func TestBuildRouteArtifactWithMirrorWhileRouted(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage int
+		matchRules []routing.MatchRule
+	}{
+		{
+			name:       "mirror while the traffic is split",
+			percentage: 50,
+		},
+		{
+			name: "mirror while the matching requests are routed to the target",
+			matchRules: []routing.MatchRule{
+				{Headers: map[string]*kubernetes.StringMatch{"x-beta": {Exact: "true"}}},
+			},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			routedVs := buildPetFeSrcVs(t, readPetFeSrcVs(t), tst.percentage, tst.matchRules, 0)
+			artifactFile := "./pet-be-dep-routing-artifacts.yaml"
+			defer os.Remove(artifactFile)
+			_, err := buildRouteArtifact(newPetFeSrcMockCli(t, routedVs), []string{"pet-fe-src"}, "pet-be-dep",
+				"pet-be-target", 0, false, nil, 50, true)
+			if err == nil {
+				t.Fatalf("expected an error when mirroring routed traffic")
+			}
+			if !strings.Contains(err.Error(), "traffic can only be mirrored while all the traffic is routed to "+
+				"instance pet-be-dep") {
+				t.Errorf("unexpected error, %v", err)
+			}
+		})
+	}
+}
+
+// newGrpcTcpComposite returns a composite modelled on the composite grpc-tcp sample
+func newGrpcTcpComposite(name string, version string, grpcPort int32, dependencies string) kubernetes.Composite {
+	return kubernetes.Composite{
+		Kind:       "Composite",
+		APIVersion: "mesh.cellery.io/v1alpha2",
+		CompositeMetaData: kubernetes.K8SMetaData{
+			Name: name,
+			Annotations: kubernetes.CellAnnotations{
+				Organization: "myorg",
+				Name:         "grpc-tcp",
+				Version:      version,
+				Dependencies: dependencies,
+			},
+		},
+		CompositeSpec: kubernetes.CompositeSpec{
+			ComponentTemplates: []kubernetes.ComponentTemplate{
+				{
+					Metadata: kubernetes.ComponentTemplateMetadata{Name: "mysql"},
+					Spec: kubernetes.ComponentTemplateSpec{
+						Ports: []kubernetes.Port{{Protocol: "TCP", Port: 3306, TargetPort: 3306}},
+					},
+				},
+				{
+					Metadata: kubernetes.ComponentTemplateMetadata{Name: "grpc"},
+					Spec: kubernetes.ComponentTemplateSpec{
+						Ports: []kubernetes.Port{{Protocol: "GRPC", Port: grpcPort, TargetPort: grpcPort}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newGrpcClientVs returns the virtual service of a source instance calling the gRPC API of the given host
+func newGrpcClientVs(source string, host string, port uint32) kubernetes.VirtualService {
+	return kubernetes.VirtualService{
+		Kind:       "VirtualService",
+		APIVersion: "networking.istio.io/v1alpha3",
+		VsMetaData: kubernetes.VsMetaData{Name: source + "--vs"},
+		VsSpec: kubernetes.VsSpec{
+			HTTP: []kubernetes.HTTP{
+				{
+					Route: []kubernetes.HTTPRoute{
+						{Destination: kubernetes.Destination{Host: host, Port: &kubernetes.PortSelector{Number: port}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildGrpcRouteArtifact(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, name := range []string{"grpc-tcp-dep", "grpc-tcp-target"} {
+		cell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", name+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file, %v", name, err)
+		}
+		cellMap[name] = cell
+	}
+	grpcTcpTarget := kubernetes.Cell{}
+	if err := json.Unmarshal(cellMap["grpc-tcp-target"], &grpcTcpTarget); err != nil {
+		t.Fatalf("failed to unmarshal grpc-tcp-target cell, %v", err)
+	}
+	grpcTcpTarget.CellMetaData.Name = "grpc-tcp-target-other-port"
+	grpcTcpTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.GrpcApis[0].Port = 31408
+	cellMap["grpc-tcp-target-other-port"], _ = json.Marshal(grpcTcpTarget)
+
+	cellDependency := `[{"org":"myorg","name":"grpc-tcp","version":"1.0.0","instance":"grpc-tcp-dep","kind":"Cell"}]`
+	compositeDependency := `[{"org":"myorg","name":"grpc-tcp","version":"1.0.0","instance":"grpc-tcp-comp-dep",` +
+		`"kind":"Composite"}]`
+	sourceCells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				Kind:       "Cell",
+				APIVersion: "mesh.cellery.io/v1alpha2",
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:        "grpc-client",
+					Annotations: kubernetes.CellAnnotations{Dependencies: cellDependency},
+				},
+			},
+			{
+				Kind:       "Cell",
+				APIVersion: "mesh.cellery.io/v1alpha2",
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:        "grpc-client-to-comp",
+					Annotations: kubernetes.CellAnnotations{Dependencies: compositeDependency},
+				},
+			},
+		},
+	}
+	composites := kubernetes.Composites{
+		Items: []kubernetes.Composite{
+			newGrpcTcpComposite("grpc-client-comp", "1.0.0", 4406, cellDependency),
+			newGrpcTcpComposite("grpc-client-comp-to-comp", "1.0.0", 4406, compositeDependency),
+			newGrpcTcpComposite("grpc-tcp-comp-dep", "1.0.0", 4406, ""),
+			newGrpcTcpComposite("grpc-tcp-comp-target", "1.0.1", 4406, ""),
+		},
+	}
+	cellGatewayPort := &kubernetes.PortSelector{Number: 31407}
+	compositeGrpcPort := &kubernetes.PortSelector{Number: 4406}
+	grpcClientMatch := kubernetes.HTTPMatch{
+		Authority: kubernetes.Authority{Regex: `^(grpc-tcp-dep)(--gateway-service)(\S*)$`},
+		SourceLabels: map[string]string{
+			"mesh.cellery.io.cell":      "grpc-client",
+			"mesh.cellery.io.component": "true",
+		},
+	}
+	grpcClientVsWithMatch := newGrpcClientVs("grpc-client", "grpc-tcp-dep--gateway-service", 31407)
+	grpcClientVsWithMatch.VsSpec.HTTP[0].Match = []kubernetes.HTTPMatch{grpcClientMatch}
+	tests := []struct {
+		name                    string
+		source                  string
+		dependency              string
+		target                  string
+		vs                      kubernetes.VirtualService
+		percentage              int
+		matchRules              []routing.MatchRule
+		wantHttp                kubernetes.HTTP
+		wantErrorMessagePortion string
+	}{
+		{
+			name:       "route gRPC traffic from a cell to a cell",
+			source:     "grpc-client",
+			dependency: "grpc-tcp-dep",
+			target:     "grpc-tcp-target",
+			vs:         newGrpcClientVs("grpc-client", "grpc-tcp-dep--gateway-service", 31407),
+			percentage: 25,
+			wantHttp: kubernetes.HTTP{
+				Route: []kubernetes.HTTPRoute{
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-dep--gateway-service", Port: cellGatewayPort},
+						Weight: 75},
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-target--gateway-service",
+						Port: cellGatewayPort}, Weight: 25},
+				},
+			},
+		},
+		{
+			name:       "route gRPC traffic from a composite to a cell",
+			source:     "grpc-client-comp",
+			dependency: "grpc-tcp-dep",
+			target:     "grpc-tcp-target",
+			vs:         newGrpcClientVs("grpc-client-comp", "grpc-tcp-dep--gateway-service", 31407),
+			percentage: 25,
+			wantHttp: kubernetes.HTTP{
+				Route: []kubernetes.HTTPRoute{
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-dep--gateway-service", Port: cellGatewayPort},
+						Weight: 75},
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-target--gateway-service",
+						Port: cellGatewayPort}, Weight: 25},
+				},
+			},
+		},
+		{
+			name:       "route gRPC traffic from a cell to a composite",
+			source:     "grpc-client-to-comp",
+			dependency: "grpc-tcp-comp-dep",
+			target:     "grpc-tcp-comp-target",
+			vs:         newGrpcClientVs("grpc-client-to-comp", "grpc-tcp-comp-dep--grpc-service", 4406),
+			percentage: 25,
+			wantHttp: kubernetes.HTTP{
+				Route: []kubernetes.HTTPRoute{
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-comp-dep--grpc-service",
+						Port: compositeGrpcPort}, Weight: 75},
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-comp-target--grpc-service",
+						Port: compositeGrpcPort}, Weight: 25},
+				},
+			},
+		},
+		{
+			name:       "route gRPC traffic from a composite to a composite",
+			source:     "grpc-client-comp-to-comp",
+			dependency: "grpc-tcp-comp-dep",
+			target:     "grpc-tcp-comp-target",
+			vs:         newGrpcClientVs("grpc-client-comp-to-comp", "grpc-tcp-comp-dep--grpc-service", 4406),
+			percentage: 25,
+			wantHttp: kubernetes.HTTP{
+				Route: []kubernetes.HTTPRoute{
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-comp-dep--grpc-service",
+						Port: compositeGrpcPort}, Weight: 75},
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-comp-target--grpc-service",
+						Port: compositeGrpcPort}, Weight: 25},
+				},
+			},
+		},
+		{
+			name:       "route a gRPC method to a cell",
+			source:     "grpc-client",
+			dependency: "grpc-tcp-dep",
+			target:     "grpc-tcp-target",
+			vs:         grpcClientVsWithMatch,
+			matchRules: []routing.MatchRule{
+				{Grpc: &routing.GrpcMethod{Service: "helloworld.Greeter", Method: "SayHello"}},
+			},
+			wantHttp: kubernetes.HTTP{
+				Match: []kubernetes.HTTPMatch{
+					{
+						Authority:    grpcClientMatch.Authority,
+						SourceLabels: grpcClientMatch.SourceLabels,
+						Uri:          &kubernetes.StringMatch{Exact: "/helloworld.Greeter/SayHello"},
+					},
+				},
+				Route: []kubernetes.HTTPRoute{
+					{Destination: kubernetes.Destination{Host: "grpc-tcp-target--gateway-service",
+						Port: cellGatewayPort}, Weight: 100},
+				},
+			},
+		},
+		{
+			name:                    "route gRPC traffic to a cell exposing the gRPC API on another port",
+			source:                  "grpc-client",
+			dependency:              "grpc-tcp-dep",
+			target:                  "grpc-tcp-target-other-port",
+			vs:                      newGrpcClientVs("grpc-client", "grpc-tcp-dep--gateway-service", 31407),
+			percentage:              25,
+			wantErrorMessagePortion: "gRPC API exposed on port 31407 of instance grpc-tcp-dep not found",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := test.NewMockKubeCli(test.WithCells(sourceCells), test.WithCellsAsBytes(cellMap),
+				test.WithComposites(composites),
+				test.WithVirtualServices(map[string]kubernetes.VirtualService{tst.vs.VsMetaData.Name: tst.vs}))
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			artifactFile := fmt.Sprintf("./%s-routing-artifacts.yaml", tst.dependency)
+			defer os.Remove(artifactFile)
+			_, err := buildRouteArtifact(mockCli, []string{tst.source}, tst.dependency, tst.target, tst.percentage,
+				false, tst.matchRules, 0, true)
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in buildRouteArtifact, %v", err)
+			}
+			artifacts, err := ioutil.ReadFile(artifactFile)
+			if err != nil {
+				t.Fatalf("failed to read route artifact file, %v", err)
+			}
+			var modifiedVs *kubernetes.VirtualService
+			for _, artifact := range strings.Split(string(artifacts), "---\n") {
+				vs := kubernetes.VirtualService{}
+				if err := yaml.Unmarshal([]byte(artifact), &vs); err == nil && vs.Kind == "VirtualService" {
+					modifiedVs = &vs
+				}
+			}
+			if modifiedVs == nil {
+				t.Fatalf("virtual service %s not found in the route artifacts", tst.vs.VsMetaData.Name)
+			}
+			if diff := cmp.Diff(tst.wantHttp, modifiedVs.VsSpec.HTTP[0]); diff != "" {
+				t.Errorf("invalid http rule (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
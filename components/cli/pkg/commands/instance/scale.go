@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattbaird/jsonpatch"
+
+	"cellery.io/cellery/components/cli/cli"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/revision"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunScale sets the replicas of the components of an instance, keyed by the component name. The scaling policies
+// of the autoscaled components are only replaced with the replicas if overridePolicy is set, and the scaling
+// policies which are not overridable are only replaced if force is set.
+func RunScale(cli cli.Cli, instance string, replicas map[string]int, overridePolicy bool, force bool) error {
+	kind, err := getInstanceKind(cli, instance)
+	if err != nil {
+		return err
+	}
+	var originalData, desiredData []byte
+	if err = cli.ExecuteTask("Preparing scaling policies to apply", "Failed to prepare patch",
+		"", func() error {
+			originalData, desiredData, err = createScalePatch(cli, kind, instance, replicas, overridePolicy, force)
+			return err
+		}); err != nil {
+		return fmt.Errorf("failed to create patch, %v", err)
+	}
+	patch, err := jsonpatch.CreatePatch(originalData, desiredData)
+	if err != nil {
+		return fmt.Errorf("failed to create patch, %v", err)
+	}
+	cause := getScaleCause(replicas)
+	if len(patch) == 0 {
+		util.PrintSuccessMessage(fmt.Sprintf("Nothing to apply. Components of instance %q are already scaled to %s",
+			instance, cause))
+		return nil
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("failed to marshall patch, %v", err)
+	}
+	ik := string(kind)
+	snapshot, err := takeSnapshot(cli, instance, []revision.Resource{{Kind: ik, Name: instance}})
+	if err != nil {
+		return err
+	}
+	if err = cli.ExecuteTask("Scaling components", "Failed to scale components",
+		"", func() error {
+			return cli.KubeCli().JsonPatch(ik, instance, string(patchBytes))
+		}); err != nil {
+		return fmt.Errorf("failed to apply patch, %v", err)
+	}
+	if err = recordRevision(cli, instance, "scale "+cause, snapshot); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully scaled components of instance %q to %s", instance, cause))
+	util.PrintWhatsNextMessage("view the status of the instance", "cellery status "+instance)
+	return nil
+}
+
+// createScalePatch returns the scaling policies of the instance before and after setting the replicas
+func createScalePatch(cli cli.Cli, kind kubernetes.InstanceKind, instance string, replicas map[string]int,
+	overridePolicy bool, force bool) ([]byte, []byte, error) {
+	var originalData, desiredData []byte
+	resource, err := getScaleResource(cli, kind, instance)
+	if err != nil {
+		return originalData, desiredData, err
+	}
+	originalData, err = json.Marshal(resource)
+	if err != nil {
+		return originalData, desiredData, fmt.Errorf("failed to marshall original data, %v", err)
+	}
+	policies := make(map[string]*interface{})
+	for i := range resource.Spec.Components {
+		policies[resource.Spec.Components[i].Metadata.Name] = &resource.Spec.Components[i].Spec.ScalingPolicy
+	}
+	if kind == kubernetes.InstanceKindCell {
+		policies[gatewayComponent] = &resource.Spec.Gateway.Spec.ScalingPolicy
+	}
+	if err := validateComponents(instance, getScaleComponents(replicas), getPolicyComponents(policies)); err != nil {
+		return originalData, desiredData, err
+	}
+	for component, componentReplicas := range replicas {
+		policy, err := getScaledPolicy(component, *policies[component], componentReplicas, overridePolicy, force)
+		if err != nil {
+			return originalData, desiredData, err
+		}
+		*policies[component] = policy
+	}
+	desiredData, err = json.Marshal(resource)
+	if err != nil {
+		return originalData, desiredData, fmt.Errorf("failed to marshall desired resource, %v", err)
+	}
+	return originalData, desiredData, nil
+}
+
+// getScaledPolicy returns the scaling policy of a component with the replicas set. The autoscaling policy is
+// removed as the replicas are set manually.
+func getScaledPolicy(component string, policy interface{}, replicas int, overridePolicy bool,
+	force bool) (map[string]interface{}, error) {
+	scalingPolicy, err := parseScalingPolicy(policy)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scaling policy of component %s, %v", component, err)
+	}
+	overridable := true
+	if scalingPolicy.Overridable != nil {
+		overridable = *scalingPolicy.Overridable
+	} else if scalingPolicy.Hpa != nil && scalingPolicy.Hpa.Overridable != nil {
+		overridable = *scalingPolicy.Hpa.Overridable
+	}
+	if !overridable && !force {
+		return nil, fmt.Errorf("scaling policy of component %s is not overridable, use --force to override it",
+			component)
+	}
+	if (scalingPolicy.Hpa != nil || scalingPolicy.Kpa != nil) && !overridePolicy {
+		autoscaling, _ := getAutoscaling(policy)
+		return nil, fmt.Errorf("component %s is autoscaled by a %s policy, use --override-policy to replace it "+
+			"with %d replicas", component, autoscaling.Policy, replicas)
+	}
+	scaledPolicy := make(map[string]interface{})
+	if policyMap, ok := policy.(map[string]interface{}); ok {
+		for key, value := range policyMap {
+			scaledPolicy[key] = value
+		}
+	}
+	delete(scaledPolicy, "hpa")
+	delete(scaledPolicy, "kpa")
+	scaledPolicy["replicas"] = replicas
+	return scaledPolicy, nil
+}
+
+func getScaleResource(cli cli.Cli, kind kubernetes.InstanceKind, instance string) (*kubernetes.ScaleResource, error) {
+	instanceData, err := cli.KubeCli().GetInstanceBytes(string(kind), instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance bytes of instance %s, %v", instance, err)
+	}
+	resource := &kubernetes.ScaleResource{}
+	if err = json.Unmarshal(instanceData, resource); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall instance data of instance %s, %v", instance, err)
+	}
+	return resource, nil
+}
+
+// getInstanceKind returns whether an instance is a cell or a composite
+func getInstanceKind(cli cli.Cli, instance string) (kubernetes.InstanceKind, error) {
+	_, err := cli.KubeCli().GetCell(instance)
+	if err == nil {
+		return kubernetes.InstanceKindCell, nil
+	}
+	if notFound, _ := errorpkg.IsCellInstanceNotFoundError(instance, err); !notFound {
+		return "", fmt.Errorf("error checking if cell exists, %v", err)
+	}
+	if _, err := cli.KubeCli().GetComposite(instance); err != nil {
+		if notFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instance, err); notFound {
+			return "", fmt.Errorf("instance %s does not exist", instance)
+		}
+		return "", fmt.Errorf("error checking if composite exists, %v", err)
+	}
+	return kubernetes.InstanceKindComposite, nil
+}
+
+// validateComponents checks whether the components are available in the instance
+func validateComponents(instance string, components []string, available []string) error {
+	for _, component := range components {
+		if !util.ContainsInStringArray(available, component) {
+			return fmt.Errorf("component %s not found in instance %s, available components: %s", component,
+				instance, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
+
+func getScaleComponents(replicas map[string]int) []string {
+	var components []string
+	for component := range replicas {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	return components
+}
+
+func getPolicyComponents(policies map[string]*interface{}) []string {
+	var components []string
+	for component := range policies {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// getScaleCause returns the replicas in the form <component>=<replicas>, sorted by the component
+func getScaleCause(replicas map[string]int) string {
+	var cause []string
+	for _, component := range getScaleComponents(replicas) {
+		cause = append(cause, fmt.Sprintf("%s=%d", component, replicas[component]))
+	}
+	return strings.Join(cause, ", ")
+}
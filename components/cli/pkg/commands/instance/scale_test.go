@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/revision"
+)
+
+func newScaleMockKubeCli(t *testing.T) *test.MockKubeCli {
+	petBeAutoCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-auto.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock cell file, %v", err)
+	}
+	return test.NewMockKubeCli(
+		test.WithCellsAsBytes(map[string][]byte{"pet-be-auto": petBeAutoCell}),
+		test.WithComposites(kubernetes.Composites{
+			Items: []kubernetes.Composite{
+				{CompositeMetaData: kubernetes.K8SMetaData{Name: "stock"}},
+				{CompositeMetaData: kubernetes.K8SMetaData{Name: "orders"}},
+			},
+		}),
+		test.WithResources(map[string][]byte{
+			"composites.mesh.cellery.io/stock": []byte(`{"spec":{"components":[{"metadata":{"name":"stock"},` +
+				`"spec":{"scalingPolicy":{"overridable":false,"replicas":1}}}]}}`),
+			"composites.mesh.cellery.io/orders": []byte(`{"spec":{"components":[{"metadata":{"name":"api"},` +
+				`"spec":{"scalingPolicy":{"replicas":1}}},{"metadata":{"name":"processor"},` +
+				`"spec":{"scalingPolicy":{"kpa":{"minReplicas":0,"maxReplicas":5,"concurrency":10}}}}]}}`),
+		}))
+}
+
+func TestRunScale(t *testing.T) {
+	tests := []struct {
+		name                    string
+		instance                string
+		replicas                map[string]int
+		overridePolicy          bool
+		force                   bool
+		wantPatch               []map[string]interface{}
+		wantErrorMessagePortion string
+	}{
+		{
+			name:     "scale component without a scaling policy",
+			instance: "pet-be-auto",
+			replicas: map[string]int{"catalog": 3},
+			wantPatch: []map[string]interface{}{
+				{
+					"op":    "add",
+					"path":  "/spec/components/1/spec/scalingPolicy",
+					"value": map[string]interface{}{"replicas": 3.0},
+				},
+			},
+		},
+		{
+			name:     "scale gateway",
+			instance: "pet-be-auto",
+			replicas: map[string]int{"gateway": 2},
+			wantPatch: []map[string]interface{}{
+				{"op": "replace", "path": "/spec/gateway/spec/scalingPolicy/replicas", "value": 2.0},
+			},
+		},
+		{
+			name:                    "scale autoscaled component",
+			instance:                "pet-be-auto",
+			replicas:                map[string]int{"controller": 4},
+			wantErrorMessagePortion: "component controller is autoscaled by a hpa policy, use --override-policy",
+		},
+		{
+			name:           "scale autoscaled component overriding the policy",
+			instance:       "pet-be-auto",
+			replicas:       map[string]int{"controller": 4},
+			overridePolicy: true,
+			wantPatch: []map[string]interface{}{
+				{"op": "remove", "path": "/spec/components/0/spec/scalingPolicy/hpa"},
+				{"op": "remove", "path": "/spec/components/0/spec/scalingPolicy/kpa"},
+				{"op": "replace", "path": "/spec/components/0/spec/scalingPolicy/replicas", "value": 4.0},
+			},
+		},
+		{
+			name:                    "scale component with a policy which is not overridable",
+			instance:                "stock",
+			replicas:                map[string]int{"stock": 2},
+			wantErrorMessagePortion: "scaling policy of component stock is not overridable, use --force",
+		},
+		{
+			name:     "force scale component with a policy which is not overridable",
+			instance: "stock",
+			replicas: map[string]int{"stock": 2},
+			force:    true,
+			wantPatch: []map[string]interface{}{
+				{"op": "replace", "path": "/spec/components/0/spec/scalingPolicy/replicas", "value": 2.0},
+			},
+		},
+		{
+			name:     "scale component to the current replicas",
+			instance: "pet-be-auto",
+			replicas: map[string]int{"gateway": 1},
+		},
+		{
+			name:                    "scale unknown component",
+			instance:                "pet-be-auto",
+			replicas:                map[string]int{"gateway": 1, "payments": 2},
+			wantErrorMessagePortion: "component payments not found in instance pet-be-auto",
+		},
+		{
+			name:                    "scale gateway of composite",
+			instance:                "stock",
+			replicas:                map[string]int{"gateway": 2},
+			wantErrorMessagePortion: "component gateway not found in instance stock, available components: stock",
+		},
+		{
+			name:                    "scale unknown instance",
+			instance:                "hr",
+			replicas:                map[string]int{"hr": 2},
+			wantErrorMessagePortion: "instance hr does not exist",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := newScaleMockKubeCli(t)
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunScale(mockCli, tst.instance, tst.replicas, tst.overridePolicy, tst.force)
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in RunScale, %v", err)
+			}
+			var gotPatch []map[string]interface{}
+			for key, patch := range mockKubeCli.JsonPatches() {
+				if !strings.HasSuffix(key, "/"+tst.instance) {
+					continue
+				}
+				if err := json.Unmarshal([]byte(patch), &gotPatch); err != nil {
+					t.Fatalf("failed to unmarshal the applied patch, %v", err)
+				}
+			}
+			sort.Slice(gotPatch, func(i, j int) bool {
+				return gotPatch[i]["path"].(string) < gotPatch[j]["path"].(string)
+			})
+			if diff := cmp.Diff(tst.wantPatch, gotPatch); diff != "" {
+				t.Errorf("invalid patch (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+type failingPatchKubeCli struct {
+	*test.MockKubeCli
+}
+
+func (failingPatchKubeCli) JsonPatch(kind, instance, jsonPatch string) error {
+	return fmt.Errorf("admission webhook denied the request")
+}
+
+func TestRunScaleWithFailedPatch(t *testing.T) {
+	mockCli := test.NewMockCli(test.SetKubeCli(failingPatchKubeCli{newScaleMockKubeCli(t)}))
+	err := RunScale(mockCli, "pet-be-auto", map[string]int{"catalog": 3}, false, false)
+	if err == nil || !strings.Contains(err.Error(), "admission webhook denied the request") {
+		t.Fatalf("expected the patch to fail, received %v", err)
+	}
+	history, err := revision.History(mockCli, "pet-be-auto")
+	if err != nil {
+		t.Fatalf("error getting history, %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("expected no revision to be recorded for a failed patch, got %d", len(history))
+	}
+}
@@ -20,18 +20,110 @@ package instance
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
-func RunStatus(cli cli.Cli, instance string) error {
+// statusRefreshInterval is the interval at which the status is refreshed in the watch mode
+const statusRefreshInterval = 2 * time.Second
+
+// maxStatusEvents is the number of the most recent events shown in the status
+const maxStatusEvents = 10
+
+const instanceStatusReady = "Ready"
+
+type statusData struct {
+	Instance      string                `json:"instance"`
+	Kind          string                `json:"kind"`
+	Created       string                `json:"created"`
+	Status        string                `json:"status"`
+	GatewayStatus string                `json:"gatewayStatus,omitempty"`
+	Components    []componentStatusData `json:"components"`
+	Events        []eventData           `json:"events"`
+	// EventsError is the error which prevented getting the events, which does not fail the status
+	EventsError string `json:"eventsError,omitempty"`
+}
+
+type componentStatusData struct {
+	Name       string                `json:"name"`
+	Status     string                `json:"status"`
+	Ready      string                `json:"ready"`
+	Restarts   int                   `json:"restarts"`
+	Message    string                `json:"message,omitempty"`
+	Pod        string                `json:"pod"`
+	Containers []containerStatusData `json:"containers"`
+}
+
+type containerStatusData struct {
+	Name     string `json:"name"`
+	Ready    bool   `json:"ready"`
+	Restarts int    `json:"restarts"`
+	State    string `json:"state"`
+	Reason   string `json:"reason,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type eventData struct {
+	LastSeen  string `json:"lastSeen"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Component string `json:"component"`
+	Object    string `json:"object"`
+	Count     int    `json:"count"`
+	Message   string `json:"message"`
+}
+
+// RunStatus prints the status of an instance, along with the status of the containers of its components and the
+// recent Kubernetes events of the instance. The status is refreshed until the instance is ready if watch is set.
+func RunStatus(cli cli.Cli, instance string, outputFormat string, watch bool) error {
+	if !watch {
+		_, err := printStatus(cli, cli.Out(), instance, outputFormat)
+		return err
+	}
+	return runWatch(cli, statusRefreshInterval, func(out io.Writer) (bool, error) {
+		status, err := printStatus(cli, out, instance, outputFormat)
+		return status == instanceStatusReady, err
+	})
+}
+
+// printStatus prints the status of an instance and returns the status of the instance
+func printStatus(cli cli.Cli, out io.Writer, instance string, outputFormat string) (string, error) {
+	instanceStatus, err := getInstanceStatus(cli, instance)
+	if err != nil {
+		return "", err
+	}
+	if !output.IsTable(outputFormat) {
+		return instanceStatus.Status, output.Print(out, outputFormat, instanceStatus)
+	}
+	displayStatusSummaryTable(out, instanceStatus)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "  -COMPONENTS-")
+	fmt.Fprintln(out)
+	displayStatusDetailedTable(out, instanceStatus.Components, output.IsWide(outputFormat))
+	if len(instanceStatus.Events) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "  -EVENTS-")
+		fmt.Fprintln(out)
+		displayEventsTable(out, instanceStatus.Events, output.IsWide(outputFormat))
+	}
+	if instanceStatus.EventsError != "" {
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "%s %s\n", util.YellowBold("\U000026A0"), instanceStatus.EventsError)
+	}
+	return instanceStatus.Status, nil
+}
+
+func getInstanceStatus(cli cli.Cli, instance string) (statusData, error) {
 	creationTime, status, err := getCellSummary(cli, instance)
 	var canBeComposite bool
 	if err != nil {
@@ -39,50 +131,49 @@ func RunStatus(cli cli.Cli, instance string) error {
 			// could be a composite
 			canBeComposite = true
 		} else {
-			return fmt.Errorf("error checking if cell exists, %v", err)
+			return statusData{}, fmt.Errorf("error checking if cell exists, %v", err)
 		}
 	}
+	var pods kubernetes.Pods
+	instanceStatus := statusData{Instance: instance, Created: creationTime, Status: status}
 	if canBeComposite {
 		creationTime, status, err = getCompositeSummary(cli, instance)
 		if err != nil {
 			if compositeNotFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instance, err); compositeNotFound {
 				// given instance name does not correspond either to a cell or a composite
-				return fmt.Errorf("instance %s does not exist", instance)
+				return statusData{}, fmt.Errorf("instance %s does not exist", instance)
 			} else {
-				return fmt.Errorf("error checking if composite exists, %v", err)
+				return statusData{}, fmt.Errorf("error checking if composite exists, %v", err)
 			}
 		}
-		return displayCompositeStatus(cli, instance, creationTime, status)
+		instanceStatus = statusData{Instance: instance, Kind: "Composite", Created: creationTime, Status: status}
+		pods, err = cli.KubeCli().GetPodsForComposite(instance)
+		if err != nil {
+			return statusData{}, fmt.Errorf("error getting pods information of composite %s, %v", instance, err)
+		}
 	} else {
-		return displayCellStatus(cli, instance, creationTime, status)
-	}
-	return nil
-}
-
-func displayCellStatus(cli cli.Cli, instance, cellCreationTime, cellStatus string) error {
-	displayStatusSummaryTable(cellCreationTime, cellStatus)
-	fmt.Println()
-	fmt.Println("  -COMPONENTS-")
-	fmt.Println()
-	pods, err := cli.KubeCli().GetPodsForCell(instance)
-	if err != nil {
-		return fmt.Errorf("error getting pods information of cell %s, %v", instance, err)
+		instanceStatus.Kind = "Cell"
+		cell, err := cli.KubeCli().GetCell(instance)
+		if err != nil {
+			return statusData{}, fmt.Errorf("error getting cell %s, %v", instance, err)
+		}
+		instanceStatus.GatewayStatus = cell.CellStatus.GatewayStatus
+		pods, err = cli.KubeCli().GetPodsForCell(instance)
+		if err != nil {
+			return statusData{}, fmt.Errorf("error getting pods information of cell %s, %v", instance, err)
+		}
 	}
-	displayStatusDetailedTable(pods, instance)
-	return nil
-}
-
-func displayCompositeStatus(cli cli.Cli, instance, cellCreationTime, cellStatus string) error {
-	displayStatusSummaryTable(cellCreationTime, cellStatus)
-	fmt.Println()
-	fmt.Println("  -COMPONENTS-")
-	fmt.Println()
-	pods, err := cli.KubeCli().GetPodsForComposite(instance)
+	instanceStatus.Components = getComponentStatuses(pods, instance)
+	instanceStatus.Events = []eventData{}
+	// The events are listed once and filtered locally, since a field selector cannot match the pods, the deployments
+	// and the replica sets of the instance at once
+	events, err := cli.KubeCli().GetEvents("", "")
 	if err != nil {
-		return fmt.Errorf("error getting pods information of composite %s, %v", instance, err)
+		instanceStatus.EventsError = fmt.Sprintf("unable to get the events of instance %s, %v", instance, err)
+		return instanceStatus, nil
 	}
-	displayStatusDetailedTable(pods, instance)
-	return nil
+	instanceStatus.Events = getInstanceEvents(events, pods, instanceStatus.Kind, instance)
+	return instanceStatus, nil
 }
 
 func getCellSummary(cli cli.Cli, cellName string) (cellCreationTime, cellStatus string, err error) {
@@ -113,57 +204,187 @@ func getCompositeSummary(cli cli.Cli, compName string) (compCreationTime, compSt
 	return duration, compStatus, err
 }
 
-func displayStatusSummaryTable(cellCreationTime, cellStatus string) error {
-	tableData := []string{cellCreationTime, cellStatus}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"CREATED", "STATUS"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold})
-	table.SetColumnColor(
-		tablewriter.Colors{},
-		tablewriter.Colors{})
-
-	table.Append(tableData)
-	table.Render()
-	return nil
+func displayStatusSummaryTable(out io.Writer, instanceStatus statusData) {
+	if instanceStatus.Kind == "Cell" {
+		table := output.NewTable("CREATED", "STATUS", "GATEWAY")
+		table.Append(instanceStatus.Created, instanceStatus.Status, valueOrDefault(instanceStatus.GatewayStatus, "-"))
+		table.Render(out, false)
+		return
+	}
+	table := output.NewTable("CREATED", "STATUS")
+	table.Append(instanceStatus.Created, instanceStatus.Status)
+	table.Render(out, false)
 }
 
-func displayStatusDetailedTable(pods kubernetes.Pods, cellName string) error {
-	var tableData [][]string
+func getComponentStatuses(pods kubernetes.Pods, cellName string) []componentStatusData {
+	components := []componentStatusData{}
 	for _, pod := range pods.Items {
-		name := strings.Replace(strings.Split(pod.MetaData.Name, "-deployment-")[0], cellName+"--", "", -1)
-		state := pod.PodStatus.Phase
-		if strings.EqualFold(state, "Running") {
-			// Get the time since pod's last transition to running state
-			duration := util.GetDuration(util.ConvertStringToTime(pod.PodStatus.Conditions[1].LastTransitionTime))
-			state = "Up for " + duration
+		component := componentStatusData{Name: getComponentName(pod.MetaData.Name, cellName), Pod: pod.MetaData.Name,
+			Containers: []containerStatusData{}}
+		var readyContainers int
+		for _, containerStatus := range pod.PodStatus.ContainerStatuses {
+			container := getContainerStatus(containerStatus)
+			if container.Ready {
+				readyContainers++
+			}
+			component.Restarts += container.Restarts
+			component.Containers = append(component.Containers, container)
+		}
+		containerCount := len(pod.PodStatus.ContainerStatuses)
+		if containerCount == 0 {
+			containerCount = len(pod.PodSpec.Containers)
 		}
-		status := []string{name, state}
-		tableData = append(tableData, status)
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"NAME", "STATUS"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold})
-	table.SetColumnColor(
-		tablewriter.Colors{tablewriter.FgHiBlueColor},
-		tablewriter.Colors{})
-
-	table.AppendBulk(tableData)
-	table.Render()
+		component.Ready = fmt.Sprintf("%d/%d", readyContainers, containerCount)
+		component.Status, component.Message = getPodStatus(pod, component.Containers)
+		components = append(components, component)
+	}
+	return components
+}
+
+// getContainerStatus returns the state of a container, along with the reason if the container is not running
+func getContainerStatus(containerStatus kubernetes.ContainerStatus) containerStatusData {
+	container := containerStatusData{Name: containerStatus.Name, Ready: containerStatus.Ready,
+		Restarts: containerStatus.RestartCount}
+	state := containerStatus.State
+	switch {
+	case state.Waiting != nil:
+		container.State = "Waiting"
+		container.Reason = state.Waiting.Reason
+		container.Message = state.Waiting.Message
+		if lastState := containerStatus.LastState.Terminated; lastState != nil && container.Message == "" {
+			// the reason for the container to be restarted, such as OOMKilled
+			container.Message = fmt.Sprintf("last terminated with %s (exit code %d)",
+				valueOrDefault(lastState.Reason, "Error"), lastState.ExitCode)
+		}
+	case state.Terminated != nil:
+		container.State = "Terminated"
+		container.Reason = valueOrDefault(state.Terminated.Reason, "Error")
+		container.Message = fmt.Sprintf("exit code %d", state.Terminated.ExitCode)
+		if state.Terminated.Message != "" {
+			container.Message += ", " + state.Terminated.Message
+		}
+	case state.Running != nil:
+		container.State = "Running"
+	}
+	return container
+}
+
+// getPodStatus returns the status of a pod, which is the reason a container is failing if any or the reason the
+// pod cannot be scheduled, along with a message explaining the status
+func getPodStatus(pod kubernetes.Pod, containers []containerStatusData) (string, string) {
+	for _, container := range containers {
+		if !container.Ready && container.Reason != "" {
+			message := container.Message
+			if len(containers) > 1 {
+				message = strings.TrimSuffix(fmt.Sprintf("container %s: %s", container.Name, message), ": ")
+			}
+			return container.Reason, message
+		}
+	}
+	if condition := getPodCondition(pod, "PodScheduled"); condition != nil && condition.Status == "False" {
+		return valueOrDefault(condition.Reason, "Unschedulable"), condition.Message
+	}
+	if !strings.EqualFold(pod.PodStatus.Phase, "Running") {
+		return pod.PodStatus.Phase, ""
+	}
+	condition := getPodCondition(pod, "Ready")
+	if condition != nil && condition.Status != "True" {
+		return "Not Ready", condition.Message
+	}
+	// Get the time since pod's last transition to running state
+	since := pod.PodStatus.StartTime
+	if condition != nil {
+		since = condition.LastTransitionTime
+	}
+	if runningTime, err := time.Parse(time.RFC3339, since); err == nil {
+		return "Up for " + util.GetDuration(runningTime), ""
+	}
+	return pod.PodStatus.Phase, ""
+}
 
+func getPodCondition(pod kubernetes.Pod, conditionType string) *kubernetes.PodCondition {
+	for i, condition := range pod.PodStatus.Conditions {
+		if condition.Type == conditionType {
+			return &pod.PodStatus.Conditions[i]
+		}
+	}
 	return nil
 }
+
+// getInstanceEvents returns the most recent events of an instance, its pods and the deployments and replica sets of
+// its components, in the order they occurred
+func getInstanceEvents(events kubernetes.Events, pods kubernetes.Pods, kind string, instance string) []eventData {
+	instancePods := make(map[string]bool)
+	for _, pod := range pods.Items {
+		instancePods[pod.MetaData.Name] = true
+	}
+	instanceEvents := []eventData{}
+	for _, event := range events.Items {
+		object := event.InvolvedObject
+		var component string
+		if object.Kind == "Pod" && instancePods[object.Name] {
+			component = getComponentName(object.Name, instance)
+		} else if object.Kind == "ReplicaSet" && strings.HasPrefix(object.Name, instance+"--") {
+			// the replica sets are named <instance>--<component>-deployment-<hash>
+			component = getComponentName(object.Name, instance)
+		} else if object.Kind == "Deployment" && strings.HasPrefix(object.Name, instance+"--") {
+			component = strings.TrimPrefix(strings.TrimSuffix(object.Name, "-deployment"), instance+"--")
+		} else if object.Kind == kind && object.Name == instance {
+			component = instance
+		} else {
+			continue
+		}
+		instanceEvents = append(instanceEvents, eventData{
+			LastSeen:  valueOrDefault(event.LastTimestamp, event.FirstTimestamp),
+			Type:      event.Type,
+			Reason:    event.Reason,
+			Component: component,
+			Object:    strings.ToLower(object.Kind) + "/" + object.Name,
+			Count:     event.Count,
+			Message:   event.Message,
+		})
+	}
+	sort.SliceStable(instanceEvents, func(i, j int) bool {
+		return instanceEvents[i].LastSeen < instanceEvents[j].LastSeen
+	})
+	if len(instanceEvents) > maxStatusEvents {
+		instanceEvents = instanceEvents[len(instanceEvents)-maxStatusEvents:]
+	}
+	return instanceEvents
+}
+
+// getComponentName returns the name of the component running in a pod of an instance, using the
+// <instance>--<component>-deployment- naming convention of the pods
+func getComponentName(pod string, instance string) string {
+	return strings.Replace(strings.Split(pod, "-deployment-")[0], instance+"--", "", -1)
+}
+
+func displayStatusDetailedTable(out io.Writer, components []componentStatusData, wide bool) {
+	table := output.NewTable("NAME", "STATUS", "READY", "RESTARTS").AddWideColumns("POD", "MESSAGE").
+		SetColumnColor(0, tablewriter.Colors{tablewriter.FgHiBlueColor})
+	for _, component := range components {
+		table.Append(component.Name, component.Status, component.Ready, fmt.Sprint(component.Restarts),
+			component.Pod, component.Message)
+	}
+	table.Render(out, wide)
+}
+
+func displayEventsTable(out io.Writer, events []eventData, wide bool) {
+	table := output.NewTable("LAST SEEN", "TYPE", "REASON", "COMPONENT", "MESSAGE").AddWideColumns("OBJECT", "COUNT")
+	for _, event := range events {
+		lastSeen := "-"
+		if eventTime, err := time.Parse(time.RFC3339, event.LastSeen); err == nil {
+			lastSeen = util.GetDuration(eventTime) + " ago"
+		}
+		table.Append(lastSeen, event.Type, event.Reason, event.Component, event.Message, event.Object,
+			fmt.Sprint(event.Count))
+	}
+	table.Render(out, wide)
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -19,12 +19,16 @@
 package instance
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func TestRunStatus(t *testing.T) {
@@ -77,7 +81,7 @@ func TestRunStatus(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunStatus(mockCli, tst.instance)
+			err := RunStatus(mockCli, tst.instance, "", false)
 			if err != nil {
 				t.Errorf("error in RunStatus, %v", err)
 			}
@@ -202,3 +206,351 @@ func TestGetCompositeSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestGetComponentStatuses(t *testing.T) {
+	readyTime := time.Now().Add(-2 * time.Hour).UTC()
+	tests := []struct {
+		name string
+		pod  kubernetes.Pod
+		want componentStatusData
+	}{
+		{
+			name: "running pod",
+			pod: kubernetes.Pod{
+				MetaData: kubernetes.PodMetaData{Name: "employee--salary-deployment-5c7b9d8f6-mz7rt"},
+				PodStatus: kubernetes.PodStatus{
+					Phase: "Running",
+					Conditions: []kubernetes.PodCondition{
+						{Type: "Initialized", Status: "True", LastTransitionTime: "2019-10-18T11:40:36Z"},
+						{Type: "Ready", Status: "True", LastTransitionTime: readyTime.Format(time.RFC3339)},
+					},
+					ContainerStatuses: []kubernetes.ContainerStatus{
+						{
+							Name:         "salary",
+							Ready:        true,
+							RestartCount: 1,
+							State:        kubernetes.ContainerState{Running: &kubernetes.ContainerStateRunning{}},
+						},
+					},
+				},
+			},
+			want: componentStatusData{
+				Name:     "salary",
+				Status:   "Up for " + util.GetDuration(readyTime),
+				Ready:    "1/1",
+				Restarts: 1,
+				Pod:      "employee--salary-deployment-5c7b9d8f6-mz7rt",
+				Containers: []containerStatusData{
+					{Name: "salary", Ready: true, Restarts: 1, State: "Running"},
+				},
+			},
+		},
+		{
+			name: "running pod without conditions",
+			pod: kubernetes.Pod{
+				MetaData:  kubernetes.PodMetaData{Name: "employee--salary-deployment-5c7b9d8f6-mz7rt"},
+				PodStatus: kubernetes.PodStatus{Phase: "Running"},
+				PodSpec: kubernetes.PodTemplate{
+					Containers: []kubernetes.ContainerTemplate{{Name: "salary"}},
+				},
+			},
+			want: componentStatusData{
+				Name:       "salary",
+				Status:     "Running",
+				Ready:      "0/1",
+				Pod:        "employee--salary-deployment-5c7b9d8f6-mz7rt",
+				Containers: []containerStatusData{},
+			},
+		},
+		{
+			name: "crash looping container",
+			pod: kubernetes.Pod{
+				MetaData: kubernetes.PodMetaData{Name: "employee--salary-deployment-5c7b9d8f6-mz7rt"},
+				PodStatus: kubernetes.PodStatus{
+					Phase: "Running",
+					ContainerStatuses: []kubernetes.ContainerStatus{
+						{
+							Name:         "salary",
+							RestartCount: 5,
+							State: kubernetes.ContainerState{
+								Waiting: &kubernetes.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+							},
+							LastState: kubernetes.ContainerState{
+								Terminated: &kubernetes.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+							},
+						},
+						{
+							Name:  "istio-proxy",
+							Ready: true,
+							State: kubernetes.ContainerState{Running: &kubernetes.ContainerStateRunning{}},
+						},
+					},
+				},
+			},
+			want: componentStatusData{
+				Name:     "salary",
+				Status:   "CrashLoopBackOff",
+				Ready:    "1/2",
+				Restarts: 5,
+				Message:  "container salary: last terminated with OOMKilled (exit code 137)",
+				Pod:      "employee--salary-deployment-5c7b9d8f6-mz7rt",
+				Containers: []containerStatusData{
+					{
+						Name:     "salary",
+						Restarts: 5,
+						State:    "Waiting",
+						Reason:   "CrashLoopBackOff",
+						Message:  "last terminated with OOMKilled (exit code 137)",
+					},
+					{Name: "istio-proxy", Ready: true, State: "Running"},
+				},
+			},
+		},
+		{
+			name: "image pull failure",
+			pod: kubernetes.Pod{
+				MetaData: kubernetes.PodMetaData{Name: "employee--job-deployment-6b8c7d9f5-p4l9s"},
+				PodStatus: kubernetes.PodStatus{
+					Phase: "Pending",
+					ContainerStatuses: []kubernetes.ContainerStatus{
+						{
+							Name: "job",
+							State: kubernetes.ContainerState{
+								Waiting: &kubernetes.ContainerStateWaiting{Reason: "ImagePullBackOff",
+									Message: "Back-off pulling image \"myorg/job:1.0.0\""},
+							},
+						},
+					},
+				},
+			},
+			want: componentStatusData{
+				Name:    "job",
+				Status:  "ImagePullBackOff",
+				Ready:   "0/1",
+				Message: "Back-off pulling image \"myorg/job:1.0.0\"",
+				Pod:     "employee--job-deployment-6b8c7d9f5-p4l9s",
+				Containers: []containerStatusData{
+					{
+						Name:    "job",
+						State:   "Waiting",
+						Reason:  "ImagePullBackOff",
+						Message: "Back-off pulling image \"myorg/job:1.0.0\"",
+					},
+				},
+			},
+		},
+		{
+			name: "terminated container",
+			pod: kubernetes.Pod{
+				MetaData: kubernetes.PodMetaData{Name: "employee--job-deployment-6b8c7d9f5-p4l9s"},
+				PodStatus: kubernetes.PodStatus{
+					Phase: "Failed",
+					ContainerStatuses: []kubernetes.ContainerStatus{
+						{
+							Name: "job",
+							State: kubernetes.ContainerState{
+								Terminated: &kubernetes.ContainerStateTerminated{ExitCode: 1},
+							},
+						},
+					},
+				},
+			},
+			want: componentStatusData{
+				Name:    "job",
+				Status:  "Error",
+				Ready:   "0/1",
+				Message: "exit code 1",
+				Pod:     "employee--job-deployment-6b8c7d9f5-p4l9s",
+				Containers: []containerStatusData{
+					{Name: "job", State: "Terminated", Reason: "Error", Message: "exit code 1"},
+				},
+			},
+		},
+		{
+			name: "unschedulable pod",
+			pod: kubernetes.Pod{
+				MetaData: kubernetes.PodMetaData{Name: "employee--job-deployment-6b8c7d9f5-p4l9s"},
+				PodSpec: kubernetes.PodTemplate{
+					Containers: []kubernetes.ContainerTemplate{{Name: "job"}, {Name: "istio-proxy"}},
+				},
+				PodStatus: kubernetes.PodStatus{
+					Phase: "Pending",
+					Conditions: []kubernetes.PodCondition{
+						{
+							Type:    "PodScheduled",
+							Status:  "False",
+							Reason:  "Unschedulable",
+							Message: "0/1 nodes are available: 1 Insufficient cpu.",
+						},
+					},
+				},
+			},
+			want: componentStatusData{
+				Name:       "job",
+				Status:     "Unschedulable",
+				Ready:      "0/2",
+				Message:    "0/1 nodes are available: 1 Insufficient cpu.",
+				Pod:        "employee--job-deployment-6b8c7d9f5-p4l9s",
+				Containers: []containerStatusData{},
+			},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			got := getComponentStatuses(kubernetes.Pods{Items: []kubernetes.Pod{tst.pod}}, "employee")
+			if diff := cmp.Diff([]componentStatusData{tst.want}, got); diff != "" {
+				t.Errorf("getComponentStatuses: unexpected status (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestGetInstanceEvents(t *testing.T) {
+	pods := kubernetes.Pods{
+		Items: []kubernetes.Pod{
+			{MetaData: kubernetes.PodMetaData{Name: "employee--salary-deployment-5c7b9d8f6-mz7rt"}},
+		},
+	}
+	events := kubernetes.Events{
+		Items: []kubernetes.Event{
+			{
+				InvolvedObject: kubernetes.ObjectReference{Kind: "Pod",
+					Name: "employee--salary-deployment-5c7b9d8f6-mz7rt"},
+				Type:          "Warning",
+				Reason:        "BackOff",
+				Message:       "Back-off restarting failed container",
+				Count:         4,
+				LastTimestamp: "2019-10-18T11:45:36Z",
+			},
+			{
+				InvolvedObject: kubernetes.ObjectReference{Kind: "Pod", Name: "stock--stock-deployment-6f9c8b7d5-q8w2e"},
+				Type:           "Normal",
+				Reason:         "Pulled",
+				LastTimestamp:  "2019-10-18T11:41:36Z",
+			},
+			{
+				InvolvedObject: kubernetes.ObjectReference{Kind: "Cell", Name: "employee"},
+				Type:           "Normal",
+				Reason:         "Created",
+				Message:        "Created cell employee",
+				Count:          1,
+				FirstTimestamp: "2019-10-18T11:40:36Z",
+			},
+			{
+				InvolvedObject: kubernetes.ObjectReference{Kind: "ReplicaSet",
+					Name: "employee--job-deployment-6b8c7d9f5"},
+				Type:          "Warning",
+				Reason:        "FailedCreate",
+				Message:       "exceeded quota: compute-resources",
+				Count:         2,
+				LastTimestamp: "2019-10-18T11:40:46Z",
+			},
+			{
+				InvolvedObject: kubernetes.ObjectReference{Kind: "Deployment", Name: "employee--job-deployment"},
+				Type:           "Normal",
+				Reason:         "ScalingReplicaSet",
+				Count:          1,
+				LastTimestamp:  "2019-10-18T11:40:41Z",
+			},
+			{
+				InvolvedObject: kubernetes.ObjectReference{Kind: "ReplicaSet",
+					Name: "stock--stock-deployment-6f9c8b7d5"},
+				Type:          "Normal",
+				Reason:        "SuccessfulCreate",
+				LastTimestamp: "2019-10-18T11:40:40Z",
+			},
+		},
+	}
+	for i := 0; i < maxStatusEvents; i++ {
+		events.Items = append(events.Items, kubernetes.Event{
+			InvolvedObject: kubernetes.ObjectReference{Kind: "Pod", Name: "employee--salary-deployment-5c7b9d8f6-mz7rt"},
+			Type:           "Normal",
+			Reason:         "Pulled",
+			Count:          1,
+			LastTimestamp:  fmt.Sprintf("2019-10-18T11:42:%02dZ", i),
+		})
+	}
+	got := getInstanceEvents(events, pods, "Cell", "employee")
+	if diff := cmp.Diff(maxStatusEvents, len(got)); diff != "" {
+		t.Fatalf("getInstanceEvents: unexpected number of events (-want, +got)\n%v", diff)
+	}
+	// the oldest events are dropped
+	if diff := cmp.Diff(eventData{LastSeen: "2019-10-18T11:42:01Z", Type: "Normal", Reason: "Pulled",
+		Component: "salary", Object: "pod/employee--salary-deployment-5c7b9d8f6-mz7rt", Count: 1}, got[0]); diff != "" {
+		t.Errorf("getInstanceEvents: unexpected oldest event (-want, +got)\n%v", diff)
+	}
+	if diff := cmp.Diff(eventData{LastSeen: "2019-10-18T11:45:36Z", Type: "Warning", Reason: "BackOff",
+		Component: "salary", Object: "pod/employee--salary-deployment-5c7b9d8f6-mz7rt", Count: 4,
+		Message: "Back-off restarting failed container"}, got[maxStatusEvents-1]); diff != "" {
+		t.Errorf("getInstanceEvents: unexpected latest event (-want, +got)\n%v", diff)
+	}
+	got = getInstanceEvents(kubernetes.Events{Items: events.Items[:6]}, pods, "Cell", "employee")
+	var gotObjects []string
+	for _, event := range got {
+		gotObjects = append(gotObjects, event.Component+" "+event.Object)
+	}
+	if diff := cmp.Diff([]string{"employee cell/employee", "job deployment/employee--job-deployment",
+		"job replicaset/employee--job-deployment-6b8c7d9f5", "salary pod/employee--salary-deployment-5c7b9d8f6-mz7rt"},
+		gotObjects); diff != "" {
+		t.Errorf("getInstanceEvents: unexpected events (-want, +got)\n%v", diff)
+	}
+}
+
+func TestRunStatusWatch(t *testing.T) {
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:              "employee",
+					CreationTimestamp: "2019-10-18T11:40:36Z",
+				},
+				CellStatus: kubernetes.CellStatus{Status: "Ready", GatewayStatus: "Ready"},
+			},
+		},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells))))
+	// the watch ends as the instance is ready
+	if err := RunStatus(mockCli, "employee", "json", true); err != nil {
+		t.Fatalf("error in RunStatus, %v", err)
+	}
+	out := mockCli.OutBuffer().String()
+	if !strings.HasPrefix(out, clearScreen) || strings.Count(out, clearScreen) != 1 {
+		t.Errorf("RunStatus: expected the status to be printed once, got %q", out)
+	}
+	if !strings.Contains(out, "\"gatewayStatus\": \"Ready\"") {
+		t.Errorf("RunStatus: expected the gateway status in %q", out)
+	}
+}
+
+type forbiddenEventsKubeCli struct {
+	*test.MockKubeCli
+}
+
+func (forbiddenEventsKubeCli) GetEvents(involvedObjectKind string, involvedObjectName string) (kubernetes.Events,
+	error) {
+	return kubernetes.Events{}, fmt.Errorf("events is forbidden: User cannot list resource \"events\"")
+}
+
+func TestRunStatusWithoutEvents(t *testing.T) {
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:              "employee",
+					CreationTimestamp: "2019-10-18T11:40:36Z",
+				},
+				CellStatus: kubernetes.CellStatus{Status: "Ready", GatewayStatus: "Ready"},
+			},
+		},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(forbiddenEventsKubeCli{test.NewMockKubeCli(test.WithCells(cells))}))
+	// the status is printed even if the events cannot be listed
+	if err := RunStatus(mockCli, "employee", "", false); err != nil {
+		t.Fatalf("error in RunStatus, %v", err)
+	}
+	out := mockCli.OutBuffer().String()
+	if !strings.Contains(out, "-COMPONENTS-") ||
+		!strings.Contains(out, "unable to get the events of instance employee, events is forbidden") {
+		t.Errorf("RunStatus: expected the status with a warning about the events, got %q", out)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/revision"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
@@ -68,5 +69,10 @@ func terminateInstance(cli cli.Cli, instance string) error {
 	if output, err = cli.KubeCli().DeleteResource("secret", secretName); err != nil {
 		return fmt.Errorf("error occurred while deleting the secret: %s, %v", secretName, fmt.Errorf(output))
 	}
+	// Delete the revision history
+	historyName := revision.HistoryConfigMapName(instance)
+	if output, err = cli.KubeCli().DeleteResource("configmap", historyName); err != nil {
+		return fmt.Errorf("error occurred while deleting the revision history: %s, %s", historyName, output)
+	}
 	return nil
 }
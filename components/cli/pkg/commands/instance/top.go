@@ -0,0 +1,360 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
+)
+
+// topRefreshInterval is the interval at which the resource usage is refreshed in the watch mode
+const topRefreshInterval = 5 * time.Second
+
+const resourceCpu = "cpu"
+const resourceMemory = "memory"
+
+type componentUsageData struct {
+	Instance    string            `json:"instance"`
+	Component   string            `json:"component"`
+	Pods        int               `json:"pods"`
+	Cpu         resourceUsageData `json:"cpuMillicores"`
+	Memory      resourceUsageData `json:"memoryBytes"`
+	Autoscaling *autoscalingData  `json:"autoscaling,omitempty"`
+}
+
+// resourceUsageData is the usage of a resource by the pods of a component, along with the total of the requests
+// and the limits of the pods. The requests and the limits are omitted if they are not set for all the containers.
+type resourceUsageData struct {
+	Usage    int64 `json:"usage"`
+	Requests int64 `json:"requests,omitempty"`
+	Limits   int64 `json:"limits,omitempty"`
+}
+
+type autoscalingData struct {
+	Policy      string   `json:"policy"`
+	MinReplicas int      `json:"minReplicas"`
+	MaxReplicas int      `json:"maxReplicas"`
+	Targets     []string `json:"targets,omitempty"`
+}
+
+// scalingPolicyData is the scaling policy of a component in the cell and the composite resources
+type scalingPolicyData struct {
+	Replicas    *int  `json:"replicas"`
+	Overridable *bool `json:"overridable"`
+	Hpa         *struct {
+		Overridable *bool `json:"overridable"`
+		MinReplicas *int  `json:"minReplicas"`
+		MaxReplicas int   `json:"maxReplicas"`
+		Metrics     []struct {
+			Resource struct {
+				Name   string `json:"name"`
+				Target struct {
+					AverageUtilization int    `json:"averageUtilization"`
+					AverageValue       string `json:"averageValue"`
+				} `json:"target"`
+				TargetAverageUtilization int    `json:"targetAverageUtilization"`
+				TargetAverageValue       string `json:"targetAverageValue"`
+			} `json:"resource"`
+		} `json:"metrics"`
+	} `json:"hpa"`
+	Kpa *struct {
+		MinReplicas *int `json:"minReplicas"`
+		MaxReplicas int  `json:"maxReplicas"`
+		Concurrency int  `json:"concurrency"`
+	} `json:"kpa"`
+}
+
+// RunTop prints the cpu and the memory usage of the components of an instance, or of all the instances if the
+// instance is empty. The usage is read from the metrics server and shown against the requests, the limits and the
+// autoscale policies of the components. The usage is refreshed until interrupted if watch is set.
+func RunTop(cli cli.Cli, instance string, outputFormat string, watch bool) error {
+	if !watch {
+		return printTop(cli, cli.Out(), instance, outputFormat)
+	}
+	return runWatch(cli, topRefreshInterval, func(out io.Writer) (bool, error) {
+		return false, printTop(cli, out, instance, outputFormat)
+	})
+}
+
+func printTop(cli cli.Cli, out io.Writer, instance string, outputFormat string) error {
+	instances := []string{instance}
+	if instance == "" {
+		var err error
+		if instances, err = cli.KubeCli().GetInstancesNames(); err != nil {
+			return fmt.Errorf("error getting running instances, %v", err)
+		}
+	}
+	usage := []componentUsageData{}
+	for _, instanceName := range instances {
+		instanceUsage, err := getInstanceUsage(cli, instanceName)
+		if err != nil {
+			return err
+		}
+		usage = append(usage, instanceUsage...)
+	}
+	if !output.IsTable(outputFormat) {
+		return output.Print(out, outputFormat, usage)
+	}
+	if len(usage) == 0 {
+		fmt.Fprintln(out, "No resource usage found")
+		return nil
+	}
+	table := output.NewTable("INSTANCE", "COMPONENT", "PODS", "CPU", "MEMORY", "AUTOSCALING").
+		AddWideColumns("CPU REQUESTS", "CPU LIMITS", "MEMORY REQUESTS", "MEMORY LIMITS").
+		SetColumnColor(1, tablewriter.Colors{tablewriter.FgHiBlueColor})
+	for _, component := range usage {
+		table.Append(component.Instance, component.Component, fmt.Sprint(component.Pods),
+			formatUsage(component.Cpu, formatCpu), formatUsage(component.Memory, formatMemory),
+			formatAutoscaling(component.Autoscaling), formatQuantity(component.Cpu.Requests, formatCpu),
+			formatQuantity(component.Cpu.Limits, formatCpu), formatQuantity(component.Memory.Requests, formatMemory),
+			formatQuantity(component.Memory.Limits, formatMemory))
+	}
+	table.Render(out, output.IsWide(outputFormat))
+	return nil
+}
+
+// getInstanceUsage aggregates the resource usage of the pods of an instance by the components
+func getInstanceUsage(cli cli.Cli, instance string) ([]componentUsageData, error) {
+	pods, isComposite, err := getInstancePods(cli, instance)
+	if err != nil {
+		return nil, err
+	}
+	kind := kubernetes.InstanceKindCell
+	var metrics kubernetes.PodMetricsList
+	if isComposite {
+		kind = kubernetes.InstanceKindComposite
+		metrics, err = cli.KubeCli().GetPodMetricsForComposite(instance)
+	} else {
+		metrics, err = cli.KubeCli().GetPodMetricsForCell(instance)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting resource usage of instance %s, make sure the metrics server is "+
+			"running in the cluster, %v", instance, err)
+	}
+	policies, err := getScalingPolicies(cli, kind, instance)
+	if err != nil {
+		return nil, err
+	}
+	podSpecs := make(map[string]kubernetes.PodTemplate)
+	for _, pod := range pods.Items {
+		podSpecs[pod.MetaData.Name] = pod.PodSpec
+	}
+	var usage []componentUsageData
+	componentIndexes := make(map[string]int)
+	for _, podMetrics := range metrics.Items {
+		name := getComponentName(podMetrics.MetaData.Name, instance)
+		index, ok := componentIndexes[name]
+		if !ok {
+			autoscaling, err := getAutoscaling(policies[name])
+			if err != nil {
+				return nil, fmt.Errorf("error reading scaling policy of component %s, %v", name, err)
+			}
+			index = len(usage)
+			componentIndexes[name] = index
+			usage = append(usage, componentUsageData{Instance: instance, Component: name, Autoscaling: autoscaling})
+		}
+		component := &usage[index]
+		component.Pods++
+		for _, container := range podMetrics.Containers {
+			if err := addQuantity(&component.Cpu.Usage, container.Usage[resourceCpu], 1e3); err != nil {
+				return nil, err
+			}
+			if err := addQuantity(&component.Memory.Usage, container.Usage[resourceMemory], 1); err != nil {
+				return nil, err
+			}
+		}
+		podSpec, found := podSpecs[podMetrics.MetaData.Name]
+		if !found {
+			// the pod was deleted after reading the metrics
+			component.Cpu.Requests, component.Cpu.Limits = -1, -1
+			component.Memory.Requests, component.Memory.Limits = -1, -1
+			continue
+		}
+		for _, container := range podSpec.Containers {
+			var requests, limits kubernetes.ResourceList
+			if container.Resources != nil {
+				requests, limits = container.Resources.Requests, container.Resources.Limits
+			}
+			addRequirement(&component.Cpu.Requests, requests[resourceCpu], 1e3)
+			addRequirement(&component.Cpu.Limits, limits[resourceCpu], 1e3)
+			addRequirement(&component.Memory.Requests, requests[resourceMemory], 1)
+			addRequirement(&component.Memory.Limits, limits[resourceMemory], 1)
+		}
+	}
+	for i := range usage {
+		for _, quantity := range []*int64{&usage[i].Cpu.Requests, &usage[i].Cpu.Limits, &usage[i].Memory.Requests,
+			&usage[i].Memory.Limits} {
+			if *quantity < 0 {
+				*quantity = 0
+			}
+		}
+	}
+	return usage, nil
+}
+
+// getScalingPolicies returns the scaling policies of the components of an instance, keyed by the component name
+func getScalingPolicies(cli cli.Cli, kind kubernetes.InstanceKind, instance string) (map[string]interface{}, error) {
+	data, err := cli.KubeCli().GetInstanceBytes(string(kind), instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance bytes, %v", err)
+	}
+	resource := &kubernetes.ScaleResource{}
+	if err = json.Unmarshal(data, resource); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall, %v", err)
+	}
+	policies := make(map[string]interface{})
+	for _, component := range resource.Spec.Components {
+		policies[component.Metadata.Name] = component.Spec.ScalingPolicy
+	}
+	if kind == kubernetes.InstanceKindCell && gwScalePolicyExists(resource) {
+		policies[gatewayComponent] = resource.Spec.Gateway.Spec.ScalingPolicy
+	}
+	return policies, nil
+}
+
+// getAutoscaling returns the replicas and the targets of a scaling policy
+func getAutoscaling(policy interface{}) (*autoscalingData, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	scalingPolicy, err := parseScalingPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	if hpa := scalingPolicy.Hpa; hpa != nil {
+		autoscaling := &autoscalingData{Policy: "hpa", MinReplicas: 1, MaxReplicas: hpa.MaxReplicas}
+		if hpa.MinReplicas != nil {
+			autoscaling.MinReplicas = *hpa.MinReplicas
+		}
+		for _, metric := range hpa.Metrics {
+			resource := metric.Resource
+			switch {
+			case resource.Target.AverageUtilization > 0:
+				autoscaling.Targets = append(autoscaling.Targets, fmt.Sprintf("%s %d%%", resource.Name,
+					resource.Target.AverageUtilization))
+			case resource.TargetAverageUtilization > 0:
+				autoscaling.Targets = append(autoscaling.Targets, fmt.Sprintf("%s %d%%", resource.Name,
+					resource.TargetAverageUtilization))
+			case resource.Target.AverageValue != "":
+				autoscaling.Targets = append(autoscaling.Targets, fmt.Sprintf("%s %s", resource.Name,
+					resource.Target.AverageValue))
+			case resource.TargetAverageValue != "":
+				autoscaling.Targets = append(autoscaling.Targets, fmt.Sprintf("%s %s", resource.Name,
+					resource.TargetAverageValue))
+			}
+		}
+		return autoscaling, nil
+	}
+	if kpa := scalingPolicy.Kpa; kpa != nil {
+		autoscaling := &autoscalingData{Policy: "kpa", MaxReplicas: kpa.MaxReplicas}
+		if kpa.MinReplicas != nil {
+			autoscaling.MinReplicas = *kpa.MinReplicas
+		}
+		if kpa.Concurrency > 0 {
+			autoscaling.Targets = []string{fmt.Sprintf("concurrency %d", kpa.Concurrency)}
+		}
+		return autoscaling, nil
+	}
+	if scalingPolicy.Replicas != nil {
+		return &autoscalingData{Policy: "replicas", MinReplicas: *scalingPolicy.Replicas,
+			MaxReplicas: *scalingPolicy.Replicas}, nil
+	}
+	return nil, nil
+}
+
+func parseScalingPolicy(policy interface{}) (scalingPolicyData, error) {
+	scalingPolicy := scalingPolicyData{}
+	policyBytes, err := json.Marshal(policy)
+	if err != nil {
+		return scalingPolicy, err
+	}
+	err = json.Unmarshal(policyBytes, &scalingPolicy)
+	return scalingPolicy, err
+}
+
+// addQuantity adds a quantity to the total in the given unit, where the unit is the multiplier of the base unit
+func addQuantity(total *int64, quantity string, unit float64) error {
+	if quantity == "" {
+		return nil
+	}
+	value, err := kubernetes.ParseQuantity(quantity)
+	if err != nil {
+		return err
+	}
+	*total += int64(math.Round(value * unit))
+	return nil
+}
+
+// addRequirement adds a request or a limit of a container to the total. The total is set to -1 if the requirement
+// is not set for any of the containers, as the usage cannot be compared against it.
+func addRequirement(total *int64, quantity string, unit float64) {
+	if *total < 0 {
+		return
+	}
+	if quantity == "" || addQuantity(total, quantity, unit) != nil {
+		*total = -1
+	}
+}
+
+func formatCpu(millicores int64) string {
+	return fmt.Sprintf("%dm", millicores)
+}
+
+func formatMemory(bytes int64) string {
+	return fmt.Sprintf("%dMi", int64(math.Round(float64(bytes)/(1<<20))))
+}
+
+func formatQuantity(quantity int64, format func(int64) string) string {
+	if quantity <= 0 {
+		return "-"
+	}
+	return format(quantity)
+}
+
+// formatUsage formats the usage along with the percentage of the requests, which is the utilization used by the
+// autoscalers
+func formatUsage(usage resourceUsageData, format func(int64) string) string {
+	if usage.Requests <= 0 {
+		return format(usage.Usage)
+	}
+	return fmt.Sprintf("%s (%d%%)", format(usage.Usage), usage.Usage*100/usage.Requests)
+}
+
+func formatAutoscaling(autoscaling *autoscalingData) string {
+	if autoscaling == nil {
+		return "-"
+	}
+	if autoscaling.Policy == "replicas" {
+		return fmt.Sprintf("replicas %d", autoscaling.MinReplicas)
+	}
+	formatted := fmt.Sprintf("%s %d-%d", autoscaling.Policy, autoscaling.MinReplicas, autoscaling.MaxReplicas)
+	if len(autoscaling.Targets) > 0 {
+		formatted += fmt.Sprintf(" (%s)", strings.Join(autoscaling.Targets, ", "))
+	}
+	return formatted
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// newResourcePod returns a running pod with a container having the given requests and limits
+func newResourcePod(name string, container string, requests, limits kubernetes.ResourceList) kubernetes.Pod {
+	pod := newPod(name, "Running")
+	pod.PodSpec.Containers = []kubernetes.ContainerTemplate{
+		{
+			Name:      container,
+			Resources: &kubernetes.ResourceRequirements{Requests: requests, Limits: limits},
+		},
+		{
+			Name: "istio-proxy",
+			Resources: &kubernetes.ResourceRequirements{
+				Requests: kubernetes.ResourceList{"cpu": "10m", "memory": "40Mi"},
+			},
+		},
+	}
+	return pod
+}
+
+// newPodMetrics returns the usage of a pod with the usage of a container and the istio-proxy
+func newPodMetrics(name string, container string, cpu, memory string) kubernetes.PodMetrics {
+	return kubernetes.PodMetrics{
+		MetaData: kubernetes.PodMetaData{Name: name},
+		Containers: []kubernetes.ContainerMetrics{
+			{Name: container, Usage: kubernetes.ResourceList{"cpu": cpu, "memory": memory}},
+			{Name: "istio-proxy", Usage: kubernetes.ResourceList{"cpu": "2000000n", "memory": "20Mi"}},
+		},
+	}
+}
+
+func newTopMockKubeCli(t *testing.T, podMetrics map[string]kubernetes.PodMetricsList) *test.MockKubeCli {
+	petBeAutoCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-auto.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []func(*test.MockKubeCli){
+		test.WithCells(kubernetes.Cells{
+			Items: []kubernetes.Cell{{CellMetaData: kubernetes.K8SMetaData{Name: "pet-be-auto"}}},
+		}),
+		test.WithCellsAsBytes(map[string][]byte{"pet-be-auto": petBeAutoCell}),
+		test.WithComposites(kubernetes.Composites{
+			Items: []kubernetes.Composite{{CompositeMetaData: kubernetes.K8SMetaData{Name: "stock"}}},
+		}),
+		test.WithResources(map[string][]byte{
+			"composites.mesh.cellery.io/stock": []byte(`{"spec":{"components":[{"metadata":{"name":"stock"},` +
+				`"spec":{"scalingPolicy":{"kpa":{"maxReplicas":3,"concurrency":10}}}}]}}`),
+		}),
+		test.WithPods(map[string]kubernetes.Pods{
+			"pet-be-auto": {
+				Items: []kubernetes.Pod{
+					newResourcePod("pet-be-auto--controller-deployment-5c7b9d8f6-8kq2w", "controller",
+						kubernetes.ResourceList{"cpu": "125m"}, kubernetes.ResourceList{"cpu": "125m"}),
+					newResourcePod("pet-be-auto--controller-deployment-5c7b9d8f6-mz7rt", "controller",
+						kubernetes.ResourceList{"cpu": "125m"}, kubernetes.ResourceList{"cpu": "125m"}),
+					newResourcePod("pet-be-auto--gateway-deployment-7d9f8c6b5-x2x4k", "envoy-gateway",
+						kubernetes.ResourceList{"cpu": "100m", "memory": "128Mi"},
+						kubernetes.ResourceList{"cpu": "500m", "memory": "256Mi"}),
+				},
+			},
+			"stock": {
+				Items: []kubernetes.Pod{newPod("stock--stock-deployment-6f9c8b7d5-q8w2e", "Running")},
+			},
+		}),
+	}
+	if podMetrics != nil {
+		opts = append(opts, test.WithPodMetrics(podMetrics))
+	}
+	return test.NewMockKubeCli(opts...)
+}
+
+var petBeAutoPodMetrics = map[string]kubernetes.PodMetricsList{
+	"pet-be-auto": {
+		Items: []kubernetes.PodMetrics{
+			newPodMetrics("pet-be-auto--controller-deployment-5c7b9d8f6-8kq2w", "controller", "60m", "100Mi"),
+			newPodMetrics("pet-be-auto--controller-deployment-5c7b9d8f6-mz7rt", "controller", "40m", "80Mi"),
+			newPodMetrics("pet-be-auto--gateway-deployment-7d9f8c6b5-x2x4k", "envoy-gateway", "8000000n", "44Mi"),
+		},
+	},
+	"stock": {
+		Items: []kubernetes.PodMetrics{
+			newPodMetrics("stock--stock-deployment-6f9c8b7d5-q8w2e", "stock", "1", "1Gi"),
+		},
+	},
+}
+
+func TestRunTop(t *testing.T) {
+	controllerUsage := componentUsageData{
+		Instance:  "pet-be-auto",
+		Component: "controller",
+		Pods:      2,
+		Cpu:       resourceUsageData{Usage: 104, Requests: 270, Limits: 0},
+		Memory:    resourceUsageData{Usage: 220 << 20},
+		Autoscaling: &autoscalingData{
+			Policy:      "hpa",
+			MinReplicas: 1,
+			MaxReplicas: 6,
+			Targets:     []string{"cpu 55%"},
+		},
+	}
+	gatewayUsage := componentUsageData{
+		Instance:    "pet-be-auto",
+		Component:   "gateway",
+		Pods:        1,
+		Cpu:         resourceUsageData{Usage: 10, Requests: 110},
+		Memory:      resourceUsageData{Usage: 64 << 20, Requests: 168 << 20},
+		Autoscaling: &autoscalingData{Policy: "replicas", MinReplicas: 1, MaxReplicas: 1},
+	}
+	stockUsage := componentUsageData{
+		Instance:  "stock",
+		Component: "stock",
+		Pods:      1,
+		Cpu:       resourceUsageData{Usage: 1002},
+		Memory:    resourceUsageData{Usage: 1044 << 20},
+		Autoscaling: &autoscalingData{
+			Policy:      "kpa",
+			MinReplicas: 0,
+			MaxReplicas: 3,
+			Targets:     []string{"concurrency 10"},
+		},
+	}
+	tests := []struct {
+		name     string
+		instance string
+		want     []componentUsageData
+	}{
+		{
+			name:     "usage of a cell",
+			instance: "pet-be-auto",
+			want:     []componentUsageData{controllerUsage, gatewayUsage},
+		},
+		{
+			name:     "usage of a composite",
+			instance: "stock",
+			want:     []componentUsageData{stockUsage},
+		},
+		{
+			name: "usage of all the instances",
+			want: []componentUsageData{controllerUsage, gatewayUsage, stockUsage},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(newTopMockKubeCli(t, petBeAutoPodMetrics)))
+			if err := RunTop(mockCli, tst.instance, "json", false); err != nil {
+				t.Fatalf("error in RunTop, %v", err)
+			}
+			var got []componentUsageData
+			if err := json.Unmarshal(mockCli.OutBuffer().Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal the output, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, got); diff != "" {
+				t.Errorf("RunTop: unexpected usage (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRunTopTable(t *testing.T) {
+	mockCli := test.NewMockCli(test.SetKubeCli(newTopMockKubeCli(t, petBeAutoPodMetrics)))
+	if err := RunTop(mockCli, "pet-be-auto", "wide", false); err != nil {
+		t.Fatalf("error in RunTop, %v", err)
+	}
+	// the colors of the columns are removed
+	table := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(mockCli.OutBuffer().String(), "")
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimSpace(table), "\n")[2:] {
+		rows = append(rows, strings.Fields(line))
+	}
+	want := [][]string{
+		{"pet-be-auto", "controller", "2", "104m", "(38%)", "220Mi", "hpa", "1-6", "(cpu", "55%)", "270m", "-",
+			"-", "-"},
+		{"pet-be-auto", "gateway", "1", "10m", "(9%)", "64Mi", "(38%)", "replicas", "1", "110m", "-", "168Mi", "-"},
+	}
+	if diff := cmp.Diff(want, rows); diff != "" {
+		t.Errorf("RunTop: unexpected table (-want, +got)\n%v", diff)
+	}
+}
+
+func TestRunTopError(t *testing.T) {
+	tests := []struct {
+		name                    string
+		instance                string
+		podMetrics              map[string]kubernetes.PodMetricsList
+		wantErrorMessagePortion string
+	}{
+		{
+			name:                    "instance does not exist",
+			instance:                "hr",
+			podMetrics:              petBeAutoPodMetrics,
+			wantErrorMessagePortion: "instance hr does not exist",
+		},
+		{
+			name:     "metrics server not available",
+			instance: "pet-be-auto",
+			wantErrorMessagePortion: "error getting resource usage of instance pet-be-auto, make sure the " +
+				"metrics server is running in the cluster",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(newTopMockKubeCli(t, tst.podMetrics)))
+			err := RunTop(mockCli, tst.instance, "", false)
+			if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+				t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package instance
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+)
+
+// clearScreen moves the cursor to the top left corner of the terminal and clears it
+const clearScreen = "\033[H\033[2J"
+
+// runWatch clears the screen and prints the output of the print function at the given interval until the print
+// function returns done or the command is interrupted. The output is rendered before clearing the screen to avoid
+// flickering.
+func runWatch(cli cli.Cli, interval time.Duration, print func(out io.Writer) (bool, error)) error {
+	for {
+		var buffer bytes.Buffer
+		done, err := print(&buffer)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cli.Out(), "%sEvery %s: %s\n\n%s", clearScreen, interval, time.Now().Format(time.RFC1123),
+			buffer.String())
+		if done {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}
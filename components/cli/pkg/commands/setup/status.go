@@ -20,13 +20,13 @@ package setup
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/runtime"
 )
 
@@ -39,7 +39,18 @@ type SystemComponent struct {
 	status    string
 }
 
-func RunSetupStatus(cli cli.Cli) error {
+type setupStatusData struct {
+	ClusterName      string                `json:"clusterName"`
+	SystemComponents []systemComponentData `json:"systemComponents"`
+}
+
+type systemComponentData struct {
+	Component string `json:"component"`
+	Enabled   bool   `json:"enabled"`
+	Status    string `json:"status"`
+}
+
+func RunSetupStatus(cli cli.Cli, outputFormat string) error {
 	k8sServerVersion, _, err := cli.KubeCli().Version()
 	if err != nil {
 		if strings.Contains(k8sServerVersion, "Unable to connect to the server") {
@@ -67,30 +78,26 @@ func RunSetupStatus(cli cli.Cli) error {
 	if err != nil {
 		return fmt.Errorf("error getting cluster name, %v", err)
 	}
+	if !output.IsTable(outputFormat) {
+		setupStatus := setupStatusData{ClusterName: clusterName, SystemComponents: []systemComponentData{}}
+		for _, systemComponent := range systemComponents {
+			setupStatus.SystemComponents = append(setupStatus.SystemComponents, systemComponentData{
+				Component: string(systemComponent.component),
+				Enabled:   systemComponent.enabled,
+				Status:    systemComponent.status,
+			})
+		}
+		return output.Print(cli.Out(), outputFormat, setupStatus)
+	}
 	fmt.Fprintf(cli.Out(), componentLabelColor("cluster name: %s\n\n"), componentColor(clusterName))
-	displayClusterComponentsTable(systemComponents)
+	displayClusterComponentsTable(cli, systemComponents)
 	return nil
 }
 
-func displayClusterComponentsTable(systemComponents []*SystemComponent) {
-	var tableData [][]string
+func displayClusterComponentsTable(cli cli.Cli, systemComponents []*SystemComponent) {
+	table := output.NewTable("SYSTEM COMPONENT", "STATUS").SetColumnColor(0, tablewriter.Colors{tablewriter.Bold})
 	for _, systemComponent := range systemComponents {
-		tableData = append(tableData, []string{string(systemComponent.component), systemComponent.status})
+		table.Append(string(systemComponent.component), systemComponent.status)
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"SYSTEM COMPONENT", "STATUS"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold})
-	table.SetColumnColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{})
-
-	table.AppendBulk(tableData)
-	table.Render()
+	table.Render(cli.Out(), false)
 }
@@ -77,7 +77,7 @@ func TestRunSetupStatus(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunSetupStatus(tst.mockCli)
+			err := RunSetupStatus(tst.mockCli, "")
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunSetupStatus, %v", err)
@@ -0,0 +1,398 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	imagecmd "cellery.io/cellery/components/cli/pkg/commands/image"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// Stack is a set of cell and composite instances which are deployed together
+type Stack struct {
+	Instances []Instance `json:"instances"`
+}
+
+// Instance is a cell or composite instance of a stack
+type Instance struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	// Dependencies maps the dependency aliases of the image to the instances which should be linked
+	Dependencies      map[string]string `json:"dependencies,omitempty"`
+	Env               map[string]string `json:"env,omitempty"`
+	StartDependencies bool              `json:"startDependencies,omitempty"`
+	ShareDependencies bool              `json:"shareDependencies,omitempty"`
+	AutoscalePolicy   string            `json:"autoscalePolicy,omitempty"`
+}
+
+// stackEnvHashAnnotation holds the hash of the environment variables an instance was created with by a stack, since
+// the environment variables are only passed to the image when the instance is created
+const stackEnvHashAnnotation = "mesh.cellery.io/stack-env-hash"
+
+type action string
+
+const (
+	actionCreate    action = "create"
+	actionRecreate  action = "recreate"
+	actionUnchanged action = "unchanged"
+	actionOutdated  action = "outdated"
+)
+
+type instancePlan struct {
+	instance     Instance
+	action       action
+	runningImage string
+	kind         kubernetes.InstanceKind
+	// changes are the differences between the running instance and the instance of the stack
+	changes []string
+}
+
+// RunApply deploys the instances of the stack file which are not running. Instances running with a different image,
+// dependency links or environment variables are only replaced if recreate is set. Autoscale policies are applied to all the instances of the stack.
+func RunApply(cli cli.Cli, file string, recreate bool) error {
+	stack, err := LoadStack(file)
+	if err != nil {
+		return err
+	}
+	instances, err := orderInstances(stack.Instances)
+	if err != nil {
+		return err
+	}
+	plans, err := planInstances(cli, instances, recreate)
+	if err != nil {
+		return err
+	}
+	printPlan(cli, plans)
+	for _, plan := range plans {
+		switch plan.action {
+		case actionRecreate:
+			if err := instance.RunTerminate(cli, []string{plan.instance.Name}, false); err != nil {
+				return fmt.Errorf("failed to terminate instance %s, %v", plan.instance.Name, err)
+			}
+			fallthrough
+		case actionCreate:
+			if err := imagecmd.RunRun(cli, plan.instance.Image, plan.instance.Name,
+				plan.instance.StartDependencies, plan.instance.ShareDependencies, dependencyLinks(plan.instance),
+				envVars(plan.instance), ""); err != nil {
+				return fmt.Errorf("failed to run instance %s, %v", plan.instance.Name, err)
+			}
+			if plan.instance.AutoscalePolicy != "" || len(plan.instance.Env) > 0 {
+				if plan.kind, err = instanceKind(cli, plan.instance.Name); err != nil {
+					return err
+				}
+			}
+			if err := annotateEnvHash(cli, plan); err != nil {
+				return err
+			}
+		case actionOutdated:
+			util.PrintWarningMessage(fmt.Sprintf("Instance %s differs from the stack in its %s, use --recreate "+
+				"to replace it", plan.instance.Name, strings.Join(plan.changes, ", ")))
+		}
+		if plan.instance.AutoscalePolicy != "" {
+			if err := instance.RunApplyAutoscalePolicies(cli, plan.kind, plan.instance.Name,
+				plan.instance.AutoscalePolicy); err != nil {
+				return fmt.Errorf("failed to apply autoscale policies of instance %s, %v", plan.instance.Name, err)
+			}
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully applied stack %s", util.Bold(file)))
+	util.PrintWhatsNextMessage("list running instances", "cellery list instances")
+	return nil
+}
+
+// LoadStack reads and validates a stack file. Paths of the autoscale policies are resolved relative to the
+// directory of the stack file.
+func LoadStack(file string) (*Stack, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading stack file %s, %v", file, err)
+	}
+	stack := &Stack{}
+	if err := yaml.Unmarshal(content, stack); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall stack file %s, %v", file, err)
+	}
+	if len(stack.Instances) == 0 {
+		return nil, fmt.Errorf("stack file %s does not contain any instances", file)
+	}
+	instanceNamePattern := regexp.MustCompile(fmt.Sprintf("^%s$", constants.CelleryIdPattern))
+	names := map[string]bool{}
+	for i, stackInstance := range stack.Instances {
+		if !instanceNamePattern.MatchString(stackInstance.Name) {
+			return nil, fmt.Errorf("expects a valid instance name, received %q", stackInstance.Name)
+		}
+		if names[stackInstance.Name] {
+			return nil, fmt.Errorf("instance %s is defined more than once", stackInstance.Name)
+		}
+		names[stackInstance.Name] = true
+		if _, err := image.ParseImageTag(stackInstance.Image); err != nil {
+			return nil, fmt.Errorf("invalid image of instance %s, %v", stackInstance.Name, err)
+		}
+		if stackInstance.AutoscalePolicy != "" && !filepath.IsAbs(stackInstance.AutoscalePolicy) {
+			stack.Instances[i].AutoscalePolicy = filepath.Join(filepath.Dir(file), stackInstance.AutoscalePolicy)
+		}
+	}
+	return stack, nil
+}
+
+// orderInstances orders the instances so that the instances of the stack which are linked as dependencies are
+// applied before the instances depending on them.
+func orderInstances(instances []Instance) ([]Instance, error) {
+	instancesByName := map[string]Instance{}
+	for _, stackInstance := range instances {
+		instancesByName[stackInstance.Name] = stackInstance
+	}
+	var ordered []Instance
+	visited := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(stackInstance Instance) error
+	visit = func(stackInstance Instance) error {
+		if visited[stackInstance.Name] {
+			return nil
+		}
+		if visiting[stackInstance.Name] {
+			return fmt.Errorf("circular dependency found on instance %s", stackInstance.Name)
+		}
+		visiting[stackInstance.Name] = true
+		for _, alias := range sortedKeys(stackInstance.Dependencies) {
+			if dependency, ok := instancesByName[stackInstance.Dependencies[alias]]; ok {
+				if err := visit(dependency); err != nil {
+					return err
+				}
+			}
+		}
+		visiting[stackInstance.Name] = false
+		visited[stackInstance.Name] = true
+		ordered = append(ordered, stackInstance)
+		return nil
+	}
+	for _, stackInstance := range instances {
+		if err := visit(stackInstance); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// planInstances compares the instances of the stack with the running instances
+func planInstances(cli cli.Cli, instances []Instance, recreate bool) ([]*instancePlan, error) {
+	runningInstances, err := cli.KubeCli().GetInstancesNames()
+	if err != nil {
+		return nil, fmt.Errorf("error getting running instances, %v", err)
+	}
+	var plans []*instancePlan
+	for _, stackInstance := range instances {
+		plan := &instancePlan{instance: stackInstance, action: actionCreate}
+		if util.ContainsInStringArray(runningInstances, stackInstance.Name) {
+			var annotations kubernetes.CellAnnotations
+			if plan.kind, annotations, err = runningInstance(cli, stackInstance.Name); err != nil {
+				return nil, err
+			}
+			plan.runningImage = annotatedImage(annotations)
+			if plan.changes, err = instanceChanges(stackInstance, annotations); err != nil {
+				return nil, err
+			}
+			if len(plan.changes) == 0 {
+				plan.action = actionUnchanged
+			} else if recreate {
+				plan.action = actionRecreate
+			} else {
+				plan.action = actionOutdated
+			}
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+func printPlan(cli cli.Cli, plans []*instancePlan) {
+	table := output.NewTable("INSTANCE", "IMAGE", "RUNNING IMAGE", "ACTION")
+	for _, plan := range plans {
+		runningImage := plan.runningImage
+		if runningImage == "" {
+			runningImage = "-"
+		}
+		table.Append(plan.instance.Name, plan.instance.Image, runningImage, string(plan.action))
+	}
+	table.Render(cli.Out(), false)
+}
+
+// instanceChanges returns the differences between an instance of the stack and the annotations of the running
+// instance
+func instanceChanges(stackInstance Instance, annotations kubernetes.CellAnnotations) ([]string, error) {
+	var changes []string
+	parsedImage, _ := image.ParseImageTag(stackInstance.Image)
+	if annotatedImage(annotations) != fmt.Sprintf("%s/%s:%s", parsedImage.Organization, parsedImage.ImageName,
+		parsedImage.ImageVersion) {
+		changes = append(changes, fmt.Sprintf("image (running %s)", annotatedImage(annotations)))
+	}
+	linked, err := dependenciesLinked(stackInstance, annotations.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	if !linked {
+		changes = append(changes, "dependency links")
+	}
+	if annotations.StackEnvHash != envHash(stackInstance) {
+		changes = append(changes, "environment variables")
+	}
+	return changes, nil
+}
+
+// dependenciesLinked checks whether the running instance depends on the instances linked in the stack, and only on
+// them unless the dependencies are started along with the instance. The dependencies are matched by the alias if the
+// dependency annotation contains the aliases, and by the instance otherwise.
+func dependenciesLinked(stackInstance Instance, dependencyAnnotation string) (bool, error) {
+	dependencies, err := routing.ExtractDependencies(dependencyAnnotation)
+	if err != nil {
+		return false, fmt.Errorf("error reading the dependencies of instance %s, %v", stackInstance.Name, err)
+	}
+	instancesByAlias := map[string]string{}
+	dependencyInstances := map[string]bool{}
+	for _, dependency := range dependencies {
+		if dependency["alias"] != "" {
+			instancesByAlias[dependency["alias"]] = dependency["instance"]
+		}
+		dependencyInstances[dependency["instance"]] = true
+	}
+	for alias, dependencyInstance := range stackInstance.Dependencies {
+		if runningInstance, ok := instancesByAlias[alias]; ok {
+			if runningInstance != dependencyInstance {
+				return false, nil
+			}
+		} else if !dependencyInstances[dependencyInstance] {
+			return false, nil
+		}
+	}
+	if stackInstance.StartDependencies {
+		// the dependencies which are not linked are started along with the instance
+		return true, nil
+	}
+	linkedInstances := map[string]bool{}
+	for _, dependencyInstance := range stackInstance.Dependencies {
+		linkedInstances[dependencyInstance] = true
+	}
+	for _, dependency := range dependencies {
+		if dependency["alias"] != "" {
+			if _, ok := stackInstance.Dependencies[dependency["alias"]]; !ok {
+				return false, nil
+			}
+		} else if !linkedInstances[dependency["instance"]] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// envHash returns the hash of the environment variables of an instance of the stack, which is empty if the instance
+// does not have environment variables
+func envHash(stackInstance Instance) string {
+	if len(stackInstance.Env) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(envVars(stackInstance), "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// annotateEnvHash annotates a created instance with the hash of its environment variables, which is compared with
+// the stack when it is applied again
+func annotateEnvHash(cli cli.Cli, plan *instancePlan) error {
+	hash := envHash(plan.instance)
+	if hash == "" {
+		return nil
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{
+			"op":    "add",
+			"path":  "/metadata/annotations/" + strings.Replace(stackEnvHashAnnotation, "/", "~1", -1),
+			"value": hash,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshall patch, %v", err)
+	}
+	if err := cli.KubeCli().JsonPatch(string(plan.kind), plan.instance.Name, string(patch)); err != nil {
+		return fmt.Errorf("failed to annotate instance %s, %v", plan.instance.Name, err)
+	}
+	return nil
+}
+
+// runningInstance returns the kind and the annotations of a running instance
+func runningInstance(cli cli.Cli, instanceName string) (kubernetes.InstanceKind, kubernetes.CellAnnotations, error) {
+	if cell, err := cli.KubeCli().GetCell(instanceName); err == nil {
+		return kubernetes.InstanceKindCell, cell.CellMetaData.Annotations, nil
+	}
+	composite, err := cli.KubeCli().GetComposite(instanceName)
+	if err != nil {
+		return "", kubernetes.CellAnnotations{}, fmt.Errorf("error getting running instance %s, %v", instanceName,
+			err)
+	}
+	return kubernetes.InstanceKindComposite, composite.CompositeMetaData.Annotations, nil
+}
+
+func instanceKind(cli cli.Cli, instanceName string) (kubernetes.InstanceKind, error) {
+	kind, _, err := runningInstance(cli, instanceName)
+	return kind, err
+}
+
+func annotatedImage(annotations kubernetes.CellAnnotations) string {
+	return fmt.Sprintf("%s/%s:%s", annotations.Organization, annotations.Name, annotations.Version)
+}
+
+func dependencyLinks(stackInstance Instance) []string {
+	var links []string
+	for _, alias := range sortedKeys(stackInstance.Dependencies) {
+		links = append(links, alias+":"+stackInstance.Dependencies[alias])
+	}
+	return links
+}
+
+func envVars(stackInstance Instance) []string {
+	var vars []string
+	for _, key := range sortedKeys(stackInstance.Env) {
+		vars = append(vars, key+"="+stackInstance.Env[key])
+	}
+	return vars
+}
+
+func sortedKeys(values map[string]string) []string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func TestLoadStack(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    *Stack
+		wantErr string
+	}{
+		{
+			name: "stack with dependencies",
+			file: filepath.Join("testdata", "stack.yaml"),
+			want: &Stack{
+				Instances: []Instance{
+					{
+						Name:  "hr",
+						Image: "myorg/hr:1.0.0",
+						Dependencies: map[string]string{
+							"employeeCellDep": "employee",
+							"stockCellDep":    "stock",
+						},
+						Env:             map[string]string{"mode": "dev"},
+						AutoscalePolicy: filepath.Join("testdata", "hr-scale-policy.yaml"),
+					},
+					{
+						Name:         "employee",
+						Image:        "myorg/employee:1.0.0",
+						Dependencies: map[string]string{"stockCellDep": "stock"},
+					},
+					{
+						Name:  "stock",
+						Image: "registry.foo.io/myorg/stock:1.0.0",
+					},
+				},
+			},
+		},
+		{
+			name:    "stack with duplicate instances",
+			file:    filepath.Join("testdata", "duplicate-stack.yaml"),
+			wantErr: "instance foo is defined more than once",
+		},
+		{
+			name:    "missing stack file",
+			file:    filepath.Join("testdata", "missing-stack.yaml"),
+			wantErr: "error reading stack file",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			got, err := LoadStack(tst.file)
+			if tst.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tst.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error loading stack, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, got); diff != "" {
+				t.Errorf("invalid stack (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestOrderInstances(t *testing.T) {
+	stack, err := LoadStack(filepath.Join("testdata", "stack.yaml"))
+	if err != nil {
+		t.Fatalf("error loading stack, %v", err)
+	}
+	ordered, err := orderInstances(stack.Instances)
+	if err != nil {
+		t.Fatalf("error ordering instances, %v", err)
+	}
+	var got []string
+	for _, stackInstance := range ordered {
+		got = append(got, stackInstance.Name)
+	}
+	if diff := cmp.Diff([]string{"stock", "employee", "hr"}, got); diff != "" {
+		t.Errorf("invalid order of instances (-want, +got)\n%v", diff)
+	}
+
+	circularStack, err := LoadStack(filepath.Join("testdata", "circular-stack.yaml"))
+	if err != nil {
+		t.Fatalf("error loading stack, %v", err)
+	}
+	if _, err := orderInstances(circularStack.Instances); err == nil ||
+		!strings.Contains(err.Error(), "circular dependency") {
+		t.Errorf("expected a circular dependency error, got %v", err)
+	}
+}
+
+func TestRunApply(t *testing.T) {
+	currentDir, err := ioutil.TempDir("", "current-dir")
+	if err != nil {
+		t.Errorf("failed to create current dir")
+	}
+	defer func() {
+		if err := os.RemoveAll(currentDir); err != nil {
+			t.Errorf("failed to remove current dir")
+		}
+	}()
+	runningCell := func(version string) kubernetes.Cells {
+		return kubernetes.Cells{
+			Items: []kubernetes.Cell{
+				{
+					CellMetaData: kubernetes.K8SMetaData{
+						Name: "hello",
+						Annotations: kubernetes.CellAnnotations{
+							Organization: "myorg",
+							Name:         "hello",
+							Version:      version,
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name       string
+		cells      kubernetes.Cells
+		wantAction action
+	}{
+		{
+			name:       "instance not running",
+			cells:      kubernetes.Cells{},
+			wantAction: actionCreate,
+		},
+		{
+			name:       "instance running with the same image",
+			cells:      runningCell("1.0.0"),
+			wantAction: actionUnchanged,
+		},
+		{
+			name:       "instance running with a different image",
+			cells:      runningCell("0.9.0"),
+			wantAction: actionOutdated,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(
+				test.SetKubeCli(test.NewMockKubeCli(test.WithCells(tst.cells))),
+				test.SetFileSystem(test.NewMockFileSystem(test.SetCurrentDir(currentDir),
+					test.SetRepository(filepath.Join("..", "image", "testdata", "repo")))),
+				test.SetBalExecutor(test.NewMockBalExecutor(test.SetBalCurrentDir(currentDir))),
+				test.SetRuntime(test.NewMockRuntime()))
+			if err := RunApply(mockCli, filepath.Join("testdata", "hello-stack.yaml"), false); err != nil {
+				t.Errorf("error in RunApply, %v", err)
+			}
+			if !strings.Contains(mockCli.OutBuffer().String(), string(tst.wantAction)) {
+				t.Errorf("expected action %s in the plan, got %s", tst.wantAction, mockCli.OutBuffer().String())
+			}
+		})
+	}
+}
+
+func TestInstanceChanges(t *testing.T) {
+	stackInstance := Instance{
+		Name:         "hr",
+		Image:        "myorg/hr:1.0.0",
+		Dependencies: map[string]string{"stockCellDep": "stock"},
+		Env:          map[string]string{"mode": "dev"},
+	}
+	annotations := func(version string, dependencies string, env map[string]string) kubernetes.CellAnnotations {
+		return kubernetes.CellAnnotations{
+			Organization: "myorg",
+			Name:         "hr",
+			Version:      version,
+			Dependencies: dependencies,
+			StackEnvHash: envHash(Instance{Env: env}),
+		}
+	}
+	tests := []struct {
+		name              string
+		startDependencies bool
+		annotations       kubernetes.CellAnnotations
+		want              []string
+	}{
+		{
+			name: "unchanged instance",
+			annotations: annotations("1.0.0", `[{"instance":"stock","alias":"stockCellDep"}]`,
+				map[string]string{"mode": "dev"}),
+		},
+		{
+			name: "dependency not linked in the stack",
+			annotations: annotations("1.0.0", `[{"instance":"stock","alias":"stockCellDep"},`+
+				`{"instance":"employee","alias":"employeeCellDep"}]`, map[string]string{"mode": "dev"}),
+			want: []string{"dependency links"},
+		},
+		{
+			name:              "dependency started along with the instance",
+			startDependencies: true,
+			annotations: annotations("1.0.0", `[{"instance":"stock","alias":"stockCellDep"},`+
+				`{"instance":"employee","alias":"employeeCellDep"}]`, map[string]string{"mode": "dev"}),
+		},
+		{
+			name: "dependency annotation without aliases not linked in the stack",
+			annotations: annotations("1.0.0", `[{"instance":"stock"},{"instance":"employee"}]`,
+				map[string]string{"mode": "dev"}),
+			want: []string{"dependency links"},
+		},
+		{
+			name:        "dependency annotation without aliases",
+			annotations: annotations("1.0.0", `[{"instance":"stock"}]`, map[string]string{"mode": "dev"}),
+		},
+		{
+			name: "different image",
+			annotations: annotations("0.9.0", `[{"instance":"stock","alias":"stockCellDep"}]`,
+				map[string]string{"mode": "dev"}),
+			want: []string{"image (running myorg/hr:0.9.0)"},
+		},
+		{
+			name: "different dependency links",
+			annotations: annotations("1.0.0", `[{"instance":"stock-old","alias":"stockCellDep"}]`,
+				map[string]string{"mode": "dev"}),
+			want: []string{"dependency links"},
+		},
+		{
+			name: "different environment variables",
+			annotations: annotations("1.0.0", `[{"instance":"stock","alias":"stockCellDep"}]`,
+				map[string]string{"mode": "prod"}),
+			want: []string{"environment variables"},
+		},
+		{
+			name:        "instance not created by a stack",
+			annotations: annotations("1.0.0", `[{"instance":"stock","alias":"stockCellDep"}]`, nil),
+			want:        []string{"environment variables"},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			stackInstance.StartDependencies = tst.startDependencies
+			got, err := instanceChanges(stackInstance, tst.annotations)
+			if err != nil {
+				t.Fatalf("error comparing instance, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, got); diff != "" {
+				t.Errorf("invalid changes (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+const cellImageExt = ".zip"
+
+const (
+	usageTypeImage   = "image"
+	usageTypeTempDir = "temp"
+)
+
+// usage is a directory in the local repository or the temp directory of cellery
+type usage struct {
+	usageType string
+	name      string
+	// image is the org/name of the image, which is empty for temp directories
+	image     string
+	path      string
+	size      int64
+	created   time.Time
+	instances []string
+}
+
+type usageData struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Size      string `json:"size"`
+	Created   string `json:"created"`
+	Instances int    `json:"instances"`
+	// Fields only displayed in the wide output format
+	Path string `json:"path"`
+}
+
+// RunDiskUsage reports the disk usage of each image version in the local repository and the temp directories
+func RunDiskUsage(cli cli.Cli, outputFormat string) error {
+	images, err := repositoryUsage(cli)
+	if err != nil {
+		return err
+	}
+	tempDirs, err := tempDirUsage(cli)
+	if err != nil {
+		return err
+	}
+	instances, err := runningImages(cli)
+	if err != nil {
+		// Disk usage is still reported when the cluster cannot be reached
+		log.Printf("Unable to find the images of the running instances, %v", err)
+	}
+	for _, imageUsage := range images {
+		imageUsage.instances = instances[imageUsage.name]
+	}
+	usages := append(images, tempDirs...)
+	table := output.NewTable("TYPE", "NAME", "SIZE", "CREATED", "INSTANCES").AddWideColumns("PATH")
+	data := []usageData{}
+	for _, u := range usages {
+		d := u.data()
+		data = append(data, d)
+		table.Append(d.Type, d.Name, d.Size, d.Created, fmt.Sprint(d.Instances), d.Path)
+	}
+	if err := output.PrintTable(cli.Out(), outputFormat, data, table); err != nil {
+		return err
+	}
+	if output.IsTable(outputFormat) {
+		fmt.Fprintf(cli.Out(), "\nImages: %d (%s), Temporary directories: %d (%s)\n", len(images),
+			units.HumanSize(float64(totalSize(images))), len(tempDirs), units.HumanSize(float64(totalSize(tempDirs))))
+	}
+	return nil
+}
+
+func (u *usage) data() usageData {
+	return usageData{
+		Type:      u.usageType,
+		Name:      u.name,
+		Size:      units.HumanSize(float64(u.size)),
+		Created:   fmt.Sprintf("%s ago", units.HumanDuration(time.Since(u.created))),
+		Instances: len(u.instances),
+		Path:      u.path,
+	}
+}
+
+// repositoryUsage returns the usage of each image version in the local repository. The image versions of an image
+// are ordered from the latest to the oldest.
+func repositoryUsage(cli cli.Cli) ([]*usage, error) {
+	var usages []*usage
+	repoLocation := cli.FileSystem().Repository()
+	if exists, err := util.FileExists(repoLocation); err != nil || !exists {
+		return usages, err
+	}
+	organizations, err := util.GetSubDirectoryNames(repoLocation)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the local repository, %v", err)
+	}
+	for _, organization := range organizations {
+		projects, err := util.GetSubDirectoryNames(filepath.Join(repoLocation, organization))
+		if err != nil {
+			return nil, fmt.Errorf("error reading the local repository, %v", err)
+		}
+		for _, project := range projects {
+			versions, err := util.GetSubDirectoryNames(filepath.Join(repoLocation, organization, project))
+			if err != nil {
+				return nil, fmt.Errorf("error reading the local repository, %v", err)
+			}
+			var projectUsages []*usage
+			for _, version := range versions {
+				versionDir := filepath.Join(repoLocation, organization, project, version)
+				size, err := util.GetDirSize(versionDir)
+				if err != nil {
+					return nil, fmt.Errorf("error calculating the size of %s, %v", versionDir, err)
+				}
+				created, err := imageCreated(repoLocation, organization, project, version)
+				if err != nil {
+					return nil, err
+				}
+				projectUsages = append(projectUsages, &usage{
+					usageType: usageTypeImage,
+					name:      fmt.Sprintf("%s/%s:%s", organization, project, version),
+					image:     fmt.Sprintf("%s/%s", organization, project),
+					path:      versionDir,
+					size:      size,
+					created:   created,
+				})
+			}
+			sort.SliceStable(projectUsages, func(i, j int) bool {
+				if projectUsages[i].created.Equal(projectUsages[j].created) {
+					return projectUsages[i].name > projectUsages[j].name
+				}
+				return projectUsages[i].created.After(projectUsages[j].created)
+			})
+			usages = append(usages, projectUsages...)
+		}
+	}
+	return usages, nil
+}
+
+// imageCreated returns the build time of an image, or the modified time of the image directory if the image
+// cannot be read
+func imageCreated(repoLocation, organization, project, version string) (time.Time, error) {
+	zipFile := filepath.Join(repoLocation, organization, project, version, project+cellImageExt)
+	if exists, _ := util.FileExists(zipFile); exists {
+		if meta, err := image.ReadMetaData(repoLocation, organization, project, version); err == nil {
+			return time.Unix(meta.BuildTimestamp, 0), nil
+		}
+	}
+	info, err := ioutil.ReadDir(filepath.Join(repoLocation, organization, project))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error reading the local repository, %v", err)
+	}
+	for _, dir := range info {
+		if dir.Name() == version {
+			return dir.ModTime(), nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// tempDirUsage returns the usage of each directory created in the temp directory of cellery
+func tempDirUsage(cli cli.Cli) ([]*usage, error) {
+	var usages []*usage
+	tempDir := cli.FileSystem().TempDir()
+	if exists, err := util.FileExists(tempDir); err != nil || !exists {
+		return usages, err
+	}
+	files, err := ioutil.ReadDir(tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the temp directory, %v", err)
+	}
+	for _, file := range files {
+		size := file.Size()
+		if file.IsDir() {
+			if size, err = util.GetDirSize(filepath.Join(tempDir, file.Name())); err != nil {
+				return nil, fmt.Errorf("error calculating the size of %s, %v", file.Name(), err)
+			}
+		}
+		usages = append(usages, &usage{
+			usageType: usageTypeTempDir,
+			name:      file.Name(),
+			path:      filepath.Join(tempDir, file.Name()),
+			size:      size,
+			created:   file.ModTime(),
+		})
+	}
+	return usages, nil
+}
+
+// runningImages returns the running instances of each image
+func runningImages(cli cli.Cli) (map[string][]string, error) {
+	instances := map[string][]string{}
+	cells, err := cli.KubeCli().GetCells()
+	if err != nil {
+		return instances, fmt.Errorf("error getting cell instances, %v", err)
+	}
+	for _, cell := range cells {
+		addInstance(instances, cell.CellMetaData)
+	}
+	composites, err := cli.KubeCli().GetComposites()
+	if err != nil {
+		return instances, fmt.Errorf("error getting composite instances, %v", err)
+	}
+	for _, composite := range composites {
+		addInstance(instances, composite.CompositeMetaData)
+	}
+	return instances, nil
+}
+
+func addInstance(instances map[string][]string, metadata kubernetes.K8SMetaData) {
+	cellImage := fmt.Sprintf("%s/%s:%s", metadata.Annotations.Organization, metadata.Annotations.Name,
+		metadata.Annotations.Version)
+	instances[cellImage] = append(instances[cellImage], metadata.Name)
+}
+
+func totalSize(usages []*usage) int64 {
+	var size int64
+	for _, u := range usages {
+		size += u.size
+	}
+	return size
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// newTestCelleryHome copies the test repository and creates a temp directory modified at the given time
+func newTestCelleryHome(t *testing.T, tempDirModified time.Time) (string, string, func()) {
+	celleryHome, err := ioutil.TempDir("", "cellery-home")
+	if err != nil {
+		t.Fatalf("failed to create cellery home, %v", err)
+	}
+	repo := filepath.Join(celleryHome, "repo")
+	if err := util.CopyDir(filepath.Join("..", "image", "testdata", "repo"), repo); err != nil {
+		t.Fatalf("failed to copy the repository, %v", err)
+	}
+	// Adding a second version of the hello image
+	if err := util.CopyDir(filepath.Join(repo, "myorg", "hello", "1.0.0"),
+		filepath.Join(repo, "myorg", "hello", "1.0.1")); err != nil {
+		t.Fatalf("failed to copy the image, %v", err)
+	}
+	tempDir := filepath.Join(celleryHome, "tmp")
+	imageTempDir := filepath.Join(tempDir, "cellery-cell-image123")
+	if err := os.MkdirAll(imageTempDir, os.ModePerm); err != nil {
+		t.Fatalf("failed to create temp dir, %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(imageTempDir, "hello.bal"), []byte("hello"), os.ModePerm); err != nil {
+		t.Fatalf("failed to write temp file, %v", err)
+	}
+	if err := os.Chtimes(imageTempDir, tempDirModified, tempDirModified); err != nil {
+		t.Fatalf("failed to change the modified time of the temp dir, %v", err)
+	}
+	return repo, tempDir, func() { os.RemoveAll(celleryHome) }
+}
+
+func runningEmployeeCell() kubernetes.Cells {
+	return kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name: "employee-inst",
+					Annotations: kubernetes.CellAnnotations{
+						Organization: "myorg",
+						Name:         "employee",
+						Version:      "1.0.0",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRunDiskUsage(t *testing.T) {
+	repo, tempDir, cleanup := newTestCelleryHome(t, time.Now())
+	defer cleanup()
+	mockCli := test.NewMockCli(
+		test.SetFileSystem(test.NewMockFileSystem(test.SetRepository(repo), test.SetTempDir(tempDir))),
+		test.SetKubeCli(test.NewMockKubeCli(test.WithCells(runningEmployeeCell()))))
+	if err := RunDiskUsage(mockCli, "json"); err != nil {
+		t.Fatalf("error in RunDiskUsage, %v", err)
+	}
+	out := mockCli.OutBuffer().String()
+	for _, want := range []string{`"name": "myorg/hello:1.0.0"`, `"name": "myorg/hello:1.0.1"`,
+		`"name": "myorg/employee:1.0.0"`, `"name": "cellery-cell-image123"`, `"instances": 1`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in the disk usage, got %s", want, out)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// tempDirMinAge is the minimum age of a temp directory to be pruned, which avoids removing the directories of
+// commands which are still running
+const tempDirMinAge = time.Hour
+
+// PrunePolicy selects the image versions to be removed from the local repository. An image version is only removed
+// if it is allowed by all the given policies.
+type PrunePolicy struct {
+	// KeepLast keeps the given number of latest versions of each image
+	KeepLast int
+	// OlderThan only removes the image versions built before the given duration
+	OlderThan time.Duration
+	// Unused only removes the image versions which are not used by running instances
+	Unused bool
+}
+
+func (p PrunePolicy) isEmpty() bool {
+	return p.KeepLast <= 0 && p.OlderThan <= 0 && !p.Unused
+}
+
+// RunPrune removes the image versions selected by the policy from the local repository, and the temp directories
+// which have not been modified during the last hour. Image versions used by running instances are never removed.
+func RunPrune(cli cli.Cli, policy PrunePolicy, dryRun bool, assumeYes bool) error {
+	images, err := repositoryUsage(cli)
+	if err != nil {
+		return err
+	}
+	tempDirs, err := tempDirUsage(cli)
+	if err != nil {
+		return err
+	}
+	var prunable []*usage
+	if !policy.isEmpty() {
+		instances, err := runningImages(cli)
+		if err != nil {
+			if policy.Unused {
+				return fmt.Errorf("unable to find the images used by running instances, %v", err)
+			}
+			// Images cannot be removed safely without knowing the images used by the running instances
+			util.PrintWarningMessage(fmt.Sprintf("Skipping the images since the images used by running "+
+				"instances cannot be found, %v", err))
+		} else {
+			prunable = append(prunable, prunableImages(images, instances, policy)...)
+		}
+	}
+	for _, tempDir := range tempDirs {
+		if time.Since(tempDir.created) > tempDirMinAge {
+			prunable = append(prunable, tempDir)
+		}
+	}
+	if len(prunable) == 0 {
+		fmt.Fprintln(cli.Out(), "Nothing to prune.")
+		return nil
+	}
+	table := output.NewTable("TYPE", "NAME", "SIZE", "CREATED")
+	for _, u := range prunable {
+		d := u.data()
+		table.Append(d.Type, d.Name, d.Size, d.Created)
+	}
+	table.Render(cli.Out(), false)
+	reclaimed := units.HumanSize(float64(totalSize(prunable)))
+	if dryRun {
+		fmt.Fprintf(cli.Out(), "\nSpace which would be reclaimed: %s\n", reclaimed)
+		return nil
+	}
+	if !assumeYes {
+		canContinue, _, err := util.GetYesOrNoFromUser("Do you want to remove the above images and "+
+			"temporary directories", false)
+		if err != nil {
+			return err
+		}
+		if !canContinue {
+			fmt.Fprintln(cli.Out(), "Prune aborted.")
+			return nil
+		}
+	}
+	for _, u := range prunable {
+		if err := os.RemoveAll(u.path); err != nil {
+			return fmt.Errorf("error removing %s, %v", u.name, err)
+		}
+		if u.usageType == usageTypeImage {
+			// Removing the image and the organization directories if no versions are left
+			imageDir := filepath.Dir(u.path)
+			if err := removeIfEmpty(imageDir); err != nil {
+				return err
+			}
+			if err := removeIfEmpty(filepath.Dir(imageDir)); err != nil {
+				return err
+			}
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully pruned %d item(s), reclaimed %s", len(prunable), reclaimed))
+	return nil
+}
+
+// prunableImages returns the image versions allowed to be removed by the policy. The versions of each image are
+// expected to be ordered from the latest to the oldest.
+func prunableImages(images []*usage, instances map[string][]string, policy PrunePolicy) []*usage {
+	var prunable []*usage
+	versionIndex := map[string]int{}
+	for _, imageUsage := range images {
+		index := versionIndex[imageUsage.image]
+		versionIndex[imageUsage.image]++
+		if len(instances[imageUsage.name]) > 0 {
+			continue
+		}
+		if policy.KeepLast > 0 && index < policy.KeepLast {
+			continue
+		}
+		if policy.OlderThan > 0 && time.Since(imageUsage.created) < policy.OlderThan {
+			continue
+		}
+		prunable = append(prunable, imageUsage)
+	}
+	return prunable
+}
+
+func removeIfEmpty(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading %s, %v", dir, err)
+	}
+	if len(files) == 0 {
+		if err := os.Remove(dir); err != nil {
+			return fmt.Errorf("error removing %s, %v", dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package system
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func TestRunPrune(t *testing.T) {
+	allImages := []string{"myorg/employee:1.0.0", "myorg/hello:1.0.0", "myorg/hello:1.0.1", "myorg/hr:1.0.0",
+		"myorg/stock-comp:1.0.0", "myorg/stock:1.0.0"}
+	tests := []struct {
+		name            string
+		policy          PrunePolicy
+		dryRun          bool
+		tempDirModified time.Time
+		wantImages      []string
+		wantTempDir     bool
+	}{
+		{
+			name:            "without image policies",
+			tempDirModified: time.Now().Add(-2 * time.Hour),
+			wantImages:      allImages,
+			wantTempDir:     false,
+		},
+		{
+			name:            "recently modified temp dir",
+			tempDirModified: time.Now(),
+			wantImages:      allImages,
+			wantTempDir:     true,
+		},
+		{
+			name:            "keep last version",
+			policy:          PrunePolicy{KeepLast: 1},
+			tempDirModified: time.Now(),
+			wantImages: []string{"myorg/employee:1.0.0", "myorg/hello:1.0.1", "myorg/hr:1.0.0",
+				"myorg/stock-comp:1.0.0", "myorg/stock:1.0.0"},
+			wantTempDir: true,
+		},
+		{
+			name:            "unused images",
+			policy:          PrunePolicy{Unused: true},
+			tempDirModified: time.Now(),
+			wantImages:      []string{"myorg/employee:1.0.0"},
+			wantTempDir:     true,
+		},
+		{
+			name:            "images older than a duration",
+			policy:          PrunePolicy{OlderThan: 24 * time.Hour},
+			tempDirModified: time.Now(),
+			wantImages:      []string{"myorg/employee:1.0.0"},
+			wantTempDir:     true,
+		},
+		{
+			name:            "dry run",
+			policy:          PrunePolicy{Unused: true},
+			dryRun:          true,
+			tempDirModified: time.Now().Add(-2 * time.Hour),
+			wantImages:      allImages,
+			wantTempDir:     true,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			repo, tempDir, cleanup := newTestCelleryHome(t, tst.tempDirModified)
+			defer cleanup()
+			mockCli := test.NewMockCli(
+				test.SetFileSystem(test.NewMockFileSystem(test.SetRepository(repo), test.SetTempDir(tempDir))),
+				test.SetKubeCli(test.NewMockKubeCli(test.WithCells(runningEmployeeCell()))))
+			if err := RunPrune(mockCli, tst.policy, tst.dryRun, true); err != nil {
+				t.Fatalf("error in RunPrune, %v", err)
+			}
+			images, err := repositoryUsage(mockCli)
+			if err != nil {
+				t.Fatalf("error reading the repository, %v", err)
+			}
+			var gotImages []string
+			for _, imageUsage := range images {
+				gotImages = append(gotImages, imageUsage.name)
+			}
+			sort.Strings(gotImages)
+			if diff := cmp.Diff(tst.wantImages, gotImages); diff != "" {
+				t.Errorf("invalid images after prune (-want, +got)\n%v", diff)
+			}
+			gotTempDir, _ := util.FileExists(filepath.Join(tempDir, "cellery-cell-image123"))
+			if gotTempDir != tst.wantTempDir {
+				t.Errorf("expected temp dir to exist: %v, got %v", tst.wantTempDir, gotTempDir)
+			}
+		})
+	}
+}
+
+// unreachableKubeCli fails to list the running instances.
+type unreachableKubeCli struct {
+	*test.MockKubeCli
+}
+
+func (unreachableKubeCli) GetCells() ([]kubernetes.Cell, error) {
+	return nil, fmt.Errorf("unable to connect to the server")
+}
+
+func TestRunPruneWithoutRunningInstances(t *testing.T) {
+	repo, tempDir, cleanup := newTestCelleryHome(t, time.Now().Add(-2*time.Hour))
+	defer cleanup()
+	mockCli := test.NewMockCli(
+		test.SetFileSystem(test.NewMockFileSystem(test.SetRepository(repo), test.SetTempDir(tempDir))),
+		test.SetKubeCli(unreachableKubeCli{test.NewMockKubeCli()}))
+	if err := RunPrune(mockCli, PrunePolicy{Unused: true}, false, true); err == nil {
+		t.Errorf("RunPrune: expected an error when the running instances cannot be found")
+	}
+	if err := RunPrune(mockCli, PrunePolicy{KeepLast: 1}, false, true); err != nil {
+		t.Fatalf("error in RunPrune, %v", err)
+	}
+	images, err := repositoryUsage(mockCli)
+	if err != nil {
+		t.Fatalf("error reading the repository, %v", err)
+	}
+	if len(images) != 6 {
+		t.Errorf("RunPrune: expected the images to be skipped, got %d images", len(images))
+	}
+	if gotTempDir, _ := util.FileExists(filepath.Join(tempDir, "cellery-cell-image123")); gotTempDir {
+		t.Errorf("RunPrune: expected the temp dir to be removed")
+	}
+}
@@ -75,6 +75,9 @@ const BalInitTestExecFIle = "init-project.sh"
 const Wso2ApimHost = "https://wso2-apim-gateway"
 
 const CelleryImageDirEnvVar = "CELLERY_IMAGE_DIR"
+const KubeClientEnvVar = "CELLERY_KUBE_CLIENT"
+const KubeClientKubectl = "kubectl"
+const KubeClientApi = "api"
 
 const RootDir = "/"
 const VAR = "var"
@@ -24,6 +24,9 @@ import (
 )
 
 func IsCellInstanceNotFoundError(srcInst string, cellErr error) (bool, error) {
+	if notFoundErr, ok := cellErr.(NotFoundError); ok {
+		return notFoundErr.Kind == "cell" && notFoundErr.Name == srcInst, nil
+	}
 	matches, err := regexp.MatchString(buildCellInstanceNonExistErrorMatcher(srcInst),
 		cellErr.Error())
 	if err != nil {
@@ -37,6 +40,9 @@ func buildCellInstanceNonExistErrorMatcher(name string) string {
 }
 
 func IsCompositeInstanceNotFoundError(srcInst string, compositeErr error) (bool, error) {
+	if notFoundErr, ok := compositeErr.(NotFoundError); ok {
+		return notFoundErr.Kind == "composite" && notFoundErr.Name == srcInst, nil
+	}
 	matches, err := regexp.MatchString(buildCompositeInstanceNonExistErrorMatcher(srcInst),
 		compositeErr.Error())
 	if err != nil {
@@ -60,3 +66,19 @@ type CellGwApiVersionMismatchError struct {
 func (err CellGwApiVersionMismatchError) Error() string {
 	return fmt.Sprintf("Version mismatch between gateway APIs exposed in instances %s and %s", err.CurrentTargetInstance, err.NewTargetInstance)
 }
+
+// NotFoundError is returned by the Kubernetes clients when a requested resource does not exist.
+type NotFoundError struct {
+	Kind string
+	Name string
+}
+
+func (err NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", err.Kind, err.Name)
+}
+
+// IsNotFoundError checks whether the given error is a NotFoundError for the given kind.
+func IsNotFoundError(kind string, err error) bool {
+	notFoundErr, ok := err.(NotFoundError)
+	return ok && notFoundErr.Kind == kind
+}
@@ -143,3 +143,37 @@ func ValidateImageTagWithRegistry(imageTag string) error {
 
 	return nil
 }
+
+// ParseImageRepository parses the given image repository string of the form
+// [<registry>/]<organization>/<cell-image> and returns a CellImage struct without a version.
+func ParseImageRepository(repository string) (*CellImage, error) {
+	r := regexp.MustCompile("^(?:([^/]*)/)?([^/:]*)/([^/:]*)$")
+	subMatch := r.FindStringSubmatch(repository)
+	if subMatch == nil {
+		return nil, fmt.Errorf("expects [<registry>/]<organization>/<cell-image> as the image, received %s",
+			repository)
+	}
+	cellImage := &CellImage{
+		Registry:     constants.CentralRegistryHost,
+		Organization: subMatch[2],
+		ImageName:    subMatch[3],
+	}
+	if subMatch[1] != "" {
+		isValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.DomainNamePattern), subMatch[1])
+		if err != nil || !isValid {
+			return nil, fmt.Errorf("expects a valid URL as the registry, received %s", subMatch[1])
+		}
+		cellImage.Registry = subMatch[1]
+	}
+	isValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), cellImage.Organization)
+	if err != nil || !isValid {
+		return nil, fmt.Errorf("expects a valid organization name (lower case letters, numbers and dashes "+
+			"with only letters and numbers at the begining and end), received %s", cellImage.Organization)
+	}
+	isValid, err = regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), cellImage.ImageName)
+	if err != nil || !isValid {
+		return nil, fmt.Errorf("expects a valid image name (lower case letters, numbers and dashes "+
+			"with only letters and numbers at the begining and end), received %s", cellImage.ImageName)
+	}
+	return cellImage, nil
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+
+	"cellery.io/cellery/components/cli/pkg/constants"
+)
+
+// ExecInContainer is not supported as streaming the input and the output of the command requires upgrading the
+// connection to the API server
+func (kubeCli *CelleryApiKubeCli) ExecInContainer(pod, container string, tty bool, command []string) error {
+	return fmt.Errorf("executing commands in containers is not supported by the native kubernetes client, "+
+		"use kubectl instead by setting %s=%s", constants.KubeClientEnvVar, constants.KubeClientKubectl)
+}
+
+// PortForward is not supported as forwarding the ports requires upgrading the connection to the API server
+func (kubeCli *CelleryApiKubeCli) PortForward(pod string, ports []string) error {
+	return fmt.Errorf("forwarding ports is not supported by the native kubernetes client, use kubectl instead "+
+		"by setting %s=%s", constants.KubeClientEnvVar, constants.KubeClientKubectl)
+}
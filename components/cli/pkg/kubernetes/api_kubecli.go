@@ -0,0 +1,379 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"cellery.io/cellery/components/cli/pkg/constants"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+const contentTypeJson = "application/json"
+const contentTypeJsonPatch = "application/json-patch+json"
+const contentTypeMergePatch = "application/merge-patch+json"
+const istioNetworkingGroup = "networking.istio.io"
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// CelleryApiKubeCli is a KubeCli which talks to the Kubernetes API server directly instead of
+// shelling out to kubectl. The API server and the credentials are read from the kubeconfig file.
+type CelleryApiKubeCli struct {
+	kubeConfigPath string
+	httpClient     *http.Client
+	server         string
+	namespace      string
+	authorize      func(req *http.Request) error
+	initOnce       sync.Once
+	initErr        error
+	discoveryLock  sync.Mutex
+	groupVersions  map[string]string
+	discovery      map[string][]apiResource
+}
+
+// apiResource describes a resource served by the API server.
+type apiResource struct {
+	Group      string
+	Version    string
+	Name       string
+	Kind       string
+	Namespaced bool
+	Aliases    []string
+}
+
+type apiGroupList struct {
+	Groups []struct {
+		Name             string `json:"name"`
+		PreferredVersion struct {
+			GroupVersion string `json:"groupVersion"`
+			Version      string `json:"version"`
+		} `json:"preferredVersion"`
+	} `json:"groups"`
+}
+
+type apiResourceList struct {
+	GroupVersion string `json:"groupVersion"`
+	Resources    []struct {
+		Name         string   `json:"name"`
+		SingularName string   `json:"singularName"`
+		Kind         string   `json:"kind"`
+		Namespaced   bool     `json:"namespaced"`
+		ShortNames   []string `json:"shortNames"`
+	} `json:"resources"`
+}
+
+type apiStatus struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+	Code    int    `json:"code"`
+}
+
+// NewCelleryApiKubeCli returns a CelleryApiKubeCli instance.
+// The kubeconfig is loaded lazily upon the first request sent to the API server.
+func NewCelleryApiKubeCli(opts ...func(*CelleryApiKubeCli)) *CelleryApiKubeCli {
+	kubeCli := &CelleryApiKubeCli{
+		kubeConfigPath: defaultKubeConfigPath(),
+		discovery:      map[string][]apiResource{},
+	}
+	for _, opt := range opts {
+		opt(kubeCli)
+	}
+	return kubeCli
+}
+
+// SetKubeConfigPath sets the kubeconfig file used to connect to the API server.
+func SetKubeConfigPath(path string) func(*CelleryApiKubeCli) {
+	return func(kubeCli *CelleryApiKubeCli) {
+		kubeCli.kubeConfigPath = path
+	}
+}
+
+func (kubeCli *CelleryApiKubeCli) SetVerboseMode(enable bool) {
+	verboseMode = enable
+}
+
+func (kubeCli *CelleryApiKubeCli) init() error {
+	kubeCli.initOnce.Do(func() {
+		kubeCli.initErr = kubeCli.loadCurrentContext()
+	})
+	return kubeCli.initErr
+}
+
+func (kubeCli *CelleryApiKubeCli) newRequest(method, path string, query url.Values, contentType string,
+	body []byte) (*http.Request, error) {
+	if err := kubeCli.init(); err != nil {
+		return nil, err
+	}
+	requestUrl := strings.TrimSuffix(kubeCli.server, "/") + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", contentTypeJson)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if kubeCli.authorize != nil {
+		if err := kubeCli.authorize(req); err != nil {
+			return nil, err
+		}
+	}
+	displayVerboseRequest(req)
+	return req, nil
+}
+
+// do sends a request to the API server and returns the response body.
+// Error responses are converted to errors with the message returned by the API server.
+func (kubeCli *CelleryApiKubeCli) do(method, path string, query url.Values, contentType string,
+	body []byte) ([]byte, error) {
+	resp, err := kubeCli.send(method, path, query, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// send sends a request to the API server and returns the response if it was successful.
+// The caller is responsible for closing the response body.
+func (kubeCli *CelleryApiKubeCli) send(method, path string, query url.Values, contentType string,
+	body []byte) (*http.Response, error) {
+	req, err := kubeCli.newRequest(method, path, query, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := kubeCli.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	status := apiStatus{}
+	if err := json.Unmarshal(respBody, &status); err != nil || status.Message == "" {
+		status.Message = strings.TrimSpace(string(respBody))
+	}
+	return nil, &apiError{statusCode: resp.StatusCode, reason: status.Reason, message: status.Message}
+}
+
+// apiError is an error response returned by the API server.
+type apiError struct {
+	statusCode int
+	reason     string
+	message    string
+}
+
+func (err *apiError) Error() string {
+	if err.message == "" {
+		return fmt.Sprintf("request failed with status %d", err.statusCode)
+	}
+	return err.message
+}
+
+func isApiNotFoundError(err error) bool {
+	apiErr, ok := err.(*apiError)
+	return ok && apiErr.statusCode == http.StatusNotFound
+}
+
+// notFoundOr converts a not found response of the API server to a typed NotFoundError.
+func notFoundOr(err error, resource apiResource, name string) error {
+	if isApiNotFoundError(err) {
+		return errorpkg.NotFoundError{Kind: strings.ToLower(resource.Kind), Name: name}
+	}
+	return err
+}
+
+// resolveResource finds the resource served by the API server for a resource name, singular name,
+// short name or kind. A name qualified with a group (eg:- cells.mesh.cellery.io) is only looked up in
+// that group. Otherwise the core group, the Cellery group and then all other groups are searched in order.
+func (kubeCli *CelleryApiKubeCli) resolveResource(name string) (apiResource, error) {
+	name = strings.ToLower(name)
+	var groups []string
+	if parts := strings.SplitN(name, ".", 2); len(parts) == 2 {
+		name = parts[0]
+		groups = []string{parts[1]}
+	} else {
+		groupVersions, err := kubeCli.preferredGroupVersions()
+		if err != nil {
+			return apiResource{}, err
+		}
+		var otherGroups []string
+		for group := range groupVersions {
+			if group != constants.GroupName && group != istioNetworkingGroup {
+				otherGroups = append(otherGroups, group)
+			}
+		}
+		sort.Strings(otherGroups)
+		groups = append([]string{"", constants.GroupName, istioNetworkingGroup}, otherGroups...)
+	}
+	for _, group := range groups {
+		resources, err := kubeCli.discoverGroup(group)
+		if err != nil {
+			return apiResource{}, err
+		}
+		for _, resource := range resources {
+			if resource.Name == name || strings.ToLower(resource.Kind) == name ||
+				util.ContainsInStringArray(resource.Aliases, name) {
+				return resource, nil
+			}
+		}
+	}
+	return apiResource{}, fmt.Errorf("the server doesn't have a resource type \"%s\"", name)
+}
+
+// preferredGroupVersions returns the preferred version of each API group served by the API server.
+func (kubeCli *CelleryApiKubeCli) preferredGroupVersions() (map[string]string, error) {
+	kubeCli.discoveryLock.Lock()
+	defer kubeCli.discoveryLock.Unlock()
+	if kubeCli.groupVersions != nil {
+		return kubeCli.groupVersions, nil
+	}
+	out, err := kubeCli.do(http.MethodGet, "/apis", nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	groupList := apiGroupList{}
+	if err := json.Unmarshal(out, &groupList); err != nil {
+		return nil, err
+	}
+	groupVersions := map[string]string{}
+	for _, group := range groupList.Groups {
+		groupVersions[group.Name] = group.PreferredVersion.Version
+	}
+	kubeCli.groupVersions = groupVersions
+	return groupVersions, nil
+}
+
+// discoverGroup returns the resources of the preferred version of a group. Results are cached.
+func (kubeCli *CelleryApiKubeCli) discoverGroup(group string) ([]apiResource, error) {
+	version := "v1"
+	path := "/api/v1"
+	if group != "" {
+		groupVersions, err := kubeCli.preferredGroupVersions()
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if version, ok = groupVersions[group]; !ok {
+			return nil, nil
+		}
+		path = "/apis/" + group + "/" + version
+	}
+	kubeCli.discoveryLock.Lock()
+	defer kubeCli.discoveryLock.Unlock()
+	if resources, ok := kubeCli.discovery[group]; ok {
+		return resources, nil
+	}
+	out, err := kubeCli.do(http.MethodGet, path, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	resourceList := apiResourceList{}
+	if err := json.Unmarshal(out, &resourceList); err != nil {
+		return nil, err
+	}
+	var resources []apiResource
+	for _, r := range resourceList.Resources {
+		// Sub resources such as pods/log are not addressable by name
+		if strings.Contains(r.Name, "/") {
+			continue
+		}
+		resources = append(resources, apiResource{
+			Group:      group,
+			Version:    version,
+			Name:       r.Name,
+			Kind:       r.Kind,
+			Namespaced: r.Namespaced,
+			Aliases:    append(r.ShortNames, r.SingularName),
+		})
+	}
+	kubeCli.discovery[group] = resources
+	return resources, nil
+}
+
+// resourcePath builds the API path of a resource. The name is omitted to build the collection path.
+func (kubeCli *CelleryApiKubeCli) resourcePath(resource apiResource, name string) string {
+	path := "/api/" + resource.Version
+	if resource.Group != "" {
+		path = "/apis/" + resource.Group + "/" + resource.Version
+	}
+	if resource.Namespaced {
+		path += "/namespaces/" + kubeCli.namespace
+	}
+	path += "/" + resource.Name
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
+
+func (kubeCli *CelleryApiKubeCli) getResourceBytes(resourceName, name string) ([]byte, apiResource, error) {
+	resource, err := kubeCli.resolveResource(resourceName)
+	if err != nil {
+		return nil, resource, err
+	}
+	out, err := kubeCli.do(http.MethodGet, kubeCli.resourcePath(resource, name), nil, "", nil)
+	if err != nil {
+		return nil, resource, notFoundOr(err, resource, name)
+	}
+	return out, resource, nil
+}
+
+func (kubeCli *CelleryApiKubeCli) listResourceBytes(resourceName, labelSelector string) ([]byte, error) {
+	return kubeCli.listResourceBytesWithFieldSelector(resourceName, labelSelector, "")
+}
+
+func (kubeCli *CelleryApiKubeCli) listResourceBytesWithFieldSelector(resourceName, labelSelector,
+	fieldSelector string) ([]byte, error) {
+	resource, err := kubeCli.resolveResource(resourceName)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+	if fieldSelector != "" {
+		query.Set("fieldSelector", fieldSelector)
+	}
+	return kubeCli.do(http.MethodGet, kubeCli.resourcePath(resource, ""), query, "", nil)
+}
+
+func displayVerboseRequest(req *http.Request) {
+	// If running on verbose mode expose the API server requests.
+	if verboseMode {
+		fmt.Println(verboseColor(">> " + req.Method + " " + req.URL.String()))
+	}
+}
@@ -0,0 +1,428 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+)
+
+const fakeApiGroups = `{"groups":[
+	{"name":"mesh.cellery.io","preferredVersion":{"groupVersion":"mesh.cellery.io/v1alpha2","version":"v1alpha2"}},
+	{"name":"networking.istio.io","preferredVersion":{"groupVersion":"networking.istio.io/v1alpha3","version":"v1alpha3"}},
+	{"name":"metrics.k8s.io","preferredVersion":{"groupVersion":"metrics.k8s.io/v1beta1","version":"v1beta1"}}
+]}`
+
+const fakeCoreResources = `{"groupVersion":"v1","resources":[
+	{"name":"pods","singularName":"","namespaced":true,"kind":"Pod","shortNames":["po"]},
+	{"name":"pods/log","singularName":"","namespaced":true,"kind":"Pod"},
+	{"name":"events","singularName":"","namespaced":true,"kind":"Event","shortNames":["ev"]},
+	{"name":"namespaces","singularName":"","namespaced":false,"kind":"Namespace","shortNames":["ns"]}
+]}`
+
+const fakeCelleryResources = `{"groupVersion":"mesh.cellery.io/v1alpha2","resources":[
+	{"name":"cells","singularName":"cell","namespaced":true,"kind":"Cell"},
+	{"name":"composites","singularName":"composite","namespaced":true,"kind":"Composite"}
+]}`
+
+const fakeMetricsResources = `{"groupVersion":"metrics.k8s.io/v1beta1","resources":[
+	{"name":"pods","singularName":"","namespaced":true,"kind":"PodMetrics"},
+	{"name":"nodes","singularName":"","namespaced":false,"kind":"NodeMetrics"}
+]}`
+
+// newFakeApiServer starts an API server serving the employee cell in the cellery namespace.
+func newFakeApiServer(t *testing.T, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /apis":
+			fmt.Fprint(w, fakeApiGroups)
+		case "GET /api/v1":
+			fmt.Fprint(w, fakeCoreResources)
+		case "GET /apis/mesh.cellery.io/v1alpha2":
+			fmt.Fprint(w, fakeCelleryResources)
+		case "GET /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells":
+			fmt.Fprint(w, `{"items":[{"metadata":{"name":"employee"}}]}`)
+		case "GET /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells/employee":
+			fmt.Fprint(w, `{"metadata":{"name":"employee"},"status":{"status":"Ready"}}`)
+		case "GET /apis/metrics.k8s.io/v1beta1":
+			fmt.Fprint(w, fakeMetricsResources)
+		case "GET /apis/metrics.k8s.io/v1beta1/namespaces/cellery/pods":
+			if r.URL.Query().Get("labelSelector") != "mesh.cellery.io/cell=employee" {
+				fmt.Fprint(w, `{"items":[]}`)
+				return
+			}
+			fmt.Fprint(w, `{"items":[{"metadata":{"name":"employee--salary-deployment-5c7b9d8f6-mz7rt"},`+
+				`"containers":[{"name":"salary","usage":{"cpu":"12m","memory":"24Mi"}}]}]}`)
+		case "GET /api/v1/namespaces/cellery/pods/employee--salary-deployment-5c7b9d8f6-mz7rt/log":
+			fmt.Fprintf(w, "started\n%s\n", strings.Repeat("x", 100*1024))
+		case "GET /api/v1/namespaces/cellery/events":
+			if selector := r.URL.Query().Get("fieldSelector"); selector != "" &&
+				selector != "involvedObject.kind=Cell,involvedObject.name=employee" {
+				fmt.Fprint(w, `{"items":[]}`)
+				return
+			}
+			fmt.Fprint(w, `{"items":[{"involvedObject":{"kind":"Cell","name":"employee"},"type":"Normal",`+
+				`"reason":"Created","count":1}]}`)
+		case "PATCH /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells/employee",
+			"PUT /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells/employee",
+			"POST /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells",
+			"DELETE /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells/employee":
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","message":"not found","reason":"NotFound","code":404}`)
+		}
+	}))
+}
+
+func newTestApiKubeCli(t *testing.T, server string) *CelleryApiKubeCli {
+	return newTestApiKubeCliWithUser(t, server, "    token: test-token\n")
+}
+
+// newTestApiKubeCliWithUser returns a client using a kubeconfig in which the test user has the given credentials
+func newTestApiKubeCliWithUser(t *testing.T, server, user string) *CelleryApiKubeCli {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, %v", err)
+	}
+	kubeConfigPath := filepath.Join(dir, "config")
+	writeTestKubeConfig(t, kubeConfigPath, server, user)
+	return NewCelleryApiKubeCli(SetKubeConfigPath(kubeConfigPath))
+}
+
+func writeTestKubeConfig(t *testing.T, kubeConfigPath, server, user string) {
+	kubeConfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+users:
+- name: test
+  user:
+%scontexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: cellery
+`, server, user)
+	if err := ioutil.WriteFile(kubeConfigPath, []byte(kubeConfig), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig, %v", err)
+	}
+}
+
+func TestApiKubeCliGetCells(t *testing.T) {
+	var requests []string
+	server := newFakeApiServer(t, &requests)
+	defer server.Close()
+	kubeCli := newTestApiKubeCli(t, server.URL)
+	defer os.RemoveAll(filepath.Dir(kubeCli.kubeConfigPath))
+
+	cells, err := kubeCli.GetCells()
+	if err != nil {
+		t.Fatalf("error in GetCells, %v", err)
+	}
+	if len(cells) != 1 || cells[0].CellMetaData.Name != "employee" {
+		t.Errorf("GetCells: unexpected cells %v", cells)
+	}
+	cell, err := kubeCli.GetCell("employee")
+	if err != nil {
+		t.Fatalf("error in GetCell, %v", err)
+	}
+	if diff := cmp.Diff("Ready", cell.CellStatus.Status); diff != "" {
+		t.Errorf("GetCell: unexpected status (-want, +got)\n%v", diff)
+	}
+	// Discovery information should be reused across requests
+	if diff := cmp.Diff([]string{
+		"GET /apis",
+		"GET /apis/mesh.cellery.io/v1alpha2",
+		"GET /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells",
+		"GET /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells/employee",
+	}, requests); diff != "" {
+		t.Errorf("GetCell: unexpected requests (-want, +got)\n%v", diff)
+	}
+}
+
+func TestApiKubeCliNotFound(t *testing.T) {
+	var requests []string
+	server := newFakeApiServer(t, &requests)
+	defer server.Close()
+	kubeCli := newTestApiKubeCli(t, server.URL)
+	defer os.RemoveAll(filepath.Dir(kubeCli.kubeConfigPath))
+
+	_, err := kubeCli.GetCell("hr")
+	if diff := cmp.Diff(errorpkg.NotFoundError{Kind: "cell", Name: "hr"}, err); diff != "" {
+		t.Errorf("GetCell: unexpected error (-want, +got)\n%v", diff)
+	}
+	if notFound, _ := errorpkg.IsCellInstanceNotFoundError("hr", err); !notFound {
+		t.Errorf("GetCell: expected a cell instance not found error, got %v", err)
+	}
+	if diff := cmp.Diff("instance hr not available in the runtime",
+		kubeCli.IsInstanceAvailable("hr").Error()); diff != "" {
+		t.Errorf("IsInstanceAvailable: unexpected error (-want, +got)\n%v", diff)
+	}
+	if err := kubeCli.IsInstanceAvailable("employee"); err != nil {
+		t.Errorf("IsInstanceAvailable: unexpected error %v", err)
+	}
+}
+
+func TestApiKubeCliModify(t *testing.T) {
+	var requests []string
+	server := newFakeApiServer(t, &requests)
+	defer server.Close()
+	kubeCli := newTestApiKubeCli(t, server.URL)
+	defer os.RemoveAll(filepath.Dir(kubeCli.kubeConfigPath))
+
+	if err := kubeCli.JsonPatch("cell", "employee", `[]`); err != nil {
+		t.Errorf("error in JsonPatch, %v", err)
+	}
+	// The existing cell is replaced while the other cell is created
+	cellsFile := filepath.Join(filepath.Dir(kubeCli.kubeConfigPath), "cells.yaml")
+	if err := ioutil.WriteFile(cellsFile, []byte("apiVersion: mesh.cellery.io/v1alpha2\nkind: Cell\n"+
+		"metadata:\n  name: employee\n---\napiVersion: mesh.cellery.io/v1alpha2\nkind: Cell\nmetadata:\n"+
+		"  name: hr\n"), 0644); err != nil {
+		t.Fatalf("failed to write the cells file, %v", err)
+	}
+	requests = nil
+	if err := kubeCli.ReplaceFile(cellsFile); err != nil {
+		t.Errorf("error in ReplaceFile, %v", err)
+	}
+	var modifications []string
+	for _, request := range requests {
+		if !strings.HasPrefix(request, "GET ") {
+			modifications = append(modifications, request)
+		}
+	}
+	if diff := cmp.Diff([]string{"PUT /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells/employee",
+		"POST /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells"}, modifications); diff != "" {
+		t.Errorf("ReplaceFile: unexpected requests (-want, +got)\n%v", diff)
+	}
+	out, err := kubeCli.DeleteResource("cell", "employee")
+	if err != nil {
+		t.Errorf("error in DeleteResource, %v", err)
+	}
+	if diff := cmp.Diff(`cell "employee" deleted`, out); diff != "" {
+		t.Errorf("DeleteResource: unexpected output (-want, +got)\n%v", diff)
+	}
+	// Deleting a non existing resource is ignored
+	if _, err := kubeCli.DeleteResource("composite", "employee"); err != nil {
+		t.Errorf("error in DeleteResource, %v", err)
+	}
+	if err := kubeCli.SetNamespace("default"); err != nil {
+		t.Errorf("error in SetNamespace, %v", err)
+	}
+	if diff := cmp.Diff("/apis/mesh.cellery.io/v1alpha2/namespaces/default/cells",
+		kubeCli.resourcePath(apiResource{Group: "mesh.cellery.io", Version: "v1alpha2", Name: "cells",
+			Namespaced: true}, "")); diff != "" {
+		t.Errorf("SetNamespace: unexpected namespace (-want, +got)\n%v", diff)
+	}
+}
+
+func TestApiKubeCliApplyRemovesDroppedFields(t *testing.T) {
+	var patches []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /apis":
+			fmt.Fprint(w, fakeApiGroups)
+		case "GET /apis/mesh.cellery.io/v1alpha2":
+			fmt.Fprint(w, fakeCelleryResources)
+		case "GET /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells/employee":
+			// The cell was last applied with a gateway type and a label, of which the label is set by another
+			// client as well
+			fmt.Fprint(w, `{"metadata":{"name":"employee","labels":{"team":"hr","owner":"admin"},"annotations":`+
+				`{"kubectl.kubernetes.io/last-applied-configuration":"{\"metadata\":{\"name\":\"employee\",`+
+				`\"labels\":{\"team\":\"hr\"}},\"spec\":{\"gateway\":{\"type\":\"Envoy\"}}}"}},`+
+				`"spec":{"gateway":{"type":"Envoy"}}}`)
+		case "PATCH /apis/mesh.cellery.io/v1alpha2/namespaces/cellery/cells/employee":
+			patch := map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+				t.Errorf("invalid patch, %v", err)
+			}
+			patches = append(patches, patch)
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	kubeCli := newTestApiKubeCli(t, server.URL)
+	defer os.RemoveAll(filepath.Dir(kubeCli.kubeConfigPath))
+
+	// The annotation copied from the existing cell should not be included in the applied configuration
+	cellFile := filepath.Join(filepath.Dir(kubeCli.kubeConfigPath), "cell.yaml")
+	if err := ioutil.WriteFile(cellFile, []byte("metadata:\n  name: employee\n  annotations:\n"+
+		"    kubectl.kubernetes.io/last-applied-configuration: '{}'\nspec:\n  gateway: {}\n"+
+		"apiVersion: mesh.cellery.io/v1alpha2\nkind: Cell\n"), 0644); err != nil {
+		t.Fatalf("failed to write the cell file, %v", err)
+	}
+	if err := kubeCli.ApplyFile(cellFile); err != nil {
+		t.Fatalf("error in ApplyFile, %v", err)
+	}
+	if len(patches) != 1 {
+		t.Fatalf("ApplyFile: expected a single patch, got %v", patches)
+	}
+	metadata := patches[0]["metadata"].(map[string]interface{})
+	if diff := cmp.Diff(map[string]interface{}{"team": nil}, metadata["labels"]); diff != "" {
+		t.Errorf("ApplyFile: unexpected labels patch (-want, +got)\n%v", diff)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"gateway": map[string]interface{}{"type": nil}},
+		patches[0]["spec"]); diff != "" {
+		t.Errorf("ApplyFile: unexpected spec patch (-want, +got)\n%v", diff)
+	}
+	lastApplied := metadata["annotations"].(map[string]interface{})[lastAppliedConfigAnnotation]
+	if diff := cmp.Diff(`{"apiVersion":"mesh.cellery.io/v1alpha2","kind":"Cell","metadata":{"name":"employee"},`+
+		`"spec":{"gateway":{}}}`, lastApplied); diff != "" {
+		t.Errorf("ApplyFile: unexpected last applied configuration (-want, +got)\n%v", diff)
+	}
+}
+
+func TestApiKubeCliAuthProviderToken(t *testing.T) {
+	var requests []string
+	server := newFakeApiServer(t, &requests)
+	defer server.Close()
+	expired := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	kubeCli := newTestApiKubeCliWithUser(t, server.URL, fmt.Sprintf("    auth-provider:\n      name: gcp\n"+
+		"      config:\n        access-token: old-token\n        expiry: %q\n", expired))
+	defer os.RemoveAll(filepath.Dir(kubeCli.kubeConfigPath))
+
+	_, err := kubeCli.GetCells()
+	wantErrorMessagePortion := "the token of user test issued by the gcp auth provider expired at " + expired
+	if err == nil || !strings.Contains(err.Error(), wantErrorMessagePortion) {
+		t.Errorf("GetCells: expected an error containing %q, got %v", wantErrorMessagePortion, err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("GetCells: unexpected requests with an expired token %v", requests)
+	}
+	// The token refreshed by kubectl should be read again from the kubeconfig
+	writeTestKubeConfig(t, kubeCli.kubeConfigPath, server.URL, fmt.Sprintf("    auth-provider:\n"+
+		"      name: gcp\n      config:\n        access-token: test-token\n        expiry: %q\n",
+		time.Now().Add(time.Hour).Format(time.RFC3339)))
+	if _, err := kubeCli.GetCells(); err != nil {
+		t.Errorf("error in GetCells with a refreshed token, %v", err)
+	}
+}
+
+func TestApiKubeCliGetPodMetrics(t *testing.T) {
+	var requests []string
+	server := newFakeApiServer(t, &requests)
+	defer server.Close()
+	kubeCli := newTestApiKubeCli(t, server.URL)
+	defer os.RemoveAll(filepath.Dir(kubeCli.kubeConfigPath))
+
+	metrics, err := kubeCli.GetPodMetricsForCell("employee")
+	if err != nil {
+		t.Fatalf("error in GetPodMetricsForCell, %v", err)
+	}
+	want := PodMetricsList{
+		Items: []PodMetrics{
+			{
+				MetaData: PodMetaData{Name: "employee--salary-deployment-5c7b9d8f6-mz7rt"},
+				Containers: []ContainerMetrics{
+					{Name: "salary", Usage: ResourceList{"cpu": "12m", "memory": "24Mi"}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, metrics); diff != "" {
+		t.Errorf("GetPodMetricsForCell: unexpected metrics (-want, +got)\n%v", diff)
+	}
+	metrics, err = kubeCli.GetPodMetricsForComposite("employee")
+	if err != nil {
+		t.Fatalf("error in GetPodMetricsForComposite, %v", err)
+	}
+	if len(metrics.Items) != 0 {
+		t.Errorf("GetPodMetricsForComposite: unexpected metrics %v", metrics)
+	}
+}
+
+func TestApiKubeCliGetEvents(t *testing.T) {
+	var requests []string
+	server := newFakeApiServer(t, &requests)
+	defer server.Close()
+	kubeCli := newTestApiKubeCli(t, server.URL)
+	defer os.RemoveAll(filepath.Dir(kubeCli.kubeConfigPath))
+
+	// the events are filtered by the API server using the involved object
+	events, err := kubeCli.GetEvents("Cell", "employee")
+	if err != nil {
+		t.Fatalf("error in GetEvents, %v", err)
+	}
+	want := Events{
+		Items: []Event{
+			{InvolvedObject: ObjectReference{Kind: "Cell", Name: "employee"}, Type: "Normal", Reason: "Created",
+				Count: 1},
+		},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("GetEvents: unexpected events (-want, +got)\n%v", diff)
+	}
+	events, err = kubeCli.GetEvents("ReplicaSet", "")
+	if err != nil {
+		t.Fatalf("error in GetEvents, %v", err)
+	}
+	if len(events.Items) != 0 {
+		t.Errorf("GetEvents: unexpected events %v", events)
+	}
+	// all the events in the namespace are listed if the kind is empty
+	events, err = kubeCli.GetEvents("", "")
+	if err != nil {
+		t.Fatalf("error in GetEvents, %v", err)
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("GetEvents: unexpected events (-want, +got)\n%v", diff)
+	}
+}
+
+func TestApiKubeCliStreamContainerLogs(t *testing.T) {
+	var requests []string
+	server := newFakeApiServer(t, &requests)
+	defer server.Close()
+	kubeCli := newTestApiKubeCli(t, server.URL)
+	defer os.RemoveAll(filepath.Dir(kubeCli.kubeConfigPath))
+
+	var out bytes.Buffer
+	if err := kubeCli.StreamContainerLogs("employee--salary-deployment-5c7b9d8f6-mz7rt", "salary",
+		LogOptions{Tail: -1}, &out); err != nil {
+		t.Fatalf("error in StreamContainerLogs, %v", err)
+	}
+	// lines longer than the default buffer of a scanner are streamed as well
+	if want := "started\n" + strings.Repeat("x", 100*1024) + "\n"; out.String() != want {
+		t.Errorf("StreamContainerLogs: unexpected logs of %d bytes, want %d bytes", out.Len(), len(want))
+	}
+}
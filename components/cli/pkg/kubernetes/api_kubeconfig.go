@@ -0,0 +1,401 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+const defaultNamespace = "default"
+
+// kubeConfig holds the subset of the kubeconfig file needed by the API server client.
+// The file is kept as a generic map when it is modified so that unknown fields are preserved.
+type kubeConfig struct {
+	CurrentContext string `json:"current-context"`
+	Clusters       []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server                   string `json:"server"`
+			InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify,omitempty"`
+			CertificateAuthority     string `json:"certificate-authority,omitempty"`
+			CertificateAuthorityData string `json:"certificate-authority-data,omitempty"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+	Users []struct {
+		Name string `json:"name"`
+		User struct {
+			ClientCertificate     string              `json:"client-certificate,omitempty"`
+			ClientCertificateData string              `json:"client-certificate-data,omitempty"`
+			ClientKey             string              `json:"client-key,omitempty"`
+			ClientKeyData         string              `json:"client-key-data,omitempty"`
+			Token                 string              `json:"token,omitempty"`
+			TokenFile             string              `json:"tokenFile,omitempty"`
+			Username              string              `json:"username,omitempty"`
+			Password              string              `json:"password,omitempty"`
+			AuthProvider          *AuthProviderConfig `json:"auth-provider,omitempty"`
+			Exec                  *ExecConfig         `json:"exec,omitempty"`
+		} `json:"user"`
+	} `json:"users"`
+	Contexts []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster   string `json:"cluster"`
+			User      string `json:"user"`
+			Namespace string `json:"namespace,omitempty"`
+		} `json:"context"`
+	} `json:"contexts"`
+}
+
+func defaultKubeConfigPath() string {
+	if kubeConfigEnv := os.Getenv("KUBECONFIG"); kubeConfigEnv != "" {
+		return filepath.SplitList(kubeConfigEnv)[0]
+	}
+	return filepath.Join(util.UserHomeDir(), ".kube", "config")
+}
+
+func (kubeCli *CelleryApiKubeCli) readKubeConfig() (kubeConfig, []byte, error) {
+	config := kubeConfig{}
+	configYaml, err := ioutil.ReadFile(kubeCli.kubeConfigPath)
+	if err != nil {
+		return config, nil, fmt.Errorf("failed to read kubeconfig %s, %v", kubeCli.kubeConfigPath, err)
+	}
+	configJson, err := yaml.YAMLToJSON(configYaml)
+	if err != nil {
+		return config, nil, fmt.Errorf("failed to parse kubeconfig %s, %v", kubeCli.kubeConfigPath, err)
+	}
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		return config, nil, fmt.Errorf("failed to parse kubeconfig %s, %v", kubeCli.kubeConfigPath, err)
+	}
+	return config, configJson, nil
+}
+
+// updateKubeConfig applies a modification to the kubeconfig file without dropping fields unknown to
+// the client.
+func (kubeCli *CelleryApiKubeCli) updateKubeConfig(update func(config map[string]interface{}) error) error {
+	_, configJson, err := kubeCli.readKubeConfig()
+	if err != nil {
+		return err
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		return err
+	}
+	if err := update(config); err != nil {
+		return err
+	}
+	configYaml, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(kubeCli.kubeConfigPath, configYaml, 0600)
+}
+
+// loadCurrentContext configures the client to connect to the cluster of the current context.
+func (kubeCli *CelleryApiKubeCli) loadCurrentContext() error {
+	config, _, err := kubeCli.readKubeConfig()
+	if err != nil {
+		return err
+	}
+	if config.CurrentContext == "" {
+		return fmt.Errorf("current context is not set in kubeconfig %s", kubeCli.kubeConfigPath)
+	}
+	var clusterName, userName string
+	kubeCli.namespace = defaultNamespace
+	contextFound := false
+	for _, context := range config.Contexts {
+		if context.Name == config.CurrentContext {
+			contextFound = true
+			clusterName = context.Context.Cluster
+			userName = context.Context.User
+			if context.Context.Namespace != "" {
+				kubeCli.namespace = context.Context.Namespace
+			}
+		}
+	}
+	if !contextFound {
+		return fmt.Errorf("context %s not found in kubeconfig %s", config.CurrentContext, kubeCli.kubeConfigPath)
+	}
+	tlsConfig := &tls.Config{}
+	kubeCli.server = ""
+	for _, cluster := range config.Clusters {
+		if cluster.Name != clusterName {
+			continue
+		}
+		kubeCli.server = cluster.Cluster.Server
+		tlsConfig.InsecureSkipVerify = cluster.Cluster.InsecureSkipTLSVerify
+		caData, err := readDataOrFile(cluster.Cluster.CertificateAuthorityData, cluster.Cluster.CertificateAuthority)
+		if err != nil {
+			return fmt.Errorf("failed to read certificate authority of cluster %s, %v", clusterName, err)
+		}
+		if len(caData) > 0 {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(caData) {
+				return fmt.Errorf("invalid certificate authority of cluster %s", clusterName)
+			}
+		}
+	}
+	if kubeCli.server == "" {
+		return fmt.Errorf("server of cluster %s not found in kubeconfig %s", clusterName, kubeCli.kubeConfigPath)
+	}
+	kubeCli.authorize = nil
+	for _, user := range config.Users {
+		if user.Name != userName {
+			continue
+		}
+		certData, err := readDataOrFile(user.User.ClientCertificateData, user.User.ClientCertificate)
+		if err != nil {
+			return fmt.Errorf("failed to read client certificate of user %s, %v", userName, err)
+		}
+		keyData, err := readDataOrFile(user.User.ClientKeyData, user.User.ClientKey)
+		if err != nil {
+			return fmt.Errorf("failed to read client key of user %s, %v", userName, err)
+		}
+		if len(certData) > 0 && len(keyData) > 0 {
+			cert, err := tls.X509KeyPair(certData, keyData)
+			if err != nil {
+				return fmt.Errorf("invalid client certificate of user %s, %v", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		token := user.User.Token
+		if token == "" && user.User.TokenFile != "" {
+			tokenBytes, err := ioutil.ReadFile(user.User.TokenFile)
+			if err != nil {
+				return fmt.Errorf("failed to read token file of user %s, %v", userName, err)
+			}
+			token = strings.TrimSpace(string(tokenBytes))
+		}
+		var providerCredentials *authProviderCredentials
+		if token == "" && user.User.AuthProvider != nil {
+			providerCredentials = &authProviderCredentials{kubeCli: kubeCli, user: userName}
+			if err := providerCredentials.load(user.User.AuthProvider); err != nil {
+				return err
+			}
+		}
+		if token == "" && user.User.Exec != nil {
+			return fmt.Errorf("exec credential plugins of user %s are not supported by the native "+
+				"kubernetes client, use kubectl instead", userName)
+		}
+		username, password := user.User.Username, user.User.Password
+		kubeCli.authorize = func(req *http.Request) error {
+			if providerCredentials != nil {
+				providerToken, err := providerCredentials.currentToken()
+				if err != nil {
+					return err
+				}
+				req.Header.Set("Authorization", "Bearer "+providerToken)
+			} else if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			} else if username != "" {
+				req.SetBasicAuth(username, password)
+			}
+			return nil
+		}
+	}
+	kubeCli.httpClient = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	return nil
+}
+
+// authProviderCredentials holds the token cached in the kubeconfig by the auth provider (Ex: gcp, oidc) of a user.
+// The native client does not refresh the token itself, hence an expired token is read again from the kubeconfig in
+// case it was refreshed by kubectl or the tools of the provider in the meantime.
+type authProviderCredentials struct {
+	kubeCli *CelleryApiKubeCli
+	user    string
+	lock    sync.Mutex
+	name    string
+	token   string
+	expiry  time.Time
+}
+
+func (credentials *authProviderCredentials) load(provider *AuthProviderConfig) error {
+	credentials.name = provider.Name
+	if provider.Name == "oidc" {
+		credentials.token = provider.Config["id-token"]
+		credentials.expiry = jwtExpiry(credentials.token)
+	} else {
+		credentials.token = provider.Config["access-token"]
+		credentials.expiry = time.Time{}
+		if expiry := provider.Config["expiry"]; expiry != "" {
+			var err error
+			if credentials.expiry, err = time.Parse(time.RFC3339, expiry); err != nil {
+				return fmt.Errorf("invalid expiry %s of the %s auth provider of user %s, %v", expiry,
+					provider.Name, credentials.user, err)
+			}
+		}
+	}
+	if credentials.token == "" {
+		return fmt.Errorf("no token cached by the %s auth provider of user %s, run a kubectl command to "+
+			"obtain a token or use kubectl instead", provider.Name, credentials.user)
+	}
+	return nil
+}
+
+// currentToken returns the cached token, which is read again from the kubeconfig if it has expired.
+func (credentials *authProviderCredentials) currentToken() (string, error) {
+	credentials.lock.Lock()
+	defer credentials.lock.Unlock()
+	if !credentials.expired() {
+		return credentials.token, nil
+	}
+	config, _, err := credentials.kubeCli.readKubeConfig()
+	if err != nil {
+		return "", err
+	}
+	for _, user := range config.Users {
+		if user.Name == credentials.user && user.User.AuthProvider != nil {
+			if err := credentials.load(user.User.AuthProvider); err != nil {
+				return "", err
+			}
+		}
+	}
+	if credentials.expired() {
+		return "", fmt.Errorf("the token of user %s issued by the %s auth provider expired at %s, run a kubectl "+
+			"command or refresh the credentials using the tools of the provider (Ex: gcloud) to renew it",
+			credentials.user, credentials.name, credentials.expiry.Format(time.RFC3339))
+	}
+	return credentials.token, nil
+}
+
+func (credentials *authProviderCredentials) expired() bool {
+	return !credentials.expiry.IsZero() && !time.Now().Before(credentials.expiry)
+}
+
+// jwtExpiry returns the expiry of a JWT, or the zero time if the token is not a JWT with an expiry.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	claims := struct {
+		Expiry int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Expiry == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Expiry, 0)
+}
+
+// readDataOrFile returns the base64 decoded inline data if available, or else the content of the file.
+func readDataOrFile(data, file string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return ioutil.ReadFile(file)
+	}
+	return nil, nil
+}
+
+func (kubeCli *CelleryApiKubeCli) GetContext() (string, error) {
+	config, _, err := kubeCli.readKubeConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.CurrentContext == "" {
+		return "", fmt.Errorf("current-context is not set")
+	}
+	return config.CurrentContext, nil
+}
+
+func (kubeCli *CelleryApiKubeCli) GetContexts() ([]byte, error) {
+	_, configJson, err := kubeCli.readKubeConfig()
+	return configJson, err
+}
+
+func (kubeCli *CelleryApiKubeCli) UseContext(context string) error {
+	config, _, err := kubeCli.readKubeConfig()
+	if err != nil {
+		return err
+	}
+	contextFound := false
+	for _, c := range config.Contexts {
+		if c.Name == context {
+			contextFound = true
+		}
+	}
+	if !contextFound {
+		return fmt.Errorf("no context exists with the name: \"%s\"", context)
+	}
+	if err := kubeCli.updateKubeConfig(func(config map[string]interface{}) error {
+		config["current-context"] = context
+		return nil
+	}); err != nil {
+		return err
+	}
+	return kubeCli.reload()
+}
+
+func (kubeCli *CelleryApiKubeCli) SetNamespace(namespace string) error {
+	if err := kubeCli.updateKubeConfig(func(config map[string]interface{}) error {
+		contexts, _ := config["contexts"].([]interface{})
+		for _, c := range contexts {
+			namedContext, _ := c.(map[string]interface{})
+			if namedContext["name"] != config["current-context"] {
+				continue
+			}
+			context, _ := namedContext["context"].(map[string]interface{})
+			if context == nil {
+				context = map[string]interface{}{}
+				namedContext["context"] = context
+			}
+			context["namespace"] = namespace
+			return nil
+		}
+		return fmt.Errorf("current-context is not set")
+	}); err != nil {
+		return err
+	}
+	return kubeCli.reload()
+}
+
+// reload discards the connection details and the discovered resources so that they are read again
+// with the next request.
+func (kubeCli *CelleryApiKubeCli) reload() error {
+	kubeCli.discoveryLock.Lock()
+	defer kubeCli.discoveryLock.Unlock()
+	kubeCli.groupVersions = nil
+	kubeCli.discovery = map[string][]apiResource{}
+	kubeCli.initErr = kubeCli.loadCurrentContext()
+	return kubeCli.initErr
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (kubeCli *CelleryApiKubeCli) StreamContainerLogs(pod, container string, options LogOptions,
+	out io.Writer) error {
+	resource, err := kubeCli.resolveResource("pods")
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("container", container)
+	if options.Follow {
+		query.Set("follow", "true")
+	}
+	if options.Since > 0 {
+		// the API server accepts whole seconds only, hence rounded up to include the logs of the given duration
+		query.Set("sinceSeconds", strconv.Itoa(int(math.Ceil(options.Since.Seconds()))))
+	}
+	if options.Tail >= 0 {
+		query.Set("tailLines", strconv.Itoa(options.Tail))
+	}
+	if options.Timestamps {
+		query.Set("timestamps", "true")
+	}
+	resp, err := kubeCli.send(http.MethodGet, kubeCli.resourcePath(resource, pod)+"/log", query, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to get logs of container %s in pod %s, %v", container, pod, err)
+	}
+	defer resp.Body.Close()
+	// the logs are copied as they are, since the lines of the logs are not limited in length
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
@@ -0,0 +1,555 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/pkg/constants"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+)
+
+var yamlDocumentSeparator = regexp.MustCompile("(?m)^---\\s*$")
+
+func (kubeCli *CelleryApiKubeCli) GetCells() ([]Cell, error) {
+	jsonOutput := Cells{}
+	out, err := kubeCli.listResourceBytes(string(InstanceKindCell), "")
+	if err != nil {
+		return jsonOutput.Items, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput.Items, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetComposites() ([]Composite, error) {
+	jsonOutput := Composites{}
+	out, err := kubeCli.listResourceBytes(string(InstanceKindComposite), "")
+	if err != nil {
+		return jsonOutput.Items, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput.Items, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetInstancesNames() ([]string, error) {
+	var instances []string
+	runningCellInstances, err := kubeCli.GetCells()
+	if err != nil {
+		return nil, err
+	}
+	runningCompositeInstances, err := kubeCli.GetComposites()
+	if err != nil {
+		return nil, err
+	}
+	for _, runningInstance := range runningCellInstances {
+		instances = append(instances, runningInstance.CellMetaData.Name)
+	}
+	for _, runningInstance := range runningCompositeInstances {
+		instances = append(instances, runningInstance.CompositeMetaData.Name)
+	}
+	return instances, nil
+}
+
+func (kubeCli *CelleryApiKubeCli) GetCell(cellName string) (Cell, error) {
+	jsonOutput := Cell{}
+	out, _, err := kubeCli.getResourceBytes(string(InstanceKindCell), cellName)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetComposite(compositeName string) (Composite, error) {
+	jsonOutput := Composite{}
+	out, _, err := kubeCli.getResourceBytes(string(InstanceKindComposite), compositeName)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetInstanceBytes(instanceKind, InstanceName string) ([]byte, error) {
+	out, _, err := kubeCli.getResourceBytes(instanceKind, InstanceName)
+	return out, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetCellInstanceAsMapInterface(cell string) (map[string]interface{}, error) {
+	var output map[string]interface{}
+	out, _, err := kubeCli.getResourceBytes(string(InstanceKindCell), cell)
+	if err != nil {
+		return output, err
+	}
+	err = json.Unmarshal(out, &output)
+	return output, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetCompositeInstanceAsMapInterface(composite string) (map[string]interface{}, error) {
+	var output map[string]interface{}
+	out, _, err := kubeCli.getResourceBytes(string(InstanceKindComposite), composite)
+	if err != nil {
+		return output, err
+	}
+	err = json.Unmarshal(out, &output)
+	return output, err
+}
+
+func (kubeCli *CelleryApiKubeCli) Version() (string, string, error) {
+	jsonOutput := K8sVersion{}
+	out, err := kubeCli.do(http.MethodGet, "/version", nil, "", nil)
+	if err != nil {
+		return jsonOutput.ServerVersion.GitVersion, jsonOutput.ClientVersion.GitVersion, err
+	}
+	err = json.Unmarshal(out, &jsonOutput.ServerVersion)
+	// The client talks to the API server directly and is therefore compatible with the server version
+	jsonOutput.ClientVersion.GitVersion = jsonOutput.ServerVersion.GitVersion
+	return jsonOutput.ServerVersion.GitVersion, jsonOutput.ClientVersion.GitVersion, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetServices(cellName string) (Services, error) {
+	jsonOutput := Services{}
+	out, err := kubeCli.listResourceBytes("services", constants.GroupName+"/cell="+cellName)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetPodsForCell(cellName string) (Pods, error) {
+	jsonOutput := Pods{}
+	out, err := kubeCli.listResourceBytes("pods", constants.GroupName+"/cell="+cellName)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetPodsForComposite(compName string) (Pods, error) {
+	jsonOutput := Pods{}
+	out, err := kubeCli.listResourceBytes("pods", constants.GroupName+"/composite="+compName)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetPodMetricsForCell(cellName string) (PodMetricsList, error) {
+	return kubeCli.getPodMetrics(constants.GroupName + "/cell=" + cellName)
+}
+
+func (kubeCli *CelleryApiKubeCli) GetPodMetricsForComposite(compName string) (PodMetricsList, error) {
+	return kubeCli.getPodMetrics(constants.GroupName + "/composite=" + compName)
+}
+
+// getPodMetrics returns the resource usage of the pods reported by the metrics server
+func (kubeCli *CelleryApiKubeCli) getPodMetrics(labelSelector string) (PodMetricsList, error) {
+	jsonOutput := PodMetricsList{}
+	out, err := kubeCli.listResourceBytes(podMetrics, labelSelector)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+// GetEvents returns the events of the objects of a kind in the current namespace, which are limited to the events
+// of the object with the given name if the name is not empty. All the events are returned if the kind is empty.
+func (kubeCli *CelleryApiKubeCli) GetEvents(involvedObjectKind string, involvedObjectName string) (Events, error) {
+	jsonOutput := Events{}
+	out, err := kubeCli.listResourceBytesWithFieldSelector("events", "",
+		involvedObjectSelector(involvedObjectKind, involvedObjectName))
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *CelleryApiKubeCli) GetVirtualService(vs string) (VirtualService, error) {
+	jsonOutput := VirtualService{}
+	out, _, err := kubeCli.getResourceBytes("virtualservices."+istioNetworkingGroup, vs)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *CelleryApiKubeCli) IsInstanceAvailable(instanceName string) error {
+	if _, err := kubeCli.GetCell(instanceName); err == nil {
+		return nil
+	} else if !errorpkg.IsNotFoundError("cell", err) {
+		return fmt.Errorf("failed to check available Cells, %v", err)
+	}
+	if _, err := kubeCli.GetComposite(instanceName); err == nil {
+		return nil
+	} else if !errorpkg.IsNotFoundError("composite", err) {
+		return fmt.Errorf("failed to check available Composites, %v", err)
+	}
+	return fmt.Errorf("instance %s not available in the runtime", instanceName)
+}
+
+func (kubeCli *CelleryApiKubeCli) IsComponentAvailable(instanceName, componentName string) error {
+	_, _, err := kubeCli.getResourceBytes("components."+constants.GroupName, instanceName+"--"+componentName)
+	if err != nil {
+		if errorpkg.IsNotFoundError("component", err) {
+			return fmt.Errorf("component %s not found", componentName)
+		}
+		return fmt.Errorf("unknown error: %v", err)
+	}
+	return nil
+}
+
+func (kubeCli *CelleryApiKubeCli) GetMasterNodeName() (string, error) {
+	jsonOutput := &Node{}
+	out, err := kubeCli.listResourceBytes("nodes", "node-role.kubernetes.io/master")
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(out, jsonOutput); err != nil {
+		return "", err
+	}
+	if len(jsonOutput.Items) > 0 {
+		return jsonOutput.Items[0].Metadata.Name, nil
+	}
+	return "", fmt.Errorf("node with master role does not exist")
+}
+
+func (kubeCli *CelleryApiKubeCli) GetNamespace(namespace string) ([]byte, error) {
+	out, _, err := kubeCli.getResourceBytes("namespaces", namespace)
+	return out, err
+}
+
+func (kubeCli *CelleryApiKubeCli) CreateNamespace(namespace string) error {
+	resource, err := kubeCli.resolveResource("namespaces")
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]string{
+			"name": namespace,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.do(http.MethodPost, kubeCli.resourcePath(resource, ""), nil, contentTypeJson, body)
+	return err
+}
+
+func (kubeCli *CelleryApiKubeCli) JsonPatch(kind, instance, jsonPatch string) error {
+	resource, err := kubeCli.resolveResource(kind)
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.do(http.MethodPatch, kubeCli.resourcePath(resource, instance), nil, contentTypeJsonPatch,
+		[]byte(jsonPatch))
+	return notFoundOr(err, resource, instance)
+}
+
+func (kubeCli *CelleryApiKubeCli) ApplyLabel(itemType, itemName, labelName string, overWrite bool) error {
+	resource, err := kubeCli.resolveResource(itemType)
+	if err != nil {
+		return err
+	}
+	label := strings.SplitN(labelName, "=", 2)
+	if len(label) != 2 {
+		return fmt.Errorf("invalid label %s, expected <key>=<value>", labelName)
+	}
+	if !overWrite {
+		out, err := kubeCli.do(http.MethodGet, kubeCli.resourcePath(resource, itemName), nil, "", nil)
+		if err != nil {
+			return notFoundOr(err, resource, itemName)
+		}
+		current := struct {
+			Metadata struct {
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		}{}
+		if err := json.Unmarshal(out, &current); err != nil {
+			return err
+		}
+		if value, ok := current.Metadata.Labels[label[0]]; ok && value != label[1] {
+			return fmt.Errorf("'%s' already has a value (%s), and overwrite is false", label[0], value)
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{
+				label[0]: label[1],
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.do(http.MethodPatch, kubeCli.resourcePath(resource, itemName), nil, contentTypeMergePatch,
+		patch)
+	return notFoundOr(err, resource, itemName)
+}
+
+func (kubeCli *CelleryApiKubeCli) ApplyFile(file string) error {
+	return forEachObjectInFile(file, kubeCli.applyObject)
+}
+
+// ReplaceFile creates the objects in the file if they do not exist or else replaces the existing objects.
+func (kubeCli *CelleryApiKubeCli) ReplaceFile(file string) error {
+	return forEachObjectInFile(file, kubeCli.replaceObject)
+}
+
+// forEachObjectInFile calls the function with the json of each object declared in a yaml file
+func forEachObjectInFile(file string, fn func(objectJson []byte) error) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	for _, document := range yamlDocumentSeparator.Split(string(content), -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		documentJson, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return fmt.Errorf("failed to parse %s, %v", file, err)
+		}
+		if bytes.Equal(documentJson, []byte("null")) {
+			continue
+		}
+		if err := fn(documentJson); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyObject creates the object if it does not exist or else merges it with the existing object. The fields
+// dropped from the object since it was last applied are removed from the existing object.
+func (kubeCli *CelleryApiKubeCli) applyObject(objectJson []byte) error {
+	object := struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Items []json.RawMessage `json:"items"`
+	}{}
+	if err := json.Unmarshal(objectJson, &object); err != nil {
+		return err
+	}
+	if object.Kind == "List" {
+		for _, item := range object.Items {
+			if err := kubeCli.applyObject(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	group := ""
+	if parts := strings.SplitN(object.APIVersion, "/", 2); len(parts) == 2 {
+		group = parts[0]
+	}
+	resourceName := strings.ToLower(object.Kind)
+	if group != "" {
+		resourceName += "." + group
+	}
+	resource, err := kubeCli.resolveResource(resourceName)
+	if err != nil {
+		return err
+	}
+	appliedObject, err := withLastAppliedConfig(objectJson)
+	if err != nil {
+		return err
+	}
+	existingJson, err := kubeCli.do(http.MethodGet, kubeCli.resourcePath(resource, object.Metadata.Name), nil, "",
+		nil)
+	if isApiNotFoundError(err) {
+		appliedJson, err := json.Marshal(appliedObject)
+		if err != nil {
+			return err
+		}
+		_, err = kubeCli.do(http.MethodPost, kubeCli.resourcePath(resource, ""), nil, contentTypeJson, appliedJson)
+		return err
+	} else if err != nil {
+		return err
+	}
+	existing := struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(existingJson, &existing); err != nil {
+		return err
+	}
+	if lastAppliedJson := existing.Metadata.Annotations[lastAppliedConfigAnnotation]; lastAppliedJson != "" {
+		lastApplied := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(lastAppliedJson), &lastApplied); err != nil {
+			return fmt.Errorf("invalid %s annotation of %s %s, %v", lastAppliedConfigAnnotation, object.Kind,
+				object.Metadata.Name, err)
+		}
+		removeDroppedFields(lastApplied, appliedObject)
+	}
+	patchJson, err := json.Marshal(appliedObject)
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.do(http.MethodPatch, kubeCli.resourcePath(resource, object.Metadata.Name), nil,
+		contentTypeMergePatch, patchJson)
+	return err
+}
+
+// withLastAppliedConfig returns the object along with an annotation holding the object itself, as done by
+// kubectl apply. The annotation is used to find the fields dropped from the object when it is applied again.
+func withLastAppliedConfig(objectJson []byte) (map[string]interface{}, error) {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(objectJson, &object); err != nil {
+		return nil, err
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		object["metadata"] = metadata
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	// the annotation copied from an existing object is not a part of the applied configuration
+	delete(annotations, lastAppliedConfigAnnotation)
+	if len(annotations) == 0 {
+		delete(metadata, "annotations")
+	}
+	lastAppliedJson, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+	}
+	metadata["annotations"] = annotations
+	annotations[lastAppliedConfigAnnotation] = string(lastAppliedJson)
+	return object, nil
+}
+
+// removeDroppedFields sets the fields of the last applied object which are not found in the object to null, so
+// that they are removed by a merge patch. The fields of a dropped object are removed one by one to keep the fields
+// set by other clients, while lists are replaced as a whole by a merge patch.
+func removeDroppedFields(lastApplied, object map[string]interface{}) {
+	for key, lastValue := range lastApplied {
+		lastFields, lastIsObject := lastValue.(map[string]interface{})
+		value, found := object[key]
+		if !found {
+			if lastIsObject {
+				fields := map[string]interface{}{}
+				removeDroppedFields(lastFields, fields)
+				object[key] = fields
+			} else {
+				object[key] = nil
+			}
+			continue
+		}
+		fields, isObject := value.(map[string]interface{})
+		if lastIsObject && isObject {
+			removeDroppedFields(lastFields, fields)
+		}
+	}
+}
+
+// replaceObject creates the object if it does not exist or else replaces the existing object.
+func (kubeCli *CelleryApiKubeCli) replaceObject(objectJson []byte) error {
+	object := struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(objectJson, &object); err != nil {
+		return err
+	}
+	resourceName := strings.ToLower(object.Kind)
+	if parts := strings.SplitN(object.APIVersion, "/", 2); len(parts) == 2 {
+		resourceName += "." + parts[0]
+	}
+	resource, err := kubeCli.resolveResource(resourceName)
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.do(http.MethodGet, kubeCli.resourcePath(resource, object.Metadata.Name), nil, "", nil)
+	if isApiNotFoundError(err) {
+		_, err = kubeCli.do(http.MethodPost, kubeCli.resourcePath(resource, ""), nil, contentTypeJson, objectJson)
+		return err
+	} else if err != nil {
+		return err
+	}
+	_, err = kubeCli.do(http.MethodPut, kubeCli.resourcePath(resource, object.Metadata.Name), nil,
+		contentTypeJson, objectJson)
+	return err
+}
+
+func (kubeCli *CelleryApiKubeCli) DeleteResource(kind, instance string) (string, error) {
+	resource, err := kubeCli.resolveResource(kind)
+	if err != nil {
+		return err.Error(), err
+	}
+	_, err = kubeCli.do(http.MethodDelete, kubeCli.resourcePath(resource, instance), nil, "", nil)
+	if isApiNotFoundError(err) {
+		// Equivalent of --ignore-not-found
+		return "", nil
+	} else if err != nil {
+		return err.Error(), err
+	}
+	return fmt.Sprintf("%s \"%s\" deleted", strings.ToLower(resource.Kind), instance), nil
+}
+
+func (kubeCli *CelleryApiKubeCli) deleteCollection(kind string) error {
+	resource, err := kubeCli.resolveResource(kind)
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.do(http.MethodDelete, kubeCli.resourcePath(resource, ""), nil, "", nil)
+	return err
+}
+
+func (kubeCli *CelleryApiKubeCli) DeleteAllCells() error {
+	return kubeCli.deleteCollection(string(InstanceKindCell))
+}
+
+func (kubeCli *CelleryApiKubeCli) DeleteAllComposites() error {
+	return kubeCli.deleteCollection(string(InstanceKindComposite))
+}
+
+func (kubeCli *CelleryApiKubeCli) DeletePersistedVolume(persistedVolume string) error {
+	_, err := kubeCli.DeleteResource("persistentvolumes", persistedVolume)
+	return err
+}
+
+func (kubeCli *CelleryApiKubeCli) DeleteNameSpace(nameSpace string) error {
+	_, err := kubeCli.DeleteResource("namespaces", nameSpace)
+	return err
+}
@@ -21,6 +21,9 @@ package kubernetes
 import (
 	"os"
 	"os/exec"
+	"strings"
+
+	"cellery.io/cellery/components/cli/pkg/osexec"
 )
 
 func ApplyFileWithNamespace(file, namespace string) error {
@@ -59,3 +62,28 @@ func (kubeCli *CelleryKubeCli) ApplyFile(file string) error {
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
+
+// ReplaceFile creates the resources in the file, or replaces them if they already exist. Unlike ApplyFile, the
+// content is not copied to the last applied configuration annotation, which is limited in size.
+func (kubeCli *CelleryKubeCli) ReplaceFile(file string) error {
+	cmd := exec.Command(
+		kubectl,
+		"create",
+		"-f",
+		file,
+	)
+	displayVerboseOutput(cmd)
+	out, err := osexec.GetCommandOutput(cmd)
+	if err == nil || !strings.Contains(out, "AlreadyExists") {
+		return err
+	}
+	cmd = exec.Command(
+		kubectl,
+		"replace",
+		"-f",
+		file,
+	)
+	displayVerboseOutput(cmd)
+	_, err = osexec.GetCommandOutput(cmd)
+	return err
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"os/exec"
+)
+
+func (kubeCli *CelleryKubeCli) ExecInContainer(pod, container string, tty bool, command []string) error {
+	cmd := exec.Command(kubectl,
+		"exec",
+		"-i",
+		pod,
+	)
+	// the default container of the pod is used if the container is not given
+	if container != "" {
+		cmd.Args = append(cmd.Args, "-c", container)
+	}
+	if tty {
+		cmd.Args = append(cmd.Args, "-t")
+	}
+	cmd.Args = append(cmd.Args, "--")
+	cmd.Args = append(cmd.Args, command...)
+	displayVerboseOutput(cmd)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (kubeCli *CelleryKubeCli) PortForward(pod string, ports []string) error {
+	cmd := exec.Command(kubectl,
+		"port-forward",
+		"pod/"+pod,
+	)
+	cmd.Args = append(cmd.Args, ports...)
+	displayVerboseOutput(cmd)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
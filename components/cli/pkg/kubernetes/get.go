@@ -216,6 +216,64 @@ func (kubeCli *CelleryKubeCli) GetPodsForComposite(compName string) (Pods, error
 	return jsonOutput, err
 }
 
+func (kubeCli *CelleryKubeCli) GetPodMetricsForCell(cellName string) (PodMetricsList, error) {
+	return kubeCli.getPodMetrics(constants.GroupName + "/cell=" + cellName)
+}
+
+func (kubeCli *CelleryKubeCli) GetPodMetricsForComposite(compName string) (PodMetricsList, error) {
+	return kubeCli.getPodMetrics(constants.GroupName + "/composite=" + compName)
+}
+
+// getPodMetrics returns the resource usage of the pods reported by the metrics server
+func (kubeCli *CelleryKubeCli) getPodMetrics(labelSelector string) (PodMetricsList, error) {
+	cmd := exec.Command(kubectl,
+		"get",
+		podMetrics,
+		"-l",
+		labelSelector,
+		"-o",
+		"json",
+	)
+	displayVerboseOutput(cmd)
+	jsonOutput := PodMetricsList{}
+	out, err := osexec.GetCommandOutputFromTextFile(cmd)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+// GetEvents returns the events of the objects of a kind in the current namespace, which are limited to the events
+// of the object with the given name if the name is not empty. All the events are returned if the kind is empty.
+func (kubeCli *CelleryKubeCli) GetEvents(involvedObjectKind string, involvedObjectName string) (Events, error) {
+	cmd := exec.Command(kubectl, "get", "events", "-o", "json")
+	if selector := involvedObjectSelector(involvedObjectKind, involvedObjectName); selector != "" {
+		cmd.Args = append(cmd.Args, "--field-selector", selector)
+	}
+	displayVerboseOutput(cmd)
+	jsonOutput := Events{}
+	out, err := osexec.GetCommandOutputFromTextFile(cmd)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+// involvedObjectSelector returns the field selector of the events of the objects of a kind, or of the object with
+// the given name if the name is not empty. The selector is empty if the kind is empty.
+func involvedObjectSelector(kind string, name string) string {
+	if kind == "" {
+		return ""
+	}
+	selector := "involvedObject.kind=" + kind
+	if name != "" {
+		selector += ",involvedObject.name=" + name
+	}
+	return selector
+}
+
 func (kubeCli *CelleryKubeCli) GetServices(cellName string) (Services, error) {
 	cmd := exec.Command(
 		kubectl,
@@ -342,28 +400,6 @@ func GetService(service, namespace string) (Service, error) {
 	return jsonOutput, err
 }
 
-func getContainerCount(cellName string, considerSystemPods bool) (int, error) {
-	cmd := exec.Command(
-		kubectl,
-		"get",
-		"pods",
-		"-o",
-		"jsonpath={.items[*].spec['containers','initContainers'][*].name}",
-	)
-	if considerSystemPods {
-		cmd.Args = append(cmd.Args, "-l", constants.GroupName+"/cell="+cellName)
-	} else {
-		cmd.Args = append(cmd.Args, "-l",
-			constants.GroupName+"/cell="+cellName+","+constants.GroupName+"/component")
-	}
-	out, err := cmd.Output()
-	displayVerboseOutput(cmd)
-	if err != nil {
-		return 0, err
-	}
-	return len(strings.Split(string(out), " ")), nil
-}
-
 func (kubeCli *CelleryKubeCli) GetInstancesNames() ([]string, error) {
 	var instances []string
 	runningCellInstances, err := kubeCli.GetCells()
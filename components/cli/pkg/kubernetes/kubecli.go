@@ -18,8 +18,13 @@
 
 package kubernetes
 
+import "io"
+
 const kubectl = "kubectl"
 
+// podMetrics is the resource served by the metrics server (metrics.k8s.io API) with the resource usage of the pods
+const podMetrics = "pods.metrics.k8s.io"
+
 // KubeCli represents kubernetes client.
 type KubeCli interface {
 	GetCells() ([]Cell, error)
@@ -30,18 +35,21 @@ type KubeCli interface {
 	GetCell(cellName string) (Cell, error)
 	GetComposite(compositeName string) (Composite, error)
 	GetInstanceBytes(instanceKind, InstanceName string) ([]byte, error)
-	DescribeCell(cellName string) error
 	Version() (string, string, error)
 	GetServices(cellName string) (Services, error)
-	StreamCellLogsUserComponents(instanceName string, follow bool) error
-	StreamCellLogsAllComponents(instanceName string, follow bool) error
-	StreamComponentLogs(instanceName, componentName string, follow bool) error
+	StreamContainerLogs(pod, container string, options LogOptions, out io.Writer) error
 	JsonPatch(kind, instance, jsonPatch string) error
 	ApplyFile(file string) error
+	ReplaceFile(file string) error
 	GetCellInstanceAsMapInterface(cell string) (map[string]interface{}, error)
 	GetCompositeInstanceAsMapInterface(composite string) (map[string]interface{}, error)
 	GetPodsForCell(cellName string) (Pods, error)
 	GetPodsForComposite(compName string) (Pods, error)
+	GetPodMetricsForCell(cellName string) (PodMetricsList, error)
+	GetPodMetricsForComposite(compName string) (PodMetricsList, error)
+	GetEvents(involvedObjectKind string, involvedObjectName string) (Events, error)
+	ExecInContainer(pod, container string, tty bool, command []string) error
+	PortForward(pod string, ports []string) error
 	GetVirtualService(vs string) (VirtualService, error)
 	IsInstanceAvailable(instanceName string) error
 	IsComponentAvailable(instanceName, componentName string) error
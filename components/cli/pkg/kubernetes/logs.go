@@ -20,59 +20,41 @@ package kubernetes
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
-
-	"cellery.io/cellery/components/cli/pkg/constants"
-	"cellery.io/cellery/components/cli/pkg/osexec"
+	"time"
 )
 
-func (kubeCli *CelleryKubeCli) StreamCellLogsUserComponents(instanceName string, follow bool) error {
-	cmd := exec.Command(kubectl,
-		"logs",
-		"-l",
-		constants.GroupName+"/cell="+instanceName+","+constants.GroupName+"/component",
-		"--all-containers=true",
-	)
-	if follow {
-		noOfContainers, err := getContainerCount(instanceName, false)
-		if err != nil {
-			return err
-		}
-		cmd.Args = append(cmd.Args, "-f", fmt.Sprintf("--max-log-requests=%d", noOfContainers))
-	}
-	displayVerboseOutput(cmd)
-	return osexec.PrintCommandOutput(cmd)
-}
-
-func (kubeCli *CelleryKubeCli) StreamCellLogsAllComponents(instanceName string, follow bool) error {
-	cmd := exec.Command(kubectl,
-		"logs",
-		"-l",
-		constants.GroupName+"/cell="+instanceName,
-		"--all-containers=true",
-	)
-	if follow {
-		noOfContainers, err := getContainerCount(instanceName, true)
-		if err != nil {
-			return err
-		}
-		cmd.Args = append(cmd.Args, "-f", fmt.Sprintf("--max-log-requests=%d", noOfContainers))
-	}
-	displayVerboseOutput(cmd)
-	return osexec.PrintCommandOutput(cmd)
+// LogOptions select the logs of a container. All the logs are selected if Since is zero and Tail is negative.
+type LogOptions struct {
+	Follow     bool
+	Since      time.Duration
+	Tail       int
+	Timestamps bool
 }
 
-func (kubeCli *CelleryKubeCli) StreamComponentLogs(instanceName string, componentName string, follow bool) error {
+func (kubeCli *CelleryKubeCli) StreamContainerLogs(pod, container string, options LogOptions, out io.Writer) error {
 	cmd := exec.Command(kubectl,
 		"logs",
-		"-l",
-		constants.GroupName+"/cell="+instanceName+","+constants.GroupName+"/component="+instanceName+"--"+componentName,
+		pod,
 		"-c",
-		componentName,
+		container,
 	)
-	if follow {
+	if options.Follow {
 		cmd.Args = append(cmd.Args, "-f")
 	}
+	if options.Since > 0 {
+		cmd.Args = append(cmd.Args, fmt.Sprintf("--since=%s", options.Since))
+	}
+	if options.Tail >= 0 {
+		cmd.Args = append(cmd.Args, fmt.Sprintf("--tail=%d", options.Tail))
+	}
+	if options.Timestamps {
+		cmd.Args = append(cmd.Args, "--timestamps")
+	}
 	displayVerboseOutput(cmd)
-	return osexec.PrintCommandOutput(cmd)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
 }
@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package kubernetes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// quantitySuffixes are the multipliers of the suffixes of the Kubernetes resource quantities
+var quantitySuffixes = map[string]float64{
+	"n":  1e-9,
+	"u":  1e-6,
+	"m":  1e-3,
+	"":   1,
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"P":  1e15,
+	"E":  1e18,
+	"Ki": math.Pow(2, 10),
+	"Mi": math.Pow(2, 20),
+	"Gi": math.Pow(2, 30),
+	"Ti": math.Pow(2, 40),
+	"Pi": math.Pow(2, 50),
+	"Ei": math.Pow(2, 60),
+}
+
+// ParseQuantity returns the value of a Kubernetes resource quantity such as 250m (cpu) or 128Mi (memory).
+func ParseQuantity(quantity string) (float64, error) {
+	quantity = strings.TrimSpace(quantity)
+	number := strings.TrimRightFunc(quantity, func(r rune) bool {
+		return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+	})
+	suffix := quantity[len(number):]
+	multiplier, ok := quantitySuffixes[suffix]
+	if !ok {
+		// decimal exponent such as 1e3, where the exponent is not a suffix
+		if value, err := strconv.ParseFloat(quantity, 64); err == nil {
+			return value, nil
+		}
+		return 0, fmt.Errorf("invalid quantity %s, unknown suffix %s", quantity, suffix)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %s, %v", quantity, err)
+	}
+	return value * multiplier, nil
+}
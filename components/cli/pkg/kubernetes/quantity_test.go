@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		name                    string
+		quantity                string
+		want                    float64
+		wantErrorMessagePortion string
+	}{
+		{
+			name:     "cores",
+			quantity: "2",
+			want:     2,
+		},
+		{
+			name:     "millicores",
+			quantity: "250m",
+			want:     0.25,
+		},
+		{
+			name:     "nanocores",
+			quantity: "12500000n",
+			want:     0.0125,
+		},
+		{
+			name:     "binary suffix",
+			quantity: "128Mi",
+			want:     128 * 1024 * 1024,
+		},
+		{
+			name:     "decimal suffix",
+			quantity: "1.5G",
+			want:     1.5e9,
+		},
+		{
+			name:     "decimal exponent",
+			quantity: "1e3",
+			want:     1000,
+		},
+		{
+			name:                    "unknown suffix",
+			quantity:                "10Xi",
+			wantErrorMessagePortion: "invalid quantity 10Xi, unknown suffix Xi",
+		},
+		{
+			name:                    "invalid number",
+			quantity:                "1.2.3m",
+			wantErrorMessagePortion: "invalid quantity 1.2.3m",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			got, err := ParseQuantity(tst.quantity)
+			if tst.wantErrorMessagePortion != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErrorMessagePortion) {
+					t.Fatalf("expected an error containing %q, received %v", tst.wantErrorMessagePortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in ParseQuantity, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, got); diff != "" {
+				t.Errorf("ParseQuantity: unexpected value (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@
 
 package kubernetes
 
+import "encoding/json"
+
 type Node struct {
 	Items []NodeItem `json:"items"`
 }
@@ -98,6 +100,7 @@ type K8SMetaData struct {
 	CreationTimestamp string          `json:"creationTimestamp"`
 	Annotations       CellAnnotations `json:"annotations"`
 	Name              string          `json:"name"`
+	UID               string          `json:"uid,omitempty"`
 }
 
 type CellSpec struct {
@@ -128,11 +131,20 @@ type ComponentTemplateSpec struct {
 }
 
 type ContainerTemplate struct {
-	Env   []Env  `json:"env,omitempty"`
-	Image string `json:"image"`
-	Name  string `json:"name"`
+	Env       []Env                 `json:"env,omitempty"`
+	Image     string                `json:"image"`
+	Name      string                `json:"name"`
+	Resources *ResourceRequirements `json:"resources,omitempty"`
+}
+
+type ResourceRequirements struct {
+	Limits   ResourceList `json:"limits,omitempty"`
+	Requests ResourceList `json:"requests,omitempty"`
 }
 
+// ResourceList holds the quantities of the resources (cpu, memory) keyed by the resource name
+type ResourceList map[string]string
+
 type Env struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -173,14 +185,19 @@ type ClusterIngress struct {
 }
 
 type GatewayHttpApi struct {
-	Context      string          `json:"context"`
-	Version      string          `json:"version"`
-	Definitions  []APIDefinition `json:"definitions"`
-	Global       bool            `json:"global"`
-	Authenticate bool            `json:"authenticate"`
-	Port         uint32          `json:"port"`
-	Destination  Destination     `json:"destination,omitempty"`
-	ZeroScale    bool            `json:"zeroScale,omitempty"`
+	Context      string             `json:"context"`
+	Version      string             `json:"version"`
+	Definitions  []APIDefinition    `json:"definitions"`
+	Global       bool               `json:"global"`
+	Authenticate bool               `json:"authenticate"`
+	Port         uint32             `json:"port"`
+	Destination  GatewayDestination `json:"destination,omitempty"`
+	ZeroScale    bool               `json:"zeroScale,omitempty"`
+}
+
+type GatewayDestination struct {
+	Host string `json:"host"`
+	Port uint32 `json:"port,omitempty"`
 }
 
 type GatewayConfig struct {
@@ -214,6 +231,8 @@ type GatewayGrpcApi struct {
 	Definitions []GatewayDefinition `json:"definitions"`
 	Global      bool                `json:"global"`
 	Vhost       string              `json:"vhost"`
+	Port        uint32              `json:"port"`
+	Destination GatewayDestination  `json:"destination,omitempty"`
 }
 
 type GatewayDefinition struct {
@@ -228,12 +247,14 @@ type CellAnnotations struct {
 	Dependencies                        string `json:"mesh.cellery.io/cell-dependencies"`
 	ApiVersion                          string `json:"mesh.cellery.io/apiVersion"`
 	OriginalDependencyComponentServices string `json:"mesh.cellery.io/original-component-svcs,omitempty"`
+	StackEnvHash                        string `json:"mesh.cellery.io/stack-env-hash,omitempty"`
 }
 
 type CellStatus struct {
-	Status       string `json:"status"`
-	Gateway      string `json:"gatewayServiceName"`
-	ServiceCount int    `json:"componentCount"`
+	Status        string `json:"status"`
+	Gateway       string `json:"gatewayServiceName"`
+	GatewayStatus string `json:"gatewayStatus"`
+	ServiceCount  int    `json:"componentCount"`
 }
 
 type ComponentStatus struct {
@@ -253,23 +274,122 @@ type Pods struct {
 
 type Pod struct {
 	MetaData  PodMetaData `json:"metadata"`
+	PodSpec   PodTemplate `json:"spec"`
 	PodStatus PodStatus   `json:"status"`
 }
 
 type PodMetaData struct {
-	Name string `json:"name"`
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type Deployment struct {
+	Metadata DeploymentMetaData `json:"metadata"`
+	Spec     DeploymentSpec     `json:"spec"`
+	Status   DeploymentStatus   `json:"status"`
+}
+
+type DeploymentMetaData struct {
+	Name       string `json:"name"`
+	Generation int64  `json:"generation"`
+}
+
+type DeploymentSpec struct {
+	Replicas *int               `json:"replicas,omitempty"`
+	Template DeploymentTemplate `json:"template"`
+}
+
+type DeploymentTemplate struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations,omitempty"`
+	} `json:"metadata"`
+}
+
+type DeploymentStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration"`
+	Replicas           int   `json:"replicas"`
+	UpdatedReplicas    int   `json:"updatedReplicas"`
+	AvailableReplicas  int   `json:"availableReplicas"`
+}
+
+type PodMetricsList struct {
+	Items []PodMetrics `json:"items"`
+}
+
+type PodMetrics struct {
+	MetaData   PodMetaData        `json:"metadata"`
+	Timestamp  string             `json:"timestamp"`
+	Window     string             `json:"window"`
+	Containers []ContainerMetrics `json:"containers"`
+}
+
+type ContainerMetrics struct {
+	Name  string       `json:"name"`
+	Usage ResourceList `json:"usage"`
 }
 
 type PodStatus struct {
-	Phase      string         `json:"phase"`
-	StartTime  string         `json:"startTime"`
-	Conditions []PodCondition `json:"conditions"`
+	Phase             string            `json:"phase"`
+	StartTime         string            `json:"startTime"`
+	Conditions        []PodCondition    `json:"conditions"`
+	ContainerStatuses []ContainerStatus `json:"containerStatuses,omitempty"`
 }
 
 type PodCondition struct {
 	Type               string `json:"type"`
 	Status             string `json:"status"`
 	LastTransitionTime string `json:"lastTransitionTime"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+}
+
+type ContainerStatus struct {
+	Name         string         `json:"name"`
+	Ready        bool           `json:"ready"`
+	RestartCount int            `json:"restartCount"`
+	State        ContainerState `json:"state"`
+	LastState    ContainerState `json:"lastState"`
+}
+
+// ContainerState is the state of a container, where only one of the states is set
+type ContainerState struct {
+	Waiting    *ContainerStateWaiting    `json:"waiting,omitempty"`
+	Running    *ContainerStateRunning    `json:"running,omitempty"`
+	Terminated *ContainerStateTerminated `json:"terminated,omitempty"`
+}
+
+type ContainerStateWaiting struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type ContainerStateRunning struct {
+	StartedAt string `json:"startedAt,omitempty"`
+}
+
+type ContainerStateTerminated struct {
+	ExitCode int    `json:"exitCode"`
+	Reason   string `json:"reason,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type Events struct {
+	Items []Event `json:"items"`
+}
+
+type Event struct {
+	InvolvedObject ObjectReference `json:"involvedObject"`
+	Type           string          `json:"type"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Count          int             `json:"count"`
+	FirstTimestamp string          `json:"firstTimestamp"`
+	LastTimestamp  string          `json:"lastTimestamp"`
+}
+
+type ObjectReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
 }
 
 type Services struct {
@@ -302,7 +422,22 @@ type VirtualService struct {
 }
 
 type VsMetaData struct {
-	Name string `json:"name"`
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// UnmarshalJSON drops the annotation holding the configuration last applied by kubectl, which should not be copied to
+// the virtual services built from an existing virtual service
+func (metadata *VsMetaData) UnmarshalJSON(data []byte) error {
+	type vsMetaData VsMetaData
+	if err := json.Unmarshal(data, (*vsMetaData)(metadata)); err != nil {
+		return err
+	}
+	delete(metadata.Annotations, lastAppliedConfigAnnotation)
+	if len(metadata.Annotations) == 0 {
+		metadata.Annotations = nil
+	}
+	return nil
 }
 
 type VsSpec struct {
@@ -336,14 +471,45 @@ type TCPPort struct {
 }
 
 type HTTP struct {
-	Match []HTTPMatch `json:"match"`
-	Route []HTTPRoute `json:"route"`
+	Match         []HTTPMatch         `json:"match"`
+	Route         []HTTPRoute         `json:"route"`
+	Mirror        *Destination        `json:"mirror,omitempty"`
+	MirrorPercent int                 `json:"mirror_percent,omitempty"`
+	Timeout       string              `json:"timeout,omitempty"`
+	Retries       *HTTPRetry          `json:"retries,omitempty"`
+	Fault         *HTTPFaultInjection `json:"fault,omitempty"`
+}
+
+type HTTPRetry struct {
+	Attempts      int    `json:"attempts"`
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+	RetryOn       string `json:"retryOn,omitempty"`
+}
+
+type HTTPFaultInjection struct {
+	Delay *FaultDelay `json:"delay,omitempty"`
+	Abort *FaultAbort `json:"abort,omitempty"`
+}
+
+type FaultDelay struct {
+	Percentage *Percent `json:"percentage,omitempty"`
+	FixedDelay string   `json:"fixedDelay"`
+}
+
+type FaultAbort struct {
+	Percentage *Percent `json:"percentage,omitempty"`
+	HttpStatus int      `json:"httpStatus"`
+}
+
+type Percent struct {
+	Value float64 `json:"value"`
 }
 
 type HTTPMatch struct {
 	Authority    Authority               `json:"authority"`
 	SourceLabels map[string]string       `json:"sourceLabels"`
 	Headers      map[string]*StringMatch `json:"headers,omitempty"`
+	Uri          *StringMatch            `json:"uri,omitempty"`
 }
 
 type StringMatch struct {
@@ -362,7 +528,64 @@ type HTTPRoute struct {
 }
 
 type Destination struct {
-	Host string `json:"host"`
+	Host string        `json:"host"`
+	Port *PortSelector `json:"port,omitempty"`
+}
+
+type PortSelector struct {
+	Number uint32 `json:"number"`
+}
+
+type DestinationRule struct {
+	Kind       string              `json:"kind"`
+	APIVersion string              `json:"apiVersion"`
+	Metadata   DestinationRuleMeta `json:"metadata"`
+	Spec       DestinationRuleSpec `json:"spec"`
+}
+
+type DestinationRuleMeta struct {
+	Name            string            `json:"name"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	OwnerReferences []OwnerReference  `json:"ownerReferences,omitempty"`
+}
+
+type OwnerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+}
+
+type DestinationRuleSpec struct {
+	Host          string        `json:"host"`
+	TrafficPolicy TrafficPolicy `json:"trafficPolicy"`
+}
+
+type TrafficPolicy struct {
+	ConnectionPool   *ConnectionPool   `json:"connectionPool,omitempty"`
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty"`
+}
+
+type ConnectionPool struct {
+	Tcp  *TCPConnectionPool  `json:"tcp,omitempty"`
+	Http *HTTPConnectionPool `json:"http,omitempty"`
+}
+
+type TCPConnectionPool struct {
+	MaxConnections int `json:"maxConnections,omitempty"`
+}
+
+type HTTPConnectionPool struct {
+	Http1MaxPendingRequests  int `json:"http1MaxPendingRequests,omitempty"`
+	MaxRequestsPerConnection int `json:"maxRequestsPerConnection,omitempty"`
+}
+
+type OutlierDetection struct {
+	ConsecutiveErrors  int    `json:"consecutiveErrors,omitempty"`
+	Interval           string `json:"interval,omitempty"`
+	BaseEjectionTime   string `json:"baseEjectionTime,omitempty"`
+	MaxEjectionPercent int    `json:"maxEjectionPercent,omitempty"`
 }
 
 type AutoscalePolicy struct {
@@ -0,0 +1,38 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package metrics
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoSamples is returned by a Source when there are no metrics to evaluate, which is the case when the instance
+// has not received any requests or when its metrics are not collected.
+var ErrNoSamples = errors.New("no samples found")
+
+// Source provides the request metrics of the cell/composite instances from the observability stack
+type Source interface {
+	// ErrorRate returns the percentage of the requests to an instance which failed with a server error
+	ErrorRate(instance string, window time.Duration) (float64, error)
+	// Latency returns the given quantile of the request durations of an instance
+	Latency(instance string, quantile float64, window time.Duration) (time.Duration, error)
+	// RequestRate returns the number of requests per second received by an instance
+	RequestRate(instance string, window time.Duration) (float64, error)
+}
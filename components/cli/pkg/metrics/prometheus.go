@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// PrometheusSource reads the Istio request metrics from a Prometheus compatible query API
+type PrometheusSource struct {
+	url    string
+	client *http.Client
+}
+
+type PrometheusOption func(*PrometheusSource)
+
+// WithHttpClient sets the client used to query the Prometheus API
+func WithHttpClient(client *http.Client) PrometheusOption {
+	return func(source *PrometheusSource) {
+		source.client = client
+	}
+}
+
+type queryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// NewPrometheusSource returns a Source which queries the Prometheus API at the given url
+func NewPrometheusSource(url string, opts ...PrometheusOption) *PrometheusSource {
+	source := &PrometheusSource{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(source)
+	}
+	return source
+}
+
+// ErrorRate returns the percentage of the requests to the gateway of an instance which resulted in a 5xx response
+func (source *PrometheusSource) ErrorRate(instance string, window time.Duration) (float64, error) {
+	selector := workloadSelector(instance)
+	// The failed requests are counted as zero when there are no series with 5xx responses
+	query := fmt.Sprintf("100 * (sum(rate(istio_requests_total{%s,response_code=~\"5..\"}[%s])) or on() vector(0)) / "+
+		"sum(rate(istio_requests_total{%s}[%s]))", selector, rangeOf(window), selector, rangeOf(window))
+	return source.query(query)
+}
+
+// Latency returns the given quantile of the durations of the requests to the gateway of an instance
+func (source *PrometheusSource) Latency(instance string, quantile float64, window time.Duration) (time.Duration,
+	error) {
+	query := fmt.Sprintf("histogram_quantile(%g, sum(rate(istio_request_duration_seconds_bucket{%s}[%s])) by (le))",
+		quantile, workloadSelector(instance), rangeOf(window))
+	seconds, err := source.query(query)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RequestRate returns the number of requests per second received by the gateway of an instance
+func (source *PrometheusSource) RequestRate(instance string, window time.Duration) (float64, error) {
+	return source.query(fmt.Sprintf("sum(rate(istio_requests_total{%s}[%s]))", workloadSelector(instance),
+		rangeOf(window)))
+}
+
+// query evaluates an instant query which results in a single sample. ErrNoSamples is returned if the query does
+// not result in a sample or the sample is not a number, which is the case when there are no requests to evaluate.
+func (source *PrometheusSource) query(query string) (float64, error) {
+	resp, err := source.client.Get(source.url + "/api/v1/query?query=" + url.QueryEscape(query))
+	if err != nil {
+		return 0, fmt.Errorf("error querying metrics from %s, %v", source.url, err)
+	}
+	defer resp.Body.Close()
+	result := &queryResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return 0, fmt.Errorf("error reading metrics from %s, unexpected response with status %s, %v",
+			source.url, resp.Status, err)
+	}
+	if result.Status != "success" {
+		return 0, fmt.Errorf("error querying metrics from %s, %s: %s", source.url, result.ErrorType, result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return 0, fmt.Errorf("unexpected result type %s for query %s", result.Data.ResultType, query)
+	}
+	if len(result.Data.Result) == 0 || len(result.Data.Result[0].Value) != 2 {
+		return 0, ErrNoSamples
+	}
+	sample, ok := result.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected sample %v for query %s", result.Data.Result[0].Value[1], query)
+	}
+	value, err := strconv.ParseFloat(sample, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected sample %s for query %s, %v", sample, query, err)
+	}
+	if math.IsNaN(value) {
+		return 0, ErrNoSamples
+	}
+	return value, nil
+}
+
+// workloadSelector matches the gateway of an instance. The requests to the components pass through the gateway,
+// hence they would be counted twice if the components were matched as well.
+func workloadSelector(instance string) string {
+	return fmt.Sprintf("destination_workload=\"%s--gateway-deployment\"", instance)
+}
+
+func rangeOf(window time.Duration) string {
+	seconds := int64(window / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
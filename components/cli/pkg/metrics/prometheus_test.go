@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrometheusSource(t *testing.T) {
+	tests := []struct {
+		name                string
+		errorRateResponse   string
+		latencyResponse     string
+		requestRateResponse string
+		expectedErrorRate   float64
+		expectedLatency     time.Duration
+		expectedRequestRate float64
+		expectedNoSamples   bool
+		expectedError       string
+	}{
+		{
+			name:                "instance with traffic",
+			errorRateResponse:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1565000000,"2.5"]}]}}`,
+			latencyResponse:     `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1565000000,"0.25"]}]}}`,
+			requestRateResponse: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1565000000,"12.5"]}]}}`,
+			expectedErrorRate:   2.5,
+			expectedLatency:     250 * time.Millisecond,
+			expectedRequestRate: 12.5,
+		},
+		{
+			name:                "instance without traffic",
+			errorRateResponse:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1565000000,"NaN"]}]}}`,
+			latencyResponse:     `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			requestRateResponse: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			expectedNoSamples:   true,
+		},
+		{
+			name:              "invalid query",
+			errorRateResponse: `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			expectedError:     "bad_data: parse error",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			var queries []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query().Get("query")
+				queries = append(queries, query)
+				if strings.HasPrefix(query, "histogram_quantile") {
+					w.Write([]byte(tst.latencyResponse))
+				} else if strings.HasPrefix(query, "sum(rate(") {
+					w.Write([]byte(tst.requestRateResponse))
+				} else {
+					w.Write([]byte(tst.errorRateResponse))
+				}
+			}))
+			defer server.Close()
+			source := NewPrometheusSource(server.URL+"/", WithHttpClient(server.Client()))
+
+			errorRate, err := source.ErrorRate("pet-be", time.Minute)
+			if tst.expectedError != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), tst.expectedError) {
+					t.Errorf("ErrorRate: expected error %q, got %v", tst.expectedError, err)
+				}
+				return
+			}
+			latency, latencyErr := source.Latency("pet-be", 0.99, time.Minute)
+			requestRate, requestRateErr := source.RequestRate("pet-be", 30*time.Second)
+			if tst.expectedNoSamples {
+				for _, err := range []error{err, latencyErr, requestRateErr} {
+					if err != ErrNoSamples {
+						t.Errorf("PrometheusSource: expected error %v, got %v", ErrNoSamples, err)
+					}
+				}
+			} else {
+				for _, err := range []error{err, latencyErr, requestRateErr} {
+					if err != nil {
+						t.Fatalf("error in PrometheusSource, %v", err)
+					}
+				}
+			}
+			if diff := cmp.Diff(tst.expectedErrorRate, errorRate); diff != "" {
+				t.Errorf("ErrorRate: unexpected error rate (-want, +got)\n%v", diff)
+			}
+			if diff := cmp.Diff(tst.expectedLatency, latency); diff != "" {
+				t.Errorf("Latency: unexpected latency (-want, +got)\n%v", diff)
+			}
+			if diff := cmp.Diff(tst.expectedRequestRate, requestRate); diff != "" {
+				t.Errorf("RequestRate: unexpected request rate (-want, +got)\n%v", diff)
+			}
+			expectedQueries := []string{
+				`100 * (sum(rate(istio_requests_total{destination_workload="pet-be--gateway-deployment",response_code=~"5.."}[60s])) ` +
+					`or on() vector(0)) / ` +
+					`sum(rate(istio_requests_total{destination_workload="pet-be--gateway-deployment"}[60s]))`,
+				`histogram_quantile(0.99, sum(rate(istio_request_duration_seconds_bucket{destination_workload="pet-be--gateway-deployment"}[60s])) by (le))`,
+				`sum(rate(istio_requests_total{destination_workload="pet-be--gateway-deployment"}[30s]))`,
+			}
+			if diff := cmp.Diff(expectedQueries, queries); diff != "" {
+				t.Errorf("PrometheusSource: unexpected queries (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a template made of literal text and {...} expressions. The supported expressions
+// are a subset of the kubectl jsonpath syntax: field access (.a.b), array indexes ([0]) and
+// wildcards ([*]). Multiple results of an expression are separated by spaces.
+type jsonPath struct {
+	parts []jsonPathPart
+}
+
+type jsonPathPart struct {
+	text  string
+	steps []jsonPathStep
+}
+
+type jsonPathStep struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func parseJsonPath(template string) (*jsonPath, error) {
+	if template == "" {
+		return nil, fmt.Errorf("jsonpath template cannot be empty")
+	}
+	path := &jsonPath{}
+	rest := template
+	for rest != "" {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			path.parts = append(path.parts, jsonPathPart{text: rest})
+			break
+		}
+		if start > 0 {
+			path.parts = append(path.parts, jsonPathPart{text: rest[:start]})
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid jsonpath template %s, unclosed expression", template)
+		}
+		steps, err := parseJsonPathExpression(rest[start+1 : start+end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath template %s, %v", template, err)
+		}
+		path.parts = append(path.parts, jsonPathPart{steps: steps})
+		rest = rest[start+end+1:]
+	}
+	return path, nil
+}
+
+func parseJsonPathExpression(expression string) ([]jsonPathStep, error) {
+	expression = strings.TrimSpace(expression)
+	expression = strings.TrimPrefix(expression, "$")
+	var steps []jsonPathStep
+	for expression != "" {
+		switch expression[0] {
+		case '.':
+			expression = expression[1:]
+			end := strings.IndexAny(expression, ".[")
+			if end < 0 {
+				end = len(expression)
+			}
+			if end > 0 {
+				field := expression[:end]
+				if field == "*" {
+					steps = append(steps, jsonPathStep{wildcard: true})
+				} else {
+					steps = append(steps, jsonPathStep{field: field})
+				}
+			}
+			expression = expression[end:]
+		case '[':
+			end := strings.Index(expression, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed index in %s", expression)
+			}
+			index := strings.Trim(expression[1:end], "'\"")
+			if index == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+			} else if i, err := strconv.Atoi(index); err == nil {
+				steps = append(steps, jsonPathStep{index: i, isIndex: true})
+			} else {
+				steps = append(steps, jsonPathStep{field: index})
+			}
+			expression = expression[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected character %q in expression", expression[0])
+		}
+	}
+	return steps, nil
+}
+
+func (path *jsonPath) execute(out io.Writer, data interface{}) error {
+	for _, part := range path.parts {
+		if part.steps == nil && part.text != "" {
+			if _, err := fmt.Fprint(out, unescapeJsonPathText(part.text)); err != nil {
+				return err
+			}
+			continue
+		}
+		results := []interface{}{data}
+		for _, step := range part.steps {
+			results = step.apply(results)
+		}
+		var values []string
+		for _, result := range results {
+			value, err := jsonPathValue(result)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if _, err := fmt.Fprint(out, strings.Join(values, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (step jsonPathStep) apply(inputs []interface{}) []interface{} {
+	var results []interface{}
+	for _, input := range inputs {
+		switch value := input.(type) {
+		case map[string]interface{}:
+			if step.wildcard {
+				// the values are ordered by the keys, as the iteration order of a map is random
+				keys := make([]string, 0, len(value))
+				for key := range value {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					results = append(results, value[key])
+				}
+			} else if item, ok := value[step.field]; ok && !step.isIndex {
+				results = append(results, item)
+			}
+		case []interface{}:
+			if step.wildcard {
+				results = append(results, value...)
+			} else if step.isIndex {
+				index := step.index
+				if index < 0 {
+					index += len(value)
+				}
+				if index >= 0 && index < len(value) {
+					results = append(results, value[index])
+				}
+			}
+		}
+	}
+	return results
+}
+
+func jsonPathValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	case map[string]interface{}, []interface{}:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+func unescapeJsonPathText(text string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(text)
+}
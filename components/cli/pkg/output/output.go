@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+)
+
+const FormatTable = ""
+const FormatWide = "wide"
+const FormatJson = "json"
+const FormatYaml = "yaml"
+const FormatGoTemplate = "go-template"
+const FormatJsonPath = "jsonpath"
+
+// FlagUsage is the usage of the output flag shared by all commands supporting output formats.
+const FlagUsage = "Output format. One of: json|yaml|wide|go-template=<template>|jsonpath=<template>"
+
+// ValidateFormat checks whether the given output format is supported.
+func ValidateFormat(format string) error {
+	name, tmpl := splitFormat(format)
+	switch name {
+	case FormatTable, FormatWide, FormatJson, FormatYaml:
+		if tmpl != "" {
+			return fmt.Errorf("output format %s does not accept a template", name)
+		}
+		return nil
+	case FormatGoTemplate:
+		_, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid go-template %s, %v", tmpl, err)
+		}
+		return nil
+	case FormatJsonPath:
+		_, err := parseJsonPath(tmpl)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %s, expected one of json|yaml|wide|"+
+			"go-template=<template>|jsonpath=<template>", format)
+	}
+}
+
+// IsTable returns true if the output should be rendered as a table.
+func IsTable(format string) bool {
+	return format == FormatTable || format == FormatWide
+}
+
+// IsWide returns true if additional columns should be rendered in tables.
+func IsWide(format string) bool {
+	return format == FormatWide
+}
+
+// Print writes the data in a machine readable output format. The data is converted to JSON
+// first, hence go-template and jsonpath templates refer to the JSON field names.
+func Print(out io.Writer, format string, data interface{}) error {
+	name, tmpl := splitFormat(format)
+	dataJson, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error marshalling output, %v", err)
+	}
+	switch name {
+	case FormatJson:
+		var indented interface{}
+		if err := json.Unmarshal(dataJson, &indented); err != nil {
+			return err
+		}
+		dataJson, err = json.MarshalIndent(indented, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(dataJson))
+		return err
+	case FormatYaml:
+		dataYaml, err := yaml.JSONToYAML(dataJson)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(out, string(dataYaml))
+		return err
+	case FormatGoTemplate:
+		var generic interface{}
+		if err := json.Unmarshal(dataJson, &generic); err != nil {
+			return err
+		}
+		t, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid go-template %s, %v", tmpl, err)
+		}
+		return t.Execute(out, generic)
+	case FormatJsonPath:
+		var generic interface{}
+		if err := json.Unmarshal(dataJson, &generic); err != nil {
+			return err
+		}
+		expression, err := parseJsonPath(tmpl)
+		if err != nil {
+			return err
+		}
+		return expression.execute(out, generic)
+	default:
+		return fmt.Errorf("output format %s cannot be used to print %T", format, data)
+	}
+}
+
+// PrintTable renders the table for table formats and prints the data otherwise.
+func PrintTable(out io.Writer, format string, data interface{}, table *Table) error {
+	if IsTable(format) {
+		table.Render(out, IsWide(format))
+		return nil
+	}
+	return Print(out, format, data)
+}
+
+func splitFormat(format string) (string, string) {
+	parts := strings.SplitN(format, "=", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{name: "table", format: ""},
+		{name: "wide", format: "wide"},
+		{name: "json", format: "json"},
+		{name: "yaml", format: "yaml"},
+		{name: "go-template", format: "go-template={{.name}}"},
+		{name: "jsonpath", format: "jsonpath={.items[0].name}"},
+		{
+			name:     "unsupported format",
+			format:   "xml",
+			expected: "unsupported output format xml, expected one of json|yaml|wide|go-template=<template>|jsonpath=<template>",
+		},
+		{
+			name:     "json with template",
+			format:   "json={.name}",
+			expected: "output format json does not accept a template",
+		},
+		{
+			name:     "unclosed jsonpath expression",
+			format:   "jsonpath={.name",
+			expected: "invalid jsonpath template {.name, unclosed expression",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := ValidateFormat(tst.format)
+			actual := ""
+			if err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(tst.expected, actual); diff != "" {
+				t.Errorf("ValidateFormat: unexpected error (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	data := struct {
+		Name  string   `json:"name"`
+		Ports []int    `json:"ports"`
+		Tags  []string `json:"tags"`
+	}{
+		Name:  "employee",
+		Ports: []int{8080, 9090},
+		Tags:  []string{"v1"},
+	}
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "json",
+			format:   "json",
+			expected: "{\n    \"name\": \"employee\",\n    \"ports\": [\n        8080,\n        9090\n    ],\n    \"tags\": [\n        \"v1\"\n    ]\n}\n",
+		},
+		{
+			name:     "yaml",
+			format:   "yaml",
+			expected: "name: employee\nports:\n- 8080\n- 9090\ntags:\n- v1\n",
+		},
+		{
+			name:     "go-template",
+			format:   "go-template={{.name}}:{{range .ports}} {{.}}{{end}}",
+			expected: "employee: 8080 9090",
+		},
+		{
+			name:     "jsonpath wildcard",
+			format:   "jsonpath=name={.name}\\n{.ports[*]}",
+			expected: "name=employee\n8080 9090",
+		},
+		{
+			name:     "jsonpath index",
+			format:   "jsonpath={$.ports[-1]} {.tags[0]} {.missing}",
+			expected: "9090 v1 ",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := Print(out, tst.format, data); err != nil {
+				t.Fatalf("error in Print, %v", err)
+			}
+			if diff := cmp.Diff(tst.expected, out.String()); diff != "" {
+				t.Errorf("Print: unexpected output (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestPrintJsonPathMapWildcard(t *testing.T) {
+	data := map[string]interface{}{
+		"labels": map[string]string{"tier": "backend", "app": "employee", "env": "dev", "team": "hr", "zone": "a"},
+	}
+	// the values of a map are printed in the order of the keys every time
+	for i := 0; i < 10; i++ {
+		out := &bytes.Buffer{}
+		if err := Print(out, "jsonpath={.labels[*]}", data); err != nil {
+			t.Fatalf("error in Print, %v", err)
+		}
+		if diff := cmp.Diff("employee dev hr backend a", out.String()); diff != "" {
+			t.Fatalf("Print: unexpected output (-want, +got)\n%v", diff)
+		}
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	table := NewTable("NAME").AddWideColumns("POD")
+	table.Append("salary", "employee--salary-deployment-1")
+	out := &bytes.Buffer{}
+	table.Render(out, false)
+	if bytes.Contains(out.Bytes(), []byte("employee--salary-deployment-1")) {
+		t.Errorf("Render: wide column rendered in the default format\n%s", out.String())
+	}
+	out.Reset()
+	table.Render(out, true)
+	if !bytes.Contains(out.Bytes(), []byte("employee--salary-deployment-1")) {
+		t.Errorf("Render: wide column not rendered in the wide format\n%s", out.String())
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package output
+
+import (
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// Column is a column of a table. Wide columns are only rendered in the wide output format.
+type Column struct {
+	Header string
+	Wide   bool
+	Color  tablewriter.Colors
+}
+
+// Table is a table rendered in the default style of the CLI.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// NewTable returns a table with the given headers.
+func NewTable(headers ...string) *Table {
+	table := &Table{}
+	for _, header := range headers {
+		table.Columns = append(table.Columns, Column{Header: header})
+	}
+	return table
+}
+
+// AddWideColumns adds columns which are only rendered in the wide output format.
+func (t *Table) AddWideColumns(headers ...string) *Table {
+	for _, header := range headers {
+		t.Columns = append(t.Columns, Column{Header: header, Wide: true})
+	}
+	return t
+}
+
+// SetColumnColor sets the color of the column with the given index.
+func (t *Table) SetColumnColor(index int, color tablewriter.Colors) *Table {
+	t.Columns[index].Color = color
+	return t
+}
+
+// Append adds a row with a value for each column, including the wide columns.
+func (t *Table) Append(row ...string) {
+	t.Rows = append(t.Rows, row)
+}
+
+// Render writes the table to the writer.
+func (t *Table) Render(out io.Writer, wide bool) {
+	var headers []string
+	var headerColors, columnColors []tablewriter.Colors
+	var columnIndexes []int
+	for i, column := range t.Columns {
+		if column.Wide && !wide {
+			continue
+		}
+		columnIndexes = append(columnIndexes, i)
+		headers = append(headers, column.Header)
+		headerColors = append(headerColors, tablewriter.Colors{tablewriter.Bold})
+		columnColors = append(columnColors, column.Color)
+	}
+	table := tablewriter.NewWriter(out)
+	table.SetHeader(headers)
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetAlignment(3)
+	table.SetRowSeparator("-")
+	table.SetCenterSeparator(" ")
+	table.SetColumnSeparator(" ")
+	table.SetHeaderColor(headerColors...)
+	table.SetColumnColor(columnColors...)
+	for _, row := range t.Rows {
+		var record []string
+		for _, i := range columnIndexes {
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			record = append(record, value)
+		}
+		table.Append(record)
+	}
+	table.Render()
+}
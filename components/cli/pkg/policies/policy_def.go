@@ -19,6 +19,7 @@
 package policies
 
 const PolicyTypeAutoscale = "AutoscalePolicy"
+const PolicyTypeResilience = "ResiliencePolicy"
 
 type CellPolicy struct {
 	Type  string `json:"type"`
@@ -52,3 +53,39 @@ type Resource struct {
 	TargetAverageUtilization int    `json:"targetAverageUtilization,omitempty"`
 	TargetAverageValue       string `json:"targetAverageValue,omitempty"`
 }
+
+type ResiliencePolicy struct {
+	Type  string           `json:"type"`
+	Rules []ResilienceRule `json:"rules"`
+}
+
+type ResilienceRule struct {
+	Target Target           `json:"target"`
+	Policy DependencyPolicy `json:"policy"`
+}
+
+type DependencyPolicy struct {
+	Timeout          string            `json:"timeout,omitempty"`
+	Retries          *Retries          `json:"retries,omitempty"`
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty"`
+	ConnectionPool   *ConnectionPool   `json:"connectionPool,omitempty"`
+}
+
+type Retries struct {
+	Attempts      int    `json:"attempts"`
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+	RetryOn       string `json:"retryOn,omitempty"`
+}
+
+type OutlierDetection struct {
+	ConsecutiveErrors  int    `json:"consecutiveErrors,omitempty"`
+	Interval           string `json:"interval,omitempty"`
+	BaseEjectionTime   string `json:"baseEjectionTime,omitempty"`
+	MaxEjectionPercent int    `json:"maxEjectionPercent,omitempty"`
+}
+
+type ConnectionPool struct {
+	MaxConnections           int `json:"maxConnections,omitempty"`
+	MaxPendingRequests       int `json:"maxPendingRequests,omitempty"`
+	MaxRequestsPerConnection int `json:"maxRequestsPerConnection,omitempty"`
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package policies
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+const DependencyTargetType = "dependency"
+const IstioNetworkingApiVersion = "networking.istio.io/v1alpha3"
+const DestinationRuleKind = "DestinationRule"
+const DestinationRuleResourceKind = "destinationrule.networking.istio.io"
+const ResiliencePolicyLabel = "mesh.cellery.io/resilience-policy"
+const ResiliencePolicySourcesAnnotation = "mesh.cellery.io/resilience-policy-of"
+
+// GetResilienceDestinationRuleName returns the name of the destination rule applying the resilience policies to a
+// service host. The destination rule is shared by all the instances applying a policy to the host, since Istio
+// expects a single destination rule for a host.
+func GetResilienceDestinationRuleName(host string) string {
+	return fmt.Sprintf("%s-resilience", strings.TrimSuffix(host, "-service"))
+}
+
+// ReadResiliencePolicy reads and validates a resilience policy file
+func ReadResiliencePolicy(file string) (*ResiliencePolicy, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s, %v", file, err)
+	}
+	policy := &ResiliencePolicy{}
+	if err := yaml.Unmarshal(content, policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall data in file %s, %v", file, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid resilience policy %s, %v", file, err)
+	}
+	return policy, nil
+}
+
+// Validate checks the type, the targets and the durations of the policy
+func (policy *ResiliencePolicy) Validate() error {
+	if policy.Type != PolicyTypeResilience {
+		return fmt.Errorf("expects policy type %s, received %q", PolicyTypeResilience, policy.Type)
+	}
+	dependencies := make(map[string]bool)
+	for _, rule := range policy.Rules {
+		if rule.Target.Type != DependencyTargetType || rule.Target.Name == "" {
+			return fmt.Errorf("expects a target of type %s with the name of the dependency instance",
+				DependencyTargetType)
+		}
+		if dependencies[rule.Target.Name] {
+			return fmt.Errorf("multiple rules found for dependency %s", rule.Target.Name)
+		}
+		dependencies[rule.Target.Name] = true
+		durations := map[string]string{"timeout": rule.Policy.Timeout}
+		if rule.Policy.Retries != nil {
+			if rule.Policy.Retries.Attempts < 1 {
+				return fmt.Errorf("expects at least one retry attempt for dependency %s", rule.Target.Name)
+			}
+			durations["perTryTimeout"] = rule.Policy.Retries.PerTryTimeout
+		}
+		if rule.Policy.OutlierDetection != nil {
+			durations["interval"] = rule.Policy.OutlierDetection.Interval
+			durations["baseEjectionTime"] = rule.Policy.OutlierDetection.BaseEjectionTime
+			if rule.Policy.OutlierDetection.MaxEjectionPercent > 100 {
+				return fmt.Errorf("invalid maxEjectionPercent %d for dependency %s",
+					rule.Policy.OutlierDetection.MaxEjectionPercent, rule.Target.Name)
+			}
+		}
+		for field, duration := range durations {
+			if duration == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(duration); err != nil {
+				return fmt.Errorf("invalid %s %q for dependency %s", field, duration, rule.Target.Name)
+			}
+		}
+	}
+	return nil
+}
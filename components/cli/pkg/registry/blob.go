@@ -0,0 +1,339 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution"
+	registry2 "github.com/nokia/docker-registry-client/registry"
+	"github.com/opencontainers/go-digest"
+)
+
+// chunkSize is the size of the chunks in which the cell image is uploaded.
+const chunkSize = 5 * 1024 * 1024
+
+// maxTransferRetries is the number of times a failed upload or download is resumed before giving up.
+const maxTransferRetries = 3
+
+// retryInterval is the interval between resuming failed uploads and downloads, multiplied by the attempt.
+var retryInterval = time.Second
+
+// ProgressFunc is called with the number of bytes transferred and the total number of bytes of a blob.
+type ProgressFunc func(transferred, total int64)
+
+// Blob is the content of a cell image being pushed.
+type Blob interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+// blobDescriptor returns the descriptor of the blob with the given media type, reading the blob once to
+// compute its digest.
+func blobDescriptor(mediaType string, blob Blob) (distribution.Descriptor, error) {
+	size, err := blob.Seek(0, io.SeekEnd)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(blob, 0, size)); err != nil {
+		return distribution.Descriptor{}, err
+	}
+	return distribution.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.NewDigestFromEncoded(digest.SHA256, hex.EncodeToString(hash.Sum(nil))),
+		Size:      size,
+	}, nil
+}
+
+// uploadContentIfMissing uploads a small blob held in memory unless the registry already has it.
+func uploadContentIfMissing(hub *registry2.Registry, repository string, descriptor distribution.Descriptor,
+	content []byte) error {
+	exists, err := hub.HasBlob(repository, descriptor.Digest)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return hub.UploadBlob(repository, descriptor.Digest, bytes.NewReader(content), func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	})
+}
+
+// uploadBlobInChunks uploads the blob in chunks. If uploading a chunk fails, the upload is resumed from the
+// offset reported by the registry.
+func uploadBlobInChunks(hub *registry2.Registry, repository string, descriptor distribution.Descriptor, blob Blob,
+	progress ProgressFunc) error {
+	location, err := initiateUpload(hub, repository)
+	if err != nil {
+		return fmt.Errorf("failed to initiate upload, %v", err)
+	}
+	var offset int64
+	retries := 0
+	for offset < descriptor.Size {
+		end := offset + chunkSize
+		if end > descriptor.Size {
+			end = descriptor.Size
+		}
+		nextLocation, err := uploadChunk(hub, location, blob, offset, end)
+		if err != nil {
+			if retries >= maxTransferRetries {
+				return fmt.Errorf("failed to upload blob %s, %v", descriptor.Digest, err)
+			}
+			retries++
+			log.Printf("Failed to upload chunk %d-%d of blob %s, resuming (attempt %d), %v", offset, end-1,
+				descriptor.Digest, retries, err)
+			time.Sleep(time.Duration(retries) * retryInterval)
+			if location, offset, err = uploadOffset(hub, location); err != nil {
+				return fmt.Errorf("failed to resume upload of blob %s, %v", descriptor.Digest, err)
+			}
+			continue
+		}
+		location = nextLocation
+		offset = end
+		retries = 0
+		if progress != nil {
+			progress(offset, descriptor.Size)
+		}
+	}
+	return completeUpload(hub, location, descriptor.Digest)
+}
+
+func initiateUpload(hub *registry2.Registry, repository string) (*url.URL, error) {
+	initiateUrl, err := url.Parse(fmt.Sprintf("%s/v2/%s/blobs/uploads/", hub.URL, repository))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hub.Client.Post(initiateUrl.String(), "application/octet-stream", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return uploadLocation(initiateUrl, resp)
+}
+
+// uploadChunk uploads the bytes of the blob from start (inclusive) to end (exclusive) and returns the location
+// to continue the upload.
+func uploadChunk(hub *registry2.Registry, location *url.URL, blob Blob, start, end int64) (*url.URL, error) {
+	req, err := http.NewRequest(http.MethodPatch, location.String(), io.NewSectionReader(blob, start, end-start))
+	if err != nil {
+		return nil, err
+	}
+	// The request body should be replayable to retry after token authentication
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(io.NewSectionReader(blob, start, end-start)), nil
+	}
+	req.ContentLength = end - start
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Range", fmt.Sprintf("%d-%d", start, end-1))
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return nil, fmt.Errorf("unexpected status %s while uploading chunk", resp.Status)
+	}
+	return uploadLocation(location, resp)
+}
+
+// uploadOffset returns the location to continue the upload and the number of bytes of the upload already
+// received by the registry.
+func uploadOffset(hub *registry2.Registry, location *url.URL) (*url.URL, int64, error) {
+	resp, err := hub.Client.Get(location.String())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return nil, 0, fmt.Errorf("unexpected status %s while getting the upload status", resp.Status)
+	}
+	nextLocation, err := uploadLocation(location, resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The range is of the form 0-<offset of the last byte received>
+	uploadRange := resp.Header.Get("Range")
+	if uploadRange == "" {
+		return nil, 0, fmt.Errorf("upload range not returned by the registry")
+	}
+	parts := strings.SplitN(uploadRange, "-", 2)
+	if len(parts) != 2 || parts[0] != "0" {
+		return nil, 0, fmt.Errorf("invalid range %s received from the registry", uploadRange)
+	}
+	last, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || last < -1 {
+		return nil, 0, fmt.Errorf("invalid range %s received from the registry", uploadRange)
+	}
+	return nextLocation, last + 1, nil
+}
+
+func completeUpload(hub *registry2.Registry, location *url.URL, blobDigest digest.Digest) error {
+	completeUrl := *location
+	query := completeUrl.Query()
+	query.Set("digest", blobDigest.String())
+	completeUrl.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodPut, completeUrl.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to complete upload of blob %s, %v", blobDigest, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to complete upload of blob %s, unexpected status %s", blobDigest, resp.Status)
+	}
+	return nil
+}
+
+// uploadLocation resolves the location of the upload returned by the registry, which can be relative.
+func uploadLocation(base *url.URL, resp *http.Response) (*url.URL, error) {
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil, fmt.Errorf("upload location not returned by the registry")
+	}
+	locationUrl, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(locationUrl), nil
+}
+
+// downloadBlob writes the blob to the writer while verifying its digest. If the connection drops, the download
+// is resumed from the last byte received using a HTTP range request.
+func downloadBlob(hub *registry2.Registry, repository string, descriptor distribution.Descriptor, writer io.Writer,
+	progress ProgressFunc) error {
+	if err := descriptor.Digest.Validate(); err != nil {
+		return fmt.Errorf("invalid blob digest %s, %v", descriptor.Digest, err)
+	}
+	verifier := descriptor.Digest.Verifier()
+	counter := &progressWriter{total: descriptor.Size, progress: progress}
+	destination := io.MultiWriter(writer, verifier, counter)
+	retries := 0
+	for {
+		err := downloadBlobFrom(hub, repository, descriptor.Digest, counter.transferred, destination)
+		if err == nil {
+			break
+		}
+		if retries >= maxTransferRetries {
+			return fmt.Errorf("failed to download blob %s, %v", descriptor.Digest, err)
+		}
+		retries++
+		log.Printf("Failed to download blob %s after %d bytes, resuming (attempt %d), %v", descriptor.Digest,
+			counter.transferred, retries, err)
+		time.Sleep(time.Duration(retries) * retryInterval)
+	}
+	if descriptor.Size > 0 && counter.transferred != descriptor.Size {
+		return fmt.Errorf("invalid blob %s, expected %d bytes but received %d", descriptor.Digest, descriptor.Size,
+			counter.transferred)
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("invalid blob %s, digest verification failed", descriptor.Digest)
+	}
+	return nil
+}
+
+func downloadBlobFrom(hub *registry2.Registry, repository string, blobDigest digest.Digest, offset int64,
+	writer io.Writer) error {
+	resp, err := getBlob(hub, repository, blobDigest, offset)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	skipped := offset
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// The registry does not support range requests, skip the bytes already received
+	case http.StatusPartialContent:
+		start, err := contentRangeStart(resp.Header.Get("Content-Range"))
+		if err == nil && start == offset {
+			skipped = 0
+			break
+		}
+		// The range returned does not continue from the bytes already received, download the blob from the start
+		resp.Body.Close()
+		if resp, err = getBlob(hub, repository, blobDigest, 0); err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status %s while downloading blob", resp.Status)
+		}
+	default:
+		return fmt.Errorf("unexpected status %s while downloading blob", resp.Status)
+	}
+	if _, err := io.CopyN(ioutil.Discard, resp.Body, skipped); err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, resp.Body)
+	return err
+}
+
+// getBlob requests the blob starting from the offset, using a HTTP range request if the offset is not zero.
+func getBlob(hub *registry2.Registry, repository string, blobDigest digest.Digest, offset int64) (*http.Response,
+	error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/%s/blobs/%s", hub.URL, repository, blobDigest),
+		nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	return hub.Client.Do(req)
+}
+
+// contentRangeStart returns the first byte of the range in a Content-Range header, ex: bytes 100-199/200.
+func contentRangeStart(contentRange string) (int64, error) {
+	var start, end int64
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/", &start, &end); err != nil {
+		return 0, fmt.Errorf("invalid content range %q, %v", contentRange, err)
+	}
+	return start, nil
+}
+
+// progressWriter counts the bytes written and reports the progress.
+type progressWriter struct {
+	transferred int64
+	total       int64
+	progress    ProgressFunc
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.transferred += int64(len(p))
+	if w.progress != nil {
+		w.progress(w.transferred, w.total)
+	}
+	return len(p), nil
+}
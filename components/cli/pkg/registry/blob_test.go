@@ -0,0 +1,227 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution"
+	registry2 "github.com/nokia/docker-registry-client/registry"
+)
+
+func TestUploadBlobInChunks(t *testing.T) {
+	retryInterval = 0
+	content := bytes.Repeat([]byte("cell"), chunkSize/2)
+	descriptor, err := blobDescriptor(MediaTypeCellImageLayer, bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("error computing the blob descriptor, %v", err)
+	}
+	var received []byte
+	var completedDigest string
+	failedOnce := false
+	// The registry returns a new location with every response, which should be used for the next request
+	state := 0
+	nextLocation := func(w http.ResponseWriter) {
+		state++
+		w.Header().Set("Location", fmt.Sprintf("/v2/myorg/hello/blobs/uploads/1?_state=%d", state))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.URL.Query().Get("_state") != strconv.Itoa(state) {
+			t.Errorf("%s request sent to the stale upload state %s, expected %d", r.Method,
+				r.URL.Query().Get("_state"), state)
+		}
+		switch r.Method {
+		case http.MethodPost:
+			nextLocation(w)
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodPatch:
+			chunk, _ := ioutil.ReadAll(r.Body)
+			// Fail the second chunk once after receiving it partially to verify resuming the upload
+			if len(received) > 0 && !failedOnce {
+				failedOnce = true
+				received = append(received, chunk[:10]...)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			var start int
+			fmt.Sscanf(r.Header.Get("Content-Range"), "%d-", &start)
+			received = append(received[:start], chunk...)
+			nextLocation(w)
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodGet:
+			nextLocation(w)
+			w.Header().Set("Range", fmt.Sprintf("0-%d", len(received)-1))
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPut:
+			completedDigest = r.URL.Query().Get("digest")
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+	hub := &registry2.Registry{URL: server.URL, Client: server.Client(), Logf: registry2.Quiet}
+
+	var transferred int64
+	err = uploadBlobInChunks(hub, "myorg/hello", descriptor, bytes.NewReader(content), func(done, total int64) {
+		transferred = done
+	})
+	if err != nil {
+		t.Fatalf("error uploading the blob, %v", err)
+	}
+	if !bytes.Equal(received, content) {
+		t.Errorf("uploaded content does not match, received %d bytes, expected %d bytes", len(received),
+			len(content))
+	}
+	if completedDigest != descriptor.Digest.String() {
+		t.Errorf("upload completed with digest %s, expected %s", completedDigest, descriptor.Digest)
+	}
+	if transferred != descriptor.Size {
+		t.Errorf("progress reported %d bytes, expected %d bytes", transferred, descriptor.Size)
+	}
+}
+
+func TestUploadBlobInChunksWithoutRange(t *testing.T) {
+	retryInterval = 0
+	content := bytes.Repeat([]byte("cell"), chunkSize/2)
+	descriptor, err := blobDescriptor(MediaTypeCellImageLayer, bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("error computing the blob descriptor, %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/v2/myorg/hello/blobs/uploads/1")
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodPatch:
+			w.WriteHeader(http.StatusInternalServerError)
+		case http.MethodGet:
+			// The range of the received bytes is not returned
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	hub := &registry2.Registry{URL: server.URL, Client: server.Client(), Logf: registry2.Quiet}
+
+	err = uploadBlobInChunks(hub, "myorg/hello", descriptor, bytes.NewReader(content), nil)
+	wantErrorMessagePortion := "upload range not returned by the registry"
+	if err == nil || !strings.Contains(err.Error(), wantErrorMessagePortion) {
+		t.Errorf("expected an error containing %q, got %v", wantErrorMessagePortion, err)
+	}
+}
+
+func TestDownloadBlob(t *testing.T) {
+	retryInterval = 0
+	content := []byte(strings.Repeat("cell image", 100))
+	descriptor, err := blobDescriptor(MediaTypeCellImageLayer, bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("error computing the blob descriptor, %v", err)
+	}
+	tests := []struct {
+		name          string
+		descriptor    distribution.Descriptor
+		supportsRange bool
+		// rangeShift moves the start of the range returned by the registry before the requested offset
+		rangeShift    int
+		wantRequests  int
+		expectedError bool
+	}{
+		{
+			name:          "resume with range requests",
+			descriptor:    descriptor,
+			supportsRange: true,
+			wantRequests:  2,
+		},
+		{
+			name:          "resume without range requests",
+			descriptor:    descriptor,
+			supportsRange: false,
+			wantRequests:  2,
+		},
+		{
+			name:          "range not starting at the offset",
+			descriptor:    descriptor,
+			supportsRange: true,
+			rangeShift:    10,
+			wantRequests:  3,
+		},
+		{
+			name: "digest mismatch",
+			descriptor: distribution.Descriptor{
+				MediaType: MediaTypeCellImageLayer,
+				Digest:    newDescriptor(MediaTypeCellImageLayer, []byte("other")).Digest,
+				Size:      descriptor.Size,
+			},
+			supportsRange: true,
+			expectedError: true,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				var offset int
+				if tst.supportsRange {
+					fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &offset)
+				}
+				if offset > 0 {
+					offset -= tst.rangeShift
+				}
+				w.Header().Set("Content-Length", fmt.Sprint(len(content)-offset))
+				if offset > 0 {
+					w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1,
+						len(content)))
+					w.WriteHeader(http.StatusPartialContent)
+				}
+				// Drop the connection half way through the first request
+				if requests == 1 {
+					w.Write(content[:len(content)/2])
+					return
+				}
+				w.Write(content[offset:])
+			}))
+			defer server.Close()
+			hub := &registry2.Registry{URL: server.URL, Client: server.Client(), Logf: registry2.Quiet}
+
+			var downloaded bytes.Buffer
+			err := downloadBlob(hub, "myorg/hello", tst.descriptor, &downloaded, nil)
+			if tst.expectedError {
+				if err == nil {
+					t.Errorf("expected an error for a blob with an invalid digest")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error downloading the blob, %v", err)
+			}
+			if requests != tst.wantRequests {
+				t.Errorf("expected %d requests, got %d", tst.wantRequests, requests)
+			}
+			if !bytes.Equal(downloaded.Bytes(), content) {
+				t.Errorf("downloaded content does not match the blob")
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/libtrust"
+	registry2 "github.com/nokia/docker-registry-client/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+// ManifestFormat is the format of the manifest used when pushing a cell image.
+type ManifestFormat string
+
+const (
+	// ManifestFormatOci stores the cell image as an OCI artifact
+	ManifestFormatOci ManifestFormat = "oci"
+	// ManifestFormatSchema2 stores the cell image using a Docker image manifest v2 schema 2
+	ManifestFormatSchema2 ManifestFormat = "schema2"
+	// ManifestFormatSchema1 stores the cell image using the deprecated Docker image manifest v2 schema 1
+	ManifestFormatSchema1 ManifestFormat = "schema1"
+)
+
+// MediaTypeCellImageConfig is the media type of the config blob holding the metadata.json of a cell image.
+const MediaTypeCellImageConfig = "application/vnd.cellery.image.config.v1+json"
+
+// MediaTypeCellImageLayer is the media type of the layer holding the cell image zip.
+const MediaTypeCellImageLayer = "application/vnd.cellery.image.layer.v1+zip"
+
+// MediaTypeCellImageSignature is the media type of the layer holding a detached signature of a cell image.
+const MediaTypeCellImageSignature = "application/vnd.cellery.image.signature.v1+json"
+
+// MediaTypeCellImageSignatureConfig is the media type of the empty config blob of a cell image signature.
+const MediaTypeCellImageSignatureConfig = "application/vnd.cellery.image.signature.config.v1+json"
+
+// acceptedManifestMediaTypes are the manifest media types understood when pulling, in the order of preference.
+var acceptedManifestMediaTypes = []string{
+	ociv1.MediaTypeImageManifest,
+	ociv1.MediaTypeImageIndex,
+	schema2.MediaTypeManifest,
+	manifestlist.MediaTypeManifestList,
+	schema1.MediaTypeSignedManifest,
+	schema1.MediaTypeManifest,
+}
+
+// ValidateManifestFormat checks whether the given manifest format is supported.
+func ValidateManifestFormat(format string) error {
+	switch ManifestFormat(format) {
+	case ManifestFormatOci, ManifestFormatSchema2, ManifestFormatSchema1:
+		return nil
+	default:
+		return fmt.Errorf("unsupported manifest format %s, expected one of %s|%s|%s", format, ManifestFormatOci,
+			ManifestFormatSchema2, ManifestFormatSchema1)
+	}
+}
+
+// rawManifest is a manifest which is uploaded as it is, used for the manifest types not supported by
+// the distribution library.
+type rawManifest struct {
+	mediaType  string
+	payload    []byte
+	references []distribution.Descriptor
+}
+
+func (m *rawManifest) References() []distribution.Descriptor {
+	return m.references
+}
+
+func (m *rawManifest) Payload() (string, []byte, error) {
+	return m.mediaType, m.payload, nil
+}
+
+// buildManifest creates the manifest referring the config blob and the cell image layer in the given format.
+func buildManifest(format ManifestFormat, repository, tag string, config,
+	layer distribution.Descriptor) (distribution.Manifest, error) {
+	switch format {
+	case ManifestFormatOci:
+		ociManifest := ociv1.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			Config:    toOciDescriptor(config),
+			Layers:    []ociv1.Descriptor{toOciDescriptor(layer)},
+		}
+		payload, err := json.Marshal(struct {
+			MediaType string `json:"mediaType"`
+			ociv1.Manifest
+		}{ociv1.MediaTypeImageManifest, ociManifest})
+		if err != nil {
+			return nil, err
+		}
+		return &rawManifest{
+			mediaType:  ociv1.MediaTypeImageManifest,
+			payload:    payload,
+			references: []distribution.Descriptor{config, layer},
+		}, nil
+	case ManifestFormatSchema2:
+		return schema2.FromStruct(schema2.Manifest{
+			Versioned: manifest.Versioned{
+				SchemaVersion: 2,
+				MediaType:     schema2.MediaTypeManifest,
+			},
+			Config: config,
+			Layers: []distribution.Descriptor{layer},
+		})
+	case ManifestFormatSchema1:
+		cellImageManifest := &schema1.Manifest{
+			Name: repository,
+			Versioned: manifest.Versioned{
+				SchemaVersion: 1,
+				MediaType:     schema1.MediaTypeSignedManifest,
+			},
+			Tag:          tag,
+			Architecture: "amd64",
+			FSLayers: []schema1.FSLayer{
+				{BlobSum: layer.Digest},
+			},
+			History: []schema1.History{
+				{},
+			},
+		}
+		// Schema 1 manifests are required to be signed
+		key, err := libtrust.GenerateECP256PrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		return schema1.Sign(cellImageManifest, key)
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %s", format)
+	}
+}
+
+func toOciDescriptor(descriptor distribution.Descriptor) ociv1.Descriptor {
+	return ociv1.Descriptor{
+		MediaType: descriptor.MediaType,
+		Digest:    descriptor.Digest,
+		Size:      descriptor.Size,
+	}
+}
+
+// manifestStatusError is returned when the registry responds with an unexpected status to a manifest request.
+type manifestStatusError struct {
+	statusCode int
+	status     string
+	reference  string
+}
+
+func (e *manifestStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %s while fetching manifest %s", e.status, e.reference)
+}
+
+// fetchManifest downloads the manifest addressed by repository/reference accepting all the manifest types
+// a cell image can be stored with.
+func fetchManifest(hub *registry2.Registry, repository, reference string) (string, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/%s/manifests/%s", hub.URL, repository,
+		reference), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Accept", strings.Join(acceptedManifestMediaTypes, ", "))
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, &manifestStatusError{statusCode: resp.StatusCode, status: resp.Status, reference: reference}
+	}
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	mediaType := resp.Header.Get("Content-Type")
+	if index := strings.Index(mediaType, ";"); index >= 0 {
+		mediaType = mediaType[:index]
+	}
+	if mediaType == "" || mediaType == "application/json" {
+		// Some registries do not set the content type, fall back to the media type in the manifest
+		versioned := manifest.Versioned{}
+		if err := json.Unmarshal(payload, &versioned); err == nil && versioned.MediaType != "" {
+			mediaType = versioned.MediaType
+		} else if versioned.SchemaVersion == 1 {
+			mediaType = schema1.MediaTypeSignedManifest
+		}
+	}
+	return mediaType, payload, nil
+}
+
+// resolveCellImageLayer finds the layer holding the cell image zip in the manifest addressed by
+// repository/reference. Image indexes and manifest lists are resolved to the first manifest they refer.
+func resolveCellImageLayer(hub *registry2.Registry, repository, reference string) (distribution.Descriptor, error) {
+	return resolveDescriptor(hub, repository, reference, cellImageLayer)
+}
+
+// resolveCellImageConfig finds the config blob holding the metadata.json of the cell image in the manifest
+// addressed by repository/reference.
+func resolveCellImageConfig(hub *registry2.Registry, repository, reference string) (distribution.Descriptor, error) {
+	return resolveDescriptor(hub, repository, reference, cellImageConfig)
+}
+
+// resolveDescriptor finds a descriptor in the manifest addressed by repository/reference using the find function,
+// following at most one level of image indexes.
+func resolveDescriptor(hub *registry2.Registry, repository, reference string,
+	find func(mediaType string, payload []byte) (distribution.Descriptor, string, error)) (distribution.Descriptor,
+	error) {
+	mediaType, payload, err := fetchManifest(hub, repository, reference)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	descriptor, nextReference, err := find(mediaType, payload)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	if nextReference == "" {
+		return descriptor, nil
+	}
+	mediaType, payload, err = fetchManifest(hub, repository, nextReference)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	descriptor, nextReference, err = find(mediaType, payload)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	if nextReference != "" {
+		return distribution.Descriptor{}, fmt.Errorf("invalid cell image, nested image indexes are not supported")
+	}
+	return descriptor, nil
+}
+
+// cellImageLayer returns the layer holding the cell image zip in the manifest. If the manifest is an image index
+// or a manifest list, the reference to the manifest to be used is returned instead.
+func cellImageLayer(mediaType string, payload []byte) (distribution.Descriptor, string, error) {
+	switch mediaType {
+	case ociv1.MediaTypeImageManifest, schema2.MediaTypeManifest:
+		imageManifest := struct {
+			Layers []distribution.Descriptor `json:"layers"`
+		}{}
+		if err := json.Unmarshal(payload, &imageManifest); err != nil {
+			return distribution.Descriptor{}, "", fmt.Errorf("invalid cell image manifest, %v", err)
+		}
+		for _, layer := range imageManifest.Layers {
+			if layer.MediaType == MediaTypeCellImageLayer {
+				return layer, "", nil
+			}
+		}
+		if len(imageManifest.Layers) == 1 {
+			return imageManifest.Layers[0], "", nil
+		}
+		return distribution.Descriptor{}, "", fmt.Errorf("invalid cell image, expected a layer of type %s, "+
+			"but found %d layers", MediaTypeCellImageLayer, len(imageManifest.Layers))
+	case ociv1.MediaTypeImageIndex, manifestlist.MediaTypeManifestList:
+		index := struct {
+			Manifests []distribution.Descriptor `json:"manifests"`
+		}{}
+		if err := json.Unmarshal(payload, &index); err != nil {
+			return distribution.Descriptor{}, "", fmt.Errorf("invalid cell image index, %v", err)
+		}
+		if len(index.Manifests) == 0 {
+			return distribution.Descriptor{}, "", fmt.Errorf("invalid cell image, empty image index")
+		}
+		return distribution.Descriptor{}, index.Manifests[0].Digest.String(), nil
+	case schema1.MediaTypeSignedManifest, schema1.MediaTypeManifest:
+		signedManifest := &schema1.SignedManifest{}
+		if err := json.Unmarshal(payload, signedManifest); err != nil {
+			return distribution.Descriptor{}, "", fmt.Errorf("invalid cell image manifest, %v", err)
+		}
+		if len(signedManifest.References()) != 1 {
+			return distribution.Descriptor{}, "", fmt.Errorf("invalid cell image, expected exactly 1 File Layer, "+
+				"but found %d", len(signedManifest.References()))
+		}
+		return signedManifest.References()[0], "", nil
+	default:
+		return distribution.Descriptor{}, "", fmt.Errorf("unexpected manifest type %s received from the registry",
+			mediaType)
+	}
+}
+
+// cellImageConfig returns the config blob holding the metadata.json of the cell image in the manifest. If the
+// manifest is an image index or a manifest list, the reference to the manifest to be used is returned instead.
+func cellImageConfig(mediaType string, payload []byte) (distribution.Descriptor, string, error) {
+	switch mediaType {
+	case ociv1.MediaTypeImageManifest, schema2.MediaTypeManifest:
+		imageManifest := struct {
+			Config distribution.Descriptor `json:"config"`
+		}{}
+		if err := json.Unmarshal(payload, &imageManifest); err != nil {
+			return distribution.Descriptor{}, "", fmt.Errorf("invalid cell image manifest, %v", err)
+		}
+		if imageManifest.Config.MediaType != MediaTypeCellImageConfig {
+			return distribution.Descriptor{}, "", fmt.Errorf("cell image metadata not found in the manifest, "+
+				"expected a config of type %s, but found %s", MediaTypeCellImageConfig,
+				imageManifest.Config.MediaType)
+		}
+		return imageManifest.Config, "", nil
+	case ociv1.MediaTypeImageIndex, manifestlist.MediaTypeManifestList:
+		_, nextReference, err := cellImageLayer(mediaType, payload)
+		return distribution.Descriptor{}, nextReference, err
+	case schema1.MediaTypeSignedManifest, schema1.MediaTypeManifest:
+		return distribution.Descriptor{}, "", fmt.Errorf("cell image metadata not found in the manifest, " +
+			"the image was pushed using the schema1 manifest format")
+	default:
+		return distribution.Descriptor{}, "", fmt.Errorf("unexpected manifest type %s received from the registry",
+			mediaType)
+	}
+}
+
+// readMetaDataFile reads the metadata.json of the cell image zip, which is used as the config blob.
+func readMetaDataFile(cellImage io.ReaderAt, size int64) ([]byte, error) {
+	zipReader, err := zip.NewReader(cellImage, size)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while reading the cell image, %v", err)
+	}
+	for _, file := range zipReader.File {
+		if file.Name != image.MetaDataFile() {
+			continue
+		}
+		metaReader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer metaReader.Close()
+		return ioutil.ReadAll(metaReader)
+	}
+	return nil, fmt.Errorf("missing metadata information in the cell image")
+}
+
+func newDescriptor(mediaType string, content []byte) distribution.Descriptor {
+	return distribution.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+}
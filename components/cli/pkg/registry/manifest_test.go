@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/google/go-cmp/cmp"
+	registry2 "github.com/nokia/docker-registry-client/registry"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestBuildManifest(t *testing.T) {
+	config := newDescriptor(MediaTypeCellImageConfig, []byte(`{"kind":"Cell"}`))
+	layer := newDescriptor(MediaTypeCellImageLayer, []byte("cell image"))
+	tests := []struct {
+		name              string
+		format            ManifestFormat
+		expectedMediaType string
+		expectedRefs      int
+	}{
+		{
+			name:              "oci manifest",
+			format:            ManifestFormatOci,
+			expectedMediaType: ociv1.MediaTypeImageManifest,
+			expectedRefs:      2,
+		},
+		{
+			name:              "schema2 manifest",
+			format:            ManifestFormatSchema2,
+			expectedMediaType: schema2.MediaTypeManifest,
+			expectedRefs:      2,
+		},
+		{
+			name:              "schema1 manifest",
+			format:            ManifestFormatSchema1,
+			expectedMediaType: "application/vnd.docker.distribution.manifest.v1+prettyjws",
+			expectedRefs:      1,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			cellImageManifest, err := buildManifest(tst.format, "myorg/hello", "1.0.0", config, layer)
+			if err != nil {
+				t.Fatalf("error in buildManifest, %v", err)
+			}
+			mediaType, payload, err := cellImageManifest.Payload()
+			if err != nil {
+				t.Fatalf("error getting manifest payload, %v", err)
+			}
+			if diff := cmp.Diff(tst.expectedMediaType, mediaType); diff != "" {
+				t.Errorf("buildManifest: unexpected media type (-want, +got)\n%v", diff)
+			}
+			if diff := cmp.Diff(tst.expectedRefs, len(cellImageManifest.References())); diff != "" {
+				t.Errorf("buildManifest: unexpected references (-want, +got)\n%v", diff)
+			}
+			// The cell image layer should be found when pulling the manifest
+			actualLayer, _, err := cellImageLayer(mediaType, payload)
+			if err != nil {
+				t.Fatalf("error in cellImageLayer, %v", err)
+			}
+			if diff := cmp.Diff(layer.Digest, actualLayer.Digest); diff != "" {
+				t.Errorf("cellImageLayer: unexpected layer (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestResolveCellImageLayerFromIndex(t *testing.T) {
+	layer := newDescriptor(MediaTypeCellImageLayer, []byte("cell image"))
+	manifest := ociv1.Manifest{
+		Config: toOciDescriptor(newDescriptor(MediaTypeCellImageConfig, []byte(`{}`))),
+		Layers: []ociv1.Descriptor{toOciDescriptor(layer)},
+	}
+	manifestBytes, _ := json.Marshal(manifest)
+	manifestDescriptor := newDescriptor(ociv1.MediaTypeImageManifest, manifestBytes)
+	index, _ := json.Marshal(struct {
+		Manifests []distribution.Descriptor `json:"manifests"`
+	}{[]distribution.Descriptor{manifestDescriptor}})
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/v2/myorg/hello/manifests/1.0.0":
+			w.Header().Set("Content-Type", ociv1.MediaTypeImageIndex)
+			w.Write(index)
+		case fmt.Sprintf("/v2/myorg/hello/manifests/%s", manifestDescriptor.Digest):
+			w.Header().Set("Content-Type", ociv1.MediaTypeImageManifest)
+			w.Write(manifestBytes)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	hub := &registry2.Registry{URL: server.URL, Client: server.Client(), Logf: registry2.Quiet}
+
+	actualLayer, err := resolveCellImageLayer(hub, "myorg/hello", "1.0.0")
+	if err != nil {
+		t.Fatalf("error in resolveCellImageLayer, %v", err)
+	}
+	if diff := cmp.Diff(layer, actualLayer); diff != "" {
+		t.Errorf("resolveCellImageLayer: unexpected layer (-want, +got)\n%v", diff)
+	}
+	if diff := cmp.Diff(2, len(requests)); diff != "" {
+		t.Errorf("resolveCellImageLayer: unexpected number of requests (-want, +got)\n%v", diff)
+	}
+}
+
+func TestValidateManifestFormat(t *testing.T) {
+	if err := ValidateManifestFormat("oci"); err != nil {
+		t.Errorf("ValidateManifestFormat: unexpected error, %v", err)
+	}
+	err := ValidateManifestFormat("schema3")
+	if diff := cmp.Diff("unsupported manifest format schema3, expected one of oci|schema2|schema1",
+		fmt.Sprint(err)); diff != "" {
+		t.Errorf("ValidateManifestFormat: unexpected error (-want, +got)\n%v", diff)
+	}
+}
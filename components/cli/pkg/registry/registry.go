@@ -19,19 +19,11 @@
 package registry
 
 import (
-	"bytes"
-	"crypto/sha256"
-	"encoding/hex"
-	"errors"
 	"fmt"
 	"io"
 	"os"
 
-	"io/ioutil"
-
-	"github.com/docker/distribution/manifest"
-	"github.com/docker/distribution/manifest/schema1"
-	"github.com/docker/libtrust"
+	"github.com/docker/distribution"
 	registry2 "github.com/nokia/docker-registry-client/registry"
 	"github.com/opencontainers/go-digest"
 
@@ -40,8 +32,17 @@ import (
 )
 
 type Registry interface {
-	Pull(parsedCellImage *image.CellImage, username string, password string) ([]byte, error)
-	Push(parsedCellImage *image.CellImage, fileBytes []byte, username, password string) error
+	Pull(parsedCellImage *image.CellImage, username string, password string, cellImage io.Writer,
+		progress ProgressFunc) error
+	Push(parsedCellImage *image.CellImage, cellImage Blob, username, password string,
+		manifestFormat ManifestFormat, progress ProgressFunc) error
+	Repositories(registryHost, username, password string) ([]string, error)
+	Tags(registryHost, repository, username, password string) ([]string, error)
+	MetaData(parsedCellImage *image.CellImage, username, password string) (*image.MetaData, error)
+	PushSignature(parsedCellImage *image.CellImage, imageDigest digest.Digest, signature []byte, username,
+		password string) error
+	PullSignature(parsedCellImage *image.CellImage, imageDigest digest.Digest, username,
+		password string) ([]byte, error)
 	Out() io.Writer
 }
 
@@ -54,7 +55,8 @@ func NewCelleryRegistry() *CelleryRegistry {
 	return registry
 }
 
-func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileBytes []byte, username, password string) error {
+func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, cellImage Blob, username, password string,
+	manifestFormat ManifestFormat, progress ProgressFunc) error {
 	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nConnecting to %s", util.Bold(parsedCellImage.Registry)))
 	// Initiating a connection to Cellery Registry
 	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
@@ -65,14 +67,14 @@ func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileByte
 		parsedCellImage.ImageVersion)
 	repository := parsedCellImage.Organization + "/" + parsedCellImage.ImageName
 	// Creating the Cell Image Digest (Docker file Layer digest)
-	hash := sha256.New()
-	hash.Write(fileBytes)
-	sha256sum := hex.EncodeToString(hash.Sum(nil))
-	cellImageDigest := digest.NewDigestFromEncoded(digest.SHA256, sha256sum)
+	cellImageLayer, err := blobDescriptor(MediaTypeCellImageLayer, cellImage)
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the cell image, %v", err)
+	}
 
 	// Checking if the the Cell Image already exists in the registry
 	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nChecking if the image %s already exists in the Registry", util.Bold(imageName)))
-	cellImageDigestExists, err := hub.HasBlob(repository, cellImageDigest)
+	cellImageDigestExists, err := hub.HasBlob(repository, cellImageLayer.Digest)
 	if err != nil {
 		return err
 	}
@@ -80,8 +82,7 @@ func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileByte
 	// Pushing the cell image if it is not already uploaded
 	if !cellImageDigestExists {
 		fmt.Fprintln(registry.Out(), fmt.Sprintf("\nPushing image %s", util.Bold(imageName)))
-		// Read stream of files
-		err = hub.UploadBlob(repository, cellImageDigest, bytes.NewReader(fileBytes), nil)
+		err = uploadBlobInChunks(hub, repository, cellImageLayer, cellImage, progress)
 		if err != nil {
 			return err
 		}
@@ -89,90 +90,60 @@ func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileByte
 		fmt.Fprintln(registry.Out(), fmt.Sprintf("\nUsing already existing image %s in %s Registry", util.Bold(imageName),
 			util.Bold(parsedCellImage.Registry)))
 	}
-
-	// Creating a Docker manifest to be uploaded
-	cellImageManifest := &schema1.Manifest{
-		Name: repository,
-		Versioned: manifest.Versioned{
-			SchemaVersion: 1,
-			MediaType:     schema1.MediaTypeSignedManifest,
-		},
-		Tag:          parsedCellImage.ImageVersion,
-		Architecture: "amd64",
-		FSLayers: []schema1.FSLayer{
-			{BlobSum: cellImageDigest},
-		},
-		History: []schema1.History{
-			{},
-		},
-	}
-	// Signing the Docker Manifest
-	key, err := libtrust.GenerateECP256PrivateKey()
-	if err != nil {
-		return fmt.Errorf("error occurred while pushing the cell image, %v", err)
+	var cellImageConfig distribution.Descriptor
+	if manifestFormat != ManifestFormatSchema1 {
+		// The metadata of the cell image is stored as the config blob, hence it can be read without
+		// downloading the cell image
+		metadata, err := readMetaDataFile(cellImage, cellImageLayer.Size)
+		if err != nil {
+			return fmt.Errorf("error occurred while pushing the cell image, %v", err)
+		}
+		cellImageConfig = newDescriptor(MediaTypeCellImageConfig, metadata)
+		if err := uploadContentIfMissing(hub, repository, cellImageConfig, metadata); err != nil {
+			return err
+		}
 	}
-	signedCellImageManifest, err := schema1.Sign(cellImageManifest, key)
+
+	// Creating the manifest to be uploaded
+	cellImageManifest, err := buildManifest(manifestFormat, repository, parsedCellImage.ImageVersion,
+		cellImageConfig, cellImageLayer)
 	if err != nil {
 		return fmt.Errorf("error occurred while pushing the cell image, %v", err)
 	}
 
 	// Uploading the manifest to the Cellery Registry (Docker Registry)
-	err = hub.PutManifest(repository, parsedCellImage.ImageVersion, signedCellImageManifest)
+	err = hub.PutManifest(repository, parsedCellImage.ImageVersion, cellImageManifest)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nImage Digest : %s\n", util.Bold(cellImageDigest)))
+	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nImage Digest : %s\n", util.Bold(cellImageLayer.Digest)))
 	return nil
 }
 
-func (registry *CelleryRegistry) Pull(parsedCellImage *image.CellImage, username string, password string) ([]byte, error) {
-	var cellImage []byte
+func (registry *CelleryRegistry) Pull(parsedCellImage *image.CellImage, username string, password string,
+	cellImage io.Writer, progress ProgressFunc) error {
 	repository := parsedCellImage.Organization + "/" + parsedCellImage.ImageName
 	// Initiating a connection to Cellery Registry
 	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+		return fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
 	}
-	// Fetching the Docker Image Manifest
-	cellImageManifest, err := hub.Manifest(repository, parsedCellImage.ImageVersion)
+	// Fetching the manifest and finding the layer holding the cell image
+	cellImageReference, err := resolveCellImageLayer(hub, repository, parsedCellImage.ImageVersion)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var cellImageDigest digest.Digest
-	if len(cellImageManifest.References()) == 1 {
-		cellImageReference := cellImageManifest.References()[0]
-		cellImageDigest = cellImageReference.Digest
-
-		imageName := fmt.Sprintf("%s/%s:%s", parsedCellImage.Organization, parsedCellImage.ImageName,
-			parsedCellImage.ImageVersion)
-		fmt.Fprintln(registry.Out(), fmt.Sprintf("\nPulling image %s", util.Bold(imageName)))
 
-		// Downloading the Cell Image from the repository
-		reader, err := hub.DownloadBlob(repository, cellImageReference.Digest)
-		if err != nil {
-			return nil, err
-		}
-		if reader != nil {
-			defer func() error {
-				err = reader.Close()
-				if err != nil {
-					return fmt.Errorf("error occurred while cleaning up, %v", err)
-				}
-				return nil
-			}()
-		}
-		cellImage, err = ioutil.ReadAll(reader)
-		if err != nil {
-			return nil, fmt.Errorf("error occurred while pulling cell image, %v", err)
-		}
+	imageName := fmt.Sprintf("%s/%s:%s", parsedCellImage.Organization, parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion)
+	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nPulling image %s", util.Bold(imageName)))
 
-	} else {
-		return nil, fmt.Errorf("invalid cell image, %v",
-			errors.New(fmt.Sprintf("expected exactly 1 File Layer, but found %d",
-				len(cellImageManifest.References()))))
+	// Downloading the Cell Image from the repository
+	if err := downloadBlob(hub, repository, cellImageReference, cellImage, progress); err != nil {
+		return fmt.Errorf("error occurred while pulling cell image, %v", err)
 	}
-	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nImage Digest : %s\n", util.Bold(cellImageDigest)))
-	return cellImage, nil
+	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nImage Digest : %s\n", util.Bold(cellImageReference.Digest)))
+	return nil
 }
 
 // Out returns the writer used for the stdout.
@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	registry2 "github.com/nokia/docker-registry-client/registry"
+
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+// Repositories lists the repositories available in the catalog of the registry.
+func (registry *CelleryRegistry) Repositories(registryHost, username, password string) ([]string, error) {
+	hub, err := registry2.New("https://"+registryHost, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+	}
+	repositories, err := hub.Repositories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the catalog of Cellery Registry %s, %v", registryHost, err)
+	}
+	return repositories, nil
+}
+
+// Tags lists the tags of a repository in the registry.
+func (registry *CelleryRegistry) Tags(registryHost, repository, username, password string) ([]string, error) {
+	hub, err := registry2.New("https://"+registryHost, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+	}
+	tags, err := hub.Tags(repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the tags of %s, %v", repository, err)
+	}
+	return tags, nil
+}
+
+// MetaData reads the metadata.json of a cell image from its config blob without downloading the cell image.
+func (registry *CelleryRegistry) MetaData(parsedCellImage *image.CellImage, username,
+	password string) (*image.MetaData, error) {
+	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+	}
+	return readRemoteMetaData(hub, parsedCellImage.Organization+"/"+parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion)
+}
+
+func readRemoteMetaData(hub *registry2.Registry, repository, reference string) (*image.MetaData, error) {
+	config, err := resolveCellImageConfig(hub, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+	var metadataJson bytes.Buffer
+	if err := downloadBlob(hub, repository, config, &metadataJson, nil); err != nil {
+		return nil, fmt.Errorf("error occurred while reading cell image metadata, %v", err)
+	}
+	metadata := &image.MetaData{}
+	if err := json.Unmarshal(metadataJson.Bytes(), metadata); err != nil {
+		return nil, fmt.Errorf("invalid cell image metadata, %v", err)
+	}
+	return metadata, nil
+}
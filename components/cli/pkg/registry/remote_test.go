@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	registry2 "github.com/nokia/docker-registry-client/registry"
+)
+
+func TestReadRemoteMetaData(t *testing.T) {
+	metadata := []byte(`{"kind":"Cell","buildTimestamp":1565000000,"buildCelleryVersion":"0.5.0"}`)
+	config := newDescriptor(MediaTypeCellImageConfig, metadata)
+	layer := newDescriptor(MediaTypeCellImageLayer, []byte("cell image"))
+	tests := []struct {
+		name          string
+		format        ManifestFormat
+		expectedError bool
+	}{
+		{
+			name:   "oci manifest",
+			format: ManifestFormatOci,
+		},
+		{
+			name:   "schema2 manifest",
+			format: ManifestFormatSchema2,
+		},
+		{
+			name:          "schema1 manifest without metadata",
+			format:        ManifestFormatSchema1,
+			expectedError: true,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			imageManifest, err := buildManifest(tst.format, "myorg/hello", "1.0.0", config, layer)
+			if err != nil {
+				t.Fatalf("error building the manifest, %v", err)
+			}
+			mediaType, payload, _ := imageManifest.Payload()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v2/myorg/hello/manifests/1.0.0":
+					w.Header().Set("Content-Type", mediaType)
+					w.Write(payload)
+				case fmt.Sprintf("/v2/myorg/hello/blobs/%s", config.Digest):
+					w.Write(metadata)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			hub := &registry2.Registry{URL: server.URL, Client: server.Client(), Logf: registry2.Quiet}
+
+			actual, err := readRemoteMetaData(hub, "myorg/hello", "1.0.0")
+			if tst.expectedError {
+				if err == nil {
+					t.Errorf("readRemoteMetaData: expected an error for a %s manifest", tst.format)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in readRemoteMetaData, %v", err)
+			}
+			if diff := cmp.Diff([]string{"Cell", "0.5.0"}, []string{actual.Kind,
+				actual.BuildCelleryVersion}); diff != "" {
+				t.Errorf("readRemoteMetaData: unexpected metadata (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestReadRemoteMetaDataNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	hub := &registry2.Registry{URL: server.URL, Client: server.Client(), Logf: registry2.Quiet}
+
+	_, err := readRemoteMetaData(hub, "myorg/hello", "1.0.0")
+	if diff := cmp.Diff("unexpected status 404 Not Found while fetching manifest 1.0.0", fmt.Sprint(err)); diff != "" {
+		t.Errorf("readRemoteMetaData: unexpected error (-want, +got)\n%v", diff)
+	}
+}
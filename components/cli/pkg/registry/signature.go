@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/docker/distribution"
+	registry2 "github.com/nokia/docker-registry-client/registry"
+	"github.com/opencontainers/go-digest"
+
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+var signatureTagPattern = regexp.MustCompile("^[a-z0-9]+-[a-f0-9]+\\.sig$")
+
+// PushSignature stores the detached signature of the cell image with the given digest in the registry. The
+// signature is stored as an OCI artifact tagged with the digest of the cell image.
+func (registry *CelleryRegistry) PushSignature(parsedCellImage *image.CellImage, imageDigest digest.Digest,
+	signature []byte, username, password string) error {
+	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
+	if err != nil {
+		return fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+	}
+	repository := parsedCellImage.Organization + "/" + parsedCellImage.ImageName
+	config := newDescriptor(MediaTypeCellImageSignatureConfig, []byte("{}"))
+	layer := newDescriptor(MediaTypeCellImageSignature, signature)
+	if err := uploadContentIfMissing(hub, repository, config, []byte("{}")); err != nil {
+		return fmt.Errorf("failed to upload the signature, %v", err)
+	}
+	if err := uploadContentIfMissing(hub, repository, layer, signature); err != nil {
+		return fmt.Errorf("failed to upload the signature, %v", err)
+	}
+	signatureManifest, err := buildManifest(ManifestFormatOci, repository, signatureTag(imageDigest), config, layer)
+	if err != nil {
+		return err
+	}
+	if err := hub.PutManifest(repository, signatureTag(imageDigest), signatureManifest); err != nil {
+		return fmt.Errorf("failed to upload the signature, %v", err)
+	}
+	return nil
+}
+
+// PullSignature fetches the detached signature of the cell image with the given digest from the registry.
+// No signature is returned if the cell image is not signed.
+func (registry *CelleryRegistry) PullSignature(parsedCellImage *image.CellImage, imageDigest digest.Digest,
+	username, password string) ([]byte, error) {
+	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+	}
+	return readSignature(hub, parsedCellImage.Organization+"/"+parsedCellImage.ImageName, imageDigest)
+}
+
+func readSignature(hub *registry2.Registry, repository string, imageDigest digest.Digest) ([]byte, error) {
+	signatureLayer, err := resolveDescriptor(hub, repository, signatureTag(imageDigest),
+		func(mediaType string, payload []byte) (distribution.Descriptor, string, error) {
+			layer, nextReference, err := cellImageLayer(mediaType, payload)
+			if err == nil && nextReference == "" && layer.MediaType != MediaTypeCellImageSignature {
+				return layer, "", fmt.Errorf("invalid signature, expected a layer of type %s, but found %s",
+					MediaTypeCellImageSignature, layer.MediaType)
+			}
+			return layer, nextReference, err
+		})
+	if statusErr, ok := err.(*manifestStatusError); ok && statusErr.statusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the signature, %v", err)
+	}
+	var signature bytes.Buffer
+	if err := downloadBlob(hub, repository, signatureLayer, &signature, nil); err != nil {
+		return nil, fmt.Errorf("failed to fetch the signature, %v", err)
+	}
+	return signature.Bytes(), nil
+}
+
+// signatureTag returns the tag of the signature of the cell image with the given digest.
+func signatureTag(imageDigest digest.Digest) string {
+	return fmt.Sprintf("%s-%s.sig", imageDigest.Algorithm(), imageDigest.Encoded())
+}
+
+// IsSignatureTag checks whether a tag holds the signature of a cell image instead of a cell image.
+func IsSignatureTag(tag string) bool {
+	return signatureTagPattern.MatchString(tag)
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	registry2 "github.com/nokia/docker-registry-client/registry"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestReadSignature(t *testing.T) {
+	imageDigest := digest.FromString("cell image")
+	signature := []byte(`{"image":"myorg/hello:1.0.0"}`)
+	config := newDescriptor(MediaTypeCellImageSignatureConfig, []byte("{}"))
+	layer := newDescriptor(MediaTypeCellImageSignature, signature)
+	signatureManifest, _ := buildManifest(ManifestFormatOci, "myorg/hello", signatureTag(imageDigest), config, layer)
+	mediaType, payload, _ := signatureManifest.Payload()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/v2/myorg/hello/manifests/sha256-%s.sig", imageDigest.Encoded()):
+			w.Header().Set("Content-Type", mediaType)
+			w.Write(payload)
+		case fmt.Sprintf("/v2/myorg/hello/blobs/%s", layer.Digest):
+			w.Write(signature)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	hub := &registry2.Registry{URL: server.URL, Client: server.Client(), Logf: registry2.Quiet}
+
+	actual, err := readSignature(hub, "myorg/hello", imageDigest)
+	if err != nil {
+		t.Fatalf("error in readSignature, %v", err)
+	}
+	if diff := cmp.Diff(string(signature), string(actual)); diff != "" {
+		t.Errorf("readSignature: unexpected signature (-want, +got)\n%v", diff)
+	}
+
+	actual, err = readSignature(hub, "myorg/hello", digest.FromString("unsigned cell image"))
+	if err != nil || actual != nil {
+		t.Errorf("readSignature: expected no signature for an unsigned image, got %s, %v", actual, err)
+	}
+}
@@ -0,0 +1,370 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package revision
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+)
+
+// HistoryLimit is the number of revisions kept for an instance
+const HistoryLimit = 10
+
+const historyConfigMapSuffix = "--revision-history"
+const historyLabel = "mesh.cellery.io/revision-history-of"
+const revisionKeyPrefix = "revision-"
+const configMapKind = "configmap"
+
+var yamlDocumentSeparator = regexp.MustCompile("(?m)^---\\s*$")
+
+// Resource is a Kubernetes resource which is modified by an operation on an instance
+type Resource struct {
+	// Kind is the lower case kind of the resource qualified with the API group (eg:- cell.mesh.cellery.io)
+	Kind string
+	Name string
+}
+
+// Revision is a snapshot of the resources of an instance taken before they were modified
+type Revision struct {
+	Number int `json:"revision"`
+	// Cause is the operation which modified the resources after the snapshot was taken
+	Cause     string `json:"cause"`
+	Timestamp int64  `json:"timestamp"`
+	// Resources is the gzip compressed yaml of the resources
+	Resources []byte `json:"resources"`
+}
+
+type configMap struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   configMapMetadata `json:"metadata"`
+	Data       map[string]string `json:"data"`
+}
+
+type configMapMetadata struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// HistoryConfigMapName returns the name of the ConfigMap holding the revision history of an instance
+func HistoryConfigMapName(instance string) string {
+	return instance + historyConfigMapSuffix
+}
+
+// ResourcesInFile returns the resources declared in a yaml file
+func ResourcesInFile(file string) ([]Resource, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s, %v", file, err)
+	}
+	var resources []Resource
+	for _, document := range yamlDocumentSeparator.Split(string(content), -1) {
+		object := struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return nil, fmt.Errorf("error parsing %s, %v", file, err)
+		}
+		if object.Kind == "" || object.Metadata.Name == "" {
+			continue
+		}
+		kind := strings.ToLower(object.Kind)
+		if parts := strings.SplitN(object.APIVersion, "/", 2); len(parts) == 2 {
+			kind += "." + parts[0]
+		}
+		resources = append(resources, Resource{Kind: kind, Name: object.Metadata.Name})
+	}
+	return resources, nil
+}
+
+// Snapshot is the state of the resources of an instance taken before they are modified. It is only added to the
+// revision history once the resources are modified successfully, hence a failed modification leaves no revision.
+type Snapshot struct {
+	instance string
+	// resources is the gzip compressed yaml of the resources, which is empty if none of the resources exist
+	resources []byte
+}
+
+// TakeSnapshot takes a snapshot of the current state of the resources of an instance
+func TakeSnapshot(cli cli.Cli, instance string, resources []Resource) (*Snapshot, error) {
+	var snapshots []string
+	for _, resource := range resources {
+		snapshot, err := snapshotResource(cli, resource)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != "" {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	if len(snapshots) == 0 {
+		return &Snapshot{instance: instance}, nil
+	}
+	compressed, err := compress([]byte(strings.Join(snapshots, "---\n")))
+	if err != nil {
+		return nil, fmt.Errorf("error compressing the revision of instance %s, %v", instance, err)
+	}
+	return &Snapshot{instance: instance, resources: compressed}, nil
+}
+
+// Record adds the snapshot to the revision history of the instance as the state before the cause. The oldest
+// revisions are dropped once the history exceeds the HistoryLimit.
+func (snapshot *Snapshot) Record(cli cli.Cli, cause string) error {
+	if len(snapshot.resources) == 0 {
+		log.Printf("No existing resources found to record a revision of instance %s", snapshot.instance)
+		return nil
+	}
+	history, err := History(cli, snapshot.instance)
+	if err != nil {
+		return err
+	}
+	number := 1
+	if len(history) > 0 {
+		number = history[len(history)-1].Number + 1
+	}
+	history = append(history, &Revision{
+		Number:    number,
+		Cause:     cause,
+		Timestamp: time.Now().Unix(),
+		Resources: snapshot.resources,
+	})
+	if len(history) > HistoryLimit {
+		history = history[len(history)-HistoryLimit:]
+	}
+	return saveHistory(cli, snapshot.instance, history)
+}
+
+// History returns the revisions of an instance ordered from the oldest to the latest
+func History(cli cli.Cli, instance string) ([]*Revision, error) {
+	var history []*Revision
+	out, err := cli.KubeCli().GetInstanceBytes(configMapKind, HistoryConfigMapName(instance))
+	if err != nil {
+		if isNotFound(err) {
+			return history, nil
+		}
+		return nil, fmt.Errorf("error getting the revision history of instance %s, %v", instance, err)
+	}
+	historyConfigMap := &configMap{}
+	if err := json.Unmarshal(out, historyConfigMap); err != nil {
+		return nil, fmt.Errorf("error reading the revision history of instance %s, %v", instance, err)
+	}
+	for key, value := range historyConfigMap.Data {
+		if !strings.HasPrefix(key, revisionKeyPrefix) {
+			continue
+		}
+		revision := &Revision{}
+		if err := json.Unmarshal([]byte(value), revision); err != nil {
+			return nil, fmt.Errorf("error reading revision %s of instance %s, %v", key, instance, err)
+		}
+		history = append(history, revision)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Number < history[j].Number
+	})
+	return history, nil
+}
+
+// Restore applies the resources of a revision of the instance. The latest revision is restored if the number is
+// zero. The state before the restore is recorded as a new revision, hence a restore can be undone as well.
+func Restore(cli cli.Cli, instance string, number int) (*Revision, error) {
+	history, err := History(cli, instance)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("no revisions found for instance %s", instance)
+	}
+	revision := history[len(history)-1]
+	if number != 0 {
+		revision = nil
+		for _, r := range history {
+			if r.Number == number {
+				revision = r
+			}
+		}
+		if revision == nil {
+			return nil, fmt.Errorf("revision %d of instance %s not found", number, instance)
+		}
+	}
+	content, err := revision.Content()
+	if err != nil {
+		return nil, err
+	}
+	var snapshot *Snapshot
+	if err := applyContent(cli, fmt.Sprintf("%s-revision-%d", instance, revision.Number), content,
+		func(file string) error {
+			resources, err := ResourcesInFile(file)
+			if err != nil {
+				return err
+			}
+			snapshot, err = TakeSnapshot(cli, instance, resources)
+			return err
+		}); err != nil {
+		return nil, err
+	}
+	if err := snapshot.Record(cli, fmt.Sprintf("undo to revision %d", revision.Number)); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// Content returns the yaml of the resources of the revision
+func (revision *Revision) Content() ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(revision.Resources))
+	if err != nil {
+		return nil, fmt.Errorf("error reading revision %d, %v", revision.Number, err)
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+func snapshotResource(cli cli.Cli, resource Resource) (string, error) {
+	out, err := cli.KubeCli().GetInstanceBytes(resource.Kind, resource.Name)
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error getting %s %s, %v", resource.Kind, resource.Name, err)
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return "", nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(out, &object); err != nil {
+		return "", fmt.Errorf("error reading %s %s, %v", resource.Kind, resource.Name, err)
+	}
+	// Removing the fields set by the API server, which would prevent applying the snapshot later
+	delete(object, "status")
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"resourceVersion", "uid", "selfLink", "creationTimestamp", "generation",
+			"managedFields"} {
+			delete(metadata, field)
+		}
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		}
+	}
+	snapshot, err := yaml.Marshal(object)
+	if err != nil {
+		return "", fmt.Errorf("error writing %s %s, %v", resource.Kind, resource.Name, err)
+	}
+	return string(snapshot), nil
+}
+
+func saveHistory(cli cli.Cli, instance string, history []*Revision) error {
+	historyConfigMap := &configMap{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata: configMapMetadata{
+			Name:   HistoryConfigMapName(instance),
+			Labels: map[string]string{historyLabel: instance},
+		},
+		Data: map[string]string{},
+	}
+	for _, revision := range history {
+		value, err := json.Marshal(revision)
+		if err != nil {
+			return fmt.Errorf("error writing revision %d of instance %s, %v", revision.Number, instance, err)
+		}
+		historyConfigMap.Data[revisionKeyPrefix+strconv.Itoa(revision.Number)] = string(value)
+	}
+	content, err := yaml.Marshal(historyConfigMap)
+	if err != nil {
+		return fmt.Errorf("error writing the revision history of instance %s, %v", instance, err)
+	}
+	// The history is replaced instead of being applied, since applying would copy the whole history to the last
+	// applied configuration annotation, which is limited to a fraction of the size allowed for the ConfigMap
+	return withContentFile(HistoryConfigMapName(instance), content, func(file string) error {
+		if err := cli.KubeCli().ReplaceFile(file); err != nil {
+			return fmt.Errorf("error saving the revision history of instance %s, %v", instance, err)
+		}
+		return nil
+	})
+}
+
+// applyContent writes the content to a temporary file and applies it. The beforeApply function is called with the
+// file before the content is applied.
+func applyContent(cli cli.Cli, name string, content []byte, beforeApply func(file string) error) error {
+	return withContentFile(name, content, func(file string) error {
+		if beforeApply != nil {
+			if err := beforeApply(file); err != nil {
+				return err
+			}
+		}
+		if err := cli.KubeCli().ApplyFile(file); err != nil {
+			return fmt.Errorf("error applying %s, %v", name, err)
+		}
+		return nil
+	})
+}
+
+// withContentFile writes the content to a temporary file, which is removed after calling the function with it
+func withContentFile(name string, content []byte, fn func(file string) error) error {
+	file, err := ioutil.TempFile("", name+"-*.yaml")
+	if err != nil {
+		return fmt.Errorf("error creating a temporary file, %v", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing %s, %v", file.Name(), err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing %s, %v", file.Name(), err)
+	}
+	return fn(file.Name())
+}
+
+func compress(content []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func isNotFound(err error) bool {
+	if _, ok := err.(errorpkg.NotFoundError); ok {
+		return true
+	}
+	return strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "not found")
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package revision
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/internal/test"
+)
+
+const expectedHelloSnapshot = `apiVersion: mesh.cellery.io/v1alpha2
+kind: Cell
+metadata:
+  annotations:
+    mesh.cellery.io/cell-image-name: hello
+  name: hello
+spec:
+  components:
+  - metadata:
+      name: controller
+    spec:
+      template:
+        containers:
+        - image: wso2cellery/hello:1.0.0
+          name: controller
+`
+
+func newMockKubeCli(t *testing.T) *test.MockKubeCli {
+	helloCell, err := ioutil.ReadFile(filepath.Join("testdata", "hello-cell.json"))
+	if err != nil {
+		t.Fatalf("failed to read the hello cell, %v", err)
+	}
+	return test.NewMockKubeCli(test.WithResources(map[string][]byte{
+		"cell.mesh.cellery.io/hello": helloCell,
+	}))
+}
+
+func TestResourcesInFile(t *testing.T) {
+	resources, err := ResourcesInFile(filepath.Join("testdata", "patched-hello.yaml"))
+	if err != nil {
+		t.Fatalf("error reading resources, %v", err)
+	}
+	want := []Resource{
+		{Kind: "cell.mesh.cellery.io", Name: "hello"},
+		{Kind: "virtualservice.networking.istio.io", Name: "hello--vs"},
+	}
+	if diff := cmp.Diff(want, resources); diff != "" {
+		t.Errorf("invalid resources (-want, +got)\n%v", diff)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	mockKubeCli := newMockKubeCli(t)
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	resources, err := ResourcesInFile(filepath.Join("testdata", "patched-hello.yaml"))
+	if err != nil {
+		t.Fatalf("error reading resources, %v", err)
+	}
+	for i := 1; i <= HistoryLimit+2; i++ {
+		record(t, mockCli, "hello", fmt.Sprintf("change %d", i), resources)
+	}
+	// The history should not be applied, as that copies the history to the last applied configuration annotation
+	if len(mockKubeCli.AppliedFiles()) > 0 || len(mockKubeCli.ReplacedFiles()) != HistoryLimit+2 {
+		t.Errorf("expected the history to be replaced, applied %d and replaced %d file(s)",
+			len(mockKubeCli.AppliedFiles()), len(mockKubeCli.ReplacedFiles()))
+	}
+	history, err := History(mockCli, "hello")
+	if err != nil {
+		t.Fatalf("error getting history, %v", err)
+	}
+	var got []string
+	for _, revision := range history {
+		got = append(got, fmt.Sprintf("%d:%s", revision.Number, revision.Cause))
+	}
+	var want []string
+	for i := 3; i <= HistoryLimit+2; i++ {
+		want = append(want, fmt.Sprintf("%d:change %d", i, i))
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("invalid history (-want, +got)\n%v", diff)
+	}
+	content, err := history[0].Content()
+	if err != nil {
+		t.Fatalf("error reading revision content, %v", err)
+	}
+	// The virtual service does not exist, hence only the cell is recorded
+	if diff := cmp.Diff(expectedHelloSnapshot, string(content)); diff != "" {
+		t.Errorf("invalid revision content (-want, +got)\n%v", diff)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	mockKubeCli := newMockKubeCli(t)
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	resources := []Resource{{Kind: "cell.mesh.cellery.io", Name: "hello"}}
+	record(t, mockCli, "hello", "patch component controller", resources)
+	if _, err := Restore(mockCli, "hello", 5); err == nil || !strings.Contains(err.Error(), "revision 5") {
+		t.Errorf("expected an error for a missing revision, got %v", err)
+	}
+	restored, err := Restore(mockCli, "hello", 0)
+	if err != nil {
+		t.Fatalf("error restoring revision, %v", err)
+	}
+	if restored.Number != 1 {
+		t.Errorf("expected revision 1 to be restored, got %d", restored.Number)
+	}
+	appliedFiles := mockKubeCli.AppliedFiles()
+	if diff := cmp.Diff(expectedHelloSnapshot, appliedFiles[len(appliedFiles)-1]); diff != "" {
+		t.Errorf("invalid restored resources (-want, +got)\n%v", diff)
+	}
+	history, err := History(mockCli, "hello")
+	if err != nil {
+		t.Fatalf("error getting history, %v", err)
+	}
+	if len(history) != 2 || history[1].Cause != "undo to revision 1" {
+		t.Errorf("expected the undo to be recorded as a revision, got %d revisions", len(history))
+	}
+}
+
+func TestSnapshotRecordsStateBeforeChange(t *testing.T) {
+	mockKubeCli := newMockKubeCli(t)
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+	resources := []Resource{{Kind: "cell.mesh.cellery.io", Name: "hello"}}
+	snapshot, err := TakeSnapshot(mockCli, "hello", resources)
+	if err != nil {
+		t.Fatalf("error taking snapshot, %v", err)
+	}
+	// Nothing is recorded until the change is applied, hence a failed change does not leave a revision behind
+	history, err := History(mockCli, "hello")
+	if err != nil {
+		t.Fatalf("error getting history, %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("expected no revisions before the snapshot is recorded, got %d", len(history))
+	}
+	if err := snapshot.Record(mockCli, "patch component controller"); err != nil {
+		t.Fatalf("error recording revision, %v", err)
+	}
+	history, err = History(mockCli, "hello")
+	if err != nil {
+		t.Fatalf("error getting history, %v", err)
+	}
+	if len(history) != 1 || history[0].Cause != "patch component controller" {
+		t.Fatalf("expected the snapshot to be recorded, got %d revisions", len(history))
+	}
+	content, err := history[0].Content()
+	if err != nil {
+		t.Fatalf("error reading revision content, %v", err)
+	}
+	if diff := cmp.Diff(expectedHelloSnapshot, string(content)); diff != "" {
+		t.Errorf("invalid revision content (-want, +got)\n%v", diff)
+	}
+}
+
+func record(t *testing.T, cli cli.Cli, instance string, cause string, resources []Resource) {
+	snapshot, err := TakeSnapshot(cli, instance, resources)
+	if err != nil {
+		t.Fatalf("error taking snapshot, %v", err)
+	}
+	if err := snapshot.Record(cli, cause); err != nil {
+		t.Fatalf("error recording revision, %v", err)
+	}
+}
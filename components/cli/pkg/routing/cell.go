@@ -33,17 +33,25 @@ const k8sMetadata = "metadata"
 const k8sAnnotations = "annotations"
 const instanceIdHeaderName = "x-instance-id"
 
-func buildRoutesForCellTarget(cli cli.Cli, newTarget *kubernetes.Cell, src string, currentTarget string, percentage int, isSessionAware bool) (*kubernetes.VirtualService, error) {
+func buildRoutesForCellTarget(cli cli.Cli, newTarget *kubernetes.Cell, src string, currentTarget string, percentage int, isSessionAware bool,
+	matchRules []MatchRule, mirrorPercentage int) (*kubernetes.VirtualService, error) {
 	vs, err := cli.KubeCli().GetVirtualService(getVsName(src))
 	if err != nil {
 		return nil, err
 	}
+	if mirrorPercentage > 0 {
+		if err := checkNoActiveRouting(&vs, currentTarget, newTarget.CellMetaData.Name); err != nil {
+			return nil, err
+		}
+	}
 	// modify the vs to include new route information.
 	modfiedVss, err := getModifiedVsForCellTarget(vs, currentTarget, newTarget.CellMetaData.Name, percentage,
 		isSessionAware)
 	if err != nil {
 		return nil, err
 	}
+	applyMatchRules(modfiedVss, currentTarget, newTarget.CellMetaData.Name, matchRules)
+	applyMirror(modfiedVss, currentTarget, newTarget.CellMetaData.Name, mirrorPercentage)
 	return modfiedVss, nil
 }
 
@@ -176,7 +184,8 @@ func getModifiedVsForCellTarget(vs kubernetes.VirtualService, dependencyInst str
 						if percentageForTarget == 100 {
 							dependencyInst = targetInst
 						}
-						route, err := getHttRouteBasedOnInstanceId(&httpRule, instanceIdHeaderName, dependencyInst, targetInst)
+						route, err := getHttRouteBasedOnInstanceId(&httpRule, instanceIdHeaderName, dependencyInst,
+							targetInst, route.Destination.Port)
 						if err != nil {
 							return nil, err
 						}
@@ -184,12 +193,12 @@ func getModifiedVsForCellTarget(vs kubernetes.VirtualService, dependencyInst str
 
 					} else {
 						httpRule.Route = *buildPercentageBasedHttpRoutesForCellInstance(dependencyInst, targetInst,
-							percentageForTarget)
+							percentageForTarget, route.Destination.Port)
 					}
 					//goto outermostloop
 				} else {
 					httpRule.Route = *buildPercentageBasedHttpRoutesForCellInstance(dependencyInst, targetInst,
-						percentageForTarget)
+						percentageForTarget, route.Destination.Port)
 					//goto outermostloop
 				}
 			}
@@ -212,7 +221,7 @@ func getModifiedVsForCellTarget(vs kubernetes.VirtualService, dependencyInst str
 }
 
 func getHttRouteBasedOnInstanceId(httpRule *kubernetes.HTTP, sessionHeader string, dependencyInstance string,
-	targetInstance string) (*[]kubernetes.HTTPRoute, error) {
+	targetInstance string, port *kubernetes.PortSelector) (*[]kubernetes.HTTPRoute, error) {
 	for _, match := range httpRule.Match {
 		if match.Headers != nil && match.Headers[sessionHeader] != nil {
 			if match.Headers[sessionHeader].Exact == "1" {
@@ -220,6 +229,7 @@ func getHttRouteBasedOnInstanceId(httpRule *kubernetes.HTTP, sessionHeader strin
 					{
 						Destination: kubernetes.Destination{
 							Host: getCellGatewayHost(dependencyInstance),
+							Port: port,
 						},
 					},
 				}, nil
@@ -228,6 +238,7 @@ func getHttRouteBasedOnInstanceId(httpRule *kubernetes.HTTP, sessionHeader strin
 					{
 						Destination: kubernetes.Destination{
 							Host: getCellGatewayHost(targetInstance),
+							Port: port,
 						},
 					},
 				}, nil
@@ -252,14 +263,17 @@ func isSessionHeaderBasedRule(httpRule *kubernetes.HTTP, sessionHeader string) b
 	return false
 }
 
+// buildPercentageBasedHttpRoutesForCellInstance splits the traffic between the gateways of the dependency and the
+// target instances. The port of the existing route is kept, which is set for the gRPC APIs of the gateways.
 func buildPercentageBasedHttpRoutesForCellInstance(dependencyInst string, targetInst string,
-	percentageForTarget int) *[]kubernetes.HTTPRoute {
+	percentageForTarget int, port *kubernetes.PortSelector) *[]kubernetes.HTTPRoute {
 	var routes []kubernetes.HTTPRoute
 	if percentageForTarget == 100 {
 		// full traffic switch to target, need only one route
 		routes = append(routes, kubernetes.HTTPRoute{
 			Destination: kubernetes.Destination{
 				Host: getCellGatewayHost(targetInst),
+				Port: port,
 			},
 			Weight: 100,
 		})
@@ -268,6 +282,7 @@ func buildPercentageBasedHttpRoutesForCellInstance(dependencyInst string, target
 		existingRoute := kubernetes.HTTPRoute{
 			Destination: kubernetes.Destination{
 				Host: getCellGatewayHost(dependencyInst),
+				Port: port,
 			},
 			Weight: 100 - percentageForTarget,
 		}
@@ -275,6 +290,7 @@ func buildPercentageBasedHttpRoutesForCellInstance(dependencyInst string, target
 		newRoute := kubernetes.HTTPRoute{
 			Destination: kubernetes.Destination{
 				Host: getCellGatewayHost(targetInst),
+				Port: port,
 			},
 			Weight: percentageForTarget,
 		}
@@ -317,6 +333,17 @@ func buildTcpRoutes(dependencyInst string, targetInst string, port kubernetes.TC
 	return &routes
 }
 
+// checkForSupportedApis checks whether the traffic can be switched to the target instance. The HTTP and the gRPC
+// APIs are routed with the http rules of the virtual services, while the TCP APIs are not supported yet.
+func checkForSupportedApis(target *kubernetes.Cell) error {
+	ingress := target.CellSpec.GateWayTemplate.GatewaySpec.Ingress
+	// TODO: remove this once TCP is supported
+	if len(ingress.HttpApis) == 0 && len(ingress.GrpcApis) == 0 && len(ingress.TcpApis) > 0 {
+		return fmt.Errorf("traffic switching to TCP cells not supported")
+	}
+	return nil
+}
+
 func checkForMatchingApis(currentTarget *kubernetes.Cell, newTarget *kubernetes.Cell) error {
 outer:
 	for _, currTargetGwApi := range currentTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.HttpApis {
@@ -340,6 +367,22 @@ outer:
 			currTargetGwApi.Context, currTargetGwApi.Version, "",
 		}
 	}
+	return checkForMatchingGrpcApis(currentTarget, newTarget)
+}
+
+// checkForMatchingGrpcApis checks whether the gRPC APIs of the current target are exposed by the new target on the
+// same gateway ports, as the clients address the gRPC services by the port
+func checkForMatchingGrpcApis(currentTarget *kubernetes.Cell, newTarget *kubernetes.Cell) error {
+outer:
+	for _, currTargetGrpcApi := range currentTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.GrpcApis {
+		for _, newTargetGrpcApi := range newTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.GrpcApis {
+			if currTargetGrpcApi.Port == newTargetGrpcApi.Port {
+				continue outer
+			}
+		}
+		return fmt.Errorf("gRPC API exposed on port %d of instance %s not found in instance %s",
+			currTargetGrpcApi.Port, currentTarget.CellMetaData.Name, newTarget.CellMetaData.Name)
+	}
 	return nil
 }
 
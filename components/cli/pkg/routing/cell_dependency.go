@@ -29,6 +29,7 @@ import (
 const compositeDependencyKind = "Composite"
 const cellDependencyKind = "Cell"
 const instance = "instance"
+const dependencyAlias = "alias"
 const imageOrg = "org"
 const imageName = "name"
 const imageVersion = "version"
@@ -36,7 +37,12 @@ const dependencyKind = "kind"
 
 type Route interface {
 	Check() error
-	Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error
+	// Build writes the rules routing a percentage of the traffic to the new target, along with the requests matching
+	// the match rules. A percentage of the traffic is mirrored to the new target if the mirror percentage is not zero.
+	Build(cli cli.Cli, percentage int, isSessionAware bool, matchRules []MatchRule, mirrorPercentage int,
+		routesFile string) error
+	// Source returns the name of the instance of which the traffic is routed
+	Source() string
 }
 
 func ExtractDependencies(depJson string) ([]map[string]string, error) {
@@ -19,7 +19,6 @@
 package routing
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/ghodss/yaml"
@@ -34,12 +33,13 @@ type CellToCellRoute struct {
 	NewTarget     kubernetes.Cell
 }
 
+func (router *CellToCellRoute) Source() string {
+	return router.Src.CellMetaData.Name
+}
+
 func (router *CellToCellRoute) Check() error {
-	// if the target instance has only TCP components exposed, will not work.
-	// TODO: remove this once TCP is supported
-	if len(router.NewTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.HttpApis) == 0 &&
-		len(router.NewTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.TcpApis) > 0 {
-		return fmt.Errorf("traffic switching to TCP cells not supported")
+	if err := checkForSupportedApis(&router.NewTarget); err != nil {
+		return err
 	}
 	// check if APIs are matching
 	err := checkForMatchingApis(&router.CurrentTarget, &router.NewTarget)
@@ -49,9 +49,10 @@ func (router *CellToCellRoute) Check() error {
 	return nil
 }
 
-func (router *CellToCellRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error {
+func (router *CellToCellRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, matchRules []MatchRule,
+	mirrorPercentage int, routesFile string) error {
 	modfiedVss, err := buildRoutesForCellTarget(cli, &router.NewTarget, router.Src.CellMetaData.Name,
-		router.CurrentTarget.CellMetaData.Name, percentage, isSessionAware)
+		router.CurrentTarget.CellMetaData.Name, percentage, isSessionAware, matchRules, mirrorPercentage)
 	if err != nil {
 		return err
 	}
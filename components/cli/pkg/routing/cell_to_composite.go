@@ -33,13 +33,19 @@ type CellToCompositeRoute struct {
 	NewTarget     kubernetes.Composite
 }
 
+func (router *CellToCompositeRoute) Source() string {
+	return router.Src.CellMetaData.Name
+}
+
 func (router *CellToCompositeRoute) Check() error {
 	return nil
 }
 
-func (router *CellToCompositeRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error {
+func (router *CellToCompositeRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, matchRules []MatchRule,
+	mirrorPercentage int, routesFile string) error {
 
-	modfiedVs, err := buildRoutesForCompositeTarget(cli, router.Src.CellMetaData.Name, &router.NewTarget, &router.CurrentTarget, percentage)
+	modfiedVs, err := buildRoutesForCompositeTarget(cli, router.Src.CellMetaData.Name, &router.NewTarget, &router.CurrentTarget, percentage,
+		matchRules, mirrorPercentage)
 	if err != nil {
 		return err
 	}
@@ -33,7 +33,7 @@ type origComponentData struct {
 }
 
 func buildRoutesForCompositeTarget(cli cli.Cli, src string, newTarget *kubernetes.Composite, currentTarget *kubernetes.Composite,
-	percentage int) (*kubernetes.VirtualService, error) {
+	percentage int, matchRules []MatchRule, mirrorPercentage int) (*kubernetes.VirtualService, error) {
 	// check if components in previous dependency and this dependency matches
 	if !doComponentsMatch(&currentTarget.CompositeSpec.ComponentTemplates,
 		&newTarget.CompositeSpec.ComponentTemplates) {
@@ -43,12 +43,20 @@ func buildRoutesForCompositeTarget(cli cli.Cli, src string, newTarget *kubernete
 	if err != nil {
 		return nil, err
 	}
+	if mirrorPercentage > 0 {
+		if err := checkNoActiveRouting(&vs, currentTarget.CompositeMetaData.Name,
+			newTarget.CompositeMetaData.Name); err != nil {
+			return nil, err
+		}
+	}
 	// modify the vs to include new route information.
 	modifiedVs, err := getModifiedVsForCompositeTarget(&vs, currentTarget.CompositeMetaData.Name,
 		newTarget.CompositeMetaData.Name, percentage, &newTarget.CompositeSpec.ComponentTemplates)
 	if err != nil {
 		return nil, err
 	}
+	applyMatchRules(modifiedVs, currentTarget.CompositeMetaData.Name, newTarget.CompositeMetaData.Name, matchRules)
+	applyMirror(modifiedVs, currentTarget.CompositeMetaData.Name, newTarget.CompositeMetaData.Name, mirrorPercentage)
 	return modifiedVs, nil
 }
 
@@ -64,7 +72,7 @@ func getModifiedVsForCompositeTarget(vs *kubernetes.VirtualService, dependencyIn
 					if strings.Contains(route.Destination.Host, "--"+compTemplate.Metadata.Name) {
 						// for each component in target composite inst, modify the rules
 						httpRule.Route = *buildPercentageBasedHttpRoutesForCompositeInstance(dependencyInst, targetInst,
-							&compTemplate, percentageForTarget)
+							&compTemplate, percentageForTarget, route.Destination.Port)
 						goto outermostloop
 					}
 				}
@@ -181,13 +189,15 @@ func buildDependencyCompositeServiceAnnotaion(composite *kubernetes.Composite) (
 }
 
 func buildPercentageBasedHttpRoutesForCompositeInstance(dependencyInst string, targetInst string,
-	compTemplate *kubernetes.ComponentTemplate, percentageForTarget int) *[]kubernetes.HTTPRoute {
+	compTemplate *kubernetes.ComponentTemplate, percentageForTarget int,
+	port *kubernetes.PortSelector) *[]kubernetes.HTTPRoute {
 	var routes []kubernetes.HTTPRoute
 	if percentageForTarget == 100 {
 		// full traffic switch to target, need only one route
 		routes = append(routes, kubernetes.HTTPRoute{
 			Destination: kubernetes.Destination{
 				Host: getCompositeServiceHost(targetInst, compTemplate.Metadata.Name),
+				Port: port,
 			},
 			Weight: 100,
 		})
@@ -196,6 +206,7 @@ func buildPercentageBasedHttpRoutesForCompositeInstance(dependencyInst string, t
 		existingRoute := kubernetes.HTTPRoute{
 			Destination: kubernetes.Destination{
 				Host: getCompositeServiceHost(dependencyInst, compTemplate.Metadata.Name),
+				Port: port,
 			},
 			Weight: 100 - percentageForTarget,
 		}
@@ -203,6 +214,7 @@ func buildPercentageBasedHttpRoutesForCompositeInstance(dependencyInst string, t
 		newRoute := kubernetes.HTTPRoute{
 			Destination: kubernetes.Destination{
 				Host: getCompositeServiceHost(targetInst, compTemplate.Metadata.Name),
+				Port: port,
 			},
 			Weight: percentageForTarget,
 		}
@@ -218,21 +230,38 @@ func getModifiedDependencies(depJson string, existingDependency string, newDepen
 	if err != nil {
 		return "", err
 	}
-	// copy all except previous dependency
+	// replace the previous dependency in place, keeping any other details recorded for it
 	var newDependencies []map[string]string
+	replaced := false
 	for _, dependency := range dependencies {
 		if dependency[instance] != existingDependency {
 			newDependencies = append(newDependencies, dependency)
+			continue
+		}
+		if replaced {
+			continue
 		}
+		newDepMap := make(map[string]string, len(dependency))
+		for key, value := range dependency {
+			newDepMap[key] = value
+		}
+		newDepMap[instance] = newDependency
+		newDepMap[imageOrg] = newOrg
+		newDepMap[imageName] = newCellImage
+		newDepMap[imageVersion] = newVersion
+		newDepMap[dependencyKind] = srcDependencyKind
+		newDependencies = append(newDependencies, newDepMap)
+		replaced = true
+	}
+	if !replaced {
+		newDependencies = append(newDependencies, map[string]string{
+			instance:       newDependency,
+			imageOrg:       newOrg,
+			imageName:      newCellImage,
+			imageVersion:   newVersion,
+			dependencyKind: srcDependencyKind,
+		})
 	}
-	// create & add the new dependency
-	newDepMap := make(map[string]string)
-	newDepMap[instance] = newDependency
-	newDepMap[imageOrg] = newOrg
-	newDepMap[imageName] = newCellImage
-	newDepMap[imageVersion] = newVersion
-	newDepMap[dependencyKind] = srcDependencyKind
-	newDependencies = append(newDependencies, newDepMap)
 	// set the new dependencies to Cell
 	newDepByteArr, err := json.Marshal(newDependencies)
 	if err != nil {
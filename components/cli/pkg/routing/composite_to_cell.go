@@ -19,7 +19,6 @@
 package routing
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/ghodss/yaml"
@@ -34,12 +33,13 @@ type CompositeToCellRoute struct {
 	NewTarget     kubernetes.Cell
 }
 
+func (router *CompositeToCellRoute) Source() string {
+	return router.Src.CompositeMetaData.Name
+}
+
 func (router *CompositeToCellRoute) Check() error {
-	// if the target instance has only TCP components exposed, will not work.
-	// TODO: remove this once TCP is supported
-	if len(router.NewTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.HttpApis) == 0 &&
-		len(router.NewTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.TcpApis) > 0 {
-		return fmt.Errorf("traffic switching to TCP cells not supported")
+	if err := checkForSupportedApis(&router.NewTarget); err != nil {
+		return err
 	}
 	err := checkForMatchingApis(&router.CurrentTarget, &router.NewTarget)
 	if err != nil {
@@ -48,9 +48,10 @@ func (router *CompositeToCellRoute) Check() error {
 	return nil
 }
 
-func (router *CompositeToCellRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error {
+func (router *CompositeToCellRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, matchRules []MatchRule,
+	mirrorPercentage int, routesFile string) error {
 	modfiedVss, err := buildRoutesForCellTarget(cli, &router.NewTarget, router.Src.CompositeMetaData.Name,
-		router.CurrentTarget.CellMetaData.Name, percentage, isSessionAware)
+		router.CurrentTarget.CellMetaData.Name, percentage, isSessionAware, matchRules, mirrorPercentage)
 	if err != nil {
 		return err
 	}
@@ -33,14 +33,20 @@ type CompositeToCompositeRoute struct {
 	NewTarget     kubernetes.Composite
 }
 
+func (router *CompositeToCompositeRoute) Source() string {
+	return router.Src.CompositeMetaData.Name
+}
+
 func (router *CompositeToCompositeRoute) Check() error {
 	return nil
 }
 
-func (router *CompositeToCompositeRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error {
+func (router *CompositeToCompositeRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, matchRules []MatchRule,
+	mirrorPercentage int, routesFile string) error {
 
 	modfiedVs, err := buildRoutesForCompositeTarget(cli, router.Src.CompositeMetaData.Name, &router.NewTarget,
-		&router.CurrentTarget, percentage)
+		&router.CurrentTarget, percentage, matchRules,
+		mirrorPercentage)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,244 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	errors "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// Fault describes the faults injected into a percentage of the requests sent to a dependency
+type Fault struct {
+	Delay       time.Duration
+	AbortStatus int
+	Percentage  int
+}
+
+// Validate checks whether the fault delays or aborts a valid percentage of the requests
+func (fault *Fault) Validate() error {
+	if fault.Delay <= 0 && fault.AbortStatus == 0 {
+		return fmt.Errorf("expects a delay or an abort status for the fault")
+	}
+	if fault.Delay < 0 {
+		return fmt.Errorf("invalid delay %s", fault.Delay)
+	}
+	if fault.AbortStatus != 0 && (fault.AbortStatus < 200 || fault.AbortStatus > 599) {
+		return fmt.Errorf("invalid abort status %d, expects a http status code between 200 and 599",
+			fault.AbortStatus)
+	}
+	if fault.Percentage < 1 || fault.Percentage > 100 {
+		return fmt.Errorf("invalid percentage %d, expects a value between 1 and 100", fault.Percentage)
+	}
+	return nil
+}
+
+// faultsBeforeInjectionAnnotation holds the faults of the http rules of a virtual service found before faults were
+// injected into the requests sent to each dependency, keyed by the dependency and the destination hosts of the rules
+const faultsBeforeInjectionAnnotation = "mesh.cellery.io/faults-before-injection"
+
+// BuildFaultArtifact writes the virtual service of an instance injecting a fault into the requests sent to a
+// dependency. The faults found before the first injection are recorded in the virtual service, so that they can be
+// restored using BuildFaultRestoreArtifact. All the faults of the requests are cleared if the fault is nil.
+func BuildFaultArtifact(cli cli.Cli, instance string, dependency string, fault *Fault, artifactFile string) error {
+	vs, rules, err := getDependencyRules(cli, instance, dependency)
+	if err != nil {
+		return err
+	}
+	recorded, err := faultsBeforeInjection(vs)
+	if err != nil {
+		return err
+	}
+	var httpFault *kubernetes.HTTPFaultInjection
+	if fault == nil {
+		delete(recorded, dependency)
+	} else {
+		httpFault = buildHttpFault(fault)
+		if _, ok := recorded[dependency]; !ok {
+			hostFaults := make(map[string]*kubernetes.HTTPFaultInjection)
+			for _, rule := range rules {
+				for _, route := range rule.Route {
+					if _, ok := hostFaults[route.Destination.Host]; !ok {
+						hostFaults[route.Destination.Host] = rule.Fault
+					}
+				}
+			}
+			recorded[dependency] = hostFaults
+		}
+	}
+	for _, rule := range rules {
+		rule.Fault = httpFault
+	}
+	return writeFaultArtifact(vs, recorded, artifactFile)
+}
+
+// BuildFaultRestoreArtifact writes the virtual service of an instance with the faults of the requests sent to a
+// dependency restored to the faults recorded before the faults were injected. The fault of each http rule is
+// restored from the fault recorded for its destination host, hence the rules added or removed since the injection do
+// not affect the other rules. False is returned if the faults were not recorded, in which case all the faults of the
+// requests are cleared.
+func BuildFaultRestoreArtifact(cli cli.Cli, instance string, dependency string, artifactFile string) (bool, error) {
+	vs, rules, err := getDependencyRules(cli, instance, dependency)
+	if err != nil {
+		return false, err
+	}
+	recorded, err := faultsBeforeInjection(vs)
+	if err != nil {
+		return false, err
+	}
+	hostFaults, found := recorded[dependency]
+	for _, rule := range rules {
+		rule.Fault = nil
+		for _, route := range rule.Route {
+			if previous, ok := hostFaults[route.Destination.Host]; ok {
+				rule.Fault = previous
+				break
+			}
+		}
+	}
+	delete(recorded, dependency)
+	return found, writeFaultArtifact(vs, recorded, artifactFile)
+}
+
+// ResolveDependency returns the dependency instance of an instance linked with the given alias. The dependency is
+// returned as it is if it is the name of a dependency instance of the instance.
+func ResolveDependency(cli cli.Cli, instanceName string, dependency string) (string, error) {
+	var dependencyAnnotation string
+	cell, err := cli.KubeCli().GetCell(instanceName)
+	if err != nil {
+		if notFound, _ := errors.IsCellInstanceNotFoundError(instanceName, err); !notFound {
+			return "", err
+		}
+		composite, err := cli.KubeCli().GetComposite(instanceName)
+		if err != nil {
+			return "", err
+		}
+		dependencyAnnotation = composite.CompositeMetaData.Annotations.Dependencies
+	} else {
+		dependencyAnnotation = cell.CellMetaData.Annotations.Dependencies
+	}
+	dependencies, err := ExtractDependencies(dependencyAnnotation)
+	if err != nil {
+		return "", fmt.Errorf("error reading the dependencies of instance %s, %v", instanceName, err)
+	}
+	for _, d := range dependencies {
+		if d[dependencyAlias] == dependency {
+			return d[instance], nil
+		}
+	}
+	for _, d := range dependencies {
+		if d[instance] == dependency {
+			return dependency, nil
+		}
+	}
+	return "", fmt.Errorf("instance %s does not depend on an instance with alias or name %s", instanceName,
+		dependency)
+}
+
+// getDependencyRules returns the virtual service of an instance along with its http rules routing the requests sent
+// to a dependency
+func getDependencyRules(cli cli.Cli, instance string, dependency string) (*kubernetes.VirtualService,
+	[]*kubernetes.HTTP, error) {
+	routes, err := GetRoutes(cli, []string{instance}, dependency, dependency)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting the routes from instance %s to instance %s, %v", instance,
+			dependency, err)
+	}
+	if len(routes) == 0 {
+		return nil, nil, fmt.Errorf("instance %s does not depend on instance %s", instance, dependency)
+	}
+	vs, err := cli.KubeCli().GetVirtualService(getVsName(instance))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting the virtual service of instance %s, %v", instance, err)
+	}
+	var rules []*kubernetes.HTTP
+	for i := range vs.VsSpec.HTTP {
+		// the rules routing the requests addressed to the dependency to another instance are included as well
+		if addressedInstance(&vs.VsSpec.HTTP[i]) == dependency {
+			rules = append(rules, &vs.VsSpec.HTTP[i])
+		}
+	}
+	if len(rules) == 0 {
+		return nil, nil, fmt.Errorf("no http routes found from instance %s to instance %s", instance, dependency)
+	}
+	return &vs, rules, nil
+}
+
+// faultsBeforeInjection returns the faults recorded in a virtual service before faults were injected, keyed by the
+// dependency and the destination host
+func faultsBeforeInjection(vs *kubernetes.VirtualService) (map[string]map[string]*kubernetes.HTTPFaultInjection,
+	error) {
+	recorded := make(map[string]map[string]*kubernetes.HTTPFaultInjection)
+	if value := vs.VsMetaData.Annotations[faultsBeforeInjectionAnnotation]; value != "" {
+		if err := json.Unmarshal([]byte(value), &recorded); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation of virtual service %s, %v",
+				faultsBeforeInjectionAnnotation, vs.VsMetaData.Name, err)
+		}
+	}
+	return recorded, nil
+}
+
+func writeFaultArtifact(vs *kubernetes.VirtualService,
+	recorded map[string]map[string]*kubernetes.HTTPFaultInjection, artifactFile string) error {
+	if vs.VsMetaData.Annotations == nil {
+		vs.VsMetaData.Annotations = make(map[string]string)
+	}
+	if len(recorded) == 0 {
+		delete(vs.VsMetaData.Annotations, faultsBeforeInjectionAnnotation)
+	} else {
+		value, err := json.Marshal(recorded)
+		if err != nil {
+			return err
+		}
+		vs.VsMetaData.Annotations[faultsBeforeInjectionAnnotation] = string(value)
+	}
+	content, err := yaml.Marshal(vs)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(artifactFile, content, 0644)
+}
+
+func buildHttpFault(fault *Fault) *kubernetes.HTTPFaultInjection {
+	httpFault := &kubernetes.HTTPFaultInjection{}
+	percentage := &kubernetes.Percent{
+		Value: float64(fault.Percentage),
+	}
+	if fault.Delay > 0 {
+		httpFault.Delay = &kubernetes.FaultDelay{
+			Percentage: percentage,
+			FixedDelay: strconv.FormatFloat(fault.Delay.Seconds(), 'f', -1, 64) + "s",
+		}
+	}
+	if fault.AbortStatus != 0 {
+		httpFault.Abort = &kubernetes.FaultAbort{
+			Percentage: percentage,
+			HttpStatus: fault.AbortStatus,
+		}
+	}
+	return httpFault
+}
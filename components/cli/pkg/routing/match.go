@@ -0,0 +1,228 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+const cookieHeaderName = "cookie"
+
+// MatchRule routes the requests matching all of its conditions to the target instance, regardless of the percentage
+// of the traffic routed to the target instance
+type MatchRule struct {
+	Headers map[string]*kubernetes.StringMatch `json:"headers,omitempty"`
+	Cookie  *Cookie                            `json:"cookie,omitempty"`
+	Uri     *kubernetes.StringMatch            `json:"uri,omitempty"`
+	Grpc    *GrpcMethod                        `json:"grpc,omitempty"`
+}
+
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// GrpcMethod matches the gRPC calls to a method, or to all the methods of a service if the method is not given.
+// The service is the fully qualified name of the service. Ex: helloworld.Greeter
+type GrpcMethod struct {
+	Service string `json:"service"`
+	Method  string `json:"method,omitempty"`
+}
+
+// uri returns the condition matching the path of the HTTP/2 requests carrying the gRPC calls
+func (method *GrpcMethod) uri() *kubernetes.StringMatch {
+	if method.Method == "" {
+		return &kubernetes.StringMatch{Prefix: fmt.Sprintf("/%s/", method.Service)}
+	}
+	return &kubernetes.StringMatch{Exact: fmt.Sprintf("/%s/%s", method.Service, method.Method)}
+}
+
+type matchRulesFile struct {
+	Rules []MatchRule `json:"rules"`
+}
+
+// ReadMatchRules reads the match rules from a yaml file. A request is routed to the target instance if it matches
+// any of the rules.
+func ReadMatchRules(file string) ([]MatchRule, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading match rules file %s, %v", file, err)
+	}
+	rules := &matchRulesFile{}
+	if err := yaml.Unmarshal(content, rules); err != nil {
+		return nil, fmt.Errorf("error parsing match rules file %s, %v", file, err)
+	}
+	if len(rules.Rules) == 0 {
+		return nil, fmt.Errorf("no match rules found in %s", file)
+	}
+	for i := range rules.Rules {
+		if err := rules.Rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid match rule %d in %s, %v", i+1, file, err)
+		}
+	}
+	return rules.Rules, nil
+}
+
+// Validate checks whether the rule has at least one condition and normalizes the header names to lower case,
+// which is required by Istio
+func (rule *MatchRule) Validate() error {
+	if len(rule.Headers) == 0 && rule.Cookie == nil && rule.Uri == nil && rule.Grpc == nil {
+		return fmt.Errorf("at least one header, cookie, uri or grpc condition is required")
+	}
+	headers := make(map[string]*kubernetes.StringMatch, len(rule.Headers))
+	for name, value := range rule.Headers {
+		name = strings.ToLower(name)
+		if name == instanceIdHeaderName || name == cookieHeaderName {
+			return fmt.Errorf("header %s cannot be matched, use the session awareness or the cookie conditions "+
+				"instead", name)
+		}
+		if err := validateStringMatch(value); err != nil {
+			return fmt.Errorf("invalid condition for header %s, %v", name, err)
+		}
+		headers[name] = value
+	}
+	rule.Headers = headers
+	if rule.Cookie != nil && (rule.Cookie.Name == "" || strings.ContainsAny(rule.Cookie.Name, "=; ")) {
+		return fmt.Errorf("invalid cookie name %q", rule.Cookie.Name)
+	}
+	if rule.Uri != nil {
+		if err := validateStringMatch(rule.Uri); err != nil {
+			return fmt.Errorf("invalid condition for uri, %v", err)
+		}
+	}
+	if rule.Grpc != nil {
+		if rule.Uri != nil {
+			return fmt.Errorf("uri and grpc conditions cannot be used together")
+		}
+		if rule.Grpc.Service == "" || strings.ContainsAny(rule.Grpc.Service, "/ ") ||
+			strings.ContainsAny(rule.Grpc.Method, "/ ") {
+			return fmt.Errorf("invalid grpc condition, expects a service name with an optional method name")
+		}
+	}
+	return nil
+}
+
+func validateStringMatch(match *kubernetes.StringMatch) error {
+	var conditions int
+	for _, condition := range []string{match.Exact, match.Prefix, match.Regex} {
+		if condition != "" {
+			conditions++
+		}
+	}
+	if conditions != 1 {
+		return fmt.Errorf("expects exactly one of exact, prefix or regex")
+	}
+	return nil
+}
+
+// httpMatch adds the conditions of the rule to a match of an existing http rule
+func (rule *MatchRule) httpMatch(match kubernetes.HTTPMatch) kubernetes.HTTPMatch {
+	headers := make(map[string]*kubernetes.StringMatch)
+	for name, value := range match.Headers {
+		headers[name] = value
+	}
+	for name, value := range rule.Headers {
+		headers[name] = value
+	}
+	if rule.Cookie != nil {
+		headers[cookieHeaderName] = &kubernetes.StringMatch{
+			Regex: fmt.Sprintf("^(.*;\\s*)?%s=%s(;.*)?$", regexp.QuoteMeta(rule.Cookie.Name),
+				regexp.QuoteMeta(rule.Cookie.Value)),
+		}
+	}
+	match.Headers = headers
+	if rule.Uri != nil {
+		match.Uri = rule.Uri
+	}
+	if rule.Grpc != nil {
+		match.Uri = rule.Grpc.uri()
+	}
+	return match
+}
+
+// applyMatchRules replaces the match based http rules of the dependency and the target instances with the given
+// match rules. A http rule routing the matching requests to the target instance is added ahead of each percentage
+// based http rule of the dependency instance.
+func applyMatchRules(vs *kubernetes.VirtualService, dependencyInst string, targetInst string,
+	matchRules []MatchRule) {
+	var httpRules []kubernetes.HTTP
+	for _, httpRule := range vs.VsSpec.HTTP {
+		targetRoute := findRoute(&httpRule, targetInst)
+		if targetRoute == nil && findRoute(&httpRule, dependencyInst) == nil {
+			httpRules = append(httpRules, httpRule)
+			continue
+		}
+		if isMatchBasedRule(&httpRule) {
+			// added by a previous traffic routing, hence replaced by the given match rules
+			continue
+		}
+		if targetRoute != nil && !isSessionHeaderBasedRule(&httpRule, instanceIdHeaderName) {
+			for _, matchRule := range matchRules {
+				var matches []kubernetes.HTTPMatch
+				for _, match := range httpRule.Match {
+					matches = append(matches, matchRule.httpMatch(match))
+				}
+				httpRules = append(httpRules, kubernetes.HTTP{
+					Match: matches,
+					Route: []kubernetes.HTTPRoute{
+						{
+							Destination: targetRoute.Destination,
+							Weight:      100,
+						},
+					},
+					Timeout: httpRule.Timeout,
+					Retries: httpRule.Retries,
+					Fault:   httpRule.Fault,
+				})
+			}
+		}
+		httpRules = append(httpRules, httpRule)
+	}
+	vs.VsSpec.HTTP = httpRules
+}
+
+func findRoute(httpRule *kubernetes.HTTP, instance string) *kubernetes.HTTPRoute {
+	for i, route := range httpRule.Route {
+		if strings.HasPrefix(route.Destination.Host, instance+"--") {
+			return &httpRule.Route[i]
+		}
+	}
+	return nil
+}
+
+func isMatchBasedRule(httpRule *kubernetes.HTTP) bool {
+	for _, match := range httpRule.Match {
+		if match.Uri != nil {
+			return true
+		}
+		for name := range match.Headers {
+			if name != instanceIdHeaderName {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"fmt"
+
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// applyMirror mirrors a percentage of the requests routed by the http rules of the dependency and the target instances
+// to the target instance. The responses of the target instance are discarded. Mirroring is removed from the http
+// rules if the percentage is zero.
+func applyMirror(vs *kubernetes.VirtualService, dependencyInst string, targetInst string, mirrorPercentage int) {
+	for i, httpRule := range vs.VsSpec.HTTP {
+		targetRoute := findRoute(&httpRule, targetInst)
+		if targetRoute == nil && findRoute(&httpRule, dependencyInst) == nil {
+			continue
+		}
+		httpRule.Mirror = nil
+		httpRule.MirrorPercent = 0
+		if mirrorPercentage > 0 && targetRoute != nil {
+			httpRule.Mirror = &kubernetes.Destination{
+				Host: targetRoute.Destination.Host,
+			}
+			httpRule.MirrorPercent = mirrorPercentage
+		}
+		vs.VsSpec.HTTP[i] = httpRule
+	}
+}
+
+// checkNoActiveRouting returns an error if the http rules of the virtual service route any traffic to the target
+// instance. Mirroring routes all the traffic back to the dependency instance, hence it would silently undo an active
+// split or match based routing.
+func checkNoActiveRouting(vs *kubernetes.VirtualService, dependencyInst string, targetInst string) error {
+	for _, httpRule := range vs.VsSpec.HTTP {
+		targetRoute := findRoute(&httpRule, targetInst)
+		if targetRoute == nil {
+			continue
+		}
+		// the weight of a single route is not set as it receives all the traffic
+		if len(httpRule.Route) == 1 || targetRoute.Weight > 0 {
+			return fmt.Errorf("traffic of instance %s is routed to instance %s, traffic can only be mirrored while "+
+				"all the traffic is routed to instance %s, route the traffic back using --percentage 0 before "+
+				"mirroring", dependencyInst, targetInst, dependencyInst)
+		}
+	}
+	return nil
+}
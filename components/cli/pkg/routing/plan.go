@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+)
+
+const (
+	FindingSeverityWarning = "warning"
+	FindingSeverityError   = "error"
+)
+
+// Plan is a set of routing changes built without modifying any instance, which can be reviewed before being applied
+// as is
+type Plan struct {
+	Dependency       string         `json:"dependency"`
+	Target           string         `json:"target"`
+	Percentage       int            `json:"percentage"`
+	MirrorPercentage int            `json:"mirrorPercentage,omitempty"`
+	SessionAwareness bool           `json:"sessionAwareness,omitempty"`
+	MatchRules       []MatchRule    `json:"matchRules,omitempty"`
+	Routes           []PlannedRoute `json:"routes"`
+}
+
+// PlannedRoute holds the API compatibility findings of a source instance and the resources of the source instance
+// modified by the plan. The resources are not built if there are blocking findings.
+type PlannedRoute struct {
+	Source    string                   `json:"source"`
+	Findings  []Finding                `json:"findings,omitempty"`
+	Resources []map[string]interface{} `json:"resources,omitempty"`
+}
+
+// Finding is an API compatibility issue found between the dependency and the target instances. Traffic routing
+// continues with a warning only if it is confirmed, while an error blocks the routing.
+type Finding struct {
+	Severity         string `json:"severity"`
+	Message          string `json:"message"`
+	ApiContext       string `json:"apiContext,omitempty"`
+	CurrentVersion   string `json:"currentVersion,omitempty"`
+	AvailableVersion string `json:"availableVersion,omitempty"`
+}
+
+// BlockingFindings returns the number of findings which prevent the plan from being applied
+func (plan *Plan) BlockingFindings() int {
+	var count int
+	for _, route := range plan.Routes {
+		for _, finding := range route.Findings {
+			if finding.Severity == FindingSeverityError {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// ReadPlan reads a plan written in yaml and checks whether it can be applied
+func ReadPlan(file string) (*Plan, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading routing plan file %s, %v", file, err)
+	}
+	plan := &Plan{}
+	if err := yaml.Unmarshal(content, plan); err != nil {
+		return nil, fmt.Errorf("error parsing routing plan file %s, %v", file, err)
+	}
+	if plan.Dependency == "" || plan.Target == "" {
+		return nil, fmt.Errorf("dependency and target instances not found in routing plan file %s", file)
+	}
+	if len(plan.Routes) == 0 {
+		return nil, fmt.Errorf("no routes found in routing plan file %s", file)
+	}
+	if blocking := plan.BlockingFindings(); blocking > 0 {
+		return nil, fmt.Errorf("routing plan file %s has %d blocking finding(s)", file, blocking)
+	}
+	for _, route := range plan.Routes {
+		if len(route.Resources) == 0 {
+			return nil, fmt.Errorf("no resources found for source instance %s in routing plan file %s",
+				route.Source, file)
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,367 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/policies"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// BuildResilienceArtifacts writes the virtual service and the destination rules applying a resilience policy to the
+// dependencies of an instance. The timeouts and the retries are set in the http rules of the virtual service, while
+// the outlier detection and the connection pool settings are set in a destination rule for each service host of a
+// dependency. A destination rule is shared by all the instances applying the same settings to a host, and a policy
+// with settings different from the ones applied by other instances is rejected. The names of the destination rules
+// no longer required by any instance are returned, so that they can be deleted.
+func BuildResilienceArtifacts(cli cli.Cli, instance string, owner *kubernetes.OwnerReference,
+	policy *policies.ResiliencePolicy, artifactFile string) ([]string, error) {
+	vs, err := cli.KubeCli().GetVirtualService(getVsName(instance))
+	if err != nil {
+		return nil, fmt.Errorf("error getting the virtual service of instance %s, %v", instance, err)
+	}
+	dependencyPolicies := make(map[string]*policies.DependencyPolicy)
+	for i, rule := range policy.Rules {
+		dependencyPolicies[rule.Target.Name] = &policy.Rules[i].Policy
+	}
+	dependenciesFound := make(map[string]bool)
+	hostDependencies := make(map[string]string)
+	var hosts []string
+	for i, httpRule := range vs.VsSpec.HTTP {
+		dependency := addressedInstance(&httpRule)
+		httpRule.Timeout = ""
+		httpRule.Retries = nil
+		if dependencyPolicy, ok := dependencyPolicies[dependency]; ok {
+			dependenciesFound[dependency] = true
+			httpRule.Timeout = formatDuration(dependencyPolicy.Timeout)
+			if dependencyPolicy.Retries != nil {
+				httpRule.Retries = &kubernetes.HTTPRetry{
+					Attempts:      dependencyPolicy.Retries.Attempts,
+					PerTryTimeout: formatDuration(dependencyPolicy.Retries.PerTryTimeout),
+					RetryOn:       dependencyPolicy.Retries.RetryOn,
+				}
+			}
+		}
+		vs.VsSpec.HTTP[i] = httpRule
+		for _, route := range httpRule.Route {
+			if _, ok := hostDependencies[route.Destination.Host]; !ok {
+				hostDependencies[route.Destination.Host] = dependency
+				hosts = append(hosts, route.Destination.Host)
+			}
+		}
+	}
+	for _, rule := range policy.Rules {
+		if !dependenciesFound[rule.Target.Name] {
+			return nil, fmt.Errorf("instance %s does not depend on instance %s", instance, rule.Target.Name)
+		}
+	}
+
+	artifacts := []interface{}{vs}
+	var unusedDestinationRules []string
+	for _, host := range hosts {
+		name := policies.GetResilienceDestinationRuleName(host)
+		existing, err := getDestinationRule(cli, name)
+		if err != nil {
+			return nil, err
+		}
+		var sources []string
+		var owners []kubernetes.OwnerReference
+		if existing != nil {
+			// keeping the other instances sharing the destination rule
+			if sources, err = liveResilienceSources(cli, existing, instance); err != nil {
+				return nil, err
+			}
+			for _, existingOwner := range existing.Metadata.OwnerReferences {
+				if util.ContainsInStringArray(sources, existingOwner.Name) {
+					owners = append(owners, existingOwner)
+				}
+			}
+		}
+		dependencyPolicy := dependencyPolicies[hostDependencies[host]]
+		if dependencyPolicy == nil || (dependencyPolicy.OutlierDetection == nil &&
+			dependencyPolicy.ConnectionPool == nil) {
+			if existing == nil || (len(sources) == len(resilienceSources(existing)) &&
+				len(owners) == len(existing.Metadata.OwnerReferences)) {
+				continue
+			}
+			if len(sources) == 0 {
+				unusedDestinationRules = append(unusedDestinationRules, name)
+			} else {
+				artifacts = append(artifacts, newResilienceDestinationRule(name, host, sources, owners,
+					existing.Spec.TrafficPolicy))
+			}
+			continue
+		}
+		trafficPolicy := newTrafficPolicy(dependencyPolicy)
+		if len(sources) > 0 && !reflect.DeepEqual(trafficPolicy, existing.Spec.TrafficPolicy) {
+			return nil, fmt.Errorf("instance(s) %s apply different circuit breaking or connection pool settings to "+
+				"host %s, the settings of a host are shared by all the instances depending on it",
+				strings.Join(sources, ", "), host)
+		}
+		sources = append(sources, instance)
+		sort.Strings(sources)
+		if owner != nil {
+			// the destination rule is removed along with the last instance using it
+			owners = append(owners, *owner)
+		}
+		artifacts = append(artifacts, newResilienceDestinationRule(name, host, sources, owners, trafficPolicy))
+	}
+	var documents []string
+	for _, artifact := range artifacts {
+		content, err := yaml.Marshal(artifact)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, string(content))
+	}
+	if err := ioutil.WriteFile(artifactFile, []byte(strings.Join(documents, "---\n")), 0644); err != nil {
+		return nil, err
+	}
+	return unusedDestinationRules, nil
+}
+
+// GetResiliencePolicy reads the resilience policy applied to the dependencies of an instance
+func GetResiliencePolicy(cli cli.Cli, instance string) (*policies.ResiliencePolicy, error) {
+	vs, err := cli.KubeCli().GetVirtualService(getVsName(instance))
+	if err != nil {
+		return nil, fmt.Errorf("error getting the virtual service of instance %s, %v", instance, err)
+	}
+	policy := &policies.ResiliencePolicy{
+		Type: policies.PolicyTypeResilience,
+	}
+	dependencies := make(map[string]bool)
+	for _, httpRule := range vs.VsSpec.HTTP {
+		dependency := addressedInstance(&httpRule)
+		if dependency == "" || dependencies[dependency] {
+			continue
+		}
+		dependencies[dependency] = true
+		rule := policies.ResilienceRule{
+			Target: policies.Target{
+				Type: policies.DependencyTargetType,
+				Name: dependency,
+			},
+			Policy: policies.DependencyPolicy{
+				Timeout: httpRule.Timeout,
+			},
+		}
+		if httpRule.Retries != nil {
+			rule.Policy.Retries = &policies.Retries{
+				Attempts:      httpRule.Retries.Attempts,
+				PerTryTimeout: httpRule.Retries.PerTryTimeout,
+				RetryOn:       httpRule.Retries.RetryOn,
+			}
+		}
+		// the same traffic policy is applied to all the service hosts of a dependency
+		for _, route := range httpRule.Route {
+			destinationRule, err := getDestinationRule(cli,
+				policies.GetResilienceDestinationRuleName(route.Destination.Host))
+			if err != nil {
+				return nil, err
+			}
+			if destinationRule != nil && util.ContainsInStringArray(resilienceSources(destinationRule), instance) {
+				setTrafficPolicy(&rule.Policy, &destinationRule.Spec.TrafficPolicy)
+				break
+			}
+		}
+		policy.Rules = append(policy.Rules, rule)
+	}
+	return policy, nil
+}
+
+func newResilienceDestinationRule(name string, host string, sources []string, owners []kubernetes.OwnerReference,
+	trafficPolicy kubernetes.TrafficPolicy) kubernetes.DestinationRule {
+	return kubernetes.DestinationRule{
+		Kind:       policies.DestinationRuleKind,
+		APIVersion: policies.IstioNetworkingApiVersion,
+		Metadata: kubernetes.DestinationRuleMeta{
+			Name: name,
+			Labels: map[string]string{
+				policies.ResiliencePolicyLabel: "true",
+			},
+			Annotations: map[string]string{
+				policies.ResiliencePolicySourcesAnnotation: strings.Join(sources, ","),
+			},
+			OwnerReferences: owners,
+		},
+		Spec: kubernetes.DestinationRuleSpec{
+			Host:          host,
+			TrafficPolicy: trafficPolicy,
+		},
+	}
+}
+
+// resilienceSources returns the instances applying their resilience policies through a destination rule
+func resilienceSources(destinationRule *kubernetes.DestinationRule) []string {
+	sources := destinationRule.Metadata.Annotations[policies.ResiliencePolicySourcesAnnotation]
+	if sources == "" {
+		return nil
+	}
+	return strings.Split(sources, ",")
+}
+
+// liveResilienceSources returns the instances other than the given instance applying their resilience policies
+// through a destination rule. The instances which no longer own the destination rule or no longer exist are
+// dropped, since the annotation is not updated when an instance is terminated.
+func liveResilienceSources(cli cli.Cli, destinationRule *kubernetes.DestinationRule, instance string) ([]string,
+	error) {
+	var sources []string
+	for _, source := range resilienceSources(destinationRule) {
+		if source == instance {
+			continue
+		}
+		owned := false
+		for _, owner := range destinationRule.Metadata.OwnerReferences {
+			owned = owned || owner.Name == source
+		}
+		if !owned {
+			continue
+		}
+		exists, err := instanceExists(cli, source)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+// instanceExists checks whether a cell or a composite instance exists
+func instanceExists(cli cli.Cli, instance string) (bool, error) {
+	_, err := cli.KubeCli().GetCell(instance)
+	if err == nil {
+		return true, nil
+	}
+	if notFound, _ := errorpkg.IsCellInstanceNotFoundError(instance, err); !notFound {
+		return false, fmt.Errorf("error checking whether instance %s exists, %v", instance, err)
+	}
+	_, err = cli.KubeCli().GetComposite(instance)
+	if err == nil {
+		return true, nil
+	}
+	if notFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instance, err); !notFound {
+		return false, fmt.Errorf("error checking whether instance %s exists, %v", instance, err)
+	}
+	return false, nil
+}
+
+func newTrafficPolicy(dependencyPolicy *policies.DependencyPolicy) kubernetes.TrafficPolicy {
+	trafficPolicy := kubernetes.TrafficPolicy{}
+	if outlierDetection := dependencyPolicy.OutlierDetection; outlierDetection != nil {
+		trafficPolicy.OutlierDetection = &kubernetes.OutlierDetection{
+			ConsecutiveErrors:  outlierDetection.ConsecutiveErrors,
+			Interval:           formatDuration(outlierDetection.Interval),
+			BaseEjectionTime:   formatDuration(outlierDetection.BaseEjectionTime),
+			MaxEjectionPercent: outlierDetection.MaxEjectionPercent,
+		}
+	}
+	if connectionPool := dependencyPolicy.ConnectionPool; connectionPool != nil {
+		pool := &kubernetes.ConnectionPool{}
+		if connectionPool.MaxConnections > 0 {
+			pool.Tcp = &kubernetes.TCPConnectionPool{
+				MaxConnections: connectionPool.MaxConnections,
+			}
+		}
+		if connectionPool.MaxPendingRequests > 0 || connectionPool.MaxRequestsPerConnection > 0 {
+			pool.Http = &kubernetes.HTTPConnectionPool{
+				Http1MaxPendingRequests:  connectionPool.MaxPendingRequests,
+				MaxRequestsPerConnection: connectionPool.MaxRequestsPerConnection,
+			}
+		}
+		trafficPolicy.ConnectionPool = pool
+	}
+	return trafficPolicy
+}
+
+func setTrafficPolicy(dependencyPolicy *policies.DependencyPolicy, trafficPolicy *kubernetes.TrafficPolicy) {
+	if outlierDetection := trafficPolicy.OutlierDetection; outlierDetection != nil {
+		dependencyPolicy.OutlierDetection = &policies.OutlierDetection{
+			ConsecutiveErrors:  outlierDetection.ConsecutiveErrors,
+			Interval:           outlierDetection.Interval,
+			BaseEjectionTime:   outlierDetection.BaseEjectionTime,
+			MaxEjectionPercent: outlierDetection.MaxEjectionPercent,
+		}
+	}
+	if connectionPool := trafficPolicy.ConnectionPool; connectionPool != nil {
+		dependencyPolicy.ConnectionPool = &policies.ConnectionPool{}
+		if connectionPool.Tcp != nil {
+			dependencyPolicy.ConnectionPool.MaxConnections = connectionPool.Tcp.MaxConnections
+		}
+		if connectionPool.Http != nil {
+			dependencyPolicy.ConnectionPool.MaxPendingRequests = connectionPool.Http.Http1MaxPendingRequests
+			dependencyPolicy.ConnectionPool.MaxRequestsPerConnection = connectionPool.Http.MaxRequestsPerConnection
+		}
+	}
+}
+
+func getDestinationRule(cli cli.Cli, name string) (*kubernetes.DestinationRule, error) {
+	out, err := cli.KubeCli().GetInstanceBytes(policies.DestinationRuleResourceKind, name)
+	if err != nil {
+		if _, ok := err.(errorpkg.NotFoundError); ok || strings.Contains(err.Error(), "NotFound") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting destination rule %s, %v", name, err)
+	}
+	destinationRule := &kubernetes.DestinationRule{}
+	if err := json.Unmarshal(out, destinationRule); err != nil {
+		return nil, fmt.Errorf("error reading destination rule %s, %v", name, err)
+	}
+	return destinationRule, nil
+}
+
+// formatDuration formats a duration in seconds as expected by Istio (Ex: 1m30s is formatted as 90s)
+func formatDuration(duration string) string {
+	if duration == "" {
+		return ""
+	}
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		// the durations are validated along with the policy
+		return duration
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// addressedInstance returns the instance addressed by the requests matched by a http rule
+func addressedInstance(httpRule *kubernetes.HTTP) string {
+	for _, match := range httpRule.Match {
+		if host := hostOfAuthority(match.Authority.Regex); host != "" {
+			return instanceOfHost(host)
+		}
+	}
+	if len(httpRule.Route) > 0 {
+		return instanceOfHost(httpRule.Route[0].Destination.Host)
+	}
+	return ""
+}
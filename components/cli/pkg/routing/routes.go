@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+const httpProtocol = "HTTP"
+const tcpProtocol = "TCP"
+
+// RouteRule is a rule in the virtual service of a source instance which routes the traffic addressed to a dependency
+type RouteRule struct {
+	Source     string
+	Dependency string
+	Host       string
+	Protocol   string
+	Conditions []string
+	Targets    []RouteTarget
+	Mirror     *RouteTarget
+}
+
+// RouteTarget is an instance receiving a percentage of the traffic of a route rule
+type RouteTarget struct {
+	Instance string
+	Host     string
+	Weight   int
+}
+
+// GetRouteRules returns the rules of the cell and composite instances which address the given instance, or route
+// traffic to it
+func GetRouteRules(cli cli.Cli, instanceName string) ([]RouteRule, error) {
+	var sources []string
+	cells, err := cli.KubeCli().GetCells()
+	if err != nil {
+		return nil, err
+	}
+	for _, cell := range cells {
+		if cell.CellMetaData.Annotations.Dependencies != "" {
+			sources = append(sources, cell.CellMetaData.Name)
+		}
+	}
+	composites, err := cli.KubeCli().GetComposites()
+	if err != nil {
+		return nil, err
+	}
+	for _, composite := range composites {
+		if composite.CompositeMetaData.Annotations.Dependencies != "" {
+			sources = append(sources, composite.CompositeMetaData.Name)
+		}
+	}
+	var rules []RouteRule
+	for _, source := range sources {
+		vs, err := cli.KubeCli().GetVirtualService(getVsName(source))
+		if err != nil {
+			return nil, fmt.Errorf("error getting the virtual service of instance %s, %v", source, err)
+		}
+		for _, rule := range getRouteRules(source, &vs) {
+			if rule.references(instanceName) {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func getRouteRules(source string, vs *kubernetes.VirtualService) []RouteRule {
+	var rules []RouteRule
+	for _, httpRule := range vs.VsSpec.HTTP {
+		rule := RouteRule{
+			Source:   source,
+			Protocol: httpProtocol,
+		}
+		for _, match := range httpRule.Match {
+			rule.Host = hostOfAuthority(match.Authority.Regex)
+			rule.Conditions = append(rule.Conditions, httpMatchConditions(&match)...)
+		}
+		for _, route := range httpRule.Route {
+			rule.Targets = append(rule.Targets, newRouteTarget(route.Destination.Host, route.Weight,
+				len(httpRule.Route)))
+		}
+		if httpRule.Mirror != nil {
+			mirror := newRouteTarget(httpRule.Mirror.Host, httpRule.MirrorPercent, 1)
+			rule.Mirror = &mirror
+		}
+		rule.setDependency()
+		rules = append(rules, rule)
+	}
+	for _, tcpRule := range vs.VsSpec.TCP {
+		rule := RouteRule{
+			Source:   source,
+			Protocol: tcpProtocol,
+		}
+		for _, match := range tcpRule.Match {
+			rule.Conditions = append(rule.Conditions, fmt.Sprintf("port=%d", match.Port))
+		}
+		for _, route := range tcpRule.Route {
+			rule.Targets = append(rule.Targets, newRouteTarget(route.Destination.Host, route.Weight,
+				len(tcpRule.Route)))
+		}
+		// TCP rules are matched by the port, hence the dependency is the first destination
+		if len(rule.Targets) > 0 {
+			rule.Host = rule.Targets[0].Host
+		}
+		rule.setDependency()
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func newRouteTarget(host string, weight int, routes int) RouteTarget {
+	// Istio routes all the traffic to the destination if the weight of the only destination is not set
+	if weight == 0 && routes == 1 {
+		weight = 100
+	}
+	return RouteTarget{
+		Instance: instanceOfHost(host),
+		Host:     host,
+		Weight:   weight,
+	}
+}
+
+func (rule *RouteRule) setDependency() {
+	if rule.Host == "" && len(rule.Targets) > 0 {
+		rule.Host = rule.Targets[0].Host
+	}
+	rule.Dependency = instanceOfHost(rule.Host)
+}
+
+func (rule *RouteRule) references(instanceName string) bool {
+	if rule.Dependency == instanceName {
+		return true
+	}
+	for _, target := range rule.Targets {
+		if target.Instance == instanceName {
+			return true
+		}
+	}
+	return rule.Mirror != nil && rule.Mirror.Instance == instanceName
+}
+
+func httpMatchConditions(match *kubernetes.HTTPMatch) []string {
+	var conditions []string
+	var headers []string
+	for name := range match.Headers {
+		headers = append(headers, name)
+	}
+	sort.Strings(headers)
+	for _, name := range headers {
+		conditions = append(conditions, stringMatchCondition(name, match.Headers[name]))
+	}
+	if match.Uri != nil {
+		conditions = append(conditions, stringMatchCondition("uri", match.Uri))
+	}
+	return conditions
+}
+
+func stringMatchCondition(name string, match *kubernetes.StringMatch) string {
+	if match.Prefix != "" {
+		return fmt.Sprintf("%s=%s*", name, match.Prefix)
+	}
+	if match.Regex != "" {
+		return fmt.Sprintf("%s~%s", name, match.Regex)
+	}
+	return fmt.Sprintf("%s=%s", name, match.Exact)
+}
+
+// hostOfAuthority returns the host matched by an authority regex, which is in the form ^(<instance>)(--<service>)(\S*)$
+func hostOfAuthority(authority string) string {
+	return strings.NewReplacer("^", "", "$", "", "(", "", ")", "", `\S*`, "").Replace(authority)
+}
+
+// instanceOfHost returns the instance of a service host in the form <instance>--<service>
+func instanceOfHost(host string) string {
+	return strings.SplitN(host, "--", 2)[0]
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package signature
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// PolicyFile is the name of the verification policy file in the Cellery home.
+const PolicyFile = "image-policy.json"
+
+// PolicyType is the requirement enforced on the cell images matching a policy.
+type PolicyType string
+
+const (
+	// PolicyAccept accepts the cell images without verifying signatures
+	PolicyAccept PolicyType = "accept"
+	// PolicyReject rejects the cell images
+	PolicyReject PolicyType = "reject"
+	// PolicySigned only accepts the cell images with a valid signature
+	PolicySigned PolicyType = "signed"
+)
+
+// Policy decides whether a cell image can be used based on its signature. The first image policy matching
+// the cell image is applied, and the default policy is applied with the default public keys and CA certificates
+// if none of them match.
+type Policy struct {
+	Default        PolicyType    `json:"default"`
+	PublicKeys     []string      `json:"publicKeys,omitempty"`
+	CACertificates []string      `json:"caCertificates,omitempty"`
+	Images         []ImagePolicy `json:"images"`
+}
+
+// ImagePolicy is the policy of the cell images with names (<organization>/<image>) matching the pattern.
+// Signatures are trusted if they are verified by one of the public keys, or if they carry a certificate
+// issued by one of the CA certificates.
+type ImagePolicy struct {
+	Match          string     `json:"match"`
+	Policy         PolicyType `json:"policy"`
+	PublicKeys     []string   `json:"publicKeys,omitempty"`
+	CACertificates []string   `json:"caCertificates,omitempty"`
+}
+
+// LoadPolicy reads the policy file. All the cell images are accepted if the policy file does not exist.
+// Relative paths of keys and certificates are resolved against the directory of the policy file.
+func LoadPolicy(file string) (*Policy, error) {
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return &Policy{Default: PolicyAccept}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading image verification policy %s, %v", file, err)
+	}
+	policy := &Policy{}
+	if err := json.Unmarshal(content, policy); err != nil {
+		return nil, fmt.Errorf("invalid image verification policy %s, %v", file, err)
+	}
+	if policy.Default == "" {
+		policy.Default = PolicyAccept
+	}
+	if err := validatePolicyType(policy.Default); err != nil {
+		return nil, fmt.Errorf("invalid image verification policy %s, %v", file, err)
+	}
+	if policy.Default == PolicySigned && len(policy.PublicKeys) == 0 && len(policy.CACertificates) == 0 {
+		return nil, fmt.Errorf("invalid image verification policy %s, the default policy requires signatures "+
+			"but does not trust any public keys or CA certificates", file)
+	}
+	policy.PublicKeys = resolvePaths(filepath.Dir(file), policy.PublicKeys)
+	policy.CACertificates = resolvePaths(filepath.Dir(file), policy.CACertificates)
+	for i, imagePolicy := range policy.Images {
+		if _, err := path.Match(imagePolicy.Match, ""); err != nil {
+			return nil, fmt.Errorf("invalid image verification policy %s, invalid pattern %s, %v", file,
+				imagePolicy.Match, err)
+		}
+		if err := validatePolicyType(imagePolicy.Policy); err != nil {
+			return nil, fmt.Errorf("invalid image verification policy %s, %v", file, err)
+		}
+		if imagePolicy.Policy == PolicySigned && len(imagePolicy.PublicKeys) == 0 &&
+			len(imagePolicy.CACertificates) == 0 {
+			return nil, fmt.Errorf("invalid image verification policy %s, the policy for %s requires signatures "+
+				"but does not trust any public keys or CA certificates", file, imagePolicy.Match)
+		}
+		policy.Images[i].PublicKeys = resolvePaths(filepath.Dir(file), imagePolicy.PublicKeys)
+		policy.Images[i].CACertificates = resolvePaths(filepath.Dir(file), imagePolicy.CACertificates)
+	}
+	return policy, nil
+}
+
+// Verify checks whether the cell image (<organization>/<image>:<version>) with the given digest is accepted by
+// the policy. The signature is nil if the cell image is not signed.
+func (p *Policy) Verify(image string, imageDigest digest.Digest, signature *Signature) error {
+	imagePolicy := p.find(image)
+	switch imagePolicy.Policy {
+	case PolicyAccept:
+		return nil
+	case PolicyReject:
+		return fmt.Errorf("image %s is rejected by the image verification policy", image)
+	}
+	if signature == nil {
+		return fmt.Errorf("image %s is not signed, a valid signature is required by the image verification "+
+			"policy", image)
+	}
+	if signature.Image != image || signature.Digest != imageDigest {
+		return fmt.Errorf("signature of image %s does not match the image, the signature was created for %s@%s",
+			image, signature.Image, signature.Digest)
+	}
+	var errors []string
+	for _, publicKeyFile := range imagePolicy.PublicKeys {
+		publicKey, err := LoadPublicKey(publicKeyFile)
+		if err != nil {
+			return err
+		}
+		if err := signature.verify(publicKey); err == nil {
+			return nil
+		}
+	}
+	if len(imagePolicy.PublicKeys) > 0 {
+		errors = append(errors, "not signed by any of the trusted keys")
+	}
+	if len(imagePolicy.CACertificates) > 0 {
+		publicKey, err := verifyCertificate(signature, imagePolicy.CACertificates)
+		if err == nil {
+			if err = signature.verify(publicKey); err == nil {
+				return nil
+			}
+		}
+		errors = append(errors, err.Error())
+	}
+	return fmt.Errorf("invalid signature of image %s, %s", image, strings.Join(errors, ", "))
+}
+
+// find returns the first image policy matching the image, or the default policy.
+func (p *Policy) find(image string) ImagePolicy {
+	name := strings.Split(image, ":")[0]
+	for _, imagePolicy := range p.Images {
+		if matched, _ := path.Match(imagePolicy.Match, name); matched {
+			return imagePolicy
+		}
+	}
+	return ImagePolicy{Match: "*", Policy: p.Default, PublicKeys: p.PublicKeys, CACertificates: p.CACertificates}
+}
+
+// verifyCertificate verifies the certificate of the signature against the CA certificates and returns the
+// public key of the signer.
+func verifyCertificate(signature *Signature, caCertificateFiles []string) (crypto.PublicKey, error) {
+	if signature.Certificate == "" {
+		return nil, fmt.Errorf("signature does not carry a certificate")
+	}
+	certificate, err := signature.certificate()
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	for _, file := range caCertificateFiles {
+		caCertificates, err := LoadCertificates(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, caCertificate := range caCertificates {
+			roots.AddCert(caCertificate)
+		}
+	}
+	if _, err := certificate.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, fmt.Errorf("untrusted certificate, %v", err)
+	}
+	return certificate.PublicKey, nil
+}
+
+func validatePolicyType(policyType PolicyType) error {
+	switch policyType {
+	case PolicyAccept, PolicyReject, PolicySigned:
+		return nil
+	default:
+		return fmt.Errorf("unsupported policy %s, expected one of %s|%s|%s", policyType, PolicyAccept,
+			PolicyReject, PolicySigned)
+	}
+}
+
+func resolvePaths(dir string, files []string) []string {
+	var resolved []string
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		resolved = append(resolved, file)
+	}
+	return resolved
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// FileExt is the extension of the detached signature stored next to the cell image in the local repository.
+const FileExt = ".sig"
+
+// Signature is a detached signature of a cell image. The signature covers the name and the digest of the
+// cell image zip, hence an image cannot be swapped with another image signed by the same key.
+type Signature struct {
+	Image     string        `json:"image"`
+	Digest    digest.Digest `json:"digest"`
+	Timestamp int64         `json:"timestamp"`
+	Signature []byte        `json:"signature"`
+	// Certificate is the PEM encoded x509 certificate of the signer, only available for x509 signatures
+	Certificate string `json:"certificate,omitempty"`
+}
+
+// Digest computes the digest of a cell image.
+func Digest(cellImage io.Reader) (digest.Digest, error) {
+	return digest.SHA256.FromReader(cellImage)
+}
+
+// Sign creates a signature of the cell image with the given name (<organization>/<image>:<version>) and digest.
+// If a certificate is provided, it should hold the public key of the signer and is added to the signature.
+func Sign(image string, imageDigest digest.Digest, signer crypto.Signer,
+	certificate *x509.Certificate) (*Signature, error) {
+	signature := &Signature{
+		Image:     image,
+		Digest:    imageDigest,
+		Timestamp: time.Now().Unix(),
+	}
+	if certificate != nil {
+		if !samePublicKey(certificate.PublicKey, signer.Public()) {
+			return nil, fmt.Errorf("the certificate does not match the signing key")
+		}
+		signature.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+			Bytes: certificate.Raw}))
+	}
+	var err error
+	payload := signature.payload()
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		signature.Signature, err = signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	} else {
+		hash := sha256.Sum256(payload)
+		signature.Signature, err = signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error signing the cell image, %v", err)
+	}
+	return signature, nil
+}
+
+// Parse reads a signature in its serialized form.
+func Parse(content []byte) (*Signature, error) {
+	signature := &Signature{}
+	if err := json.Unmarshal(content, signature); err != nil {
+		return nil, fmt.Errorf("invalid cell image signature, %v", err)
+	}
+	return signature, nil
+}
+
+// Bytes returns the signature in its serialized form.
+func (s *Signature) Bytes() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// payload returns the content covered by the signature.
+func (s *Signature) payload() []byte {
+	return []byte(fmt.Sprintf("cellery-image-signature-v1\n%s\n%s\n%d", s.Image, s.Digest, s.Timestamp))
+}
+
+// verify checks the signature against the public key.
+func (s *Signature) verify(publicKey crypto.PublicKey) error {
+	payload := s.payload()
+	hash := sha256.Sum256(payload)
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], s.Signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], s.Signature) {
+			return fmt.Errorf("invalid ECDSA signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, s.Signature) {
+			return fmt.Errorf("invalid Ed25519 signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// certificate returns the x509 certificate of the signer.
+func (s *Signature) certificate() (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(s.Certificate))
+	if block == nil {
+		return nil, fmt.Errorf("invalid certificate in the signature")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// LoadPrivateKey reads a PEM encoded PKCS#8, PKCS#1 (RSA) or SEC 1 (EC) private key.
+func LoadPrivateKey(file string) (crypto.Signer, error) {
+	block, err := readPem(file)
+	if err != nil {
+		return nil, err
+	}
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %s in %s", block.Type, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key %s, %v", file, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key %s", file)
+	}
+	return signer, nil
+}
+
+// LoadPublicKey reads a PEM encoded PKIX public key. The public key of a PEM encoded certificate is
+// returned if the file holds a certificate.
+func LoadPublicKey(file string) (crypto.PublicKey, error) {
+	block, err := readPem(file)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s, %v", file, err)
+		}
+		return key, nil
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate %s, %v", file, err)
+		}
+		return certificate.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %s in %s", block.Type, file)
+	}
+}
+
+// LoadCertificates reads the PEM encoded x509 certificates in the file.
+func LoadCertificates(file string) ([]*x509.Certificate, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s, %v", file, err)
+	}
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in %s, %v", file, err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return certificates, nil
+}
+
+func readPem(file string) (*pem.Block, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s, %v", file, err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded key found in %s", file)
+	}
+	return block, nil
+}
+
+func samePublicKey(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(b)
+}
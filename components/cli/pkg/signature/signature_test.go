@@ -0,0 +1,264 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/opencontainers/go-digest"
+)
+
+const testImage = "myorg/hello:1.0.0"
+
+var testDigest = digest.FromString("cell image")
+
+func TestSignAndVerify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	tests := []struct {
+		name string
+		key  crypto.Signer
+	}{
+		{
+			name: "rsa key",
+			key:  rsaKey,
+		},
+		{
+			name: "ecdsa key",
+			key:  ecdsaKey,
+		},
+		{
+			name: "ed25519 key",
+			key:  ed25519Key,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			signature, err := Sign(testImage, testDigest, tst.key, nil)
+			if err != nil {
+				t.Fatalf("error in Sign, %v", err)
+			}
+			content, err := signature.Bytes()
+			if err != nil {
+				t.Fatalf("error serializing the signature, %v", err)
+			}
+			parsed, err := Parse(content)
+			if err != nil {
+				t.Fatalf("error in Parse, %v", err)
+			}
+			if err := parsed.verify(tst.key.Public()); err != nil {
+				t.Errorf("verify: unexpected error, %v", err)
+			}
+			parsed.Image = "myorg/hello:2.0.0"
+			if err := parsed.verify(tst.key.Public()); err == nil {
+				t.Errorf("verify: expected an error for a signature of a different image")
+			}
+		})
+	}
+}
+
+func TestPolicyVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signature-policy")
+	if err != nil {
+		t.Fatalf("error creating temp dir, %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	trustedKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	untrustedKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	writePublicKey(t, filepath.Join(dir, "trusted.pub"), trustedKey.Public())
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caCertificate := createCertificate(t, "Cellery CA", caKey, nil, caKey)
+	writeCertificate(t, filepath.Join(dir, "ca.crt"), caCertificate)
+	signerKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signerCertificate := createCertificate(t, "signer", signerKey, caCertificate, caKey)
+	selfSignedCertificate := createCertificate(t, "signer", signerKey, nil, signerKey)
+
+	policyFile := filepath.Join(dir, PolicyFile)
+	err = ioutil.WriteFile(policyFile, []byte(`{
+  "default": "reject",
+  "images": [
+    {"match": "myorg/*", "policy": "signed", "publicKeys": ["trusted.pub"]},
+    {"match": "x509org/*", "policy": "signed", "caCertificates": ["ca.crt"]},
+    {"match": "public/*", "policy": "accept"}
+  ]
+}`), 0644)
+	if err != nil {
+		t.Fatalf("error writing the policy, %v", err)
+	}
+	policy, err := LoadPolicy(policyFile)
+	if err != nil {
+		t.Fatalf("error in LoadPolicy, %v", err)
+	}
+
+	sign := func(image string, key crypto.Signer, certificate *x509.Certificate) *Signature {
+		signature, err := Sign(image, testDigest, key, certificate)
+		if err != nil {
+			t.Fatalf("error in Sign, %v", err)
+		}
+		return signature
+	}
+	tests := []struct {
+		name          string
+		image         string
+		signature     *Signature
+		expectedError string
+	}{
+		{
+			name:      "image signed by trusted key",
+			image:     testImage,
+			signature: sign(testImage, trustedKey, nil),
+		},
+		{
+			name:          "image signed by untrusted key",
+			image:         testImage,
+			signature:     sign(testImage, untrustedKey, nil),
+			expectedError: "not signed by any of the trusted keys",
+		},
+		{
+			name:          "unsigned image",
+			image:         testImage,
+			expectedError: "image myorg/hello:1.0.0 is not signed",
+		},
+		{
+			name:          "signature of another image",
+			image:         "myorg/hello:2.0.0",
+			signature:     sign(testImage, trustedKey, nil),
+			expectedError: "does not match the image",
+		},
+		{
+			name:      "image signed by certificate issued by trusted CA",
+			image:     "x509org/hello:1.0.0",
+			signature: sign("x509org/hello:1.0.0", signerKey, signerCertificate),
+		},
+		{
+			name:          "image signed by self signed certificate",
+			image:         "x509org/hello:1.0.0",
+			signature:     sign("x509org/hello:1.0.0", signerKey, selfSignedCertificate),
+			expectedError: "untrusted certificate",
+		},
+		{
+			name:  "unsigned image accepted by policy",
+			image: "public/hello:1.0.0",
+		},
+		{
+			name:          "image rejected by default policy",
+			image:         "other/hello:1.0.0",
+			expectedError: "rejected by the image verification policy",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := policy.Verify(tst.image, testDigest, tst.signature)
+			if tst.expectedError == "" {
+				if err != nil {
+					t.Errorf("Verify: unexpected error, %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tst.expectedError) {
+				t.Errorf("Verify: expected error containing %q, got %v", tst.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	policy, err := LoadPolicy(filepath.Join("testdata", "missing", PolicyFile))
+	if err != nil {
+		t.Fatalf("error in LoadPolicy, %v", err)
+	}
+	if diff := cmp.Diff(&Policy{Default: PolicyAccept}, policy); diff != "" {
+		t.Errorf("LoadPolicy: unexpected policy for a missing policy file (-want, +got)\n%v", diff)
+	}
+
+	dir, err := ioutil.TempDir("", "signature-policy")
+	if err != nil {
+		t.Fatalf("error creating temp dir, %v", err)
+	}
+	defer os.RemoveAll(dir)
+	policyFile := filepath.Join(dir, PolicyFile)
+	ioutil.WriteFile(policyFile, []byte(`{"images": [{"match": "myorg/*", "policy": "signed"}]}`), 0644)
+	if _, err := LoadPolicy(policyFile); err == nil {
+		t.Errorf("LoadPolicy: expected an error for a signed policy without trusted keys")
+	}
+	ioutil.WriteFile(policyFile, []byte(`{"default": "signed"}`), 0644)
+	if _, err := LoadPolicy(policyFile); err == nil {
+		t.Errorf("LoadPolicy: expected an error for a signed default policy without trusted keys")
+	}
+
+	ioutil.WriteFile(policyFile, []byte(`{"default": "signed", "publicKeys": ["trusted.pub"]}`), 0644)
+	policy, err = LoadPolicy(policyFile)
+	if err != nil {
+		t.Fatalf("error in LoadPolicy, %v", err)
+	}
+	expected := ImagePolicy{Match: "*", Policy: PolicySigned, PublicKeys: []string{filepath.Join(dir, "trusted.pub")}}
+	if diff := cmp.Diff(expected, policy.find("myorg/hello:1.0.0")); diff != "" {
+		t.Errorf("LoadPolicy: unexpected default policy (-want, +got)\n%v", diff)
+	}
+}
+
+func writePublicKey(t *testing.T, file string, key crypto.PublicKey) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("error marshalling public key, %v", err)
+	}
+	ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644)
+}
+
+func writeCertificate(t *testing.T, file string, certificate *x509.Certificate) {
+	ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}), 0644)
+}
+
+func createCertificate(t *testing.T, commonName string, key crypto.Signer, issuer *x509.Certificate,
+	issuerKey crypto.Signer) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  issuer == nil,
+	}
+	if issuer == nil {
+		issuer = template
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), issuerKey)
+	if err != nil {
+		t.Fatalf("error creating certificate, %v", err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+	return certificate
+}
@@ -338,6 +338,21 @@ func GetFileSize(path string) (int64, error) {
 	return file.Size(), nil
 }
 
+// GetDirSize returns the total size of the files in a directory and its sub directories
+func GetDirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
 func RenameFile(oldName, newName string) error {
 	err := os.Rename(oldName, newName)
 	if err != nil {
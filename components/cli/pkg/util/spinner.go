@@ -21,11 +21,15 @@ package util
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/tj/go-spin"
 )
 
+const progressBarWidth = 30
+
 // StartNewSpinner starts a new spinner with the provided message
 func StartNewSpinner(action string) *Spinner {
 	newSpinner := &Spinner{
@@ -55,6 +59,14 @@ func (s *Spinner) SetNewAction(action string) {
 	s.mux.Unlock()
 }
 
+// SetProgress sets the progress shown next to the current action of a spinner
+func (s *Spinner) SetProgress(transferred, total int64) {
+	s.mux.Lock()
+	s.progress = FormatProgress(transferred, total)
+	s.spin()
+	s.mux.Unlock()
+}
+
 // Pause the spinner and clear the line
 func (s *Spinner) Pause() {
 	s.mux.Lock()
@@ -81,6 +93,23 @@ func (s *Spinner) Stop(isSuccess bool) {
 	s.mux.Unlock()
 }
 
+// FormatProgress returns a progress bar for the transferred amount of bytes
+func FormatProgress(transferred, total int64) string {
+	if total <= 0 {
+		return units.HumanSize(float64(transferred))
+	}
+	if transferred > total {
+		transferred = total
+	}
+	completed := int(transferred * progressBarWidth / total)
+	bar := strings.Repeat("=", completed)
+	if completed < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-completed-1)
+	}
+	return fmt.Sprintf("[%s] %3d%% %s/%s", bar, transferred*100/total, units.HumanSize(float64(transferred)),
+		units.HumanSize(float64(total)))
+}
+
 // spin causes the spinner to do one spin
 func (s *Spinner) spin() {
 	if s.isRunning && s.isSpinning {
@@ -95,9 +124,14 @@ func (s *Spinner) spin() {
 				fmt.Printf("\r\x1b[2K%s %s\n", icon, s.previousAction)
 			}
 			s.previousAction = s.action
+			s.progress = ""
 		}
 		if s.action != "" {
-			fmt.Printf("\r\x1b[2K\033[36m%s\033[m %s", s.core.Next(), s.action)
+			if s.progress != "" {
+				fmt.Printf("\r\x1b[2K\033[36m%s\033[m %s %s", s.core.Next(), s.action, s.progress)
+			} else {
+				fmt.Printf("\r\x1b[2K\033[36m%s\033[m %s", s.core.Next(), s.action)
+			}
 		}
 	}
 }
@@ -29,6 +29,7 @@ type Spinner struct {
 	core           *spin.Spinner
 	action         string
 	previousAction string
+	progress       string
 	isRunning      bool
 	isSpinning     bool
 	error          bool
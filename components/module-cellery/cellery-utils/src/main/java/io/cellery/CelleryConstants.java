@@ -88,6 +88,7 @@ public class CelleryConstants {
     public static final String DEFAULT_GATEWAY_PROTOCOL = "http";
     public static final String DEFAULT_PARAMETER_VALUE = "";
     public static final String CELLERY_IMAGE_DIR_ENV_VAR = "CELLERY_IMAGE_DIR";
+    public static final String CELLERY_DRY_RUN_DIR_ENV_VAR = "CELLERY_DRY_RUN_DIR";
     public static final String TEST_MODULE_ENV_VAR = "TEST_MODULE";
     public static final String GATEWAY_SERVICE = "--gateway-service";
     public static final String INSTANCE_NAME_PLACEHOLDER = "{{instance_name}}";
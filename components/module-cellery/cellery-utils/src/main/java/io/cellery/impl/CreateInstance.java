@@ -261,6 +261,13 @@ public class CreateInstance {
             // Update cell yaml with instance name
             composite.getMetadata().setName(instanceName);
             writeToFile(toYaml(composite), cellYAMLPath);
+            String dryRunDir = System.getenv(CelleryConstants.CELLERY_DRY_RUN_DIR_ENV_VAR);
+            if (StringUtils.isNotEmpty(dryRunDir)) {
+                // Write the resolved yaml of the instance instead of applying it
+                writeToFile(toYaml(composite), dryRunDir + File.separator + instanceName + CelleryConstants.YAML);
+                printInfo("Rendered instance " + instanceName);
+                return bValueArray;
+            }
             // Apply yaml file of the instance
             KubernetesClient.apply(cellYAMLPath);
             KubernetesClient.waitFor("Ready", 30 * 60, instanceArg);
@@ -310,6 +317,11 @@ public class CreateInstance {
                         Base64.encodeBase64String(web.getTlsCert().getBytes(StandardCharsets.UTF_8)));
                 String tlsSecretName = instanceName + "--tls-secret";
                 createSecret(tlsSecretName, tlsMap, destinationPath + File.separator + tlsSecretName + ".yaml");
+                String dryRunDir = System.getenv(CelleryConstants.CELLERY_DRY_RUN_DIR_ENV_VAR);
+                if (StringUtils.isNotEmpty(dryRunDir)) {
+                    createSecret(tlsSecretName, tlsMap,
+                            dryRunDir + File.separator + tlsSecretName + CelleryConstants.YAML);
+                }
                 gatewaySpec.getIngress().getExtensions().getClusterIngress().getTls().setSecret(tlsSecretName);
             }
             // Set OIDC values